@@ -0,0 +1,75 @@
+package redka
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/nalgeon/redka/internal/sqlx"
+)
+
+// Backup writes a consistent snapshot of the database
+// to a file at the given path, replacing the existing file.
+// The snapshot is a regular SQLite database, so you can
+// open it with [Open] directly.
+//
+// Backup does not block the writers. It writes to a temporary
+// file first, and renames it to path only when it's complete,
+// so path always contains either the old or the new snapshot.
+//
+// With SQLite, uses VACUUM INTO. With other databases, calls
+// [Options.Backup] if set, or returns [ErrNotSupported].
+func (db *DB) Backup(ctx context.Context, path string) error {
+	if db.backup != nil {
+		return db.backup(ctx, path)
+	}
+	if db.sdb.Dialect != sqlx.DialectSqlite {
+		return ErrNotSupported
+	}
+
+	// VACUUM INTO requires the target file
+	// to either not exist or be empty.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	defer os.Remove(tmpPath)
+
+	_, err = db.sdb.RO.ExecContext(ctx, "vacuum into ?", backupTarget(tmpPath, db.inMemory))
+	if err != nil {
+		return fmt.Errorf("vacuum into: %w", err)
+	}
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
+
+// backupTarget returns the VACUUM INTO target for the file path.
+// VACUUM INTO writes the file using the same VFS as the source
+// database, so for in-memory databases the target must
+// explicitly set the default OS VFS to end up on disk.
+func backupTarget(path string, inMemory bool) string {
+	if !inMemory {
+		return path
+	}
+	vfs := "unix"
+	if runtime.GOOS == "windows" {
+		vfs = "win32"
+	}
+	path, _ = filepath.Abs(path)
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths start with a drive letter.
+		path = "/" + path
+	}
+	uri := url.URL{Scheme: "file", Path: path, RawQuery: "vfs=" + vfs}
+	return uri.String()
+}
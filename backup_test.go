@@ -0,0 +1,57 @@
+package redka_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestBackup(t *testing.T) {
+	t.Run("backup", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.List().PushBack("list", "a")
+
+		path := filepath.Join(t.TempDir(), "backup.db")
+		err := db.Backup(context.Background(), path)
+		if errors.Is(err, redka.ErrNotSupported) {
+			t.Skip("backup is not supported")
+		}
+		be.Err(t, err, nil)
+
+		// The backup can be opened as a regular database.
+		bdb, err := redka.Open(path, &redka.Options{DriverName: "sqlite3"})
+		be.Err(t, err, nil)
+		defer bdb.Close()
+		name, _ := bdb.Str().Get("name")
+		be.Equal(t, name.String(), "alice")
+		list, _ := bdb.List().Range("list", 0, -1)
+		be.Equal(t, len(list), 1)
+
+		// The next backup replaces the previous one.
+		_ = db.Str().Set("name", "bob")
+		err = db.Backup(context.Background(), path)
+		be.Err(t, err, nil)
+		bdb, err = redka.Open(path, &redka.Options{DriverName: "sqlite3"})
+		be.Err(t, err, nil)
+		defer bdb.Close()
+		name, _ = bdb.Str().Get("name")
+		be.Equal(t, name.String(), "bob")
+	})
+	t.Run("custom", func(t *testing.T) {
+		var got string
+		opts := redka.Options{Backup: func(ctx context.Context, path string) error {
+			got = path
+			return nil
+		}}
+		db := testx.OpenDBWith(t, &opts)
+		err := db.Backup(context.Background(), "backup.db")
+		be.Err(t, err, nil)
+		be.Equal(t, got, "backup.db")
+	})
+}
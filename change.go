@@ -0,0 +1,65 @@
+package redka
+
+import "github.com/nalgeon/redka/internal/core"
+
+// ChangeEvent describes a committed change to a key.
+//
+// Op is the name of the operation that changed the key,
+// same as the keyspace notification event name
+// (e.g. "set", "hset", "lpush", "del", "expire" or "expired").
+//
+// OldVersion and NewVersion are the key versions before
+// and after the change (see [Key.Version]). OldVersion is 0
+// if the key did not exist, NewVersion is 0 if the key
+// was deleted.
+//
+// Deleting all keys (FLUSHDB) produces a single change
+// with an empty key and the "flushdb" operation.
+type ChangeEvent struct {
+	Key        string
+	Type       TypeID
+	Op         string
+	OldVersion int
+	NewVersion int
+}
+
+// OnChange registers a function that receives the changes
+// made by the write operations, both by the repository calls
+// (like db.Str().Set) and by the transactions (like db.Update).
+// Returns a function that unregisters the hook.
+//
+// Changes are delivered after the transaction commits, in the
+// order they were made. Changes of rolled back transactions
+// are never delivered. The function is called synchronously
+// by the goroutine that committed the changes, so it should
+// not block or write to the database.
+//
+// Registered hooks make each write slightly slower,
+// because Redka has to look up the key versions.
+func (db *DB) OnChange(f func(ev ChangeEvent)) (cancel func()) {
+	return db.sdb.Listen(func(events []core.Event) {
+		for _, ev := range events {
+			f(ChangeEvent{
+				Key:        ev.Key,
+				Type:       ev.Type,
+				Op:         ev.Name,
+				OldVersion: ev.OldVersion,
+				NewVersion: ev.NewVersion,
+			})
+		}
+	})
+}
+
+// OnCommit registers a function that receives the number of changes
+// made by each committed write, either by a repository call or by a
+// transaction. Returns a function that unregisters the hook.
+//
+// Unlike [DB.OnChange], does not make the writes look up the key
+// versions, so it's cheaper. Use it when you only need to know
+// how much the database has changed (e.g. to schedule backups).
+// The function is called synchronously by the goroutine that
+// committed the changes, so it should not block or write
+// to the database.
+func (db *DB) OnCommit(f func(changes int)) (cancel func()) {
+	return db.sdb.ListenCommits(f)
+}
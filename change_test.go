@@ -0,0 +1,151 @@
+package redka_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestOnChange(t *testing.T) {
+	t.Run("repository", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		got := recordChanges(db)
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("name", "bob")
+		_, _ = db.Key().Delete("name")
+		be.Equal(t, got(), []redka.ChangeEvent{
+			{Key: "name", Type: redka.TypeString, Op: "set", OldVersion: 0, NewVersion: 1},
+			{Key: "name", Type: redka.TypeString, Op: "set", OldVersion: 1, NewVersion: 2},
+			{Key: "name", Type: redka.TypeString, Op: "del", OldVersion: 2, NewVersion: 0},
+		})
+	})
+	t.Run("transaction", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		got := recordChanges(db)
+
+		err := db.Update(func(tx *redka.Tx) error {
+			_, _ = tx.Hash().Set("person", "name", "alice")
+			_, _ = tx.Hash().Set("person", "age", 25)
+			be.Equal(t, len(got()), 0)
+			return nil
+		})
+		be.Err(t, err, nil)
+		be.Equal(t, got(), []redka.ChangeEvent{
+			{Key: "person", Type: redka.TypeHash, Op: "hset", OldVersion: 0, NewVersion: 1},
+			{Key: "person", Type: redka.TypeHash, Op: "hset", OldVersion: 1, NewVersion: 2},
+		})
+	})
+	t.Run("rollback", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		got := recordChanges(db)
+
+		var errRollback = errors.New("rollback")
+		err := db.Update(func(tx *redka.Tx) error {
+			_ = tx.Str().Set("name", "alice")
+			_, _ = tx.List().PushBack("queue", "task")
+			return errRollback
+		})
+		be.Equal(t, err, errRollback)
+		be.Equal(t, len(got()), 0)
+	})
+	t.Run("rename", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("user", "bob")
+		got := recordChanges(db)
+
+		err := db.Key().Rename("name", "user")
+		be.Err(t, err, nil)
+		be.Equal(t, got(), []redka.ChangeEvent{
+			{Key: "name", Type: redka.TypeString, Op: "rename_from", OldVersion: 1, NewVersion: 0},
+			{Key: "user", Type: redka.TypeString, Op: "rename_to", OldVersion: 1, NewVersion: 2},
+		})
+		user, _ := db.Key().Get("user")
+		be.Equal(t, user.Version, 2)
+	})
+	t.Run("cancel", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		var n int
+		cancel := db.OnChange(func(ev redka.ChangeEvent) { n++ })
+		_ = db.Str().Set("name", "alice")
+		cancel()
+		_ = db.Str().Set("name", "bob")
+		be.Equal(t, n, 1)
+	})
+}
+
+// recordChanges collects the database changes.
+func recordChanges(db *redka.DB) func() []redka.ChangeEvent {
+	var mu sync.Mutex
+	var events []redka.ChangeEvent
+	db.OnChange(func(ev redka.ChangeEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	})
+	return func() []redka.ChangeEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(events)
+	}
+}
+
+func TestOnCommit(t *testing.T) {
+	t.Run("repository", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		var changes []int
+		cancel := db.OnCommit(func(n int) { changes = append(changes, n) })
+
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.Key().Delete("name")
+		be.Equal(t, changes, []int{1, 1})
+
+		cancel()
+		_ = db.Str().Set("name", "bob")
+		be.Equal(t, changes, []int{1, 1})
+	})
+	t.Run("transaction", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		var changes []int
+		db.OnCommit(func(n int) { changes = append(changes, n) })
+
+		err := db.Update(func(tx *redka.Tx) error {
+			_ = tx.Str().Set("name", "alice")
+			_, _ = tx.List().PushBack("queue", "task")
+			return nil
+		})
+		be.Err(t, err, nil)
+		be.Equal(t, changes, []int{2})
+	})
+	t.Run("rollback", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		var changes []int
+		db.OnCommit(func(n int) { changes = append(changes, n) })
+
+		var errRollback = errors.New("rollback")
+		err := db.Update(func(tx *redka.Tx) error {
+			_ = tx.Str().Set("name", "alice")
+			return errRollback
+		})
+		be.Equal(t, err, errRollback)
+		be.Equal(t, len(changes), 0)
+	})
+	t.Run("with changes", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		var commits int
+		db.OnCommit(func(n int) { commits += n })
+		got := recordChanges(db)
+
+		_ = db.Str().Set("name", "alice")
+		be.Equal(t, commits, 1)
+		be.Equal(t, got(), []redka.ChangeEvent{
+			{Key: "name", Type: redka.TypeString, Op: "set", OldVersion: 0, NewVersion: 1},
+		})
+	})
+}
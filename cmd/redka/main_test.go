@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+)
+
+func TestLoadSnapshot(t *testing.T) {
+	t.Run("snapshot", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dump.db")
+		src := openMemDB(t, "file:/snapshot-src.db?vfs=memdb")
+		_ = src.Str().Set("name", "alice")
+		err := src.Backup(context.Background(), path)
+		be.Err(t, err, nil)
+
+		const dataSource = "file:/snapshot-dst.db?vfs=memdb"
+		mem, err := loadSnapshot(path, dataSource)
+		be.Err(t, err, nil)
+		be.True(t, mem != nil)
+		defer mem.Close()
+
+		db := openMemDB(t, dataSource)
+		name, err := db.Str().Get("name")
+		be.Err(t, err, nil)
+		be.Equal(t, name.String(), "alice")
+	})
+	t.Run("missing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dump.db")
+		mem, err := loadSnapshot(path, "file:/snapshot-missing.db?vfs=memdb")
+		be.Err(t, err, nil)
+		be.True(t, mem == nil)
+	})
+	t.Run("rdb file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dump.rdb")
+		src := openMemDB(t, "file:/snapshot-rdb.db?vfs=memdb")
+		_ = src.Str().Set("name", "alice")
+		f, err := os.Create(path)
+		be.Err(t, err, nil)
+		_, err = src.ExportRDB(f)
+		be.Err(t, err, nil)
+		_ = f.Close()
+
+		mem, err := loadSnapshot(path, "file:/snapshot-rdb-dst.db?vfs=memdb")
+		be.Err(t, err)
+		be.True(t, mem == nil)
+	})
+	t.Run("ndjson file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dump.ndjson")
+		src := openMemDB(t, "file:/snapshot-ndjson.db?vfs=memdb")
+		_ = src.Str().Set("name", "alice")
+		f, err := os.Create(path)
+		be.Err(t, err, nil)
+		_, err = src.ExportJSON(f, nil)
+		be.Err(t, err, nil)
+		_ = f.Close()
+
+		mem, err := loadSnapshot(path, "file:/snapshot-ndjson-dst.db?vfs=memdb")
+		be.Err(t, err)
+		be.True(t, mem == nil)
+	})
+	t.Run("file database", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dump.db")
+		mem, err := loadSnapshot(path, filepath.Join(t.TempDir(), "redka.db"))
+		be.Err(t, err, "snapshots require an in-memory database")
+		be.True(t, mem == nil)
+
+		// Same rules as the library (only the query params count).
+		mem, err = loadSnapshot(path, filepath.Join(t.TempDir(), "vfs=memdb.db"))
+		be.Err(t, err, "snapshots require an in-memory database")
+		be.True(t, mem == nil)
+	})
+}
+
+func TestMigrateDB(t *testing.T) {
+	t.Run("table prefix", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "redka.db")
+		err := migrateDB([]string{"-prefix", "cache_", path})
+		be.Err(t, err, nil)
+
+		db, err := sql.Open(sqliteDriverName, path)
+		be.Err(t, err, nil)
+		defer func() { _ = db.Close() }()
+		var n int
+		err = db.QueryRow("select count(*) from sqlite_schema where name = 'cache_rkey'").Scan(&n)
+		be.Err(t, err, nil)
+		be.Equal(t, n, 1)
+	})
+	t.Run("usage", func(t *testing.T) {
+		err := migrateDB([]string{"-prefix"})
+		be.True(t, err != nil)
+	})
+}
+
+// openMemDB opens an in-memory database and
+// closes it when the test ends.
+func openMemDB(t *testing.T, dataSource string) *redka.DB {
+	t.Helper()
+	db, err := redka.Open(dataSource, &redka.Options{DriverName: "sqlite3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
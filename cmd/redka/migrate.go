@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/nalgeon/redka"
+)
+
+// migrateDB runs the migrate command, which upgrades
+// the database schema to the latest version:
+//
+//	redka migrate redka.db
+//	redka migrate -dry-run redka.db
+//	redka migrate -prefix cache_ redka.db
+//	redka migrate -schema cache postgres://localhost/redka
+//
+// With -dry-run, prints the SQL scripts of the pending
+// migrations without applying them. With -prefix (SQLite)
+// or -schema (Postgres), migrates the Redka instance with
+// the given table prefix or schema (see [redka.Options]).
+func migrateDB(args []string) error {
+	const usage = "usage: redka migrate [-dry-run] [-prefix prefix] [-schema schema] <data-source>"
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	dryRun := fs.Bool("dry-run", false, "")
+	prefix := fs.String("prefix", "", "")
+	schema := fs.String("schema", "", "")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errors.New(usage)
+	}
+	dataSource := fs.Arg(0)
+
+	opts := redka.Options{
+		DriverName:  inferDriverName(dataSource),
+		Pragma:      map[string]string{},
+		TablePrefix: *prefix,
+		Schema:      *schema,
+	}
+	steps, err := redka.Migrate(dataSource, &opts, *dryRun)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	if *dryRun {
+		if len(steps) == 0 {
+			fmt.Println("-- The schema is up to date.")
+		}
+		for _, step := range steps {
+			fmt.Printf("-- Version %d: %s\n", step.Version, step.Name)
+			fmt.Println(strings.TrimSpace(step.Script))
+			fmt.Println()
+		}
+		return nil
+	}
+
+	if len(steps) == 0 {
+		slog.Info("schema is up to date")
+	}
+	for _, step := range steps {
+		slog.Info("migrate", "version", step.Version, "name", step.Name)
+	}
+	return nil
+}
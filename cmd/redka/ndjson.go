@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nalgeon/redka"
+)
+
+// Key types by name.
+var typesByName = map[string]redka.TypeID{
+	"string": redka.TypeString,
+	"list":   redka.TypeList,
+	"set":    redka.TypeSet,
+	"hash":   redka.TypeHash,
+	"zset":   redka.TypeZSet,
+}
+
+// dumpJSON runs the dump command, which writes the keys
+// as newline-delimited JSON to a file or to stdout:
+//
+//	redka dump redka.db redka.ndjson
+//	redka dump -match "user:*" -type hash,zset redka.db
+func dumpJSON(args []string) error {
+	const usage = "usage: redka dump [-match pattern] [-type types] <data-source> [file]"
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	match := fs.String("match", "", "")
+	typeList := fs.String("type", "", "")
+	if err := fs.Parse(args); err != nil || fs.NArg() < 1 || fs.NArg() > 2 {
+		return errors.New(usage)
+	}
+	dataSource, path := fs.Arg(0), fs.Arg(1)
+	if path == "" {
+		path = "-"
+	}
+
+	exportOpts := redka.ExportOptions{Pattern: *match}
+	if *typeList != "" {
+		for _, name := range strings.Split(*typeList, ",") {
+			typ, ok := typesByName[strings.TrimSpace(name)]
+			if !ok {
+				return fmt.Errorf("unknown key type: %s", name)
+			}
+			exportOpts.Types = append(exportOpts.Types, typ)
+		}
+	}
+
+	opts := redka.Options{
+		DriverName: inferDriverName(dataSource),
+		Pragma:     map[string]string{},
+	}
+	db, err := redka.OpenRead(dataSource, &opts)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	out := os.Stdout
+	if path != "-" {
+		out, err = os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	start := time.Now()
+	n, err := db.ExportJSON(out, &exportOpts)
+	if err != nil {
+		return fmt.Errorf("dump: %w", err)
+	}
+	if out != os.Stdout {
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+	slog.Info("dump", "path", path, "keys", n, "time", time.Since(start))
+	return nil
+}
+
+// restoreJSON runs the restore command, which loads the keys
+// written by the dump command from a file or from stdin ("-"):
+//
+//	redka restore redka.ndjson redka.db
+func restoreJSON(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: redka restore <file> <data-source>")
+	}
+	path, dataSource := args[0], args[1]
+
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	opts := redka.Options{
+		DriverName: inferDriverName(dataSource),
+		Pragma:     map[string]string{},
+	}
+	db, err := redka.Open(dataSource, &opts)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start := time.Now()
+	n, err := db.ImportJSON(in)
+	if err != nil {
+		return fmt.Errorf("restore %s: %w (restored %d keys)", path, err, n)
+	}
+	slog.Info("restore", "path", path, "keys", n, "time", time.Since(start))
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/nalgeon/redka"
+)
+
+// importRDB runs the import-rdb command, which loads
+// a Redis RDB file into the database:
+//
+//	redka import-rdb dump.rdb redka.db
+func importRDB(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: redka import-rdb <file> <data-source>")
+	}
+	path, dataSource := args[0], args[1]
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	opts := redka.Options{
+		DriverName: inferDriverName(dataSource),
+		Pragma:     map[string]string{},
+	}
+	db, err := redka.Open(dataSource, &opts)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start := time.Now()
+	n, err := db.ImportRDB(f)
+	if err != nil {
+		return fmt.Errorf("import %s: %w (imported %d keys)", path, err, n)
+	}
+	slog.Info("import rdb", "path", path, "keys", n, "time", time.Since(start))
+	return nil
+}
+
+// exportRDB runs the export-rdb command, which writes
+// the database to a Redis RDB file:
+//
+//	redka export-rdb redka.db dump.rdb
+func exportRDB(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: redka export-rdb <data-source> <file>")
+	}
+	dataSource, path := args[0], args[1]
+
+	opts := redka.Options{
+		DriverName: inferDriverName(dataSource),
+		Pragma:     map[string]string{},
+	}
+	db, err := redka.OpenRead(dataSource, &opts)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	start := time.Now()
+	n, err := db.ExportRDB(f)
+	if err != nil {
+		return fmt.Errorf("export %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	slog.Info("export rdb", "path", path, "keys", n, "time", time.Since(start))
+	return nil
+}
@@ -0,0 +1,316 @@
+package redka
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nalgeon/redka/internal/rkey"
+)
+
+// ErrOOM is returned by [DB.Evict] when the database is over
+// the limits (see [Options.MaxKeys] and [Options.MaxBytes])
+// and the eviction policy does not allow to free up space.
+var ErrOOM = errors.New("database is over the limits")
+
+// EvictionPolicy determines which keys to delete when
+// the database is over the limits (see [Options.EvictionPolicy]).
+type EvictionPolicy = rkey.EvictionPolicy
+
+// Eviction policies (same as in Redis).
+const (
+	NoEviction     = rkey.NoEviction     // do not delete keys, fail the writes
+	AllKeysLRU     = rkey.AllKeysLRU     // least recently used keys
+	VolatileLRU    = rkey.VolatileLRU    // least recently used keys with a TTL
+	AllKeysRandom  = rkey.AllKeysRandom  // random keys
+	VolatileRandom = rkey.VolatileRandom // random keys with a TTL
+	VolatileTTL    = rkey.VolatileTTL    // keys with the nearest expiration time
+)
+
+// evictor keeps the database under the limits (the number of keys
+// and the database size) by deleting the keys according to the
+// eviction policy, similar to the Redis maxmemory eviction.
+//
+// Each cycle measures the database and deletes the excess keys
+// in batches (each in a separate transaction) within the time budget.
+// The size limit is converted to the number of keys to delete, assuming
+// the keys are of similar size. If the cycle fails to get under the
+// limits (or the policy is NoEviction), the database stays over the
+// limits until the next cycle, and the writes fail with [ErrOOM]
+// (see [DB.Evict]).
+//
+// Between the cycles, counts the committed changes to tell when
+// the writes might have pushed the database over the limits
+// (see [evictor.check]), so that the writes don't have to
+// measure the database every time.
+//
+// Also updates the access time and counter of the recently
+// accessed keys, which the LRU policies rely on. The access
+// tracking is enabled for the LRU policies, or if requested
+// explicitly (see [Options.TrackAccess]).
+//
+// evictor is safe for concurrent use by multiple goroutines.
+type evictor struct {
+	keyDB     *rkey.DB
+	size      func() (int64, error)               // returns the database size
+	take      func() map[string]int               // returns the accessed keys
+	track     func(enabled bool)                  // enables the access tracking
+	listen    func(f func(n int)) (cancel func()) // registers a commit listener
+	sticky    bool                                // the size does not shrink after deletes
+	batchSize int
+	budget    time.Duration
+	log       *slog.Logger
+
+	maxKeys  atomic.Int64
+	maxBytes atomic.Int64
+	policy   atomic.Pointer[EvictionPolicy]
+	access   atomic.Bool  // whether to track access regardless of the policy
+	over     atomic.Bool  // whether the database is over the limits
+	keys     atomic.Int64 // number of evicted keys
+	writes   atomic.Int64 // number of changes since the last measure
+	room     atomic.Int64 // number of new keys under the limits, -1 if unknown
+
+	mu       sync.Mutex // serializes the cycles
+	lastSize int64      // size before the last eviction by size
+	unlisten func()     // unregisters the commit listener, if any
+}
+
+// newEvictor creates an evictor without limits
+// and with the NoEviction policy.
+// listen registers a function that receives the number
+// of changes in each committed transaction (see [sqlx.DB.ListenCommits]).
+func newEvictor(keyDB *rkey.DB, size func() (int64, error), take func() map[string]int,
+	track func(bool), listen func(func(int)) func(), sticky bool,
+	batchSize int, budget time.Duration, log *slog.Logger) *evictor {
+	e := &evictor{
+		keyDB: keyDB, size: size, take: take, track: track, listen: listen, sticky: sticky,
+		batchSize: batchSize, budget: budget, log: log,
+	}
+	policy := NoEviction
+	e.policy.Store(&policy)
+	e.room.Store(-1)
+	return e
+}
+
+// setMaxKeys changes the maximum number of keys.
+func (e *evictor) setMaxKeys(n int) {
+	e.maxKeys.Store(int64(n))
+	e.updateLimits()
+}
+
+// setMaxBytes changes the maximum database size in bytes.
+func (e *evictor) setMaxBytes(n int64) {
+	e.maxBytes.Store(n)
+	e.updateLimits()
+}
+
+// updateLimits makes the next check measure the database.
+// Counts the committed changes while there are limits,
+// and stops counting when there are none.
+func (e *evictor) updateLimits() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.room.Store(-1)
+	limited := e.maxKeys.Load() > 0 || e.maxBytes.Load() > 0
+	if limited && e.unlisten == nil {
+		e.unlisten = e.listen(func(n int) { e.writes.Add(int64(n)) })
+	}
+	if !limited && e.unlisten != nil {
+		e.unlisten()
+		e.unlisten = nil
+	}
+}
+
+// getPolicy returns the eviction policy.
+func (e *evictor) getPolicy() EvictionPolicy {
+	return *e.policy.Load()
+}
+
+// setPolicy changes the eviction policy. Enables the access
+// tracking for the LRU policies, and disables it for others
+// (unless enabled explicitly, see [evictor.setTrackAccess]).
+func (e *evictor) setPolicy(policy EvictionPolicy) error {
+	if err := checkPolicy(policy); err != nil {
+		return err
+	}
+	e.policy.Store(&policy)
+	e.updateTracking()
+	return nil
+}
+
+// checkPolicy checks that the eviction policy is supported.
+func checkPolicy(policy EvictionPolicy) error {
+	if !slices.Contains(rkey.EvictionPolicies, policy) {
+		return fmt.Errorf("unknown eviction policy: %s", policy)
+	}
+	return nil
+}
+
+// setTrackAccess enables or disables the access
+// tracking regardless of the eviction policy.
+func (e *evictor) setTrackAccess(enabled bool) {
+	e.access.Store(enabled)
+	e.updateTracking()
+}
+
+// updateTracking enables the access tracking if it's
+// requested explicitly or the policy relies on it.
+func (e *evictor) updateTracking() {
+	policy := e.getPolicy()
+	e.track(e.access.Load() || policy == AllKeysLRU || policy == VolatileLRU)
+}
+
+// check evicts the keys if the database is over the limits.
+// Reports whether the database is still over the limits.
+// Does nothing if there are no limits.
+//
+// Measures the database only if the last measure found it over
+// the limits (the deletes might have got it under the limits since),
+// or if the changes since the last measure might have pushed it
+// over the limits (assuming each change adds a new key).
+func (e *evictor) check() bool {
+	if e.maxKeys.Load() == 0 && e.maxBytes.Load() == 0 {
+		return false
+	}
+	if e.fits() {
+		return false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.fits() {
+		// Measured by a concurrent check.
+		return false
+	}
+	e.evict()
+	return e.over.Load()
+}
+
+// fits reports whether the database is under the limits
+// according to the last measure and the changes since then.
+func (e *evictor) fits() bool {
+	room := e.room.Load()
+	return !e.over.Load() && room >= 0 && e.writes.Load() <= room
+}
+
+// cycle updates the access time and counter of the accessed
+// keys, then evicts the keys if the database is over the limits.
+// Returns the number of evicted keys.
+func (e *evictor) cycle() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if keys := e.take(); len(keys) > 0 {
+		if _, err := e.keyDB.TouchCounts(keys); err != nil {
+			e.log.Error("bg: update access time", "error", err)
+		}
+	}
+	return e.evict()
+}
+
+// evict deletes the keys over the limits according to the
+// eviction policy, and updates the over-the-limits flag.
+// Returns the number of evicted keys.
+func (e *evictor) evict() int {
+	start := time.Now()
+	over, need, err := e.measure()
+	if err != nil {
+		e.log.Error("bg: measure database", "error", err)
+		return 0
+	}
+	policy := e.getPolicy()
+	if !over || policy == NoEviction {
+		e.over.Store(over)
+		return 0
+	}
+	count := 0
+	for count < need {
+		n, err := e.keyDB.Evict(policy, min(e.batchSize, need-count))
+		if err != nil {
+			e.log.Error("bg: evict keys", "error", err)
+			break
+		}
+		count += n
+		if n == 0 {
+			// No keys match the policy.
+			break
+		}
+		if time.Since(start) >= e.budget {
+			break
+		}
+	}
+	e.over.Store(count < need)
+	e.keys.Add(int64(count))
+	if count > 0 {
+		e.log.Info("bg: evict keys", "policy", policy, "count", count, "duration", time.Since(start))
+	}
+	return count
+}
+
+// measure reports whether the database is over the limits,
+// and how many keys to delete to get under the limits.
+// Also updates the number of new keys that fit under the limits
+// (the room), and resets the number of changes since the last measure.
+func (e *evictor) measure() (over bool, need int, err error) {
+	maxKeys, maxBytes := int(e.maxKeys.Load()), e.maxBytes.Load()
+	if maxKeys == 0 && maxBytes == 0 {
+		return false, 0, nil
+	}
+
+	// Reset before measuring, so that the changes
+	// made while measuring are counted.
+	e.writes.Store(0)
+	e.room.Store(-1)
+	nkeys, err := e.keyDB.Len()
+	if err != nil {
+		return false, 0, err
+	}
+	room := math.MaxInt
+	if maxKeys > 0 {
+		room = max(maxKeys-nkeys, 0)
+	}
+	if maxKeys > 0 && nkeys > maxKeys {
+		over = true
+		need = nkeys - maxKeys
+	}
+	if maxBytes == 0 {
+		e.room.Store(int64(room))
+		return over, need, nil
+	}
+
+	size, err := e.size()
+	if err != nil {
+		return false, 0, err
+	}
+	if size <= maxBytes {
+		e.lastSize = 0
+		// Convert the free space to the number of keys,
+		// assuming the keys are of similar size.
+		if nkeys > 0 && size > 0 {
+			room = min(room, int(int64(nkeys)*(maxBytes-size)/size))
+		} else {
+			room = 0
+		}
+		e.room.Store(int64(room))
+		return over, need, nil
+	}
+	over = true
+	e.room.Store(0)
+
+	// Some databases (Postgres) keep the space freed by the deleted
+	// keys for reuse, so the size does not shrink after the eviction.
+	// Only evict for the growth since the last eviction in this case.
+	limit := maxBytes
+	if e.sticky && e.lastSize > limit {
+		limit = e.lastSize
+	}
+	if size > limit && nkeys > 0 {
+		n := int((int64(nkeys)*(size-limit) + size - 1) / size)
+		need = max(need, n)
+		e.lastSize = size
+	}
+	return over, need, nil
+}
@@ -0,0 +1,184 @@
+package redka_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestEvict(t *testing.T) {
+	t.Run("max keys", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval: 5 * time.Millisecond,
+			MaxKeys:        10,
+			EvictionPolicy: redka.AllKeysLRU,
+		})
+		setKeys(db, 20)
+		time.Sleep(50 * time.Millisecond)
+
+		n, _ := db.Key().Len()
+		be.Equal(t, n, 10)
+		be.Equal(t, db.Stats().EvictedKeys, int64(10))
+		be.Err(t, db.Evict(), nil)
+	})
+	t.Run("max bytes", func(t *testing.T) {
+		if testx.Driver() == "postgres" {
+			t.Skip("postgres does not shrink the tables after deletes")
+		}
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval: 5 * time.Millisecond,
+			EvictionPolicy: redka.AllKeysRandom,
+		})
+		value := strings.Repeat("x", 1000)
+		for i := range 200 {
+			_ = db.Str().Set(fmt.Sprintf("key:%d", i), value)
+		}
+		size, err := db.Size()
+		be.Err(t, err, nil)
+		db.SetMaxBytes(size / 2)
+		time.Sleep(100 * time.Millisecond)
+
+		n, _ := db.Key().Len()
+		be.True(t, n > 0 && n < 200)
+		size, _ = db.Size()
+		be.True(t, size <= db.MaxBytes())
+		be.Err(t, db.Evict(), nil)
+	})
+	t.Run("noeviction", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval: 5 * time.Millisecond,
+			MaxKeys:        5,
+		})
+		be.Equal(t, db.EvictionPolicy(), redka.NoEviction)
+		setKeys(db, 10)
+		time.Sleep(20 * time.Millisecond)
+
+		be.Err(t, db.Evict(), redka.ErrOOM)
+		n, _ := db.Key().Len()
+		be.Equal(t, n, 10)
+
+		// Deleting the keys gets the database under the limits
+		// without waiting for the background manager.
+		_, _ = db.Key().Delete("key:0", "key:1", "key:2", "key:3", "key:4")
+		be.Err(t, db.Evict(), nil)
+	})
+	t.Run("between cycles", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval: time.Hour,
+			MaxKeys:        5,
+			EvictionPolicy: redka.AllKeysRandom,
+		})
+		setKeys(db, 10)
+
+		// Evict does not wait for the background manager
+		// to find the database over the limits.
+		be.Err(t, db.Evict(), nil)
+		n, _ := db.Key().Len()
+		be.Equal(t, n, 5)
+
+		err := db.SetEvictionPolicy(redka.NoEviction)
+		be.Err(t, err, nil)
+		_ = db.Str().Set("key:10", 10)
+		be.Err(t, db.Evict(), redka.ErrOOM)
+	})
+	t.Run("counted writes", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval: time.Hour,
+			MaxKeys:        5,
+		})
+
+		// The writes pass until the database
+		// is over the limits.
+		for i := range 6 {
+			be.Err(t, db.Evict(), nil)
+			_ = db.Str().Set(fmt.Sprintf("key:%d", i), i)
+		}
+		be.Err(t, db.Evict(), redka.ErrOOM)
+
+		// Raising the limit takes effect right away.
+		db.SetMaxKeys(10)
+		be.Err(t, db.Evict(), nil)
+		db.SetMaxKeys(0)
+		be.Err(t, db.Evict(), nil)
+	})
+	t.Run("no matching keys", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval: 5 * time.Millisecond,
+			MaxKeys:        5,
+			EvictionPolicy: redka.VolatileTTL,
+		})
+		setKeys(db, 10)
+		_ = db.Str().SetExpire("key:9", 9, time.Hour)
+		time.Sleep(20 * time.Millisecond)
+
+		// Only the key with the TTL is evicted.
+		be.Err(t, db.Evict(), redka.ErrOOM)
+		n, _ := db.Key().Len()
+		be.Equal(t, n, 9)
+	})
+	t.Run("policy", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		err := db.SetEvictionPolicy(redka.VolatileLRU)
+		be.Err(t, err, nil)
+		be.Equal(t, db.EvictionPolicy(), redka.VolatileLRU)
+		err = db.SetEvictionPolicy("allkeys-lfu")
+		be.Err(t, err, "unknown eviction policy")
+		be.Equal(t, db.EvictionPolicy(), redka.VolatileLRU)
+
+		_, err = redka.Open(":memory:", &redka.Options{EvictionPolicy: "allkeys-lfu"})
+		be.Err(t, err, "unknown eviction policy")
+	})
+}
+
+func TestTrackAccess(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval: 5 * time.Millisecond,
+			TrackAccess:    true,
+		})
+		be.True(t, db.TrackAccess())
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.Str().Get("name")
+		_, _ = db.Str().Get("name")
+		time.Sleep(20 * time.Millisecond)
+
+		// The set and both gets are recorded.
+		info, err := db.Key().Info("name")
+		be.Err(t, err, nil)
+		be.Equal(t, info.Freq, 3)
+		be.True(t, info.ATime >= info.Key.MTime)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval: 5 * time.Millisecond,
+		})
+		be.Equal(t, db.TrackAccess(), false)
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.Str().Get("name")
+		time.Sleep(20 * time.Millisecond)
+
+		info, err := db.Key().Info("name")
+		be.Err(t, err, nil)
+		be.Equal(t, info.Freq, 0)
+		be.Equal(t, info.ATime, info.Key.MTime)
+
+		// Enable at runtime.
+		db.SetTrackAccess(true)
+		_, _ = db.Str().Get("name")
+		time.Sleep(20 * time.Millisecond)
+		info, _ = db.Key().Info("name")
+		be.Equal(t, info.Freq, 1)
+	})
+}
+
+// setKeys sets n string keys named key:0, key:1, etc.
+func setKeys(db *redka.DB, n int) {
+	for i := range n {
+		_ = db.Str().Set(fmt.Sprintf("key:%d", i), i)
+	}
+}
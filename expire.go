@@ -0,0 +1,92 @@
+package redka
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/nalgeon/redka/internal/rkey"
+)
+
+// Background expiration defaults.
+const (
+	// How often the background manager deletes expired keys.
+	defaultExpireInterval = time.Second
+	// How many expired keys to delete in a single transaction.
+	defaultExpireBatchSize = 100
+	// Maximum time to spend deleting expired keys in a single cycle.
+	defaultExpireBudget = 25 * time.Millisecond
+)
+
+// If no more than this fraction of the sampled keys with TTL
+// has expired, the expiration cycle stops, since there are too
+// few expired keys left to bother (like in Redis).
+const expireStaleRatio = 0.1
+
+// expirer deletes expired keys in the background, similar to
+// the Redis active expiration. Each cycle samples the keys with TTL
+// in small batches (each in a separate transaction, so that the
+// concurrent writers don't have to wait for long) and deletes
+// the expired ones among them. Repeats while more than
+// [expireStaleRatio] of the sampled keys have expired and
+// the time budget allows. The keys left over are deleted
+// in the next cycles.
+//
+// expirer is safe for concurrent use by multiple goroutines.
+type expirer struct {
+	keyDB     *rkey.DB
+	take      func() []string // returns the keys to expire lazily
+	batchSize int
+	budget    time.Duration
+	log       *slog.Logger
+
+	keys   atomic.Int64 // number of deleted keys
+	cycles atomic.Int64 // number of cycles
+	capped atomic.Int64 // number of cycles stopped by the time budget
+	dur    atomic.Int64 // total cycle duration in nanoseconds
+}
+
+// newExpirer creates an expirer with the given batch size
+// and time budget per cycle. take returns the expired keys
+// detected by the read paths (see [sqlx.DB.TakeExpired]).
+func newExpirer(keyDB *rkey.DB, take func() []string, batchSize int, budget time.Duration, log *slog.Logger) *expirer {
+	return &expirer{keyDB: keyDB, take: take, batchSize: batchSize, budget: budget, log: log}
+}
+
+// cycle deletes the expired keys detected by the read paths,
+// then other expired keys until there are few of them left
+// among the keys with TTL or the time budget runs out.
+// Returns the number of deleted keys.
+func (e *expirer) cycle() int {
+	start := time.Now()
+	count := 0
+	if keys := e.take(); len(keys) > 0 {
+		n, err := e.keyDB.DeleteIfExpired(keys...)
+		if err != nil {
+			e.log.Error("bg: delete expired keys", "error", err)
+		}
+		count += n
+	}
+	for {
+		sampled, n, err := e.keyDB.DeleteExpiredSample(e.batchSize)
+		if err != nil {
+			e.log.Error("bg: delete expired keys", "error", err)
+			break
+		}
+		count += n
+		if float64(n) <= float64(sampled)*expireStaleRatio {
+			break
+		}
+		if time.Since(start) >= e.budget {
+			e.capped.Add(1)
+			break
+		}
+	}
+	e.keys.Add(int64(count))
+	e.cycles.Add(1)
+	e.dur.Add(int64(time.Since(start)))
+	if count > 0 {
+		e.log.Info("bg: delete expired keys", "count", count, "duration", time.Since(start))
+	}
+	return count
+}
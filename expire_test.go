@@ -0,0 +1,53 @@
+package redka_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestExpire(t *testing.T) {
+	t.Run("batches", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval:  5 * time.Millisecond,
+			ExpireBatchSize: 10,
+		})
+		setExpiring(db, 95)
+		_ = db.Str().Set("name", "alice")
+		time.Sleep(50 * time.Millisecond)
+
+		stats := db.Stats()
+		be.Equal(t, stats.ExpiredKeys, int64(95))
+		be.True(t, stats.ExpireCycles > 0)
+		be.True(t, stats.ExpireTime > 0)
+		n, _ := db.Key().Len()
+		be.Equal(t, n, 1)
+	})
+	t.Run("time budget", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval:  5 * time.Millisecond,
+			ExpireBatchSize: 10,
+			ExpireBudget:    time.Nanosecond,
+		})
+		setExpiring(db, 95)
+		time.Sleep(100 * time.Millisecond)
+
+		// Each cycle deletes a single batch, so it takes
+		// several cycles to delete all the keys.
+		stats := db.Stats()
+		be.Equal(t, stats.ExpiredKeys, int64(95))
+		be.True(t, stats.ExpireCycles >= 10)
+		be.True(t, stats.ExpireCappedCycles >= 9)
+	})
+}
+
+// setExpiring sets n keys that expire in a millisecond.
+func setExpiring(db *redka.DB, n int) {
+	for i := range n {
+		_ = db.Str().SetExpire(fmt.Sprintf("key:%d", i), i, time.Millisecond)
+	}
+}
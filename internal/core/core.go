@@ -21,11 +21,13 @@ const (
 
 // Common errors returned by data structure methods.
 var (
-	ErrArgument   = errors.New("invalid argument")
-	ErrKeyType    = errors.New("key type mismatch") // the key already exists with a different type
-	ErrNotAllowed = errors.New("operation not allowed")
-	ErrNotFound   = errors.New("key or elem not found")
-	ErrValueType  = errors.New("invalid value type")
+	ErrArgument     = errors.New("invalid argument")
+	ErrKeyExists    = errors.New("key already exists")
+	ErrKeyType      = errors.New("key type mismatch") // the key already exists with a different type
+	ErrNotAllowed   = errors.New("operation not allowed")
+	ErrNotFound     = errors.New("key or elem not found")
+	ErrNotSupported = errors.New("operation not supported")
+	ErrValueType    = errors.New("invalid value type")
 )
 
 // Key represents a key data structure.
@@ -42,6 +44,16 @@ type Key struct {
 	MTime   int64  // last modification time in unix milliseconds
 }
 
+// Event describes a change made to a key by a write operation.
+type Event struct {
+	Name       string // operation name, e.g. "set", "del" or "expired"
+	Key        string
+	Type       TypeID
+	OldVersion int   // key version before the change, 0 if the key did not exist
+	NewVersion int   // key version after the change, 0 if the key was deleted
+	Cmd        []any // command that reproduces the change, e.g. ["set", key, value]
+}
+
 // Exists reports whether the key exists.
 // Returns false for expired keys.
 func (k Key) Exists() bool {
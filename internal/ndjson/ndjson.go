@@ -0,0 +1,324 @@
+// Package ndjson reads and writes Redka keys as newline-delimited
+// JSON, one object per key:
+//
+//	{"key":"name","type":"string","value":"alice"}
+//	{"key":"session","type":"string","ttl":60000,"value":"token"}
+//	{"key":"list","type":"list","value":["a","b"]}
+//	{"key":"set","type":"set","value":["go","sql"]}
+//	{"key":"hash","type":"hash","value":{"age":"25","name":"alice"}}
+//	{"key":"zset","type":"zset","value":[{"elem":"a","score":1}]}
+//
+// The ttl is the remaining time to live in milliseconds (omitted
+// if the key does not expire). Infinite scores are written as
+// "inf" and "-inf". If the key name or any part of the value is not
+// valid UTF-8, the record has "encoding":"base64", and the key name
+// along with all the strings in the value are base64-encoded.
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/rdb"
+)
+
+// ErrFormat is returned by the reader on invalid records.
+var ErrFormat = errors.New("invalid ndjson format")
+
+// encBase64 is the encoding of records with binary data.
+const encBase64 = "base64"
+
+// record is a single key in the file.
+type record struct {
+	Key      string          `json:"key"`
+	Type     string          `json:"type"`
+	TTL      int64           `json:"ttl,omitempty"`
+	Encoding string          `json:"encoding,omitempty"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// item is a sorted set element with its score.
+type item struct {
+	Elem  string `json:"elem"`
+	Score score  `json:"score"`
+}
+
+// score is a sorted set score. Unlike float64,
+// it supports infinite values (as "inf" and "-inf").
+type score float64
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s score) MarshalJSON() ([]byte, error) {
+	f := float64(s)
+	switch {
+	case math.IsInf(f, 1):
+		return []byte(`"inf"`), nil
+	case math.IsInf(f, -1):
+		return []byte(`"-inf"`), nil
+	case math.IsNaN(f):
+		return nil, fmt.Errorf("invalid score: %v", f)
+	default:
+		return strconv.AppendFloat(nil, f, 'g', -1, 64), nil
+	}
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *score) UnmarshalJSON(data []byte) error {
+	str := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil || math.IsNaN(f) {
+		return fmt.Errorf("invalid score: %s", data)
+	}
+	*s = score(f)
+	return nil
+}
+
+// Writer writes the keys as newline-delimited JSON.
+type Writer struct {
+	wr  *bufio.Writer
+	enc *json.Encoder
+	buf bytes.Buffer
+	val *json.Encoder
+}
+
+// NewWriter creates a writer that writes the keys to w.
+// Call Flush after writing all the keys.
+func NewWriter(w io.Writer) *Writer {
+	wr := bufio.NewWriter(w)
+	enc := json.NewEncoder(wr)
+	enc.SetEscapeHTML(false)
+	writer := &Writer{wr: wr, enc: enc}
+	writer.val = json.NewEncoder(&writer.buf)
+	writer.val.SetEscapeHTML(false)
+	return writer
+}
+
+// Write writes the key as a single line.
+// Uses the Key, Type, ETime and the value fields of the entry.
+// Computes the ttl from ETime relative to the current time.
+func (w *Writer) Write(e rdb.Entry) error {
+	rec := record{Key: e.Key}
+	binary := !isText(e)
+	str := func(b []byte) string {
+		if binary {
+			return base64.StdEncoding.EncodeToString(b)
+		}
+		return string(b)
+	}
+	if binary {
+		rec.Key = str([]byte(e.Key))
+		rec.Encoding = encBase64
+	}
+	if e.ETime != 0 {
+		// Keys that expire in less than a millisecond
+		// still need a non-zero ttl.
+		rec.TTL = max(e.ETime-time.Now().UnixMilli(), 1)
+	}
+
+	var val any
+	switch e.Type {
+	case core.TypeString:
+		val = str(e.Str)
+	case core.TypeList, core.TypeSet:
+		elems := make([]string, len(e.Elems))
+		for i, elem := range e.Elems {
+			elems[i] = str(elem)
+		}
+		if e.Type == core.TypeSet {
+			// Sets are unordered, so sort them
+			// to get stable output.
+			slices.Sort(elems)
+		}
+		val = elems
+	case core.TypeHash:
+		fields := make(map[string]string, len(e.Fields))
+		for _, field := range e.Fields {
+			fields[str(field.Name)] = str(field.Value)
+		}
+		val = fields
+	case core.TypeZSet:
+		items := make([]item, len(e.Items))
+		for i, it := range e.Items {
+			items[i] = item{Elem: str(it.Elem), Score: score(it.Score)}
+		}
+		val = items
+	default:
+		return fmt.Errorf("key %q: unsupported key type %d", e.Key, e.Type)
+	}
+	rec.Type = core.Key{Type: e.Type}.TypeName()
+
+	w.buf.Reset()
+	if err := w.val.Encode(val); err != nil {
+		return fmt.Errorf("key %q: %w", e.Key, err)
+	}
+	rec.Value = bytes.TrimSuffix(w.buf.Bytes(), []byte{'\n'})
+	return w.enc.Encode(rec)
+}
+
+// Flush writes the buffered data to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.wr.Flush()
+}
+
+// Reader reads the keys written by the Writer.
+type Reader struct {
+	dec  *json.Decoder
+	nrec int
+}
+
+// NewReader creates a reader that reads the keys from r.
+func NewReader(r io.Reader) *Reader {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	return &Reader{dec: dec}
+}
+
+// Read reads the next key. Computes ETime from the ttl
+// relative to the current time. Returns io.EOF when there
+// are no more keys.
+func (r *Reader) Read() (rdb.Entry, error) {
+	var rec record
+	err := r.dec.Decode(&rec)
+	if err == io.EOF {
+		return rdb.Entry{}, io.EOF
+	}
+	r.nrec++
+	if err != nil {
+		return rdb.Entry{}, fmt.Errorf("%w: record %d: %v", ErrFormat, r.nrec, err)
+	}
+	entry, err := parseRecord(rec)
+	if err != nil {
+		return rdb.Entry{}, fmt.Errorf("%w: record %d: %v", ErrFormat, r.nrec, err)
+	}
+	return entry, nil
+}
+
+// parseRecord converts the record to an entry.
+func parseRecord(rec record) (rdb.Entry, error) {
+	var str func(s string) ([]byte, error)
+	switch rec.Encoding {
+	case "":
+		str = func(s string) ([]byte, error) { return []byte(s), nil }
+	case encBase64:
+		str = base64.StdEncoding.DecodeString
+	default:
+		return rdb.Entry{}, fmt.Errorf("unknown encoding %q", rec.Encoding)
+	}
+
+	key, err := str(rec.Key)
+	if err != nil {
+		return rdb.Entry{}, fmt.Errorf("key: %v", err)
+	}
+	if len(key) == 0 {
+		return rdb.Entry{}, errors.New("missing key")
+	}
+	entry := rdb.Entry{Key: string(key)}
+	if rec.TTL < 0 {
+		return rdb.Entry{}, fmt.Errorf("key %q: invalid ttl %d", key, rec.TTL)
+	}
+	if rec.TTL > 0 {
+		entry.ETime = time.Now().UnixMilli() + rec.TTL
+	}
+
+	switch rec.Type {
+	case "string":
+		entry.Type = core.TypeString
+		var val string
+		if err = json.Unmarshal(rec.Value, &val); err == nil {
+			entry.Str, err = str(val)
+		}
+
+	case "list", "set":
+		entry.Type = core.TypeList
+		if rec.Type == "set" {
+			entry.Type = core.TypeSet
+		}
+		var vals []string
+		if err = json.Unmarshal(rec.Value, &vals); err == nil {
+			entry.Elems = make([][]byte, len(vals))
+			for i, val := range vals {
+				if entry.Elems[i], err = str(val); err != nil {
+					break
+				}
+			}
+		}
+
+	case "hash":
+		entry.Type = core.TypeHash
+		var vals map[string]string
+		if err = json.Unmarshal(rec.Value, &vals); err == nil {
+			entry.Fields = make([]rdb.Field, 0, len(vals))
+			for name, val := range vals {
+				var field rdb.Field
+				if field.Name, err = str(name); err != nil {
+					break
+				}
+				if field.Value, err = str(val); err != nil {
+					break
+				}
+				entry.Fields = append(entry.Fields, field)
+			}
+		}
+
+	case "zset":
+		entry.Type = core.TypeZSet
+		var vals []item
+		if err = json.Unmarshal(rec.Value, &vals); err == nil {
+			entry.Items = make([]rdb.Item, len(vals))
+			for i, val := range vals {
+				entry.Items[i].Score = float64(val.Score)
+				if entry.Items[i].Elem, err = str(val.Elem); err != nil {
+					break
+				}
+			}
+		}
+
+	default:
+		return rdb.Entry{}, fmt.Errorf("key %q: unsupported type %q", key, rec.Type)
+	}
+
+	if err != nil {
+		return rdb.Entry{}, fmt.Errorf("key %q: value: %v", key, err)
+	}
+	return entry, nil
+}
+
+// isText reports whether the key name and
+// all the value parts are valid UTF-8.
+func isText(e rdb.Entry) bool {
+	if !utf8.ValidString(e.Key) || !utf8.Valid(e.Str) {
+		return false
+	}
+	for _, elem := range e.Elems {
+		if !utf8.Valid(elem) {
+			return false
+		}
+	}
+	for _, field := range e.Fields {
+		if !utf8.Valid(field.Name) || !utf8.Valid(field.Value) {
+			return false
+		}
+	}
+	for _, it := range e.Items {
+		if !utf8.Valid(it.Elem) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package ndjson
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/rdb"
+)
+
+func TestWriter(t *testing.T) {
+	entries := []rdb.Entry{
+		{Key: "name", Type: core.TypeString, Str: []byte("alice")},
+		{Key: "html", Type: core.TypeString, Str: []byte("<a&b>")},
+		{Key: "bin", Type: core.TypeString, Str: []byte{0xff, 0x00}},
+		{Key: "list", Type: core.TypeList, Elems: [][]byte{[]byte("b"), []byte("a")}},
+		{Key: "set", Type: core.TypeSet, Elems: [][]byte{[]byte("b"), []byte("a")}},
+		{Key: "hash", Type: core.TypeHash, Fields: []rdb.Field{
+			{Name: []byte("name"), Value: []byte("alice")},
+			{Name: []byte("age"), Value: []byte("25")},
+		}},
+		{Key: "zset", Type: core.TypeZSet, Items: []rdb.Item{
+			{Elem: []byte("a"), Score: 1.5},
+			{Elem: []byte("b"), Score: math.Inf(-1)},
+		}},
+	}
+	want := []string{
+		`{"key":"name","type":"string","value":"alice"}`,
+		`{"key":"html","type":"string","value":"<a&b>"}`,
+		`{"key":"Ymlu","type":"string","encoding":"base64","value":"/wA="}`,
+		`{"key":"list","type":"list","value":["b","a"]}`,
+		`{"key":"set","type":"set","value":["a","b"]}`,
+		`{"key":"hash","type":"hash","value":{"age":"25","name":"alice"}}`,
+		`{"key":"zset","type":"zset","value":[{"elem":"a","score":1.5},{"elem":"b","score":"-inf"}]}`,
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, e := range entries {
+		err := w.Write(e)
+		be.Err(t, err, nil)
+	}
+	err := w.Flush()
+	be.Err(t, err, nil)
+	be.Equal(t, buf.String(), strings.Join(want, "\n")+"\n")
+
+	// The output can be read back.
+	r := NewReader(&buf)
+	for _, want := range entries {
+		got, err := r.Read()
+		be.Err(t, err, nil)
+		be.Equal(t, got.Key, want.Key)
+		be.Equal(t, got.Type, want.Type)
+		be.Equal(t, got.Len(), want.Len())
+		if want.Type == core.TypeString {
+			be.Equal(t, got.Str, want.Str)
+		}
+	}
+	_, err = r.Read()
+	be.Equal(t, err, io.EOF)
+}
+
+func TestTTL(t *testing.T) {
+	etime := time.Now().Add(time.Minute).UnixMilli()
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	err := w.Write(rdb.Entry{Key: "name", Type: core.TypeString, ETime: etime, Str: []byte("alice")})
+	be.Err(t, err, nil)
+	err = w.Flush()
+	be.Err(t, err, nil)
+	be.True(t, strings.Contains(buf.String(), `"ttl":`))
+
+	e, err := NewReader(&buf).Read()
+	be.Err(t, err, nil)
+	be.True(t, e.ETime > etime-1000)
+	be.True(t, e.ETime < etime+1000)
+}
+
+func TestRead(t *testing.T) {
+	in := `{"key":"zset","type":"zset","value":[{"elem":"a","score":"inf"},{"elem":"b","score":-2}]}
+{"key":"aGFzaA==","type":"hash","encoding":"base64","value":{"Zg==":"/w=="}}
+`
+	r := NewReader(strings.NewReader(in))
+
+	e, err := r.Read()
+	be.Err(t, err, nil)
+	be.Equal(t, e.Items, []rdb.Item{
+		{Elem: []byte("a"), Score: math.Inf(1)},
+		{Elem: []byte("b"), Score: -2},
+	})
+
+	e, err = r.Read()
+	be.Err(t, err, nil)
+	be.Equal(t, e.Key, "hash")
+	be.Equal(t, e.Fields, []rdb.Field{{Name: []byte("f"), Value: []byte{0xff}}})
+
+	_, err = r.Read()
+	be.Equal(t, err, io.EOF)
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"not json", `name alice`, "invalid character"},
+		{"unknown field", `{"key":"k","type":"string","value":"v","x":1}`, "unknown field"},
+		{"missing key", `{"type":"string","value":"v"}`, "missing key"},
+		{"missing value", `{"key":"k","type":"string"}`, "value"},
+		{"unknown type", `{"key":"k","type":"stream","value":[]}`, "unsupported type"},
+		{"wrong value", `{"key":"k","type":"list","value":"v"}`, "value"},
+		{"negative ttl", `{"key":"k","type":"string","ttl":-1,"value":"v"}`, "invalid ttl"},
+		{"bad score", `{"key":"k","type":"zset","value":[{"elem":"a","score":"x"}]}`, "invalid score"},
+		{"bad encoding", `{"key":"k","type":"string","encoding":"hex","value":"v"}`, "unknown encoding"},
+		{"bad base64", `{"key":"!","type":"string","encoding":"base64","value":"v"}`, "key"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(test.in)).Read()
+			be.Err(t, err, ErrFormat)
+			be.Err(t, err, test.want)
+		})
+	}
+}
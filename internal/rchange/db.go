@@ -0,0 +1,114 @@
+// Package rchange is a database-backed change log.
+// It provides methods to read and truncate the log
+// of the changes made by the write operations.
+package rchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/nalgeon/redka/internal/sqlx"
+)
+
+// How often Tail checks for new changes
+// when it has caught up with the log.
+const tailInterval = 100 * time.Millisecond
+
+// DB is a database-backed change log.
+// The log records the changes made by the write operations
+// in the same transaction as the changes themselves, so
+// it never contains rolled back changes and never misses
+// committed ones. Use the change log to replicate
+// or audit the changes.
+type DB struct {
+	dialect sqlx.Dialect
+	ro      sqlx.Tx
+	update  func(f func(tx *Tx) error) error
+}
+
+// New connects to the change log.
+// Does not create the database schema.
+func New(db *sqlx.DB) *DB {
+	db.Prepare(getSQL(db.Dialect))
+	actor := sqlx.NewTransactor(db, NewTx)
+	return &DB{dialect: db.Dialect, ro: db.Reader(), update: actor.Update}
+}
+
+// Last returns the sequence number of the last change.
+// If the log is empty, returns 0.
+func (d *DB) Last() (int64, error) {
+	tx := NewTx(d.dialect, d.ro)
+	return tx.Last()
+}
+
+// Range returns the changes with sequence numbers
+// greater than since, ordered by sequence number.
+// Returns no more than count changes (0 = default).
+// Returns an empty slice when there are no more changes.
+func (d *DB) Range(since int64, count int) ([]Change, error) {
+	tx := NewTx(d.dialect, d.ro)
+	return tx.Range(since, count)
+}
+
+// Tail calls f for each change with a sequence number
+// greater than since, in the order of sequence numbers.
+// After reaching the end of the log, waits for new changes.
+// Stops when the context is canceled (returning the context
+// error) or when f returns an error (returning that error).
+func (d *DB) Tail(ctx context.Context, since int64, f func(c Change) error) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		changes, err := d.Range(since, rangePageSize)
+		if err != nil {
+			return err
+		}
+		for _, c := range changes {
+			if err := f(c); err != nil {
+				return err
+			}
+			since = c.Seq
+		}
+
+		if len(changes) == rangePageSize {
+			// There may be more changes, read them right away.
+			timer.Reset(0)
+		} else {
+			timer.Reset(tailInterval)
+		}
+	}
+}
+
+// Trim deletes the oldest changes, so that the log contains
+// no more than maxLen changes made no earlier than maxAge ago.
+// Zero maxLen or maxAge disables the corresponding limit.
+// Returns the number of deleted changes.
+func (d *DB) Trim(maxLen int, maxAge time.Duration) (int, error) {
+	var count int
+	err := d.update(func(tx *Tx) error {
+		var err error
+		count, err = tx.Trim(maxLen, maxAge)
+		return err
+	})
+	return count, err
+}
+
+// Truncate deletes the changes with sequence numbers
+// less than or equal to upTo (e.g. the changes already
+// applied by all replicas). Returns the number
+// of deleted changes.
+func (d *DB) Truncate(upTo int64) (int, error) {
+	var count int
+	err := d.update(func(tx *Tx) error {
+		var err error
+		count, err = tx.Truncate(upTo)
+		return err
+	})
+	return count, err
+}
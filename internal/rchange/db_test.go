@@ -0,0 +1,329 @@
+package rchange_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/rchange"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(db *redka.DB)
+		want  []string
+	}{
+		{
+			name:  "set",
+			write: func(db *redka.DB) { _ = db.Str().Set("name", "alice") },
+			want:  []string{"set name alice"},
+		},
+		{
+			name: "set expire",
+			write: func(db *redka.DB) {
+				at := time.UnixMilli(4102444800000)
+				_, _ = db.Str().SetWith("name", "alice").At(at).Run()
+			},
+			want: []string{"set name alice pxat 4102444800000"},
+		},
+		{
+			name: "incr",
+			write: func(db *redka.DB) {
+				_ = db.Str().Set("age", 25)
+				_, _ = db.Str().Incr("age", 5)
+			},
+			want: []string{"set age 25", "set age 30 keepttl"},
+		},
+		{
+			name: "del",
+			write: func(db *redka.DB) {
+				_ = db.Str().Set("name", "alice")
+				_, _ = db.Key().Delete("name", "city")
+			},
+			want: []string{"set name alice", "del name"},
+		},
+		{
+			name: "expire and persist",
+			write: func(db *redka.DB) {
+				_ = db.Str().Set("name", "alice")
+				_ = db.Key().ExpireAt("name", time.UnixMilli(4102444800000))
+				_ = db.Key().Persist("name")
+			},
+			want: []string{"set name alice", "pexpireat name 4102444800000", "persist name"},
+		},
+		{
+			name: "rename",
+			write: func(db *redka.DB) {
+				_ = db.Str().Set("name", "alice")
+				_ = db.Key().Rename("name", "title")
+			},
+			want: []string{"set name alice", "rename name title"},
+		},
+		{
+			name: "hashes",
+			write: func(db *redka.DB) {
+				_, _ = db.Hash().Set("person", "name", "alice")
+				_, _ = db.Hash().Incr("person", "age", 25)
+				_, _ = db.Hash().Delete("person", "name")
+			},
+			want: []string{"hset person name alice", "hset person age 25", "hdel person name"},
+		},
+		{
+			name: "lists",
+			write: func(db *redka.DB) {
+				_, _ = db.List().PushBack("key", "a")
+				_, _ = db.List().PushFront("key", "b")
+				_, _ = db.List().InsertAfter("key", "a", "c")
+				_ = db.List().Set("key", -1, "d")
+				_, _ = db.List().DeleteBack("key", "d", 1)
+				_, _ = db.List().PopFront("key")
+			},
+			want: []string{
+				"rpush key a", "lpush key b", "linsert key after a c",
+				"lset key -1 d", "lrem key -1 d", "lpop key",
+			},
+		},
+		{
+			name: "sets",
+			write: func(db *redka.DB) {
+				_, _ = db.Set().Add("key1", "a", "b")
+				_, _ = db.Set().Delete("key1", "b")
+				_, _ = db.Set().UnionStore("dest", "key1", "key2")
+			},
+			want: []string{"sadd key1 a b", "srem key1 b", "sunionstore dest key1 key2"},
+		},
+		{
+			name: "sorted sets",
+			write: func(db *redka.DB) {
+				_, _ = db.ZSet().Add("key", "a", 11)
+				_, _ = db.ZSet().Incr("key", "a", 0.5)
+				_, _ = db.ZSet().DeleteWith("key").ByScore(1, 10).Run()
+				_, _ = db.ZSet().Delete("key", "a")
+			},
+			want: []string{"zadd key 11 a", "zadd key 11.5 a", "zrem key a"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, changes := getDB(t)
+			test.write(db)
+			list, err := changes.Range(0, 100)
+			be.Err(t, err, nil)
+			var got []string
+			for _, c := range list {
+				got = append(got, c.String())
+			}
+			be.Equal(t, got, test.want)
+		})
+	}
+}
+
+func TestRecordChange(t *testing.T) {
+	db, changes := getDB(t)
+	last, _ := changes.Last()
+	now := time.Now()
+
+	_ = db.Str().Set("name", "alice")
+
+	list, err := changes.Range(last, 10)
+	be.Err(t, err, nil)
+	be.Equal(t, len(list), 1)
+	c := list[0]
+	be.Equal(t, c.Seq > last, true)
+	be.Equal(t, c.Key, "name")
+	be.Equal(t, c.Type, core.TypeString)
+	be.Equal(t, c.Op, "set")
+	be.Equal(t, c.Cmd, [][]byte{[]byte("set"), []byte("name"), []byte("alice")})
+	be.Equal(t, c.Time.Sub(now) < time.Second, true)
+}
+
+func TestRecordTx(t *testing.T) {
+	t.Run("commit", func(t *testing.T) {
+		db, changes := getDB(t)
+		err := db.Update(func(tx *redka.Tx) error {
+			_ = tx.Str().Set("name", "alice")
+			_, _ = tx.Str().Incr("age", 25)
+			return nil
+		})
+		be.Err(t, err, nil)
+
+		list, _ := changes.Range(0, 10)
+		be.Equal(t, len(list), 2)
+		be.Equal(t, list[0].String(), "set name alice")
+		be.Equal(t, list[1].String(), "set age 25 keepttl")
+	})
+	t.Run("rollback", func(t *testing.T) {
+		db, changes := getDB(t)
+		err := db.Update(func(tx *redka.Tx) error {
+			_ = tx.Str().Set("name", "alice")
+			return errors.New("rollback")
+		})
+		be.Err(t, err)
+
+		list, _ := changes.Range(0, 10)
+		be.Equal(t, len(list), 0)
+	})
+	t.Run("flushdb", func(t *testing.T) {
+		db, changes := getDB(t)
+		_ = db.Str().Set("name", "alice")
+		err := db.Key().DeleteAll()
+		be.Err(t, err, nil)
+
+		list, _ := changes.Range(0, 10)
+		be.Equal(t, len(list), 2)
+		be.Equal(t, list[1].Key, "")
+		be.Equal(t, list[1].String(), "flushdb")
+	})
+	t.Run("disabled", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		changes := db.Changes()
+		last, _ := changes.Last()
+
+		_ = db.Str().Set("name", "alice")
+
+		list, _ := changes.Range(last, 10)
+		be.Equal(t, len(list), 0)
+	})
+}
+
+func TestLast(t *testing.T) {
+	db, changes := getDB(t)
+	last, err := changes.Last()
+	be.Err(t, err, nil)
+
+	_ = db.Str().Set("name", "alice")
+	_ = db.Str().Set("age", 25)
+
+	newLast, err := changes.Last()
+	be.Err(t, err, nil)
+	list, _ := changes.Range(last, 10)
+	be.Equal(t, len(list), 2)
+	be.Equal(t, newLast, list[1].Seq)
+}
+
+func TestRange(t *testing.T) {
+	db, changes := getDB(t)
+	_ = db.Str().Set("k1", 1)
+	_ = db.Str().Set("k2", 2)
+	_ = db.Str().Set("k3", 3)
+
+	list, err := changes.Range(0, 2)
+	be.Err(t, err, nil)
+	be.Equal(t, len(list), 2)
+	be.Equal(t, list[0].Key, "k1")
+	be.Equal(t, list[1].Key, "k2")
+
+	list, err = changes.Range(list[1].Seq, 2)
+	be.Err(t, err, nil)
+	be.Equal(t, len(list), 1)
+	be.Equal(t, list[0].Key, "k3")
+
+	list, err = changes.Range(list[0].Seq, 2)
+	be.Err(t, err, nil)
+	be.Equal(t, len(list), 0)
+}
+
+func TestTail(t *testing.T) {
+	db, changes := getDB(t)
+	_ = db.Str().Set("k1", 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var keys []string
+	errDone := errors.New("done")
+	done := make(chan error, 1)
+	go func() {
+		done <- changes.Tail(ctx, 0, func(c rchange.Change) error {
+			keys = append(keys, c.Key)
+			if len(keys) == 3 {
+				return errDone
+			}
+			return nil
+		})
+	}()
+
+	_ = db.Str().Set("k2", 2)
+	_ = db.Str().Set("k3", 3)
+
+	err := <-done
+	be.Equal(t, err, errDone)
+	be.Equal(t, keys, []string{"k1", "k2", "k3"})
+
+	// Canceling the context stops the tail.
+	cancel()
+	err = changes.Tail(ctx, 0, func(c rchange.Change) error { return nil })
+	be.Equal(t, err, context.Canceled)
+}
+
+func TestTrim(t *testing.T) {
+	t.Run("max len", func(t *testing.T) {
+		db, changes := getDB(t)
+		_ = db.Str().Set("k1", 1)
+		_ = db.Str().Set("k2", 2)
+		_ = db.Str().Set("k3", 3)
+
+		n, err := changes.Trim(2, 0)
+		be.Err(t, err, nil)
+		be.Equal(t, n, 1)
+
+		list, _ := changes.Range(0, 10)
+		be.Equal(t, len(list), 2)
+		be.Equal(t, list[0].Key, "k2")
+	})
+	t.Run("max age", func(t *testing.T) {
+		db, changes := getDB(t)
+		_ = db.Str().Set("k1", 1)
+		time.Sleep(10 * time.Millisecond)
+
+		n, err := changes.Trim(0, time.Hour)
+		be.Err(t, err, nil)
+		be.Equal(t, n, 0)
+
+		n, err = changes.Trim(0, 5*time.Millisecond)
+		be.Err(t, err, nil)
+		be.Equal(t, n, 1)
+	})
+	t.Run("no limits", func(t *testing.T) {
+		db, changes := getDB(t)
+		_ = db.Str().Set("k1", 1)
+
+		n, err := changes.Trim(0, 0)
+		be.Err(t, err, nil)
+		be.Equal(t, n, 0)
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	db, changes := getDB(t)
+	_ = db.Str().Set("k1", 1)
+	_ = db.Str().Set("k2", 2)
+	_ = db.Str().Set("k3", 3)
+	list, _ := changes.Range(0, 10)
+
+	n, err := changes.Truncate(list[1].Seq)
+	be.Err(t, err, nil)
+	be.Equal(t, n, 2)
+
+	list, _ = changes.Range(0, 10)
+	be.Equal(t, len(list), 1)
+	be.Equal(t, list[0].Key, "k3")
+}
+
+func getDB(tb testing.TB) (*redka.DB, *rchange.DB) {
+	tb.Helper()
+	db := testx.OpenDBWith(tb, &redka.Options{ChangeLog: true})
+	// Start with an empty change log.
+	_, err := db.Changes().Truncate(math.MaxInt64)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return db, db.Changes()
+}
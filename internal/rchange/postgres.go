@@ -0,0 +1,13 @@
+package rchange
+
+// Postgres queries for the change log.
+var postgres = queries{}
+
+func init() {
+	postgres.add = sqlite.add
+	postgres.last = sqlite.last
+	postgres.since = sqlite.since
+	postgres.trimAge = sqlite.trimAge
+	postgres.trimLen = sqlite.trimLen
+	postgres.truncate = sqlite.truncate
+}
@@ -0,0 +1,35 @@
+package rchange
+
+// SQLite queries for the change log.
+var sqlite = queries{
+	add: `
+	insert into
+	rchange (time, key, type, op, cmd)
+	values  (  $1,  $2,   $3, $4,  $5)`,
+
+	last: `
+	select coalesce(max(seq), 0) from rchange`,
+
+	since: `
+	select seq, time, key, type, op, cmd
+	from rchange
+	where seq > $1
+	order by seq asc
+	limit $2`,
+
+	trimAge: `
+	delete from rchange
+	where time < $1`,
+
+	trimLen: `
+	delete from rchange
+	where seq <= (
+		select seq from rchange
+		order by seq desc
+		limit 1 offset $1
+	)`,
+
+	truncate: `
+	delete from rchange
+	where seq <= $1`,
+}
@@ -0,0 +1,230 @@
+package rchange
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/sqlx"
+)
+
+// rangePageSize is the default number
+// of changes returned by Range.
+const rangePageSize = 100
+
+// SQL queries for the change log.
+type queries struct {
+	add      string
+	last     string
+	since    string
+	trimAge  string
+	trimLen  string
+	truncate string
+}
+
+// Change is an entry in the change log.
+// Describes a committed change to a key and the command
+// that reproduces it (e.g. SET name alice). Replaying
+// the commands in the order of sequence numbers
+// reproduces the changes made to the database.
+type Change struct {
+	Seq  int64       // sequence number, increases with each change
+	Time time.Time   // when the change was made
+	Key  string      // changed key, empty for database-wide changes
+	Type core.TypeID // key type, 0 for database-wide changes
+	Op   string      // operation name, e.g. "set", "lpush" or "expired"
+	Cmd  [][]byte    // command name and arguments, e.g. [set name alice]
+}
+
+// String returns the command that reproduces the change.
+func (c Change) String() string {
+	var b bytes.Buffer
+	for i, arg := range c.Cmd {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.Write(arg)
+	}
+	return b.String()
+}
+
+// Tx is a change log transaction.
+type Tx struct {
+	dialect sqlx.Dialect
+	tx      sqlx.Tx
+	sql     *queries
+}
+
+// NewTx creates a change log transaction
+// from a generic database transaction.
+func NewTx(dialect sqlx.Dialect, tx sqlx.Tx) *Tx {
+	sql := getSQL(dialect)
+	return &Tx{dialect: dialect, tx: tx, sql: sql}
+}
+
+// Last returns the sequence number of the last change.
+// If the log is empty, returns 0.
+func (tx *Tx) Last() (int64, error) {
+	var seq int64
+	err := tx.tx.QueryRow(tx.sql.last).Scan(&seq)
+	return seq, err
+}
+
+// Range returns the changes with sequence numbers
+// greater than since, ordered by sequence number.
+// Returns no more than count changes (0 = default).
+// Returns an empty slice when there are no more changes.
+func (tx *Tx) Range(since int64, count int) ([]Change, error) {
+	if count <= 0 {
+		count = rangePageSize
+	}
+	args := []any{since, count}
+	return sqlx.Select(tx.tx, tx.sql.since, args, scanChange)
+}
+
+// Record adds the change events to the log.
+// Ignores the events without a command.
+func (tx *Tx) Record(events []core.Event) error {
+	now := time.Now().UnixMilli()
+	for _, ev := range events {
+		if len(ev.Cmd) == 0 {
+			continue
+		}
+		cmd, err := encodeCmd(ev.Cmd)
+		if err != nil {
+			return err
+		}
+		args := []any{now, ev.Key, ev.Type, ev.Name, cmd}
+		_, err = tx.tx.Exec(tx.sql.add, args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Trim deletes the oldest changes, so that the log contains
+// no more than maxLen changes made no earlier than maxAge ago.
+// Zero maxLen or maxAge disables the corresponding limit.
+// Returns the number of deleted changes.
+func (tx *Tx) Trim(maxLen int, maxAge time.Duration) (int, error) {
+	var count int
+	if maxLen > 0 {
+		res, err := tx.tx.Exec(tx.sql.trimLen, maxLen)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		count += int(n)
+	}
+	if maxAge > 0 {
+		before := time.Now().Add(-maxAge).UnixMilli()
+		res, err := tx.tx.Exec(tx.sql.trimAge, before)
+		if err != nil {
+			return count, err
+		}
+		n, _ := res.RowsAffected()
+		count += int(n)
+	}
+	return count, nil
+}
+
+// Truncate deletes the changes with sequence numbers
+// less than or equal to upTo. Returns the number
+// of deleted changes.
+func (tx *Tx) Truncate(upTo int64) (int, error) {
+	res, err := tx.tx.Exec(tx.sql.truncate, upTo)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// scanChange scans a change from the current row.
+func scanChange(rows *sql.Rows) (Change, error) {
+	var c Change
+	var at int64
+	var cmd []byte
+	err := rows.Scan(&c.Seq, &at, &c.Key, &c.Type, &c.Op, &cmd)
+	if err != nil {
+		return Change{}, err
+	}
+	c.Time = time.UnixMilli(at)
+	c.Cmd, err = decodeCmd(cmd)
+	return c, err
+}
+
+// encodeCmd encodes the command as a RESP array of bulk strings
+// (the same way clients send commands to the Redis server).
+func encodeCmd(cmd []any) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("*" + strconv.Itoa(len(cmd)) + "\r\n")
+	for _, arg := range cmd {
+		var argb []byte
+		if n, ok := arg.(int64); ok {
+			argb = strconv.AppendInt(nil, n, 10)
+		} else {
+			var err error
+			argb, err = core.ToBytes(arg)
+			if err != nil {
+				return nil, err
+			}
+		}
+		b.WriteString("$" + strconv.Itoa(len(argb)) + "\r\n")
+		b.Write(argb)
+		b.WriteString("\r\n")
+	}
+	return b.Bytes(), nil
+}
+
+// decodeCmd decodes the command encoded by encodeCmd.
+func decodeCmd(data []byte) ([][]byte, error) {
+	r := bufio.NewReader(bytes.NewReader(data))
+	n, err := readLen(r, '*')
+	if err != nil {
+		return nil, err
+	}
+	cmd := make([][]byte, n)
+	for i := range cmd {
+		size, err := readLen(r, '$')
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil, err
+		}
+		cmd[i] = arg[:size]
+	}
+	return cmd, nil
+}
+
+// readLen reads a RESP length line with the given prefix.
+func readLen(r *bufio.Reader, prefix byte) (int, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	if len(line) < 3 || line[0] != prefix {
+		return 0, fmt.Errorf("invalid change command: %q", line)
+	}
+	return strconv.Atoi(line[1 : len(line)-2])
+}
+
+// getSQL returns the SQL queries for the specified dialect.
+func getSQL(dialect sqlx.Dialect) *queries {
+	switch dialect {
+	case sqlx.DialectSqlite:
+		return &sqlite
+	case sqlx.DialectPostgres:
+		return &postgres
+	default:
+		return &queries{}
+	}
+}
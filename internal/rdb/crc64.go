@@ -0,0 +1,20 @@
+package rdb
+
+import "hash/crc64"
+
+// Redis uses the CRC-64/Jones checksum (polynomial 0xad93d23594c935a9,
+// reflected input and output, zero initial value and final xor).
+// The table uses the reversed polynomial representation.
+var crcTable = crc64.MakeTable(0x95ac9329ac4bc9b5)
+
+// crcUpdate returns the result of adding the bytes in p to the crc.
+// Go's crc64 inverts the crc before and after the update,
+// so invert it here too to get the plain Jones checksum.
+func crcUpdate(crc uint64, p []byte) uint64 {
+	return ^crc64.Update(^crc, crcTable, p)
+}
+
+// Checksum returns the Redis CRC-64 checksum of the data.
+func Checksum(p []byte) uint64 {
+	return crcUpdate(0, p)
+}
@@ -0,0 +1,346 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+// cursor reads the bytes of an encoded blob
+// (e.g. a ziplist or a listpack) with bounds checking.
+type cursor struct {
+	b   []byte
+	pos int
+}
+
+// next returns the next n bytes.
+func (c *cursor) next(n int) ([]byte, error) {
+	if n < 0 || c.pos+n > len(c.b) {
+		return nil, ErrFormat
+	}
+	p := c.b[c.pos : c.pos+n]
+	c.pos += n
+	return p, nil
+}
+
+// byte returns the next byte.
+func (c *cursor) byte() (byte, error) {
+	if c.pos >= len(c.b) {
+		return 0, ErrFormat
+	}
+	b := c.b[c.pos]
+	c.pos++
+	return b, nil
+}
+
+// decodeLZF decompresses the LZF-compressed data
+// into a slice of the given length.
+func decodeLZF(in []byte, n int) ([]byte, error) {
+	out := make([]byte, 0, n)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+		if ctrl < 1<<5 {
+			// Literal run of ctrl+1 bytes.
+			size := ctrl + 1
+			if i+size > len(in) || len(out)+size > n {
+				return nil, ErrFormat
+			}
+			out = append(out, in[i:i+size]...)
+			i += size
+			continue
+		}
+		// Back reference.
+		size := ctrl >> 5
+		if size == 7 {
+			if i >= len(in) {
+				return nil, ErrFormat
+			}
+			size += int(in[i])
+			i++
+		}
+		size += 2
+		if i >= len(in) {
+			return nil, ErrFormat
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - int(in[i]) - 1
+		i++
+		if ref < 0 || len(out)+size > n {
+			return nil, ErrFormat
+		}
+		// The reference may overlap with the bytes
+		// being copied, so copy byte by byte.
+		for j := 0; j < size; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != n {
+		return nil, ErrFormat
+	}
+	return out, nil
+}
+
+// decodeZiplist returns the entries of the ziplist.
+// Integer entries are returned as decimal strings.
+func decodeZiplist(b []byte) ([][]byte, error) {
+	// Skip zlbytes, zltail and zllen.
+	c := cursor{b: b, pos: 10}
+	var entries [][]byte
+	for {
+		prevlen, err := c.byte()
+		if err != nil {
+			return nil, err
+		}
+		if prevlen == 0xff {
+			return entries, nil
+		}
+		if prevlen == 0xfe {
+			if _, err := c.next(4); err != nil {
+				return nil, err
+			}
+		}
+		entry, err := decodeZiplistEntry(&c)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// decodeZiplistEntry decodes the ziplist entry
+// at the cursor position (after the prevlen field).
+func decodeZiplistEntry(c *cursor) ([]byte, error) {
+	enc, err := c.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case enc>>6 == 0:
+		// 6-bit string length.
+		return c.next(int(enc & 0x3f))
+	case enc>>6 == 1:
+		// 14-bit string length.
+		b, err := c.byte()
+		if err != nil {
+			return nil, err
+		}
+		return c.next(int(enc&0x3f)<<8 | int(b))
+	case enc>>6 == 2:
+		// 32-bit string length.
+		p, err := c.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return c.next(int(binary.BigEndian.Uint32(p)))
+	case enc == 0xc0:
+		return readIntLE(c, 2)
+	case enc == 0xd0:
+		return readIntLE(c, 4)
+	case enc == 0xe0:
+		return readIntLE(c, 8)
+	case enc == 0xf0:
+		return readIntLE(c, 3)
+	case enc == 0xfe:
+		return readIntLE(c, 1)
+	case enc >= 0xf1 && enc <= 0xfd:
+		// 4-bit immediate integer from 0 to 12.
+		return strconv.AppendInt(nil, int64(enc&0x0f)-1, 10), nil
+	default:
+		return nil, ErrFormat
+	}
+}
+
+// decodeListpack returns the entries of the listpack.
+// Integer entries are returned as decimal strings.
+func decodeListpack(b []byte) ([][]byte, error) {
+	// Skip the total bytes and the number of elements.
+	c := cursor{b: b, pos: 6}
+	var entries [][]byte
+	for {
+		enc, err := c.byte()
+		if err != nil {
+			return nil, err
+		}
+		if enc == 0xff {
+			return entries, nil
+		}
+		start := c.pos - 1
+		entry, err := decodeListpackEntry(&c, enc)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		// Skip the backlen field.
+		if _, err := c.next(backlenSize(c.pos - start)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// decodeListpackEntry decodes the listpack entry
+// with the given encoding byte.
+func decodeListpackEntry(c *cursor, enc byte) ([]byte, error) {
+	switch {
+	case enc&0x80 == 0:
+		// 7-bit unsigned integer.
+		return strconv.AppendInt(nil, int64(enc), 10), nil
+	case enc&0xc0 == 0x80:
+		// 6-bit string length.
+		return c.next(int(enc & 0x3f))
+	case enc&0xe0 == 0xc0:
+		// 13-bit signed integer.
+		b, err := c.byte()
+		if err != nil {
+			return nil, err
+		}
+		val := int64(enc&0x1f)<<8 | int64(b)
+		if val >= 1<<12 {
+			val -= 1 << 13
+		}
+		return strconv.AppendInt(nil, val, 10), nil
+	case enc&0xf0 == 0xe0:
+		// 12-bit string length.
+		b, err := c.byte()
+		if err != nil {
+			return nil, err
+		}
+		return c.next(int(enc&0x0f)<<8 | int(b))
+	case enc == 0xf0:
+		// 32-bit string length.
+		p, err := c.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return c.next(int(binary.LittleEndian.Uint32(p)))
+	case enc == 0xf1:
+		return readIntLE(c, 2)
+	case enc == 0xf2:
+		return readIntLE(c, 3)
+	case enc == 0xf3:
+		return readIntLE(c, 4)
+	case enc == 0xf4:
+		return readIntLE(c, 8)
+	default:
+		return nil, ErrFormat
+	}
+}
+
+// backlenSize returns the size of the listpack backlen
+// field for the entry of the given size.
+func backlenSize(size int) int {
+	switch {
+	case size <= 127:
+		return 1
+	case size < 16383:
+		return 2
+	case size < 2097151:
+		return 3
+	case size < 268435455:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// decodeIntset returns the intset elements as decimal strings.
+func decodeIntset(b []byte) ([][]byte, error) {
+	c := cursor{b: b}
+	header, err := c.next(8)
+	if err != nil {
+		return nil, err
+	}
+	size := int(binary.LittleEndian.Uint32(header[0:4]))
+	n := int(binary.LittleEndian.Uint32(header[4:8]))
+	if size != 2 && size != 4 && size != 8 {
+		return nil, ErrFormat
+	}
+	if n*size != len(b)-8 {
+		return nil, ErrFormat
+	}
+	elems := make([][]byte, n)
+	for i := range elems {
+		elems[i], err = readIntLE(&c, size)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return elems, nil
+}
+
+// decodeZipmap returns the zipmap fields and values,
+// interleaved (field1, value1, field2, value2, ...).
+func decodeZipmap(b []byte) ([][]byte, error) {
+	// Skip zmlen.
+	c := cursor{b: b, pos: 1}
+	var entries [][]byte
+	for {
+		n, end, err := zipmapLen(&c)
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			break
+		}
+		field, err := c.next(n)
+		if err != nil {
+			return nil, err
+		}
+		n, end, err = zipmapLen(&c)
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			return nil, ErrFormat
+		}
+		free, err := c.byte()
+		if err != nil {
+			return nil, err
+		}
+		value, err := c.next(n)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := c.next(int(free)); err != nil {
+			return nil, err
+		}
+		entries = append(entries, field, value)
+	}
+	return entries, nil
+}
+
+// zipmapLen reads the zipmap length field.
+// Reports true if it's the end of the zipmap instead.
+func zipmapLen(c *cursor) (int, bool, error) {
+	b, err := c.byte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch b {
+	case 0xff:
+		return 0, true, nil
+	case 0xfe:
+		p, err := c.next(4)
+		if err != nil {
+			return 0, false, err
+		}
+		return int(binary.LittleEndian.Uint32(p)), false, nil
+	default:
+		return int(b), false, nil
+	}
+}
+
+// readIntLE reads a little-endian signed integer
+// of the given size and returns it as a decimal string.
+func readIntLE(c *cursor, size int) ([]byte, error) {
+	p, err := c.next(size)
+	if err != nil {
+		return nil, err
+	}
+	var val int64
+	for i := size - 1; i >= 0; i-- {
+		val = val<<8 | int64(p[i])
+	}
+	// Sign-extend the value.
+	shift := 64 - 8*size
+	val = val << shift >> shift
+	return strconv.AppendInt(nil, val, 10), nil
+}
@@ -0,0 +1,119 @@
+// Package rdb reads and writes Redis RDB files.
+// The reader supports versions 9 to 12 with strings, lists,
+// sets, hashes and sorted sets in all their encodings (including
+// ziplists, listpacks, quicklists and intsets), expiration times
+// and LZF-compressed strings. Streams and module types are
+// not supported. The writer uses version 9. The package also
+// implements the value serialization of the DUMP and RESTORE
+// commands.
+package rdb
+
+import (
+	"errors"
+
+	"github.com/nalgeon/redka/internal/core"
+)
+
+// Supported RDB versions.
+const (
+	MinVersion = 9
+	MaxVersion = 12
+)
+
+// Errors returned by the reader.
+var (
+	ErrFormat   = errors.New("invalid rdb format")
+	ErrChecksum = errors.New("rdb checksum mismatch")
+	ErrVersion  = errors.New("unsupported rdb version")
+)
+
+// Opcodes.
+const (
+	opSlotInfo      = 0xf4
+	opFunction2     = 0xf5
+	opFunctionPreGA = 0xf6
+	opModuleAux     = 0xf7
+	opIdle          = 0xf8
+	opFreq          = 0xf9
+	opAux           = 0xfa
+	opResizeDB      = 0xfb
+	opExpireTimeMs  = 0xfc
+	opExpireTime    = 0xfd
+	opSelectDB      = 0xfe
+	opEOF           = 0xff
+)
+
+// Value types.
+const (
+	typeString          = 0
+	typeList            = 1
+	typeSet             = 2
+	typeZSet            = 3
+	typeHash            = 4
+	typeZSet2           = 5
+	typeModule          = 6
+	typeModule2         = 7
+	typeHashZipmap      = 9
+	typeListZiplist     = 10
+	typeSetIntset       = 11
+	typeZSetZiplist     = 12
+	typeHashZiplist     = 13
+	typeListQuicklist   = 14
+	typeStreamListpacks = 15
+	typeHashListpack    = 16
+	typeZSetListpack    = 17
+	typeListQuicklist2  = 18
+	typeStreamListpack2 = 19
+	typeSetListpack     = 20
+	typeStreamListpack3 = 21
+)
+
+// Quicklist node containers.
+const (
+	quicklistPlain  = 1
+	quicklistPacked = 2
+)
+
+// Entry is a key stored in the RDB file along with its value.
+// Depending on the key type, the value is in Str (strings),
+// Elems (lists and sets), Fields (hashes) or Items (sorted sets).
+type Entry struct {
+	DB    int         // Redis database number
+	Key   string      // key name
+	Type  core.TypeID // key type
+	ETime int64       // expiration time in unix milliseconds, 0 if none
+
+	Str    []byte   // string value
+	Elems  [][]byte // list elements (in order) or set elements
+	Fields []Field  // hash fields
+	Items  []Item   // sorted set elements
+}
+
+// Len returns the number of elements in the value
+// (1 for strings).
+func (e Entry) Len() int {
+	switch e.Type {
+	case core.TypeString:
+		return 1
+	case core.TypeList, core.TypeSet:
+		return len(e.Elems)
+	case core.TypeHash:
+		return len(e.Fields)
+	case core.TypeZSet:
+		return len(e.Items)
+	default:
+		return 0
+	}
+}
+
+// Field is a hash field-value pair.
+type Field struct {
+	Name  []byte
+	Value []byte
+}
+
+// Item is a sorted set element with its score.
+type Item struct {
+	Elem  []byte
+	Score float64
+}
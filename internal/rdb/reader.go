@@ -0,0 +1,591 @@
+package rdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/nalgeon/redka/internal/core"
+)
+
+// Maximum string length (same as Redis' proto-max-bulk-len).
+// Protects from allocating huge buffers on corrupted files.
+const maxStringLen = 512 * 1024 * 1024
+
+// Reader reads the keys from an RDB file.
+type Reader struct {
+	rd      *bufio.Reader
+	crc     uint64
+	version int
+	db      int
+	done    bool
+	one     [1]byte // buffer for single-byte checksum updates
+}
+
+// NewReader creates a reader that reads the RDB file from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{rd: bufio.NewReader(r)}
+}
+
+// Version returns the RDB version of the file.
+// Only valid after the first call to Read.
+func (r *Reader) Version() int {
+	return r.version
+}
+
+// Read returns the next key from the file.
+// Returns io.EOF at the end of the file, after
+// verifying the checksum (if the file has one).
+func (r *Reader) Read() (Entry, error) {
+	if r.done {
+		return Entry{}, io.EOF
+	}
+	if r.version == 0 {
+		if err := r.readHeader(); err != nil {
+			return Entry{}, err
+		}
+	}
+
+	var etime int64
+	for {
+		op, err := r.readByte()
+		if err != nil {
+			return Entry{}, err
+		}
+		switch op {
+		case opEOF:
+			if err := r.readChecksum(); err != nil {
+				return Entry{}, err
+			}
+			r.done = true
+			return Entry{}, io.EOF
+		case opSelectDB:
+			n, err := r.readLen()
+			if err != nil {
+				return Entry{}, err
+			}
+			r.db = int(n)
+		case opResizeDB:
+			// Hash table sizes, not needed.
+			if err := r.skipLen(2); err != nil {
+				return Entry{}, err
+			}
+		case opSlotInfo:
+			// Cluster slot info, not needed.
+			if err := r.skipLen(3); err != nil {
+				return Entry{}, err
+			}
+		case opAux:
+			// Auxiliary fields (e.g. redis-ver), not needed.
+			if err := r.skipString(2); err != nil {
+				return Entry{}, err
+			}
+		case opFunction2:
+			// Function library code, not supported.
+			if err := r.skipString(1); err != nil {
+				return Entry{}, err
+			}
+		case opFreq:
+			if _, err := r.readByte(); err != nil {
+				return Entry{}, err
+			}
+		case opIdle:
+			if err := r.skipLen(1); err != nil {
+				return Entry{}, err
+			}
+		case opExpireTime:
+			p, err := r.readN(4)
+			if err != nil {
+				return Entry{}, err
+			}
+			etime = int64(binary.LittleEndian.Uint32(p)) * 1000
+		case opExpireTimeMs:
+			p, err := r.readN(8)
+			if err != nil {
+				return Entry{}, err
+			}
+			etime = int64(binary.LittleEndian.Uint64(p))
+		case opModuleAux, opFunctionPreGA:
+			return Entry{}, fmt.Errorf("%w: unsupported opcode 0x%x", ErrFormat, op)
+		default:
+			entry, err := r.readEntry(op)
+			if err != nil {
+				return Entry{}, err
+			}
+			entry.DB = r.db
+			entry.ETime = etime
+			return entry, nil
+		}
+	}
+}
+
+// readHeader reads the magic string and the version.
+func (r *Reader) readHeader() error {
+	p, err := r.readN(9)
+	if err != nil {
+		return err
+	}
+	if string(p[:5]) != "REDIS" {
+		return fmt.Errorf("%w: not an rdb file", ErrFormat)
+	}
+	version, err := strconv.Atoi(string(p[5:]))
+	if err != nil {
+		return fmt.Errorf("%w: invalid version %q", ErrFormat, p[5:])
+	}
+	if version < MinVersion || version > MaxVersion {
+		return fmt.Errorf("%w: %d", ErrVersion, version)
+	}
+	r.version = version
+	return nil
+}
+
+// readChecksum reads and verifies the checksum
+// at the end of the file. A zero checksum means
+// the checksum is disabled.
+func (r *Reader) readChecksum() error {
+	want := r.crc
+	p, err := r.readN(8)
+	if err != nil {
+		return err
+	}
+	got := binary.LittleEndian.Uint64(p)
+	if got != 0 && got != want {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// readEntry reads the key and the value of the given type.
+func (r *Reader) readEntry(vtype byte) (Entry, error) {
+	key, err := r.readString()
+	if err != nil {
+		return Entry{}, err
+	}
+	entry, err := r.readValue(vtype)
+	if err != nil {
+		return Entry{}, fmt.Errorf("key %q: %w", key, err)
+	}
+	entry.Key = string(key)
+	return entry, nil
+}
+
+// readValue reads the value of the given type.
+func (r *Reader) readValue(vtype byte) (Entry, error) {
+	var entry Entry
+	var err error
+	switch vtype {
+	case typeString:
+		entry.Type = core.TypeString
+		entry.Str, err = r.readString()
+
+	case typeList:
+		entry.Type = core.TypeList
+		entry.Elems, err = r.readStrings()
+	case typeListZiplist:
+		entry.Type = core.TypeList
+		entry.Elems, err = r.readEncoded(decodeZiplist)
+	case typeListQuicklist:
+		entry.Type = core.TypeList
+		entry.Elems, err = r.readQuicklist()
+	case typeListQuicklist2:
+		entry.Type = core.TypeList
+		entry.Elems, err = r.readQuicklist2()
+
+	case typeSet:
+		entry.Type = core.TypeSet
+		entry.Elems, err = r.readStrings()
+	case typeSetIntset:
+		entry.Type = core.TypeSet
+		entry.Elems, err = r.readEncoded(decodeIntset)
+	case typeSetListpack:
+		entry.Type = core.TypeSet
+		entry.Elems, err = r.readEncoded(decodeListpack)
+
+	case typeHash:
+		entry.Type = core.TypeHash
+		entry.Fields, err = r.readHash()
+	case typeHashZipmap:
+		entry.Type = core.TypeHash
+		entry.Fields, err = r.readEncodedHash(decodeZipmap)
+	case typeHashZiplist:
+		entry.Type = core.TypeHash
+		entry.Fields, err = r.readEncodedHash(decodeZiplist)
+	case typeHashListpack:
+		entry.Type = core.TypeHash
+		entry.Fields, err = r.readEncodedHash(decodeListpack)
+
+	case typeZSet, typeZSet2:
+		entry.Type = core.TypeZSet
+		entry.Items, err = r.readZSet(vtype == typeZSet2)
+	case typeZSetZiplist:
+		entry.Type = core.TypeZSet
+		entry.Items, err = r.readEncodedZSet(decodeZiplist)
+	case typeZSetListpack:
+		entry.Type = core.TypeZSet
+		entry.Items, err = r.readEncodedZSet(decodeListpack)
+
+	case typeStreamListpacks, typeStreamListpack2, typeStreamListpack3:
+		return Entry{}, fmt.Errorf("%w: streams are not supported", ErrFormat)
+	case typeModule, typeModule2:
+		return Entry{}, fmt.Errorf("%w: modules are not supported", ErrFormat)
+	default:
+		return Entry{}, fmt.Errorf("%w: unsupported value type %d", ErrFormat, vtype)
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// readStrings reads a length-prefixed sequence of strings.
+func (r *Reader) readStrings() ([][]byte, error) {
+	n, err := r.readLen()
+	if err != nil {
+		return nil, err
+	}
+	elems := make([][]byte, 0, min(n, 1024))
+	for range n {
+		elem, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+	}
+	return elems, nil
+}
+
+// readHash reads a length-prefixed sequence of field-value pairs.
+func (r *Reader) readHash() ([]Field, error) {
+	n, err := r.readLen()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]Field, 0, min(n, 1024))
+	for range n {
+		name, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		value, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field{Name: name, Value: value})
+	}
+	return fields, nil
+}
+
+// readZSet reads a length-prefixed sequence of element-score pairs.
+// The scores are either binary or string-encoded doubles.
+func (r *Reader) readZSet(binaryScores bool) ([]Item, error) {
+	n, err := r.readLen()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0, min(n, 1024))
+	for range n {
+		elem, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		var score float64
+		if binaryScores {
+			score, err = r.readBinaryDouble()
+		} else {
+			score, err = r.readStringDouble()
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, Item{Elem: elem, Score: score})
+	}
+	return items, nil
+}
+
+// readQuicklist reads a quicklist of ziplists (RDB 9).
+func (r *Reader) readQuicklist() ([][]byte, error) {
+	n, err := r.readLen()
+	if err != nil {
+		return nil, err
+	}
+	var elems [][]byte
+	for range n {
+		node, err := r.readEncoded(decodeZiplist)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, node...)
+	}
+	return elems, nil
+}
+
+// readQuicklist2 reads a quicklist of listpacks
+// and plain elements (RDB 10+).
+func (r *Reader) readQuicklist2() ([][]byte, error) {
+	n, err := r.readLen()
+	if err != nil {
+		return nil, err
+	}
+	var elems [][]byte
+	for range n {
+		container, err := r.readLen()
+		if err != nil {
+			return nil, err
+		}
+		switch container {
+		case quicklistPlain:
+			elem, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		case quicklistPacked:
+			node, err := r.readEncoded(decodeListpack)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, node...)
+		default:
+			return nil, fmt.Errorf("%w: invalid quicklist container %d", ErrFormat, container)
+		}
+	}
+	return elems, nil
+}
+
+// readEncoded reads a string and decodes it as
+// an encoded blob (e.g. a ziplist or a listpack).
+func (r *Reader) readEncoded(decode func([]byte) ([][]byte, error)) ([][]byte, error) {
+	b, err := r.readString()
+	if err != nil {
+		return nil, err
+	}
+	return decode(b)
+}
+
+// readEncodedHash reads an encoded blob
+// of interleaved fields and values.
+func (r *Reader) readEncodedHash(decode func([]byte) ([][]byte, error)) ([]Field, error) {
+	entries, err := r.readEncoded(decode)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries)%2 != 0 {
+		return nil, ErrFormat
+	}
+	fields := make([]Field, 0, len(entries)/2)
+	for i := 0; i < len(entries); i += 2 {
+		fields = append(fields, Field{Name: entries[i], Value: entries[i+1]})
+	}
+	return fields, nil
+}
+
+// readEncodedZSet reads an encoded blob
+// of interleaved elements and scores.
+func (r *Reader) readEncodedZSet(decode func([]byte) ([][]byte, error)) ([]Item, error) {
+	entries, err := r.readEncoded(decode)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries)%2 != 0 {
+		return nil, ErrFormat
+	}
+	items := make([]Item, 0, len(entries)/2)
+	for i := 0; i < len(entries); i += 2 {
+		score, err := strconv.ParseFloat(string(entries[i+1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid score %q", ErrFormat, entries[i+1])
+		}
+		items = append(items, Item{Elem: entries[i], Score: score})
+	}
+	return items, nil
+}
+
+// readLen reads a length-encoded number.
+// Fails on special string encodings.
+func (r *Reader) readLen() (uint64, error) {
+	n, special, err := r.readLenOrEnc()
+	if err != nil {
+		return 0, err
+	}
+	if special {
+		return 0, fmt.Errorf("%w: unexpected string encoding", ErrFormat)
+	}
+	return n, nil
+}
+
+// readLenOrEnc reads a length-encoded number.
+// Reports true if it's a special string encoding
+// instead (the number is the encoding type then).
+func (r *Reader) readLenOrEnc() (uint64, bool, error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch b >> 6 {
+	case 0:
+		// 6-bit length.
+		return uint64(b & 0x3f), false, nil
+	case 1:
+		// 14-bit length.
+		next, err := r.readByte()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(b&0x3f)<<8 | uint64(next), false, nil
+	case 2:
+		// 32- or 64-bit length.
+		switch b {
+		case 0x80:
+			p, err := r.readN(4)
+			if err != nil {
+				return 0, false, err
+			}
+			return uint64(binary.BigEndian.Uint32(p)), false, nil
+		case 0x81:
+			p, err := r.readN(8)
+			if err != nil {
+				return 0, false, err
+			}
+			return binary.BigEndian.Uint64(p), false, nil
+		default:
+			return 0, false, fmt.Errorf("%w: invalid length encoding 0x%x", ErrFormat, b)
+		}
+	default:
+		// Special string encoding.
+		return uint64(b & 0x3f), true, nil
+	}
+}
+
+// readString reads a string, which may be stored
+// as an integer or compressed with LZF.
+func (r *Reader) readString() ([]byte, error) {
+	n, special, err := r.readLenOrEnc()
+	if err != nil {
+		return nil, err
+	}
+	if !special {
+		if n > maxStringLen {
+			return nil, fmt.Errorf("%w: string is too long", ErrFormat)
+		}
+		return r.readN(int(n))
+	}
+
+	switch n {
+	case 0, 1, 2:
+		// 8-, 16- or 32-bit integer.
+		p, err := r.readN(1 << n)
+		if err != nil {
+			return nil, err
+		}
+		return readIntLE(&cursor{b: p}, len(p))
+	case 3:
+		// LZF-compressed string.
+		clen, err := r.readLen()
+		if err != nil {
+			return nil, err
+		}
+		ulen, err := r.readLen()
+		if err != nil {
+			return nil, err
+		}
+		if clen > maxStringLen || ulen > maxStringLen {
+			return nil, fmt.Errorf("%w: string is too long", ErrFormat)
+		}
+		p, err := r.readN(int(clen))
+		if err != nil {
+			return nil, err
+		}
+		return decodeLZF(p, int(ulen))
+	default:
+		return nil, fmt.Errorf("%w: invalid string encoding %d", ErrFormat, n)
+	}
+}
+
+// readBinaryDouble reads a little-endian binary double.
+func (r *Reader) readBinaryDouble() (float64, error) {
+	p, err := r.readN(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(p)), nil
+}
+
+// readStringDouble reads a string-encoded double
+// (used by the old sorted set encoding).
+func (r *Reader) readStringDouble() (float64, error) {
+	n, err := r.readByte()
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 253:
+		return math.NaN(), nil
+	case 254:
+		return math.Inf(1), nil
+	case 255:
+		return math.Inf(-1), nil
+	}
+	p, err := r.readN(int(n))
+	if err != nil {
+		return 0, err
+	}
+	val, err := strconv.ParseFloat(string(p), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid score %q", ErrFormat, p)
+	}
+	return val, nil
+}
+
+// skipLen skips n length-encoded numbers.
+func (r *Reader) skipLen(n int) error {
+	for range n {
+		if _, err := r.readLen(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipString skips n strings.
+func (r *Reader) skipString(n int) error {
+	for range n {
+		if _, err := r.readString(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readByte reads a single byte.
+func (r *Reader) readByte() (byte, error) {
+	b, err := r.rd.ReadByte()
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	r.one[0] = b
+	r.crc = crcUpdate(r.crc, r.one[:])
+	return b, nil
+}
+
+// readN reads exactly n bytes.
+func (r *Reader) readN(n int) ([]byte, error) {
+	p := make([]byte, n)
+	if _, err := io.ReadFull(r.rd, p); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	r.crc = crcUpdate(r.crc, p)
+	return p, nil
+}
+
+// unexpectedEOF converts io.EOF to io.ErrUnexpectedEOF,
+// because the file must end with the EOF opcode.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
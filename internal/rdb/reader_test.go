@@ -0,0 +1,193 @@
+package rdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/core"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		path    string
+		version int
+		want    []string
+	}{
+		{
+			path:    "testdata/v9.rdb",
+			version: 9,
+			want: []string{
+				"0 name string 0 alice",
+				"0 age string 0 25",
+				"0 int16 string 0 -12345",
+				"0 int32 string 0 1234567890",
+				"0 long string 0 " + strings.Repeat("x", 100),
+				"0 lzf string 0 " + strings.Repeat("abc", 10),
+				"0 session string 4102444800000 token",
+				"0 expired string 1000000000000 gone",
+				"0 list list 0 [a b 12 -100 c 1000 100000 100000000 10000000000]",
+				"0 ziplist list 0 [x y]",
+				"0 list-plain list 0 [a b]",
+				"0 intset set 0 [1 2 3]",
+				"0 intset64 set 0 [-1 10000000000]",
+				"0 set set 0 [go sql]",
+				"0 hash hash 0 [name=alice age=25]",
+				"0 hash-plain hash 0 [f1=v1]",
+				"0 zipmap hash 0 [f1=v1 f2=v2]",
+				"0 zset zset 0 [a=1 b=2.5]",
+				"0 zset2 zset 0 [a=1.5 b=-2]",
+				"0 zset-old zset 0 [a=1.5 b=+Inf]",
+			},
+		},
+		{
+			path:    "testdata/v11.rdb",
+			version: 11,
+			want: []string{
+				"0 name string 0 alice",
+				"0 age string 0 25",
+				"0 list list 0 [a 1 -2 5000 100000 3000000000 " + strings.Repeat("y", 70) + " pppppppppp]",
+				"0 hash hash 0 [name=alice age=25]",
+				"0 zset zset 0 [a=1 b=2.5 c=-3]",
+				"0 set set 0 [go sql]",
+				"0 tags set 4102444800000 [70000 -70000]",
+				"1 db1 string 0 value",
+			},
+		},
+		{
+			path:    "testdata/v12.rdb",
+			version: 12,
+			want: []string{
+				"0 name string 0 alice",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			f, err := os.Open(test.path)
+			be.Err(t, err, nil)
+			defer f.Close()
+
+			r := NewReader(f)
+			var got []string
+			for {
+				entry, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				be.Err(t, err, nil)
+				got = append(got, formatEntry(entry))
+			}
+			be.Equal(t, r.Version(), test.version)
+			be.Equal(t, got, test.want)
+
+			_, err = r.Read()
+			be.Equal(t, err, io.EOF)
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	valid, err := os.ReadFile("testdata/v9.rdb")
+	be.Err(t, err, nil)
+
+	t.Run("not rdb", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader("HELLO0009")).Read()
+		be.Err(t, err, ErrFormat)
+	})
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader("REDIS0008\xff")).Read()
+		be.Err(t, err, ErrVersion)
+		_, err = NewReader(strings.NewReader("REDIS0013\xff")).Read()
+		be.Err(t, err, ErrVersion)
+	})
+	t.Run("checksum", func(t *testing.T) {
+		data := bytes.Clone(valid)
+		data[len(data)-1] ^= 0xff
+		err := readAll(data)
+		be.Err(t, err, ErrChecksum)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		err := readAll(valid[:len(valid)-20])
+		be.Err(t, err, io.ErrUnexpectedEOF)
+	})
+	t.Run("stream", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader("REDIS0011\x0f\x03key")).Read()
+		be.Err(t, err, ErrFormat)
+		be.Err(t, err, "streams are not supported")
+	})
+	t.Run("module aux", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader("REDIS0011\xf7")).Read()
+		be.Err(t, err, ErrFormat)
+	})
+}
+
+func TestDecodeLZF(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		// Literal "abc" followed by a back reference
+		// that repeats it 9 more times.
+		in := []byte{0x02, 'a', 'b', 'c', 0xe0, 0x12, 0x02}
+		out, err := decodeLZF(in, 30)
+		be.Err(t, err, nil)
+		be.Equal(t, string(out), strings.Repeat("abc", 10))
+	})
+	t.Run("invalid reference", func(t *testing.T) {
+		in := []byte{0x00, 'a', 0x20, 0x05}
+		_, err := decodeLZF(in, 4)
+		be.Err(t, err, ErrFormat)
+	})
+	t.Run("length mismatch", func(t *testing.T) {
+		in := []byte{0x02, 'a', 'b', 'c'}
+		_, err := decodeLZF(in, 5)
+		be.Err(t, err, ErrFormat)
+	})
+}
+
+func TestChecksum(t *testing.T) {
+	be.Equal(t, Checksum([]byte("123456789")), uint64(0xe9c6d914c4b8d9ca))
+}
+
+// readAll reads all the keys from the RDB data.
+func readAll(data []byte) error {
+	r := NewReader(bytes.NewReader(data))
+	for {
+		_, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// formatEntry formats the entry as
+// "db key type etime value" for comparison.
+func formatEntry(e Entry) string {
+	var val string
+	switch {
+	case e.Str != nil:
+		val = string(e.Str)
+	case e.Elems != nil:
+		val = fmt.Sprintf("%s", e.Elems)
+	case e.Fields != nil:
+		parts := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			parts[i] = string(f.Name) + "=" + string(f.Value)
+		}
+		val = "[" + strings.Join(parts, " ") + "]"
+	case e.Items != nil:
+		parts := make([]string, len(e.Items))
+		for i, item := range e.Items {
+			parts[i] = fmt.Sprintf("%s=%v", item.Elem, item.Score)
+		}
+		val = "[" + strings.Join(parts, " ") + "]"
+	}
+	typ := core.Key{Type: e.Type}.TypeName()
+	return fmt.Sprintf("%d %s %s %d %s", e.DB, e.Key, typ, e.ETime, val)
+}
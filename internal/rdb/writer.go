@@ -0,0 +1,205 @@
+package rdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/nalgeon/redka/internal/core"
+)
+
+// Version is the RDB version used by the writer.
+// Redis 5.0 and later can load it.
+const Version = 9
+
+// Writer writes the keys to an RDB file.
+// Uses the plain (non-compact) value encodings,
+// which all Redis versions since 5.0 can load.
+type Writer struct {
+	wr      *bufio.Writer
+	crc     uint64
+	started bool
+	buf     []byte
+}
+
+// NewWriter creates a writer that writes the RDB file to w.
+// Call Close to finish the file.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{wr: bufio.NewWriter(w)}
+}
+
+// Write writes the key to the file.
+// Uses the Key, Type, ETime and the value fields of the entry.
+func (w *Writer) Write(e Entry) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	buf := w.buf[:0]
+	if e.ETime != 0 {
+		buf = append(buf, opExpireTimeMs)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(e.ETime))
+	}
+	vtype, err := valueType(e.Type)
+	if err != nil {
+		return fmt.Errorf("key %q: %w", e.Key, err)
+	}
+	buf = append(buf, vtype)
+	buf = appendString(buf, []byte(e.Key))
+	buf = appendValue(buf, e)
+	w.buf = buf
+	return w.write(buf)
+}
+
+// Close writes the end of the file with the checksum
+// and flushes the buffered data. Does not close
+// the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.write([]byte{opEOF}); err != nil {
+		return err
+	}
+	sum := binary.LittleEndian.AppendUint64(nil, w.crc)
+	if _, err := w.wr.Write(sum); err != nil {
+		return err
+	}
+	return w.wr.Flush()
+}
+
+// start writes the file header if it's not written yet.
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	header := fmt.Appendf(nil, "REDIS%04d", Version)
+	header = append(header, opSelectDB)
+	header = appendLen(header, 0)
+	return w.write(header)
+}
+
+// write writes the bytes and updates the checksum.
+func (w *Writer) write(p []byte) error {
+	w.crc = crcUpdate(w.crc, p)
+	_, err := w.wr.Write(p)
+	return err
+}
+
+// Dump serializes the entry value in the format of
+// the Redis DUMP command: the value type, the value itself,
+// the RDB version and the checksum. The key name and the
+// expiration time are not included.
+func Dump(e Entry) ([]byte, error) {
+	vtype, err := valueType(e.Type)
+	if err != nil {
+		return nil, err
+	}
+	buf := []byte{vtype}
+	buf = appendValue(buf, e)
+	buf = binary.LittleEndian.AppendUint16(buf, Version)
+	return binary.LittleEndian.AppendUint64(buf, Checksum(buf)), nil
+}
+
+// Load deserializes the value serialized by Dump
+// (or by the Redis DUMP command). Verifies the RDB version
+// and the checksum. The Key and ETime fields of
+// the returned entry are not set.
+func Load(payload []byte) (Entry, error) {
+	if len(payload) < 10 {
+		return Entry{}, ErrFormat
+	}
+	footer := payload[len(payload)-10:]
+	version := binary.LittleEndian.Uint16(footer[:2])
+	if version > MaxVersion {
+		return Entry{}, fmt.Errorf("%w: %d", ErrVersion, version)
+	}
+	sum := binary.LittleEndian.Uint64(footer[2:])
+	if sum != Checksum(payload[:len(payload)-8]) {
+		return Entry{}, ErrChecksum
+	}
+
+	body := bytes.NewReader(payload[:len(payload)-10])
+	r := &Reader{rd: bufio.NewReader(body), version: int(version)}
+	vtype, err := r.readByte()
+	if err != nil {
+		return Entry{}, err
+	}
+	entry, err := r.readValue(vtype)
+	if err != nil {
+		return Entry{}, err
+	}
+	if _, err := r.rd.ReadByte(); err != io.EOF {
+		return Entry{}, fmt.Errorf("%w: unexpected data after the value", ErrFormat)
+	}
+	return entry, nil
+}
+
+// valueType returns the RDB value type for the key type.
+func valueType(typ core.TypeID) (byte, error) {
+	switch typ {
+	case core.TypeString:
+		return typeString, nil
+	case core.TypeList:
+		return typeList, nil
+	case core.TypeSet:
+		return typeSet, nil
+	case core.TypeHash:
+		return typeHash, nil
+	case core.TypeZSet:
+		return typeZSet2, nil
+	default:
+		return 0, fmt.Errorf("%w: unsupported key type %d", ErrFormat, typ)
+	}
+}
+
+// appendValue appends the encoded entry value to the buffer.
+func appendValue(buf []byte, e Entry) []byte {
+	switch e.Type {
+	case core.TypeString:
+		buf = appendString(buf, e.Str)
+	case core.TypeList, core.TypeSet:
+		buf = appendLen(buf, uint64(len(e.Elems)))
+		for _, elem := range e.Elems {
+			buf = appendString(buf, elem)
+		}
+	case core.TypeHash:
+		buf = appendLen(buf, uint64(len(e.Fields)))
+		for _, field := range e.Fields {
+			buf = appendString(buf, field.Name)
+			buf = appendString(buf, field.Value)
+		}
+	case core.TypeZSet:
+		buf = appendLen(buf, uint64(len(e.Items)))
+		for _, item := range e.Items {
+			buf = appendString(buf, item.Elem)
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(item.Score))
+		}
+	}
+	return buf
+}
+
+// appendString appends the length-prefixed string to the buffer.
+func appendString(buf []byte, s []byte) []byte {
+	buf = appendLen(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// appendLen appends the length-encoded number to the buffer.
+func appendLen(buf []byte, n uint64) []byte {
+	switch {
+	case n < 1<<6:
+		return append(buf, byte(n))
+	case n < 1<<14:
+		return append(buf, byte(n>>8)|0x40, byte(n))
+	case n <= math.MaxUint32:
+		buf = append(buf, 0x80)
+		return binary.BigEndian.AppendUint32(buf, uint32(n))
+	default:
+		buf = append(buf, 0x81)
+		return binary.BigEndian.AppendUint64(buf, n)
+	}
+}
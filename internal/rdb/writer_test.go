@@ -0,0 +1,130 @@
+package rdb
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/core"
+)
+
+func TestWriter(t *testing.T) {
+	entries := []Entry{
+		{Key: "name", Type: core.TypeString, Str: []byte("alice")},
+		{Key: "empty", Type: core.TypeString, Str: []byte{}},
+		{Key: "long", Type: core.TypeString, Str: bytes.Repeat([]byte("x"), 20000)},
+		{Key: "session", Type: core.TypeString, ETime: 4102444800000, Str: []byte("token")},
+		{Key: "list", Type: core.TypeList, Elems: [][]byte{[]byte("a"), []byte("b"), []byte("a")}},
+		{Key: "set", Type: core.TypeSet, Elems: [][]byte{[]byte("go"), []byte("sql")}},
+		{Key: "hash", Type: core.TypeHash, ETime: 4102444800000, Fields: []Field{
+			{Name: []byte("name"), Value: []byte("alice")},
+			{Name: []byte("age"), Value: []byte("25")},
+		}},
+		{Key: "zset", Type: core.TypeZSet, Items: []Item{
+			{Elem: []byte("a"), Score: 1.5},
+			{Elem: []byte("b"), Score: math.Inf(-1)},
+		}},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, entry := range entries {
+		err := w.Write(entry)
+		be.Err(t, err, nil)
+	}
+	err := w.Close()
+	be.Err(t, err, nil)
+
+	r := NewReader(&buf)
+	var got []string
+	for {
+		entry, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		be.Err(t, err, nil)
+		got = append(got, formatEntry(entry))
+	}
+	be.Equal(t, r.Version(), Version)
+
+	want := make([]string, len(entries))
+	for i, entry := range entries {
+		want[i] = formatEntry(entry)
+	}
+	be.Equal(t, got, want)
+
+	t.Run("empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := NewWriter(&buf).Close()
+		be.Err(t, err, nil)
+		_, err = NewReader(&buf).Read()
+		be.Equal(t, err, io.EOF)
+	})
+	t.Run("unsupported type", func(t *testing.T) {
+		err := NewWriter(io.Discard).Write(Entry{Key: "key", Type: core.TypeAny})
+		be.Err(t, err, ErrFormat)
+	})
+}
+
+func TestDump(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		entry := Entry{Type: core.TypeHash, Fields: []Field{
+			{Name: []byte("name"), Value: []byte("alice")},
+		}}
+		payload, err := Dump(entry)
+		be.Err(t, err, nil)
+		got, err := Load(payload)
+		be.Err(t, err, nil)
+		be.Equal(t, formatEntry(got), formatEntry(entry))
+	})
+	t.Run("redis payload", func(t *testing.T) {
+		// DUMP of the value set with SET mykey 10 (from Redis docs).
+		payload := []byte("\x00\xc0\n\t\x00\xbem\x06\x89Z(\x00\n")
+		got, err := Load(payload)
+		be.Err(t, err, nil)
+		be.Equal(t, got.Type, core.TypeString)
+		be.Equal(t, string(got.Str), "10")
+
+		// Redka doesn't use the integer encoding,
+		// but the payload is still valid.
+		dump, err := Dump(Entry{Type: core.TypeString, Str: []byte("10")})
+		be.Err(t, err, nil)
+		got, err = Load(dump)
+		be.Err(t, err, nil)
+		be.Equal(t, string(got.Str), "10")
+	})
+	t.Run("listpack payload", func(t *testing.T) {
+		// Redis 7 dumps small hashes as listpacks.
+		lp := []byte("\x14\x00\x00\x00\x02\x00\x84name\x05\x85alice\x06\xff")
+		body := append([]byte{typeHashListpack, byte(len(lp))}, lp...)
+		body = append(body, 11, 0)
+		payload := append(body, make([]byte, 8)...)
+		sum := Checksum(body)
+		for i := range 8 {
+			payload[len(body)+i] = byte(sum >> (8 * i))
+		}
+		got, err := Load(payload)
+		be.Err(t, err, nil)
+		be.Equal(t, formatEntry(got), "0  hash 0 [name=alice]")
+	})
+	t.Run("checksum", func(t *testing.T) {
+		payload := []byte("\x00\xc0\n\t\x00\xbem\x06\x89Z(\x00\x0b")
+		_, err := Load(payload)
+		be.Err(t, err, ErrChecksum)
+	})
+	t.Run("version", func(t *testing.T) {
+		payload := []byte("\x00\xc0\n\x0d\x00")
+		sum := Checksum(payload)
+		for i := range 8 {
+			payload = append(payload, byte(sum>>(8*i)))
+		}
+		_, err := Load(payload)
+		be.Err(t, err, ErrVersion)
+	})
+	t.Run("short", func(t *testing.T) {
+		_, err := Load([]byte("\x00\x01"))
+		be.Err(t, err, ErrFormat)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"slices"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/nalgeon/be"
 	"github.com/nalgeon/redka"
@@ -663,6 +664,25 @@ func TestSet(t *testing.T) {
 		sval, _ := db.Str().Get("person")
 		be.Equal(t, sval.String(), "alice")
 	})
+	t.Run("expired key", func(t *testing.T) {
+		db, hash := getDB(t)
+		_, _ = hash.Set("person", "name", "alice")
+		_ = db.Key().Expire("person", time.Millisecond)
+		_ = db.Str().SetExpire("city", "paris", time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		// The expired key is replaced with a new one.
+		ok, err := hash.Set("person", "age", 25)
+		be.Err(t, err, nil)
+		be.True(t, ok)
+		items, _ := hash.Items("person")
+		be.Equal(t, len(items), 1)
+
+		// Even if it has another type.
+		ok, err = hash.Set("city", "name", "paris")
+		be.Err(t, err, nil)
+		be.True(t, ok)
+	})
 }
 
 func TestSetMany(t *testing.T) {
@@ -3,7 +3,7 @@ package rhash
 // Postgres queries for the hash repository.
 var postgres = queries{
 	scan: `
-	select rhash.rowid, field, value
+	select rhash.rowid, field, rhash.value
 	from rhash join rkey on kid = rkey.id and type = 4
 	where
 		key = $1 and (etime is null or etime > $2)
@@ -27,17 +27,17 @@ var sqlite = queries{
 	where key = $1 and (etime is null or etime > $2)`,
 
 	get: `
-	select value
+	select rhash.value
 	from rhash join rkey on kid = rkey.id and type = 4
 	where key = $1 and (etime is null or etime > $2) and field = $3`,
 
 	getMany: `
-	select field, value
+	select field, rhash.value
 	from rhash join rkey on kid = rkey.id and type = 4
 	where key = ? and (etime is null or etime > ?) and field in (:fields)`,
 
 	items: `
-	select field, value
+	select field, rhash.value
 	from rhash join rkey on kid = rkey.id and type = 4
 	where key = $1 and (etime is null or etime > $2)`,
 
@@ -46,7 +46,7 @@ var sqlite = queries{
 	where key = $1 and type = 4 and (etime is null or etime > $2)`,
 
 	scan: `
-	select rhash.rowid, field, value
+	select rhash.rowid, field, rhash.value
 	from rhash join rkey on kid = rkey.id and type = 4
 	where
 		key = $1 and (etime is null or etime > $2)
@@ -62,6 +62,7 @@ var sqlite = queries{
 		type = case when rkey.type = excluded.type then rkey.type else null end,
 		version = rkey.version + 1,
 		mtime = excluded.mtime
+	where rkey.etime is null or rkey.etime > excluded.mtime
 	returning id`,
 
 	set2: `
@@ -71,7 +72,7 @@ var sqlite = queries{
 	set value = excluded.value`,
 
 	values: `
-	select value
+	select rhash.value
 	from rhash join rkey on kid = rkey.id and type = 4
 	where key = $1 and (etime is null or etime > $2)`,
 }
@@ -56,6 +56,10 @@ func (tx *Tx) Delete(key string, fields ...string) (int, error) {
 	query, fieldArgs := sqlx.ExpandIn(tx.sql.delete1, ":fields", fields)
 	query = tx.dialect.Enumerate(query)
 	args := append([]any{key, now}, fieldArgs...)
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	res, err := tx.tx.Exec(query, args...)
 	if err != nil {
 		return 0, err
@@ -72,12 +76,15 @@ func (tx *Tx) Delete(key string, fields ...string) (int, error) {
 		return 0, err
 	}
 
+	cmd := append([]any{"hdel", key}, fieldArgs...)
+	change.Emit("hdel", core.TypeHash, cmd...)
 	return int(n), nil
 }
 
 // Exists checks if a field exists in a hash.
 // If the key does not exist or is not a hash, returns false.
 func (tx *Tx) Exists(key, field string) (bool, error) {
+	sqlx.Touch(tx.tx, key)
 	count, err := tx.count(key, field)
 	return count > 0, err
 }
@@ -85,6 +92,7 @@ func (tx *Tx) Exists(key, field string) (bool, error) {
 // Fields returns all fields in a hash.
 // If the key does not exist or is not a hash, returns an empty slice.
 func (tx *Tx) Fields(key string) ([]string, error) {
+	sqlx.Touch(tx.tx, key)
 	// Select hash fields.
 	var rows *sql.Rows
 	args := []any{key, time.Now().UnixMilli()}
@@ -115,6 +123,7 @@ func (tx *Tx) Fields(key string) ([]string, error) {
 // If the element does not exist, returns ErrNotFound.
 // If the key does not exist or is not a hash, returns ErrNotFound.
 func (tx *Tx) Get(key, field string) (core.Value, error) {
+	sqlx.Touch(tx.tx, key)
 	var val []byte
 	args := []any{key, time.Now().UnixMilli(), field}
 	err := tx.tx.QueryRow(tx.sql.get, args...).Scan(&val)
@@ -131,6 +140,7 @@ func (tx *Tx) Get(key, field string) (core.Value, error) {
 // Ignores fields that do not exist and do not return them in the map.
 // If the key does not exist or is not a hash, returns an empty map.
 func (tx *Tx) GetMany(key string, fields ...string) (map[string]core.Value, error) {
+	sqlx.Touch(tx.tx, key)
 	// Get the values of the requested fields.
 	query, fieldArgs := sqlx.ExpandIn(tx.sql.getMany, ":fields", fields)
 	query = tx.dialect.Enumerate(query)
@@ -179,11 +189,16 @@ func (tx *Tx) Incr(key, field string, delta int) (int, error) {
 
 	// increment the value
 	newVal := valInt + delta
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	err = tx.set(key, field, newVal)
 	if err != nil {
 		return 0, err
 	}
 
+	change.Emit("hincrby", core.TypeHash, "hset", key, field, newVal)
 	return newVal, nil
 }
 
@@ -208,17 +223,23 @@ func (tx *Tx) IncrFloat(key, field string, delta float64) (float64, error) {
 
 	// increment the value
 	newVal := valFloat + delta
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	err = tx.set(key, field, newVal)
 	if err != nil {
 		return 0, err
 	}
 
+	change.Emit("hincrbyfloat", core.TypeHash, "hset", key, field, newVal)
 	return newVal, nil
 }
 
 // Items returns a map of all fields and values in a hash.
 // If the key does not exist or is not a hash, returns an empty map.
 func (tx *Tx) Items(key string) (map[string]core.Value, error) {
+	sqlx.Touch(tx.tx, key)
 	// Select hash rows.
 	var rows *sql.Rows
 	args := []any{key, time.Now().UnixMilli()}
@@ -247,6 +268,7 @@ func (tx *Tx) Items(key string) (map[string]core.Value, error) {
 // Len returns the number of fields in a hash.
 // If the key does not exist or is not a hash, returns 0.
 func (tx *Tx) Len(key string) (int, error) {
+	sqlx.Touch(tx.tx, key)
 	var n int
 	args := []any{key, time.Now().UnixMilli()}
 	err := tx.tx.QueryRow(tx.sql.len, args...).Scan(&n)
@@ -263,6 +285,7 @@ func (tx *Tx) Len(key string) (int, error) {
 // If the key does not exist or is not a hash, returns a nil slice.
 // Supports glob-style patterns. Set count = 0 for default page size.
 func (tx *Tx) Scan(key string, cursor int, pattern string, count int) (ScanResult, error) {
+	sqlx.Touch(tx.tx, key)
 	pattern = tx.dialect.GlobToLike(pattern)
 	if count == 0 {
 		count = scanPageSize
@@ -318,10 +341,15 @@ func (tx *Tx) Set(key string, field string, value any) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return false, err
+	}
 	err = tx.set(key, field, value)
 	if err != nil {
 		return false, err
 	}
+	change.Emit("hset", core.TypeHash, "hset", key, field, value)
 	return existCount == 0, nil
 }
 
@@ -347,13 +375,23 @@ func (tx *Tx) SetMany(key string, items map[string]any) (int, error) {
 	}
 
 	// Set the values.
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
+	cmd := make([]any, 0, 2+2*len(items))
+	cmd = append(cmd, "hset", key)
 	for field, val := range items {
 		err := tx.set(key, field, val)
 		if err != nil {
 			return 0, err
 		}
+		cmd = append(cmd, field, val)
 	}
 
+	if len(items) > 0 {
+		change.Emit("hset", core.TypeHash, cmd...)
+	}
 	return len(items) - existCount, nil
 }
 
@@ -372,16 +410,22 @@ func (tx *Tx) SetNotExists(key, field string, value any) (bool, error) {
 	if exist {
 		return false, nil
 	}
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return false, err
+	}
 	err = tx.set(key, field, value)
 	if err != nil {
 		return false, err
 	}
+	change.Emit("hset", core.TypeHash, "hset", key, field, value)
 	return true, nil
 }
 
 // Values returns all values in a hash.
 // If the key does not exist or is not a hash, returns an empty slice.
 func (tx *Tx) Values(key string) ([]core.Value, error) {
+	sqlx.Touch(tx.tx, key)
 	// Select hash values.
 	var rows *sql.Rows
 	args := []any{key, time.Now().UnixMilli()}
@@ -426,7 +470,7 @@ func (tx *Tx) set(key string, field string, value any) error {
 	}
 	args := []any{key, time.Now().UnixMilli()}
 	var keyID int
-	err = tx.tx.QueryRow(tx.sql.set1, args...).Scan(&keyID)
+	err = sqlx.UpsertKey(tx.tx, key, tx.sql.set1, args, &keyID)
 	if err != nil {
 		return tx.dialect.TypedError(err)
 	}
@@ -3,7 +3,6 @@
 package rkey
 
 import (
-	"database/sql"
 	"time"
 
 	"github.com/nalgeon/redka/internal/core"
@@ -16,16 +15,17 @@ import (
 // to manage all keys regardless of their type.
 type DB struct {
 	dialect sqlx.Dialect
-	ro      *sql.DB
-	rw      *sql.DB
+	ro      sqlx.Tx
+	rw      sqlx.Tx
 	update  func(f func(tx *Tx) error) error
 }
 
 // New creates a new database-backed key repository.
 // Does not create the database schema.
 func New(db *sqlx.DB) *DB {
+	db.Prepare(getSQL(db.Dialect))
 	actor := sqlx.NewTransactor(db, NewTx)
-	return &DB{dialect: db.Dialect, ro: db.RO, rw: db.RW, update: actor.Update}
+	return &DB{dialect: db.Dialect, ro: db.Reader(), rw: db.Writer(), update: actor.Update}
 }
 
 // Count returns the number of existing keys among specified.
@@ -37,22 +37,77 @@ func (d *DB) Count(keys ...string) (int, error) {
 // Delete deletes keys and their values, regardless of the type.
 // Returns the number of deleted keys. Non-existing keys are ignored.
 func (d *DB) Delete(keys ...string) (int, error) {
-	tx := NewTx(d.dialect, d.rw)
-	return tx.Delete(keys...)
+	var count int
+	err := d.update(func(tx *Tx) error {
+		var err error
+		count, err = tx.Delete(keys...)
+		return err
+	})
+	return count, err
 }
 
 // DeleteAll deletes all keys and their values, effectively resetting
-// the database. Should not be run inside a database transaction.
+// the database, and reclaims the disk space (SQLite only).
 func (d *DB) DeleteAll() error {
 	tx := NewTx(d.dialect, d.rw)
-	return tx.DeleteAll()
+	err := tx.DeleteAll()
+	if err != nil {
+		return err
+	}
+	if tx.sql.vacuum == "" {
+		// Truncate has already reclaimed the space.
+		return nil
+	}
+	_, err = d.rw.Exec(tx.sql.vacuum)
+	return err
 }
 
 // DeleteExpired deletes keys with expired TTL, but no more than n keys.
 // If n = 0, deletes all expired keys.
 func (d *DB) DeleteExpired(n int) (count int, err error) {
-	tx := NewTx(d.dialect, d.rw)
-	return tx.deleteExpired(n)
+	err = d.update(func(tx *Tx) error {
+		var err error
+		count, err = tx.deleteExpired(n)
+		return err
+	})
+	return count, err
+}
+
+// DeleteExpiredSample samples up to n keys with TTL (expired or not)
+// and deletes the expired ones among them. Returns the number of sampled
+// and deleted keys, so that the caller can tell how many of the keys
+// with TTL have expired.
+func (d *DB) DeleteExpiredSample(n int) (sampled, deleted int, err error) {
+	err = d.update(func(tx *Tx) error {
+		var err error
+		sampled, deleted, err = tx.deleteExpiredSample(n)
+		return err
+	})
+	return sampled, deleted, err
+}
+
+// DeleteIfExpired deletes the specified keys if they have expired.
+// Ignores the keys that do not exist or have not expired.
+// Returns the number of deleted keys.
+func (d *DB) DeleteIfExpired(keys ...string) (count int, err error) {
+	err = d.update(func(tx *Tx) error {
+		var err error
+		count, err = tx.deleteIfExpired(keys)
+		return err
+	})
+	return count, err
+}
+
+// Evict deletes up to n keys according to the eviction policy,
+// to free up space in the database. Returns the number of deleted keys.
+// See [Tx.Evict] for details.
+func (d *DB) Evict(policy EvictionPolicy, n int) (count int, err error) {
+	err = d.update(func(tx *Tx) error {
+		var err error
+		count, err = tx.Evict(policy, n)
+		return err
+	})
+	return count, err
 }
 
 // Exists reports whether the key exists.
@@ -65,16 +120,18 @@ func (d *DB) Exists(key string) (bool, error) {
 // After the ttl passes, the key is expired and no longer exists.
 // If the key does not exist, returns ErrNotFound.
 func (d *DB) Expire(key string, ttl time.Duration) error {
-	tx := NewTx(d.dialect, d.rw)
-	return tx.Expire(key, ttl)
+	return d.update(func(tx *Tx) error {
+		return tx.Expire(key, ttl)
+	})
 }
 
 // ExpireAt sets an expiration time for the key. After this time,
 // the key is expired and no longer exists.
 // If the key does not exist, returns ErrNotFound.
 func (d *DB) ExpireAt(key string, at time.Time) error {
-	tx := NewTx(d.dialect, d.rw)
-	return tx.ExpireAt(key, at)
+	return d.update(func(tx *Tx) error {
+		return tx.ExpireAt(key, at)
+	})
 }
 
 // Get returns a specific key with all associated details.
@@ -84,6 +141,14 @@ func (d *DB) Get(key string) (core.Key, error) {
 	return tx.Get(key)
 }
 
+// Info returns the key details along with the access statistics,
+// the value encoding and the estimated storage size.
+// If the key does not exist, returns ErrNotFound.
+func (d *DB) Info(key string) (Info, error) {
+	tx := NewTx(d.dialect, d.ro)
+	return tx.Info(key)
+}
+
 // Keys returns all keys matching pattern.
 // Supports glob-style patterns like these:
 //
@@ -96,17 +161,25 @@ func (d *DB) Keys(pattern string) ([]core.Key, error) {
 	return tx.Keys(pattern)
 }
 
-// Len returns the total number of keys, including expired ones.
+// Len returns the number of existing keys (not expired).
 func (d *DB) Len() (int, error) {
 	tx := NewTx(d.dialect, d.ro)
 	return tx.Len()
 }
 
+// LenByType returns the number of existing keys of each type.
+// Types without keys are not included in the map.
+func (d *DB) LenByType() (map[core.TypeID]int, error) {
+	tx := NewTx(d.dialect, d.ro)
+	return tx.LenByType()
+}
+
 // Persist removes the expiration time for the key.
 // If the key does not exist, returns ErrNotFound.
 func (d *DB) Persist(key string) error {
-	tx := NewTx(d.dialect, d.rw)
-	return tx.Persist(key)
+	return d.update(func(tx *Tx) error {
+		return tx.Persist(key)
+	})
 }
 
 // Random returns a random key.
@@ -167,3 +240,38 @@ func (d *DB) Scanner(pattern string, ktype core.TypeID, pageSize int) *Scanner {
 	tx := NewTx(d.dialect, d.ro)
 	return newScanner(tx, pattern, ktype, pageSize)
 }
+
+// SetAccessTime sets the access time of the key
+// (see [Tx.SetAccessTime]).
+// If the key does not exist, returns ErrNotFound.
+func (d *DB) SetAccessTime(key string, at time.Time) error {
+	return d.update(func(tx *Tx) error {
+		return tx.SetAccessTime(key, at)
+	})
+}
+
+// Touch sets the access time of the keys to the current time
+// and increments their access counters.
+// Returns the number of existing keys among specified.
+// Non-existing keys are ignored.
+func (d *DB) Touch(keys ...string) (count int, err error) {
+	err = d.update(func(tx *Tx) error {
+		var err error
+		count, err = tx.Touch(keys...)
+		return err
+	})
+	return count, err
+}
+
+// TouchCounts sets the access time of the keys to the current
+// time and increments their access counters by the given number
+// of accesses (key -> count). Returns the number of existing keys
+// among specified. Non-existing keys are ignored.
+func (d *DB) TouchCounts(counts map[string]int) (count int, err error) {
+	err = d.update(func(tx *Tx) error {
+		var err error
+		count, err = tx.TouchCounts(counts)
+		return err
+	})
+	return count, err
+}
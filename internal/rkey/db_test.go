@@ -1,6 +1,7 @@
 package rkey_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -87,16 +88,37 @@ func TestDelete(t *testing.T) {
 }
 
 func TestDeleteAll(t *testing.T) {
-	db, kkey := getDB(t)
+	t.Run("delete all", func(t *testing.T) {
+		db, kkey := getDB(t)
 
-	_ = db.Str().Set("name", "alice")
-	_ = db.Str().Set("age", 25)
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("age", 25)
 
-	err := kkey.DeleteAll()
-	be.Err(t, err, nil)
+		err := kkey.DeleteAll()
+		be.Err(t, err, nil)
 
-	count, _ := kkey.Count("name", "age")
-	be.Equal(t, count, 0)
+		count, _ := kkey.Count("name", "age")
+		be.Equal(t, count, 0)
+	})
+	t.Run("transaction", func(t *testing.T) {
+		db, kkey := getDB(t)
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("age", 25)
+
+		var errRollback = errors.New("rollback")
+		err := db.Update(func(tx *redka.Tx) error {
+			err := tx.Key().DeleteAll()
+			be.Err(t, err, nil)
+			count, _ := tx.Key().Count("name", "age")
+			be.Equal(t, count, 0)
+			return errRollback
+		})
+		be.Err(t, err, errRollback)
+
+		count, _ := kkey.Count("name", "age")
+		be.Equal(t, count, 2)
+	})
 }
 
 func TestDeleteExpired(t *testing.T) {
@@ -127,6 +149,170 @@ func TestDeleteExpired(t *testing.T) {
 	})
 }
 
+func TestDeleteExpiredSample(t *testing.T) {
+	t.Run("sample", func(t *testing.T) {
+		db, kkey := getDB(t)
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().SetExpire("age", 25, time.Millisecond)
+		_ = db.Str().SetExpire("city", "paris", time.Hour)
+		_ = db.Str().SetExpire("lang", "go", time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		// The sample starts at a random key with TTL,
+		// so it may take several calls to delete all
+		// the expired keys.
+		total := 0
+		for range 100 {
+			sampled, deleted, err := kkey.DeleteExpiredSample(10)
+			be.Err(t, err, nil)
+			be.True(t, sampled >= 1 && sampled <= 3)
+			be.True(t, deleted <= sampled)
+			total += deleted
+			if total == 2 {
+				break
+			}
+		}
+		be.Equal(t, total, 2)
+
+		// Only the live key with TTL is sampled now.
+		sampled, deleted, err := kkey.DeleteExpiredSample(10)
+		be.Err(t, err, nil)
+		be.Equal(t, sampled, 1)
+		be.Equal(t, deleted, 0)
+		n, _ := kkey.Count("name", "city")
+		be.Equal(t, n, 2)
+	})
+	t.Run("no ttl", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().Set("name", "alice")
+
+		sampled, deleted, err := kkey.DeleteExpiredSample(10)
+		be.Err(t, err, nil)
+		be.Equal(t, sampled, 0)
+		be.Equal(t, deleted, 0)
+	})
+}
+
+func TestDeleteIfExpired(t *testing.T) {
+	db, kkey := getDB(t)
+
+	_ = db.Str().Set("name", "alice")
+	_ = db.Str().SetExpire("age", 25, time.Millisecond)
+	_ = db.Str().SetExpire("city", "paris", time.Hour)
+	time.Sleep(2 * time.Millisecond)
+
+	count, err := kkey.DeleteIfExpired("name", "age", "city", "missing")
+	be.Err(t, err, nil)
+	be.Equal(t, count, 1)
+
+	n, _ := kkey.Count("name", "city")
+	be.Equal(t, n, 2)
+}
+
+func TestEvict(t *testing.T) {
+	t.Run("allkeys-lru", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().Set("name", "alice")
+		time.Sleep(2 * time.Millisecond)
+		_ = db.Str().Set("age", 25)
+		time.Sleep(2 * time.Millisecond)
+		_ = db.Str().Set("city", "paris")
+		time.Sleep(2 * time.Millisecond)
+		_, _ = kkey.Touch("name")
+
+		count, err := kkey.Evict(rkey.AllKeysLRU, 2)
+		be.Err(t, err, nil)
+		be.Equal(t, count, 2)
+		n, _ := kkey.Count("name", "age", "city")
+		be.Equal(t, n, 1)
+		exists, _ := kkey.Exists("name")
+		be.True(t, exists)
+	})
+	t.Run("volatile-lru", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().SetExpire("age", 25, time.Hour)
+		time.Sleep(2 * time.Millisecond)
+		_ = db.Str().SetExpire("city", "paris", time.Hour)
+
+		count, err := kkey.Evict(rkey.VolatileLRU, 1)
+		be.Err(t, err, nil)
+		be.Equal(t, count, 1)
+		n, _ := kkey.Count("name", "city")
+		be.Equal(t, n, 2)
+
+		count, err = kkey.Evict(rkey.VolatileLRU, 5)
+		be.Err(t, err, nil)
+		be.Equal(t, count, 1)
+		n, _ = kkey.Len()
+		be.Equal(t, n, 1)
+	})
+	t.Run("allkeys-random", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("age", 25)
+		_ = db.Str().Set("city", "paris")
+
+		count := 0
+		for count < 2 {
+			n, err := kkey.Evict(rkey.AllKeysRandom, 2-count)
+			be.Err(t, err, nil)
+			be.True(t, n > 0)
+			count += n
+		}
+		n, _ := kkey.Len()
+		be.Equal(t, n, 1)
+	})
+	t.Run("volatile-random", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().SetExpire("age", 25, time.Hour)
+		_ = db.Str().SetExpire("city", "paris", time.Hour)
+
+		count := 0
+		for range 10 {
+			n, err := kkey.Evict(rkey.VolatileRandom, 3)
+			be.Err(t, err, nil)
+			count += n
+		}
+		be.Equal(t, count, 2)
+		exists, _ := kkey.Exists("name")
+		be.True(t, exists)
+	})
+	t.Run("volatile-ttl", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().SetExpire("age", 25, 2*time.Hour)
+		_ = db.Str().SetExpire("city", "paris", time.Hour)
+
+		count, err := kkey.Evict(rkey.VolatileTTL, 1)
+		be.Err(t, err, nil)
+		be.Equal(t, count, 1)
+		exists, _ := kkey.Exists("city")
+		be.True(t, !exists)
+		n, _ := kkey.Count("name", "age")
+		be.Equal(t, n, 2)
+	})
+	t.Run("noeviction", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().Set("name", "alice")
+
+		count, err := kkey.Evict(rkey.NoEviction, 1)
+		be.Err(t, err, nil)
+		be.Equal(t, count, 0)
+		n, _ := kkey.Len()
+		be.Equal(t, n, 1)
+	})
+	t.Run("unknown policy", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().Set("name", "alice")
+
+		_, err := kkey.Evict("allkeys-lfu", 1)
+		be.Err(t, err, "unknown eviction policy")
+	})
+}
+
 func TestExists(t *testing.T) {
 	db, kkey := getDB(t)
 
@@ -194,8 +380,10 @@ func TestExpire(t *testing.T) {
 		err = db.Str().Set("name", "bob")
 		be.Err(t, err, nil)
 
+		// The expired key is deleted before the write,
+		// so the new key starts with the first version.
 		key, _ := kkey.Get("name")
-		be.Equal(t, key.Version, 3)
+		be.Equal(t, key.Version, 1)
 		be.Equal(t, key.ETime, (*int64)(nil))
 	})
 }
@@ -256,6 +444,83 @@ func TestGet(t *testing.T) {
 	})
 }
 
+func TestInfo(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().Set("name", "alice")
+
+		info, err := kkey.Info("name")
+		be.Err(t, err, nil)
+		be.Equal(t, info.Key.Key, "name")
+		be.Equal(t, info.Key.Type, core.TypeString)
+		be.Equal(t, info.ATime, info.Key.MTime)
+		be.Equal(t, info.Freq, 0)
+		be.Equal(t, info.Encoding, rkey.EncodingBlob)
+		// len(name) + len(alice) + key overhead
+		be.Equal(t, info.Size, 4+5+64)
+	})
+	t.Run("hash", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+
+		info, err := kkey.Info("person")
+		be.Err(t, err, nil)
+		be.Equal(t, info.Key.Type, core.TypeHash)
+		be.Equal(t, info.Encoding, rkey.EncodingRows)
+		// len(person) + key overhead +
+		// len(name) + len(alice) + len(age) + len(25) + 2 * elem overhead
+		be.Equal(t, info.Size, 6+64+4+5+3+2+2*32)
+	})
+	t.Run("multibyte", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_, _ = db.Hash().Set("привет", "имя", "алиса")
+
+		info, err := kkey.Info("привет")
+		be.Err(t, err, nil)
+		// The sizes are in bytes, not characters.
+		be.Equal(t, info.Size, 12+64+6+10+32)
+	})
+	t.Run("list", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_, _ = db.List().PushBack("list", "a")
+		_, _ = db.List().PushBack("list", "bb")
+
+		info, err := kkey.Info("list")
+		be.Err(t, err, nil)
+		be.Equal(t, info.Encoding, rkey.EncodingRows)
+		be.Equal(t, info.Size, 4+64+1+2+2*32)
+	})
+	t.Run("accessed", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().Set("name", "alice")
+		time.Sleep(2 * time.Millisecond)
+		_, _ = kkey.Touch("name")
+		_, _ = kkey.TouchCounts(map[string]int{"name": 2})
+
+		info, err := kkey.Info("name")
+		be.Err(t, err, nil)
+		be.True(t, info.ATime > info.Key.MTime)
+		be.Equal(t, info.Freq, 3)
+		be.True(t, info.IdleTime() < time.Second)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, kkey := getDB(t)
+
+		info, err := kkey.Info("name")
+		be.Equal(t, err, core.ErrNotFound)
+		be.Equal(t, info, rkey.Info{})
+	})
+	t.Run("expired", func(t *testing.T) {
+		db, kkey := getDB(t)
+		_ = db.Str().SetExpire("name", "alice", time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		_, err := kkey.Info("name")
+		be.Equal(t, err, core.ErrNotFound)
+	})
+}
+
 func TestKeys(t *testing.T) {
 	db, kkey := getDB(t)
 
@@ -303,6 +568,47 @@ func TestLen(t *testing.T) {
 		be.Err(t, err, nil)
 		be.Equal(t, count, 0)
 	})
+	t.Run("expired", func(t *testing.T) {
+		db, kkey := getDB(t)
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().SetExpire("age", 25, time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		count, err := kkey.Len()
+		be.Err(t, err, nil)
+		be.Equal(t, count, 1)
+
+		_, _ = kkey.Delete("name")
+		count, _ = kkey.Len()
+		be.Equal(t, count, 0)
+	})
+}
+
+func TestLenByType(t *testing.T) {
+	t.Run("len by type", func(t *testing.T) {
+		db, kkey := getDB(t)
+
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("age", 25)
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_ = db.Str().SetExpire("city", "paris", time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		counts, err := kkey.LenByType()
+		be.Err(t, err, nil)
+		be.Equal(t, counts, map[core.TypeID]int{
+			core.TypeString: 2,
+			core.TypeHash:   1,
+		})
+	})
+	t.Run("empty", func(t *testing.T) {
+		_, kkey := getDB(t)
+
+		counts, err := kkey.LenByType()
+		be.Err(t, err, nil)
+		be.Equal(t, counts, map[core.TypeID]int{})
+	})
 }
 
 func TestPersist(t *testing.T) {
@@ -439,6 +745,21 @@ func TestRename(t *testing.T) {
 		exists, _ = kkey.Exists("hash")
 		be.Equal(t, exists, true)
 	})
+	t.Run("expired destination", func(t *testing.T) {
+		db, kkey := getDB(t)
+
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.Hash().Set("person", "name", "bob")
+		_ = kkey.Expire("person", time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		err := kkey.Rename("name", "person")
+		be.Err(t, err, nil)
+
+		val, err := db.Str().Get("person")
+		be.Err(t, err, nil)
+		be.Equal(t, val.String(), "alice")
+	})
 }
 
 func TestRenameNotExists(t *testing.T) {
@@ -595,6 +916,42 @@ func TestScanner(t *testing.T) {
 	be.Equal(t, keyNames, []string{"11", "12", "21", "22", "31"})
 }
 
+func TestTouch(t *testing.T) {
+	db, kkey := getDB(t)
+	_ = db.Str().Set("name", "alice")
+	_ = db.Str().SetExpire("age", 25, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	count, err := kkey.Touch("name", "age", "missing")
+	be.Err(t, err, nil)
+	be.Equal(t, count, 1)
+
+	count, err = kkey.Touch()
+	be.Err(t, err, nil)
+	be.Equal(t, count, 0)
+
+	count, err = kkey.TouchCounts(map[string]int{"name": 3, "age": 1, "missing": 1})
+	be.Err(t, err, nil)
+	be.Equal(t, count, 1)
+	info, _ := kkey.Info("name")
+	be.Equal(t, info.Freq, 4)
+}
+
+func TestSetAccessTime(t *testing.T) {
+	db, kkey := getDB(t)
+	_ = db.Str().Set("name", "alice")
+
+	at := time.Now().Add(-time.Hour)
+	err := kkey.SetAccessTime("name", at)
+	be.Err(t, err, nil)
+	info, _ := kkey.Info("name")
+	be.Equal(t, info.ATime, at.UnixMilli())
+	be.Equal(t, info.Freq, 0)
+
+	err = kkey.SetAccessTime("missing", at)
+	be.Err(t, err, core.ErrNotFound)
+}
+
 func getDB(tb testing.TB) (*redka.DB, *rkey.DB) {
 	tb.Helper()
 	db := testx.OpenDB(tb)
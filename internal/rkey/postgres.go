@@ -11,12 +11,52 @@ var postgres = queries{
 		select ctid from rkey
 		where etime <= $1
 		limit $2
-	)`,
+	)
+	returning key, type, version`,
+
+	evictRandom: `
+	delete from rkey
+	where id in (
+		select id from rkey
+		where id >= (select floor(random() * max(id)) from rkey)
+		order by id
+		limit $1
+	)
+	returning key, type, version`,
+
+	evictVolatileRnd: `
+	delete from rkey
+	where id in (
+		select id from rkey
+		where etime is not null
+		and id >= (select floor(random() * max(id)) from rkey where etime is not null)
+		order by id
+		limit $1
+	)
+	returning key, type, version`,
+
+	info: `
+	select
+		id, key, type, version, etime, mtime,
+		coalesce(atime, mtime), coalesce(freq, 0),
+		octet_length(key) + coalesce((select octet_length(value) from rstring where kid = rkey.id), 0)
+	from rkey
+	where key = $1`,
 
 	keys: `
 	select id, key, type, version, etime, mtime from rkey
 	where key like $1 and (etime is null or etime > $2)`,
 
+	sampleVolatile: `
+	select count(*), coalesce(min(id), 0), coalesce(max(id), 0)
+	from (
+		select id from rkey
+		where etime is not null
+		and id >= (select floor(random() * max(id)) from rkey where etime is not null)
+		order by id
+		limit $1
+	) as sample`,
+
 	scan: `
 	select id, key, type, version, etime, mtime from rkey
 	where
@@ -24,6 +64,11 @@ var postgres = queries{
 		and (etime is null or etime > $5)
 	order by id asc
 	limit $6`,
+
+	sizeHash: `
+	select count(*), coalesce(sum(octet_length(field) + octet_length(value)), 0)
+	from rhash
+	where kid = $1`,
 }
 
 func init() {
@@ -32,13 +77,29 @@ func init() {
 	// postgres.deleteAll = sqlite.deleteAll
 	postgres.deleteAllExpired = sqlite.deleteAllExpired
 	// postgres.deleteNExpired = sqlite.deleteNExpired
+	postgres.deleteRange = sqlite.deleteRange
+	postgres.evictLRU = sqlite.evictLRU
+	// postgres.evictRandom = sqlite.evictRandom
+	postgres.evictTTL = sqlite.evictTTL
+	postgres.evictVolatileLRU = sqlite.evictVolatileLRU
+	// postgres.evictVolatileRnd = sqlite.evictVolatileRnd
 	postgres.expire = sqlite.expire
 	postgres.get = sqlite.get
+	// postgres.info = sqlite.info
 	// postgres.keys = sqlite.keys
 	postgres.len = sqlite.len
+	postgres.lenByType = sqlite.lenByType
 	postgres.persist = sqlite.persist
 	postgres.random = sqlite.random
 	postgres.rename1 = sqlite.rename1
 	postgres.rename2 = sqlite.rename2
+	// postgres.sampleVolatile = sqlite.sampleVolatile
 	// postgres.scan = sqlite.scan
+	postgres.setATime = sqlite.setATime
+	// postgres.sizeHash = sqlite.sizeHash
+	postgres.sizeList = sqlite.sizeList
+	postgres.sizeSet = sqlite.sizeSet
+	postgres.sizeZSet = sqlite.sizeZSet
+	postgres.touch = sqlite.touch
+	// postgres.vacuum = sqlite.vacuum
 }
@@ -8,16 +8,16 @@ var sqlite = queries{
 
 	delete: `
 	delete from rkey
-	where key in (:keys) and (etime is null or etime > ?)`,
+	where key in (:keys) and (etime is null or etime > ?)
+	returning key, type, version`,
 
 	deleteAll: `
-	delete from rkey;
-	vacuum;
-	pragma integrity_check;`,
+	delete from rkey`,
 
 	deleteAllExpired: `
 	delete from rkey
-	where etime <= $1`,
+	where etime <= $1
+	returning key, type, version`,
 
 	deleteNExpired: `
 	delete from rkey
@@ -25,31 +25,103 @@ var sqlite = queries{
 		select rowid from rkey
 		where etime <= $1
 		limit $2
-	)`,
+	)
+	returning key, type, version`,
+
+	deleteRange: `
+	delete from rkey
+	where id between $1 and $2 and etime <= $3
+	returning key, type, version`,
+
+	evictLRU: `
+	delete from rkey
+	where id in (
+		select id from rkey
+		order by coalesce(atime, mtime)
+		limit $1
+	)
+	returning key, type, version`,
+
+	evictRandom: `
+	delete from rkey
+	where id in (
+		select id from rkey
+		where id >= (select abs(random() % max(id)) from rkey)
+		order by id
+		limit $1
+	)
+	returning key, type, version`,
+
+	evictTTL: `
+	delete from rkey
+	where id in (
+		select id from rkey
+		where etime is not null
+		order by etime
+		limit $1
+	)
+	returning key, type, version`,
+
+	evictVolatileLRU: `
+	delete from rkey
+	where id in (
+		select id from rkey
+		where etime is not null
+		order by coalesce(atime, mtime)
+		limit $1
+	)
+	returning key, type, version`,
+
+	evictVolatileRnd: `
+	delete from rkey
+	where id in (
+		select id from rkey
+		where etime is not null
+		and id >= (select abs(random() % max(id)) from rkey where etime is not null)
+		order by id
+		limit $1
+	)
+	returning key, type, version`,
 
 	expire: `
 	update rkey set
 		version = version + 1,
 		etime = $1
-	where key = $2 and (etime is null or etime > $3)`,
+	where key = $2 and (etime is null or etime > $3)
+	returning type, version`,
 
 	get: `
 	select id, key, type, version, etime, mtime
 	from rkey
-	where key = $1 and (etime is null or etime > $2)`,
+	where key = $1`,
+
+	info: `
+	select
+		id, key, type, version, etime, mtime,
+		coalesce(atime, mtime), coalesce(freq, 0),
+		length(cast(key as blob)) + coalesce(length(value), 0)
+	from rkey
+	where key = $1`,
 
 	keys: `
 	select id, key, type, version, etime, mtime from rkey
 	where key glob $1 and (etime is null or etime > $2)`,
 
 	len: `
-	select count(*) from rkey`,
+	select count(*) from rkey
+	where etime is null or etime > $1`,
+
+	lenByType: `
+	select type, count(*) from rkey
+	where etime is null or etime > $1
+	group by type`,
 
 	persist: `
 	update rkey set
 		version = version + 1,
 		etime = null
-	where key = $1 and (etime is null or etime > $2)`,
+	where key = $1 and (etime is null or etime > $2)
+	returning type, version`,
 
 	random: `
 	select id, key, type, version, etime, mtime from rkey
@@ -67,6 +139,16 @@ var sqlite = queries{
 		mtime = $2
 	where key = $3 and (etime is null or etime > $4)`,
 
+	sampleVolatile: `
+	select count(*), coalesce(min(id), 0), coalesce(max(id), 0)
+	from (
+		select id from rkey
+		where etime is not null
+		and id >= (select abs(random() % max(id)) from rkey where etime is not null)
+		order by id
+		limit $1
+	) as sample`,
+
 	scan: `
 	select id, key, type, version, etime, mtime from rkey
 	where
@@ -74,4 +156,36 @@ var sqlite = queries{
 		and (etime is null or etime > $5)
 	order by id asc
 	limit $6`,
+
+	setATime: `
+	update rkey set atime = $1
+	where key = $2 and (etime is null or etime > $3)`,
+
+	sizeHash: `
+	select count(*), coalesce(sum(length(cast(field as blob)) + length(value)), 0)
+	from rhash
+	where kid = $1`,
+
+	sizeList: `
+	select count(*), coalesce(sum(length(elem)), 0)
+	from rlist
+	where kid = $1`,
+
+	sizeSet: `
+	select count(*), coalesce(sum(length(elem)), 0)
+	from rset
+	where kid = $1`,
+
+	sizeZSet: `
+	select count(*), coalesce(sum(length(elem)), 0)
+	from rzset
+	where kid = $1`,
+
+	touch: `
+	update rkey set atime = ?, freq = coalesce(freq, 0) + ?
+	where key in (:keys) and (etime is null or etime > ?)`,
+
+	vacuum: `
+	vacuum;
+	pragma integrity_check;`,
 }
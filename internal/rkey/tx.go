@@ -2,6 +2,7 @@ package rkey
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/nalgeon/redka/internal/core"
@@ -12,6 +13,49 @@ import (
 // of keys per page when scanning.
 const scanPageSize = 10
 
+// EvictionPolicy determines which keys to delete
+// when the database exceeds its limits (see [Tx.Evict]).
+// The policies are the same as in Redis.
+type EvictionPolicy string
+
+// Eviction policies.
+const (
+	// Do not delete keys (the writes fail instead).
+	NoEviction EvictionPolicy = "noeviction"
+	// Delete the least recently used keys.
+	AllKeysLRU EvictionPolicy = "allkeys-lru"
+	// Delete the least recently used keys with an expiration time.
+	VolatileLRU EvictionPolicy = "volatile-lru"
+	// Delete random keys.
+	AllKeysRandom EvictionPolicy = "allkeys-random"
+	// Delete random keys with an expiration time.
+	VolatileRandom EvictionPolicy = "volatile-random"
+	// Delete the keys with the nearest expiration time.
+	VolatileTTL EvictionPolicy = "volatile-ttl"
+)
+
+// Value encodings reported by [Tx.Info].
+const (
+	// The value is stored as a single blob (string).
+	EncodingBlob = "sql-blob"
+	// The value is stored as a row per element
+	// (list, set, hash or sorted set).
+	EncodingRows = "sql-rows"
+)
+
+// Approximate storage overhead (row header, integer
+// columns and index entries), see [Tx.Info].
+const (
+	keyOverhead  = 64 // per key
+	elemOverhead = 32 // per list, set, hash or sorted set element
+)
+
+// EvictionPolicies lists the supported eviction policies.
+var EvictionPolicies = []EvictionPolicy{
+	NoEviction, AllKeysLRU, VolatileLRU,
+	AllKeysRandom, VolatileRandom, VolatileTTL,
+}
+
 // SQL queries for the key repository.
 type queries struct {
 	count            string
@@ -19,15 +63,31 @@ type queries struct {
 	deleteAll        string
 	deleteAllExpired string
 	deleteNExpired   string
+	deleteRange      string
+	evictLRU         string
+	evictRandom      string
+	evictTTL         string
+	evictVolatileLRU string
+	evictVolatileRnd string
 	expire           string
 	get              string
+	info             string
 	keys             string
 	len              string
+	lenByType        string
 	persist          string
 	random           string
 	rename1          string
 	rename2          string
+	sampleVolatile   string
 	scan             string
+	setATime         string
+	sizeHash         string
+	sizeList         string
+	sizeSet          string
+	sizeZSet         string
+	touch            string
+	vacuum           string
 }
 
 // Tx is a key repository transaction.
@@ -62,19 +122,65 @@ func (tx *Tx) Delete(keys ...string) (int, error) {
 	query, keyArgs := sqlx.ExpandIn(tx.sql.delete, ":keys", keys)
 	query = tx.dialect.Enumerate(query)
 	args := append(keyArgs, now)
-	res, err := tx.tx.Exec(query, args...)
+	deleted, err := sqlx.Select(tx.tx, query, args, scanKeyType)
 	if err != nil {
 		return 0, err
 	}
-	affectedCount, _ := res.RowsAffected()
-	return int(affectedCount), nil
+	for _, k := range deleted {
+		tx.emit("del", k, 0, "del", k.Key)
+	}
+	return len(deleted), nil
 }
 
 // DeleteAll deletes all keys and their values, effectively resetting
-// the database. Should not be run inside a database transaction.
+// the database. Unlike [DB.DeleteAll], does not reclaim the disk
+// space (SQLite only), so it can be run inside a transaction.
 func (tx *Tx) DeleteAll() error {
 	_, err := tx.tx.Exec(tx.sql.deleteAll)
-	return err
+	if err != nil {
+		return err
+	}
+	// The flush is not tied to a specific key,
+	// so the event has an empty key.
+	return sqlx.Emit(tx.tx, core.Event{Name: "flushdb", Cmd: []any{"flushdb"}})
+}
+
+// Evict deletes up to n keys according to the eviction policy,
+// to free up space in the database. Returns the number of deleted
+// keys, which is less than n if there are not enough keys matching
+// the policy (e.g. keys with an expiration time for the volatile
+// policies). With NoEviction, does nothing.
+//
+// The LRU policies order the keys by the last access time, which is
+// only updated if the access tracking is enabled (see [Tx.Touch]).
+// The keys that were not accessed since the last write are ordered
+// by the modification time.
+func (tx *Tx) Evict(policy EvictionPolicy, n int) (int, error) {
+	var query string
+	switch policy {
+	case NoEviction:
+		return 0, nil
+	case AllKeysLRU:
+		query = tx.sql.evictLRU
+	case VolatileLRU:
+		query = tx.sql.evictVolatileLRU
+	case AllKeysRandom:
+		query = tx.sql.evictRandom
+	case VolatileRandom:
+		query = tx.sql.evictVolatileRnd
+	case VolatileTTL:
+		query = tx.sql.evictTTL
+	default:
+		return 0, fmt.Errorf("unknown eviction policy: %s", policy)
+	}
+	evicted, err := sqlx.Select(tx.tx, query, []any{n}, scanKeyType)
+	if err != nil {
+		return 0, err
+	}
+	for _, k := range evicted {
+		tx.emit("evicted", k, 0, "del", k.Key)
+	}
+	return len(evicted), nil
 }
 
 // Exists reports whether the key exists.
@@ -96,29 +202,91 @@ func (tx *Tx) Expire(key string, ttl time.Duration) error {
 // If the key does not exist, returns ErrNotFound.
 func (tx *Tx) ExpireAt(key string, at time.Time) error {
 	args := []any{at.UnixMilli(), key, time.Now().UnixMilli()}
-	res, err := tx.tx.Exec(tx.sql.expire, args...)
+	k := core.Key{Key: key}
+	err := tx.tx.QueryRow(tx.sql.expire, args...).Scan(&k.Type, &k.Version)
+	if err == sql.ErrNoRows {
+		return core.ErrNotFound
+	}
 	if err != nil {
 		return err
 	}
-	count, _ := res.RowsAffected()
-	if count == 0 {
-		return core.ErrNotFound
-	}
+	tx.emitUpdate("expire", k, "pexpireat", key, at.UnixMilli())
 	return nil
 }
 
 // Get returns a specific key with all associated details.
 // If the key does not exist, returns ErrNotFound.
 func (tx *Tx) Get(key string) (core.Key, error) {
-	args := []any{key, time.Now().UnixMilli()}
 	var k core.Key
-	err := tx.tx.QueryRow(tx.sql.get, args...).Scan(
+	err := tx.tx.QueryRow(tx.sql.get, key).Scan(
 		&k.ID, &k.Key, &k.Type, &k.Version, &k.ETime, &k.MTime,
 	)
 	if err == sql.ErrNoRows {
 		return core.Key{}, core.ErrNotFound
 	}
-	return k, err
+	if err != nil {
+		return core.Key{}, err
+	}
+	if k.ETime != nil && *k.ETime <= time.Now().UnixMilli() {
+		// The key has expired, but the transaction
+		// may be read-only, so delete the key later.
+		sqlx.ExpireLater(tx.tx, key)
+		return core.Key{}, core.ErrNotFound
+	}
+	return k, nil
+}
+
+// Info returns the key details along with the access statistics,
+// the value encoding and the estimated storage size.
+// If the key does not exist, returns ErrNotFound.
+//
+// The size is the total length of the key and its values
+// (strings, elements, fields) plus the fixed overhead per key
+// and per element. It does not account for the database pages
+// and indexes, so the actual disk usage is higher.
+func (tx *Tx) Info(key string) (Info, error) {
+	var info Info
+	k := &info.Key
+	err := tx.tx.QueryRow(tx.sql.info, key).Scan(
+		&k.ID, &k.Key, &k.Type, &k.Version, &k.ETime, &k.MTime,
+		&info.ATime, &info.Freq, &info.Size,
+	)
+	if err == sql.ErrNoRows {
+		return Info{}, core.ErrNotFound
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	if k.ETime != nil && *k.ETime <= time.Now().UnixMilli() {
+		sqlx.ExpireLater(tx.tx, key)
+		return Info{}, core.ErrNotFound
+	}
+	info.Size += keyOverhead
+	if k.Type == core.TypeString {
+		info.Encoding = EncodingBlob
+		return info, nil
+	}
+
+	info.Encoding = EncodingRows
+	var query string
+	switch k.Type {
+	case core.TypeList:
+		query = tx.sql.sizeList
+	case core.TypeSet:
+		query = tx.sql.sizeSet
+	case core.TypeHash:
+		query = tx.sql.sizeHash
+	case core.TypeZSet:
+		query = tx.sql.sizeZSet
+	default:
+		return info, nil
+	}
+	var n, size int
+	if err := tx.tx.QueryRow(query, k.ID).Scan(&n, &size); err != nil {
+		return Info{}, err
+	}
+	info.Size += size + n*elemOverhead
+	return info, nil
 }
 
 // Keys returns all keys matching pattern.
@@ -141,28 +309,51 @@ func (tx *Tx) Keys(pattern string) ([]core.Key, error) {
 	return keys, err
 }
 
-// Len returns the total number of keys, including expired ones.
+// Len returns the number of existing keys (not expired).
 func (tx *Tx) Len() (int, error) {
 	var n int
-	err := tx.tx.QueryRow(tx.sql.len).Scan(&n)
+	err := tx.tx.QueryRow(tx.sql.len, time.Now().UnixMilli()).Scan(&n)
 	if err != nil {
 		return 0, err
 	}
 	return n, nil
 }
 
+// LenByType returns the number of existing keys of each type.
+// Types without keys are not included in the map.
+func (tx *Tx) LenByType() (map[core.TypeID]int, error) {
+	now := time.Now().UnixMilli()
+	rows, err := tx.tx.Query(tx.sql.lenByType, now)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := map[core.TypeID]int{}
+	for rows.Next() {
+		var typ core.TypeID
+		var n int
+		if err := rows.Scan(&typ, &n); err != nil {
+			return nil, err
+		}
+		counts[typ] = n
+	}
+	return counts, rows.Err()
+}
+
 // Persist removes the expiration time for the key.
 // If the key does not exist, returns ErrNotFound.
 func (tx *Tx) Persist(key string) error {
 	args := []any{key, time.Now().UnixMilli()}
-	res, err := tx.tx.Exec(tx.sql.persist, args...)
+	k := core.Key{Key: key}
+	err := tx.tx.QueryRow(tx.sql.persist, args...).Scan(&k.Type, &k.Version)
+	if err == sql.ErrNoRows {
+		return core.ErrNotFound
+	}
 	if err != nil {
 		return err
 	}
-	count, _ := res.RowsAffected()
-	if count == 0 {
-		return core.ErrNotFound
-	}
+	tx.emitUpdate("persist", k, "persist", key)
 	return nil
 }
 
@@ -198,6 +389,12 @@ func (tx *Tx) Rename(key, newKey string) error {
 		return nil
 	}
 
+	// Delete the new key if it has expired, so that
+	// it does not conflict with the renamed one.
+	if _, err := sqlx.DeleteIfExpired(tx.tx, newKey); err != nil {
+		return err
+	}
+
 	// Make sure the new key does not exist or has the same type.
 	newK, err := tx.Get(newKey)
 	if err != nil && err != core.ErrNotFound {
@@ -220,7 +417,11 @@ func (tx *Tx) Rename(key, newKey string) error {
 	now := time.Now().UnixMilli()
 	args := []any{newKey, now, key, now}
 	_, err = tx.tx.Exec(tx.sql.rename2, args...)
-	return err
+	if err != nil {
+		return err
+	}
+	tx.emitRename(oldK, newKey, newK.Version, "rename")
+	return nil
 }
 
 // RenameNotExists changes the key name.
@@ -241,6 +442,12 @@ func (tx *Tx) RenameNotExists(key, newKey string) (bool, error) {
 		return false, nil
 	}
 
+	// Delete the new key if it has expired, so that
+	// it does not conflict with the renamed one.
+	if _, err := sqlx.DeleteIfExpired(tx.tx, newKey); err != nil {
+		return false, err
+	}
+
 	// Make sure the new key does not exist.
 	exist, err := tx.Exists(newKey)
 	if err != nil {
@@ -254,7 +461,11 @@ func (tx *Tx) RenameNotExists(key, newKey string) (bool, error) {
 	now := time.Now().UnixMilli()
 	args := []any{newKey, now, key, now}
 	_, err = tx.tx.Exec(tx.sql.rename2, args...)
-	return err == nil, err
+	if err != nil {
+		return false, err
+	}
+	tx.emitRename(oldK, newKey, 0, "renamenx")
+	return true, nil
 }
 
 // Scan iterates over keys matching pattern.
@@ -314,28 +525,197 @@ func (tx *Tx) Scanner(pattern string, ktype core.TypeID, pageSize int) *Scanner
 	return newScanner(tx, pattern, ktype, pageSize)
 }
 
+// SetAccessTime sets the access time of the key
+// (e.g. to restore the key along with its idle time).
+// Unlike [Tx.Touch], does not increment the access counter
+// and works regardless of the access tracking. Discards the
+// recorded accesses to the key (see [sqlx.Touch]), so that
+// they do not override the access time later.
+// If the key does not exist, returns ErrNotFound.
+func (tx *Tx) SetAccessTime(key string, at time.Time) error {
+	args := []any{at.UnixMilli(), key, time.Now().UnixMilli()}
+	res, err := tx.tx.Exec(tx.sql.setATime, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return core.ErrNotFound
+	}
+	sqlx.Untouch(tx.tx, key)
+	return nil
+}
+
+// Touch sets the access time of the keys to the current time
+// and increments their access counters.
+// Returns the number of existing keys among specified.
+// Non-existing keys are ignored.
+func (tx *Tx) Touch(keys ...string) (int, error) {
+	return tx.touch(1, keys)
+}
+
+// TouchCounts sets the access time of the keys to the current
+// time and increments their access counters by the given number
+// of accesses (key -> count). Returns the number of existing keys
+// among specified. Non-existing keys are ignored.
+func (tx *Tx) TouchCounts(counts map[string]int) (int, error) {
+	// Update the keys with the same count at once.
+	byCount := map[int][]string{}
+	for key, n := range counts {
+		byCount[n] = append(byCount[n], key)
+	}
+	total := 0
+	for n, keys := range byCount {
+		count, err := tx.touch(n, keys)
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// Info describes how a key is stored and accessed (see [Tx.Info]).
+type Info struct {
+	Key      core.Key
+	ATime    int64  // last access time in unix milliseconds (MTime if unknown)
+	Freq     int    // number of recorded accesses
+	Encoding string // value encoding (EncodingBlob or EncodingRows)
+	Size     int    // estimated storage size in bytes
+}
+
+// IdleTime returns the time passed since the last access.
+func (i Info) IdleTime() time.Duration {
+	return time.Since(time.UnixMilli(i.ATime))
+}
+
 // ScanResult represents a result of the Scan call.
 type ScanResult struct {
 	Cursor int
 	Keys   []core.Key
 }
 
+// touch sets the access time of the keys to the current
+// time and increments their access counters by n.
+func (tx *Tx) touch(n int, keys []string) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	now := time.Now().UnixMilli()
+	query, keyArgs := sqlx.ExpandIn(tx.sql.touch, ":keys", keys)
+	query = tx.dialect.Enumerate(query)
+	args := append([]any{now, n}, keyArgs...)
+	args = append(args, now)
+	res, err := tx.tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	return int(count), err
+}
+
+// deleteExpiredSample samples up to n keys with TTL (expired or not),
+// starting at a random one, and deletes the expired keys among them.
+// Returns the number of sampled and deleted keys.
+func (tx *Tx) deleteExpiredSample(n int) (sampled, deleted int, err error) {
+	var minID, maxID int
+	err = tx.tx.QueryRow(tx.sql.sampleVolatile, n).Scan(&sampled, &minID, &maxID)
+	if err != nil || sampled == 0 {
+		return 0, 0, err
+	}
+	// The sample is the range of ids with TTL from minID to maxID,
+	// so deleting the expired keys in the range deletes the expired
+	// keys in the sample.
+	now := time.Now().UnixMilli()
+	keys, err := sqlx.Select(tx.tx, tx.sql.deleteRange, []any{minID, maxID, now}, scanKeyType)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, k := range keys {
+		tx.emit("expired", k, 0, "del", k.Key)
+	}
+	return sampled, len(keys), nil
+}
+
 // deleteExpired deletes keys with expired TTL, but no more than n keys.
 // If n = 0, deletes all expired keys.
 func (tx *Tx) deleteExpired(n int) (int, error) {
 	now := time.Now().UnixMilli()
-	var res sql.Result
+	var deleted []core.Key
 	var err error
 	if n > 0 {
-		res, err = tx.tx.Exec(tx.sql.deleteNExpired, now, n)
+		deleted, err = sqlx.Select(tx.tx, tx.sql.deleteNExpired, []any{now, n}, scanKeyType)
 	} else {
-		res, err = tx.tx.Exec(tx.sql.deleteAllExpired, now)
+		deleted, err = sqlx.Select(tx.tx, tx.sql.deleteAllExpired, []any{now}, scanKeyType)
 	}
 	if err != nil {
 		return 0, err
 	}
-	count, _ := res.RowsAffected()
-	return int(count), err
+	for _, k := range deleted {
+		tx.emit("expired", k, 0, "del", k.Key)
+	}
+	return len(deleted), nil
+}
+
+// deleteIfExpired deletes the keys that have expired.
+func (tx *Tx) deleteIfExpired(keys []string) (int, error) {
+	count := 0
+	for _, key := range keys {
+		deleted, err := sqlx.DeleteIfExpired(tx.tx, key)
+		if err != nil {
+			return 0, err
+		}
+		if deleted {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// emit records a change to the key.
+// k.Version is the key version before the change,
+// cmd is the command that reproduces the change.
+func (tx *Tx) emit(name string, k core.Key, newVersion int, cmd ...any) {
+	// Key changes are only emitted in transactions,
+	// so Emit never fails.
+	_ = sqlx.Emit(tx.tx, core.Event{
+		Name:       name,
+		Key:        k.Key,
+		Type:       k.Type,
+		OldVersion: k.Version,
+		NewVersion: newVersion,
+		Cmd:        cmd,
+	})
+}
+
+// emitUpdate records a change to the key made by a single
+// update, which increments the version by one.
+// k.Version is the key version after the change.
+func (tx *Tx) emitUpdate(name string, k core.Key, cmd ...any) {
+	newVersion := k.Version
+	k.Version--
+	tx.emit(name, k, newVersion, cmd...)
+}
+
+// emitRename records the renaming of the key.
+// The rename replaces the new key (if it exists) with the old one
+// and increments the version by one. replaced is the version of
+// the replaced key (0 if it did not exist). cmd is the name
+// of the rename command, which is recorded only once.
+func (tx *Tx) emitRename(oldK core.Key, newKey string, replaced int, cmd string) {
+	tx.emit("rename_from", oldK, 0, cmd, oldK.Key, newKey)
+	newK := core.Key{Key: newKey, Type: oldK.Type, Version: replaced}
+	tx.emit("rename_to", newK, oldK.Version+1)
+}
+
+// scanKeyType scans the key name, type and version from the current row.
+func scanKeyType(rows *sql.Rows) (core.Key, error) {
+	var k core.Key
+	err := rows.Scan(&k.Key, &k.Type, &k.Version)
+	return k, err
 }
 
 // getSQL returns the SQL queries for the specified dialect.
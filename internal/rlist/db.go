@@ -3,8 +3,6 @@
 package rlist
 
 import (
-	"database/sql"
-
 	"github.com/nalgeon/redka/internal/core"
 	"github.com/nalgeon/redka/internal/sqlx"
 )
@@ -14,16 +12,17 @@ import (
 // Use the list repository to work with lists and their elements.
 type DB struct {
 	dialect sqlx.Dialect
-	ro      *sql.DB
-	rw      *sql.DB
+	ro      sqlx.Tx
+	rw      sqlx.Tx
 	update  func(f func(tx *Tx) error) error
 }
 
 // New connects to the list repository.
 // Does not create the database schema.
 func New(db *sqlx.DB) *DB {
+	db.Prepare(getSQL(db.Dialect))
 	actor := sqlx.NewTransactor(db, NewTx)
-	return &DB{dialect: db.Dialect, ro: db.RO, rw: db.RW, update: actor.Update}
+	return &DB{dialect: db.Dialect, ro: db.Reader(), rw: db.Writer(), update: actor.Update}
 }
 
 // Delete deletes all occurrences of an element from a list.
@@ -136,6 +136,7 @@ var sqlite = queries{
 		version = rkey.version + 1,
 		mtime = excluded.mtime,
 		len = rkey.len + 1
+	where rkey.etime is null or rkey.etime > excluded.mtime
 	returning id, len`,
 
 	pushBack: `
@@ -52,11 +52,18 @@ func (tx *Tx) Delete(key string, elem any) (int, error) {
 		return 0, err
 	}
 	args := []any{key, time.Now().UnixMilli(), elemb}
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	res, err := tx.tx.Exec(tx.sql.delete, args...)
 	if err != nil {
 		return 0, err
 	}
 	n, _ := res.RowsAffected()
+	if n > 0 {
+		change.Emit("lrem", core.TypeList, "lrem", key, 0, elemb)
+	}
 	return int(n), nil
 }
 
@@ -82,6 +89,7 @@ func (tx *Tx) DeleteFront(key string, elem any, count int) (int, error) {
 // If the index is out of bounds, returns ErrNotFound.
 // If the key does not exist or is not a list, returns ErrNotFound.
 func (tx *Tx) Get(key string, idx int) (core.Value, error) {
+	sqlx.Touch(tx.tx, key)
 	var query = tx.sql.get
 	if idx < 0 {
 		// Reverse the query ordering and index, e.g.:
@@ -124,6 +132,7 @@ func (tx *Tx) InsertBefore(key string, pivot, elem any) (int, error) {
 // Len returns the number of elements in a list.
 // If the key does not exist or is not a list, returns 0.
 func (tx *Tx) Len(key string) (int, error) {
+	sqlx.Touch(tx.tx, key)
 	var count int
 	args := []any{key, time.Now().UnixMilli()}
 	err := tx.tx.QueryRow(tx.sql.len, args...).Scan(&count)
@@ -139,7 +148,7 @@ func (tx *Tx) Len(key string) (int, error) {
 // PopBack removes and returns the last element of a list.
 // If the key does not exist or is not a list, returns ErrNotFound.
 func (tx *Tx) PopBack(key string) (core.Value, error) {
-	return tx.pop(key, tx.sql.popBack)
+	return tx.pop(key, tx.sql.popBack, "rpop")
 }
 
 // PopBackPushFront removes the last element of a list
@@ -163,7 +172,7 @@ func (tx *Tx) PopBackPushFront(src, dest string) (core.Value, error) {
 // PopFront removes and returns the first element of a list.
 // If the key does not exist or is not a list, returns ErrNotFound.
 func (tx *Tx) PopFront(key string) (core.Value, error) {
-	return tx.pop(key, tx.sql.popFront)
+	return tx.pop(key, tx.sql.popFront, "lpop")
 }
 
 // PushBack appends an element to a list.
@@ -171,7 +180,7 @@ func (tx *Tx) PopFront(key string) (core.Value, error) {
 // If the key does not exist, creates it.
 // If the key exists but is not a list, returns ErrKeyType.
 func (tx *Tx) PushBack(key string, elem any) (int, error) {
-	return tx.push(key, elem, tx.sql.pushBack)
+	return tx.push(key, elem, tx.sql.pushBack, "rpush")
 }
 
 // PushFront prepends an element to a list.
@@ -179,7 +188,7 @@ func (tx *Tx) PushBack(key string, elem any) (int, error) {
 // If the key does not exist, creates it.
 // If the key exists but is not a list, returns ErrKeyType.
 func (tx *Tx) PushFront(key string, elem any) (int, error) {
-	return tx.push(key, elem, tx.sql.pushFront)
+	return tx.push(key, elem, tx.sql.pushFront, "lpush")
 }
 
 // Range returns a range of elements from a list.
@@ -188,6 +197,7 @@ func (tx *Tx) PushFront(key string, elem any) (int, error) {
 // (-1 is the last element, -2 is the second last, etc.)
 // If the key does not exist or is not a list, returns an empty slice.
 func (tx *Tx) Range(key string, start, stop int) ([]core.Value, error) {
+	sqlx.Touch(tx.tx, key)
 	if (start > stop) && (start > 0 && stop > 0 || start < 0 && stop < 0) {
 		return nil, nil
 	}
@@ -230,13 +240,18 @@ func (tx *Tx) Set(key string, idx int, elem any) error {
 	}
 
 	var query = tx.sql.set
+	pos := idx
 	if idx < 0 {
 		// Reverse the query ordering and index.
 		query = strings.Replace(query, sqlx.Asc, sqlx.Desc, 1)
-		idx = -idx - 1
+		pos = -idx - 1
 	}
 
-	args := []any{key, time.Now().UnixMilli(), elemb, idx}
+	args := []any{key, time.Now().UnixMilli(), elemb, pos}
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return err
+	}
 	out, err := tx.tx.Exec(query, args...)
 	if err != nil {
 		return err
@@ -245,7 +260,8 @@ func (tx *Tx) Set(key string, idx int, elem any) error {
 	if n == 0 {
 		return core.ErrNotFound
 	}
-	return err
+	change.Emit("lset", core.TypeList, "lset", key, idx, elemb)
+	return nil
 }
 
 // Trim removes elements from both ends of a list so that
@@ -263,11 +279,18 @@ func (tx *Tx) Trim(key string, start, stop int) (int, error) {
 		start, start, start,
 		stop, stop, stop,
 	}
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	out, err := tx.tx.Exec(tx.sql.trim, args...)
 	if err != nil {
 		return 0, err
 	}
 	n, _ := out.RowsAffected()
+	if n > 0 {
+		change.Emit("ltrim", core.TypeList, "ltrim", key, start, stop)
+	}
 	return int(n), nil
 }
 
@@ -283,6 +306,10 @@ func (tx *Tx) delete(key string, elem any, count int, query string) (int, error)
 	}
 
 	args := []any{key, time.Now().UnixMilli(), elemb, count}
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	res, err := tx.tx.Exec(query, args...)
 	if err != nil {
 		return 0, err
@@ -291,6 +318,13 @@ func (tx *Tx) delete(key string, elem any, count int, query string) (int, error)
 	if err != nil {
 		return 0, err
 	}
+	if n > 0 {
+		// LREM deletes from the back if the count is negative.
+		if query == tx.sql.deleteBack {
+			count = -count
+		}
+		change.Emit("lrem", core.TypeList, "lrem", key, count, elemb)
+	}
 	return int(n), nil
 }
 
@@ -309,6 +343,10 @@ func (tx *Tx) insert(key string, pivot, elem any, query string) (int, error) {
 	// Update the key.
 	var keyID, n int
 	args := []any{now, key, now}
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	err = tx.tx.QueryRow(tx.sql.insert, args...).Scan(&keyID, &n)
 	if err == sql.ErrNoRows {
 		return 0, core.ErrNotFound
@@ -327,25 +365,35 @@ func (tx *Tx) insert(key string, pivot, elem any, query string) (int, error) {
 		return 0, err
 	}
 
+	where := "before"
+	if query == tx.sql.insertAfter {
+		where = "after"
+	}
+	change.Emit("linsert", core.TypeList, "linsert", key, where, pivotb, elemb)
 	return n, nil
 }
 
 // pop removes and returns an element from the front or back of a list.
-func (tx *Tx) pop(key string, query string) (core.Value, error) {
+func (tx *Tx) pop(key string, query string, event string) (core.Value, error) {
 	var val []byte
 	args := []any{key, time.Now().UnixMilli()}
-	err := tx.tx.QueryRow(query, args...).Scan(&val)
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.tx.QueryRow(query, args...).Scan(&val)
 	if err == sql.ErrNoRows {
 		return nil, core.ErrNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
+	change.Emit(event, core.TypeList, event, key)
 	return core.Value(val), nil
 }
 
 // push inserts an element to the front or back of a list.
-func (tx *Tx) push(key string, elem any, query string) (int, error) {
+func (tx *Tx) push(key string, elem any, query string, event string) (int, error) {
 	elemb, err := core.ToBytes(elem)
 	if err != nil {
 		return 0, err
@@ -354,7 +402,11 @@ func (tx *Tx) push(key string, elem any, query string) (int, error) {
 	// Create or update the key.
 	args := []any{key, time.Now().UnixMilli()}
 	var keyID, n int
-	err = tx.tx.QueryRow(tx.sql.push, args...).Scan(&keyID, &n)
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
+	err = sqlx.UpsertKey(tx.tx, key, tx.sql.push, args, &keyID, &n)
 	if err != nil {
 		return 0, tx.dialect.TypedError(err)
 	}
@@ -366,6 +418,7 @@ func (tx *Tx) push(key string, elem any, query string) (int, error) {
 		return 0, err
 	}
 
+	change.Emit(event, core.TypeList, event, key, elemb)
 	return n, nil
 }
 
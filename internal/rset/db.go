@@ -3,8 +3,6 @@
 package rset
 
 import (
-	"database/sql"
-
 	"github.com/nalgeon/redka/internal/core"
 	"github.com/nalgeon/redka/internal/sqlx"
 )
@@ -15,16 +13,17 @@ import (
 // and their elements, and to perform set operations.
 type DB struct {
 	dialect sqlx.Dialect
-	ro      *sql.DB
-	rw      *sql.DB
+	ro      sqlx.Tx
+	rw      sqlx.Tx
 	update  func(f func(tx *Tx) error) error
 }
 
 // New connects to the set repository.
 // Does not create the database schema.
 func New(db *sqlx.DB) *DB {
+	db.Prepare(getSQL(db.Dialect))
 	actor := sqlx.NewTransactor(db, NewTx)
-	return &DB{dialect: db.Dialect, ro: db.RO, rw: db.RW, update: actor.Update}
+	return &DB{dialect: db.Dialect, ro: db.Reader(), rw: db.Writer(), update: actor.Update}
 }
 
 // Add adds or updates elements in a set.
@@ -10,6 +10,7 @@ var sqlite = queries{
 		type = case when rkey.type = excluded.type then rkey.type else null end,
 		version = rkey.version + 1,
 		mtime = excluded.mtime
+	where rkey.etime is null or rkey.etime > excluded.mtime
 	returning id`,
 
 	add2: `
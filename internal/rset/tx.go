@@ -64,7 +64,12 @@ func (tx *Tx) Add(key string, elems ...any) (int, error) {
 
 	// Create or update the key.
 	var keyID int
-	err = tx.tx.QueryRow(tx.sql.add1, key, time.Now().UnixMilli()).Scan(&keyID)
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
+	args := []any{key, time.Now().UnixMilli()}
+	err = sqlx.UpsertKey(tx.tx, key, tx.sql.add1, args, &keyID)
 	if err != nil {
 		return 0, tx.dialect.TypedError(err)
 	}
@@ -83,6 +88,10 @@ func (tx *Tx) Add(key string, elems ...any) (int, error) {
 		n++
 	}
 
+	if n > 0 {
+		cmd := append([]any{"sadd", key}, elems...)
+		change.Emit("sadd", core.TypeSet, cmd...)
+	}
 	return n, nil
 }
 
@@ -102,6 +111,10 @@ func (tx *Tx) Delete(key string, elems ...any) (int, error) {
 	query, elemArgs := sqlx.ExpandIn(tx.sql.delete1, ":elems", elembs)
 	query = tx.dialect.Enumerate(query)
 	args := append([]any{key, now}, elemArgs...)
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	res, err := tx.tx.Exec(query, args...)
 	if err != nil {
 		return 0, err
@@ -118,6 +131,8 @@ func (tx *Tx) Delete(key string, elems ...any) (int, error) {
 		return 0, err
 	}
 
+	cmd := append([]any{"srem", key}, elemArgs...)
+	change.Emit("srem", core.TypeSet, cmd...)
 	return int(n), nil
 }
 
@@ -127,6 +142,7 @@ func (tx *Tx) Delete(key string, elems ...any) (int, error) {
 // If the first key does not exist or is not a set, returns an empty slice.
 // If any of the remaining keys do not exist or are not sets, ignores them.
 func (tx *Tx) Diff(keys ...string) ([]core.Value, error) {
+	sqlx.Touch(tx.tx, keys...)
 	if len(keys) == 0 {
 		return nil, nil
 	}
@@ -158,7 +174,11 @@ func (tx *Tx) DiffStore(dest string, keys ...string) (int, error) {
 
 	// Delete the destination key if it exists.
 	now := time.Now().UnixMilli()
-	err := tx.deleteKey(dest, now)
+	change, err := sqlx.Watch(tx.tx, dest)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.deleteKey(dest, now)
 	if err != nil {
 		return 0, err
 	}
@@ -174,19 +194,22 @@ func (tx *Tx) DiffStore(dest string, keys ...string) (int, error) {
 	if len(others) == 0 {
 		// No sets to diff, just clone the first set.
 		args := []any{destID, keys[0], now}
-		return tx.store(tx.sql.clone, args)
+		return tx.store(change, "sdiffstore", dest, keys, tx.sql.clone, args)
 	}
 
 	// Diff the source sets and store the result.
 	query, keyArgs := sqlx.ExpandIn(tx.sql.diffStore, ":keys", others)
 	query = tx.dialect.Enumerate(query)
 	args := append(keyArgs, now, destID, keys[0], now)
-	return tx.store(query, args)
+	return tx.store(change, "sdiffstore", dest, keys, query, args)
 }
 
 // Exists reports whether the element belongs to a set.
 // If the key does not exist or is not a set, returns false.
 func (tx *Tx) Exists(key, elem any) (bool, error) {
+	if k, ok := key.(string); ok {
+		sqlx.Touch(tx.tx, k)
+	}
 	elemb, err := core.ToBytes(elem)
 	if err != nil {
 		return false, err
@@ -206,6 +229,7 @@ func (tx *Tx) Exists(key, elem any) (bool, error) {
 // If any of the source keys do not exist or are not sets,
 // returns an empty slice.
 func (tx *Tx) Inter(keys ...string) ([]core.Value, error) {
+	sqlx.Touch(tx.tx, keys...)
 	if len(keys) == 0 {
 		return nil, nil
 	}
@@ -229,7 +253,11 @@ func (tx *Tx) InterStore(dest string, keys ...string) (int, error) {
 
 	// Delete the destination key if it exists.
 	now := time.Now().UnixMilli()
-	err := tx.deleteKey(dest, now)
+	change, err := sqlx.Watch(tx.tx, dest)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.deleteKey(dest, now)
 	if err != nil {
 		return 0, err
 	}
@@ -244,12 +272,13 @@ func (tx *Tx) InterStore(dest string, keys ...string) (int, error) {
 	query, keyArgs := sqlx.ExpandIn(tx.sql.interStore, ":keys", keys)
 	query = tx.dialect.Enumerate(query)
 	args := slices.Concat([]any{destID}, keyArgs, []any{now, len(keys)})
-	return tx.store(query, args)
+	return tx.store(change, "sinterstore", dest, keys, query, args)
 }
 
 // Items returns all elements in a set.
 // If the key does not exist or is not a set, returns an empty slice.
 func (tx *Tx) Items(key string) ([]core.Value, error) {
+	sqlx.Touch(tx.tx, key)
 	args := []any{key, time.Now().UnixMilli()}
 	return tx.selectElems(tx.sql.items, args)
 }
@@ -257,6 +286,7 @@ func (tx *Tx) Items(key string) ([]core.Value, error) {
 // Len returns the number of elements in a set.
 // Returns 0 if the key does not exist or is not a set.
 func (tx *Tx) Len(key string) (int, error) {
+	sqlx.Touch(tx.tx, key)
 	var n int
 	args := []any{key, time.Now().UnixMilli()}
 	err := tx.tx.QueryRow(tx.sql.len, args...).Scan(&n)
@@ -299,7 +329,11 @@ func (tx *Tx) Pop(key string) (core.Value, error) {
 	now := time.Now().UnixMilli()
 	args := []any{key, now}
 	var val []byte
-	err := tx.tx.QueryRow(tx.sql.pop1, args...).Scan(&val)
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.tx.QueryRow(tx.sql.pop1, args...).Scan(&val)
 	if err == sql.ErrNoRows {
 		return nil, core.ErrNotFound
 	}
@@ -314,12 +348,14 @@ func (tx *Tx) Pop(key string) (core.Value, error) {
 		return nil, err
 	}
 
+	change.Emit("spop", core.TypeSet, "srem", key, val)
 	return core.Value(val), nil
 }
 
 // Random returns a random element from a set.
 // If the key does not exist or is not a set, returns ErrNotFound.
 func (tx *Tx) Random(key string) (core.Value, error) {
+	sqlx.Touch(tx.tx, key)
 	args := []any{key, time.Now().UnixMilli()}
 	var val []byte
 	err := tx.tx.QueryRow(tx.sql.random, args...).Scan(&val)
@@ -338,6 +374,7 @@ func (tx *Tx) Random(key string) (core.Value, error) {
 // If the key does not exist or is not a set, returns an empty slice.
 // Supports glob-style patterns. Set count = 0 for default page size.
 func (tx *Tx) Scan(key string, cursor int, pattern string, count int) (ScanResult, error) {
+	sqlx.Touch(tx.tx, key)
 	pattern = tx.dialect.GlobToLike(pattern)
 	if count == 0 {
 		count = scanPageSize
@@ -391,6 +428,7 @@ func (tx *Tx) Scanner(key, pattern string, pageSize int) *Scanner {
 // Ignores the keys that do not exist or are not sets.
 // If no keys exist, returns an empty slice.
 func (tx *Tx) Union(keys ...string) ([]core.Value, error) {
+	sqlx.Touch(tx.tx, keys...)
 	if len(keys) == 0 {
 		return nil, nil
 	}
@@ -415,7 +453,11 @@ func (tx *Tx) UnionStore(dest string, keys ...string) (int, error) {
 
 	// Delete the destination key if it exists.
 	now := time.Now().UnixMilli()
-	err := tx.deleteKey(dest, now)
+	change, err := sqlx.Watch(tx.tx, dest)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.deleteKey(dest, now)
 	if err != nil {
 		return 0, err
 	}
@@ -430,7 +472,7 @@ func (tx *Tx) UnionStore(dest string, keys ...string) (int, error) {
 	query, keyArgs := sqlx.ExpandIn(tx.sql.unionStore, ":keys", keys)
 	query = tx.dialect.Enumerate(query)
 	args := slices.Concat([]any{destID}, keyArgs, []any{now})
-	return tx.store(query, args)
+	return tx.store(change, "sunionstore", dest, keys, query, args)
 }
 
 // deleteKey deletes set elements and resets the key metadata.
@@ -446,21 +488,30 @@ func (tx *Tx) deleteKey(key string, now int64) error {
 // createKey creates a new set key if it does not exist.
 func (tx *Tx) createKey(key string, now int64) (int, error) {
 	var keyID int
-	err := tx.tx.QueryRow(tx.sql.add1, key, now).Scan(&keyID)
+	err := sqlx.UpsertKey(tx.tx, key, tx.sql.add1, []any{key, now}, &keyID)
 	if err != nil {
 		return 0, tx.dialect.TypedError(err)
 	}
 	return keyID, nil
 }
 
-// store executes a set operation and stores the result.
+// store executes a set operation and stores the result
+// in the destination key (tracked by the change).
+// The event is the name of the command, and keys are
+// the source keys of the operation.
 // Returns the number of elements stored.
-func (tx *Tx) store(query string, args []any) (int, error) {
+func (tx *Tx) store(change sqlx.Change, event, dest string, keys []string, query string, args []any) (int, error) {
 	res, err := tx.tx.Exec(query, args...)
 	if err != nil {
 		return 0, err
 	}
 	n, _ := res.RowsAffected()
+	cmd := make([]any, 0, 2+len(keys))
+	cmd = append(cmd, event, dest)
+	for _, key := range keys {
+		cmd = append(cmd, key)
+	}
+	change.Emit(event, core.TypeSet, cmd...)
 	return int(n), nil
 }
 
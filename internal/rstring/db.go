@@ -3,7 +3,6 @@
 package rstring
 
 import (
-	"database/sql"
 	"time"
 
 	"github.com/nalgeon/redka/internal/core"
@@ -15,16 +14,17 @@ import (
 // Use the string repository to work with individual strings.
 type DB struct {
 	dialect sqlx.Dialect
-	ro      *sql.DB
-	rw      *sql.DB
+	ro      sqlx.Tx
+	rw      sqlx.Tx
 	update  func(f func(tx *Tx) error) error
 }
 
 // New connects to the string repository.
 // Does not create the database schema.
 func New(db *sqlx.DB) *DB {
+	db.Prepare(getSQL(db.Dialect))
 	actor := sqlx.NewTransactor(db, NewTx)
-	return &DB{dialect: db.Dialect, ro: db.RO, rw: db.RW, update: actor.Update}
+	return &DB{dialect: db.Dialect, ro: db.Reader(), rw: db.Writer(), update: actor.Update}
 }
 
 // Get returns the value of the key.
@@ -36,6 +36,15 @@ func TestGet(t *testing.T) {
 		be.Err(t, err, core.ErrNotFound)
 		be.Equal(t, val, core.Value(nil))
 	})
+	t.Run("key expired", func(t *testing.T) {
+		_, str := getDB(t)
+		_ = str.SetExpire("name", "alice", time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		val, err := str.Get("name")
+		be.Err(t, err, core.ErrNotFound)
+		be.Equal(t, val, core.Value(nil))
+	})
 }
 
 func TestGetMany(t *testing.T) {
@@ -406,6 +415,48 @@ func TestSet(t *testing.T) {
 	})
 }
 
+func TestSetExpired(t *testing.T) {
+	t.Run("same type", func(t *testing.T) {
+		db, str := getDB(t)
+		_ = str.SetExpire("name", "alice", time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		// The expired key is replaced with a new one.
+		err := str.Set("name", "bob")
+		be.Err(t, err, nil)
+		val, _ := str.Get("name")
+		be.Equal(t, val, core.Value("bob"))
+		key, _ := db.Key().Get("name")
+		be.Equal(t, key.Version, 1)
+	})
+	t.Run("other type", func(t *testing.T) {
+		db, str := getDB(t)
+		_, _ = db.Hash().Set("person", "name", "alice")
+		_ = db.Key().Expire("person", time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		// The expired key does not cause a type mismatch.
+		err := str.Set("person", "alice")
+		be.Err(t, err, nil)
+		val, _ := str.Get("person")
+		be.Equal(t, val, core.Value("alice"))
+	})
+	t.Run("incr", func(t *testing.T) {
+		_, str := getDB(t)
+		_ = str.SetExpire("age", 25, time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+
+		// The increment starts from zero and does not
+		// inherit the expiration time.
+		val, err := str.Incr("age", 1)
+		be.Err(t, err, nil)
+		be.Equal(t, val, 1)
+		time.Sleep(2 * time.Millisecond)
+		got, _ := str.Get("age")
+		be.Equal(t, got, core.Value("1"))
+	})
+}
+
 func TestSetExists(t *testing.T) {
 	t.Run("key exists", func(t *testing.T) {
 		db, str := getDB(t)
@@ -1,13 +1,53 @@
 package rstring
 
 // Postgres queries for the string repository.
-var postgres queries
-
-func init() {
-	postgres.get = sqlite.get
-	postgres.getMany = sqlite.getMany
-	postgres.set1 = sqlite.set1
-	postgres.set2 = sqlite.set2
-	postgres.update1 = sqlite.update1
-	postgres.update2 = sqlite.update2
+// The string values are stored in the rstring table.
+var postgres = queries{
+	get: `
+	select value, etime
+	from rstring join rkey on kid = rkey.id and type = 1
+	where key = $1`,
+
+	getMany: `
+	select key, value
+	from rstring
+	join rkey on kid = rkey.id and type = 1
+	where key in (:keys) and (etime is null or etime > ?)`,
+
+	set: `
+	with key as (
+		insert into
+		rkey   (key, type, version, etime, mtime)
+		values ( $1,    1,       1,    $2,    $3)
+		on conflict (key) do update set
+			type = case when rkey.type = excluded.type then rkey.type else null end,
+			version = rkey.version + 1,
+			etime = excluded.etime,
+			mtime = excluded.mtime
+		where rkey.etime is null or rkey.etime > excluded.mtime
+		returning id
+	)
+	insert into rstring (kid, value)
+	select id, $4 from key
+	on conflict (kid) do update
+	set value = excluded.value
+	returning kid`,
+
+	update: `
+	with key as (
+		insert into
+		rkey   (key, type, version, etime, mtime)
+		values ( $1,    1,       1,  null,    $2)
+		on conflict (key) do update set
+			type = case when rkey.type = excluded.type then rkey.type else null end,
+			version = rkey.version + 1,
+			mtime = excluded.mtime
+		where rkey.etime is null or rkey.etime > excluded.mtime
+		returning id
+	)
+	insert into rstring (kid, value)
+	select id, $3 from key
+	on conflict (kid) do update
+	set value = excluded.value
+	returning kid`,
 }
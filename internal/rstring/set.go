@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/sqlx"
 )
 
 // SetOut is the output of the Set command.
@@ -124,7 +125,15 @@ func (c SetCmd) run(tx *Tx) (out SetOut, err error) {
 
 	// Set the value.
 	if c.keepTTL {
+		var change sqlx.Change
+		change, err = sqlx.Watch(tx.tx, c.key)
+		if err != nil {
+			return SetOut{Prev: prev}, err
+		}
 		err = tx.update(c.key, c.val)
+		if err == nil {
+			change.Emit("set", core.TypeString, "set", c.key, c.val, "keepttl")
+		}
 	} else {
 		err = tx.set(c.key, c.val, c.at)
 	}
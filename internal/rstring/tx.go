@@ -12,10 +12,8 @@ import (
 type queries struct {
 	get     string
 	getMany string
-	set1    string
-	set2    string
-	update1 string
-	update2 string
+	set     string
+	update  string
 }
 
 // Tx is a string repository transaction.
@@ -35,6 +33,7 @@ func NewTx(dialect sqlx.Dialect, tx sqlx.Tx) *Tx {
 // Get returns the value of the key.
 // If the key does not exist or is not a string, returns ErrNotFound.
 func (tx *Tx) Get(key string) (core.Value, error) {
+	sqlx.Touch(tx.tx, key)
 	return tx.get(key)
 }
 
@@ -42,6 +41,7 @@ func (tx *Tx) Get(key string) (core.Value, error) {
 // Ignores keys that do not exist or not strings,
 // and does not return them in the map.
 func (tx *Tx) GetMany(keys ...string) (map[string]core.Value, error) {
+	sqlx.Touch(tx.tx, keys...)
 	// Get the values of the requested keys.
 	now := time.Now().UnixMilli()
 	query, keyArgs := sqlx.ExpandIn(tx.sql.getMany, ":keys", keys)
@@ -93,11 +93,16 @@ func (tx *Tx) Incr(key string, delta int) (int, error) {
 
 	// increment the value
 	newVal := valInt + delta
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	err = tx.update(key, newVal)
 	if err != nil {
 		return 0, err
 	}
 
+	change.Emit("incrby", core.TypeString, "set", key, newVal, "keepttl")
 	return newVal, nil
 }
 
@@ -121,11 +126,16 @@ func (tx *Tx) IncrFloat(key string, delta float64) (float64, error) {
 
 	// increment the value
 	newVal := valFloat + delta
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	err = tx.update(key, newVal)
 	if err != nil {
 		return 0, err
 	}
 
+	change.Emit("incrbyfloat", core.TypeString, "set", key, newVal, "keepttl")
 	return newVal, nil
 }
 
@@ -177,15 +187,21 @@ func (tx *Tx) SetWith(key string, value any) SetCmd {
 }
 
 func (tx *Tx) get(key string) (core.Value, error) {
-	args := []any{key, time.Now().UnixMilli()}
 	var val []byte
-	err := tx.tx.QueryRow(tx.sql.get, args...).Scan(&val)
+	var etime *int64
+	err := tx.tx.QueryRow(tx.sql.get, key).Scan(&val, &etime)
 	if err == sql.ErrNoRows {
 		return core.Value(nil), core.ErrNotFound
 	}
 	if err != nil {
 		return core.Value(nil), err
 	}
+	if etime != nil && *etime <= time.Now().UnixMilli() {
+		// The key has expired, but the transaction
+		// may be read-only, so delete the key later.
+		sqlx.ExpireLater(tx.tx, key)
+		return core.Value(nil), core.ErrNotFound
+	}
 	return core.Value(val), nil
 }
 
@@ -202,15 +218,24 @@ func (tx *Tx) set(key string, value any, at time.Time) error {
 		*etime = at.UnixMilli()
 	}
 
-	args := []any{key, etime, time.Now().UnixMilli()}
-	_, err = tx.tx.Exec(tx.sql.set1, args...)
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return err
+	}
+	args := []any{key, etime, time.Now().UnixMilli(), valueb}
+	var keyID int
+	err = sqlx.UpsertKey(tx.tx, key, tx.sql.set, args, &keyID)
 	if err != nil {
 		return tx.dialect.TypedError(err)
 	}
 
-	args = []any{key, valueb}
-	_, err = tx.tx.Exec(tx.sql.set2, args...)
-	return err
+	if etime != nil {
+		change.Emit("set", core.TypeString, "set", key, valueb, "pxat", *etime)
+		change.Emit("expire", core.TypeString)
+	} else {
+		change.Emit("set", core.TypeString, "set", key, valueb)
+	}
+	return nil
 }
 
 // update updates the value of the existing key without changing its
@@ -222,12 +247,13 @@ func (tx *Tx) update(key string, value any) error {
 		return err
 	}
 
-	_, err = tx.tx.Exec(tx.sql.update1, key, time.Now().UnixMilli())
+	args := []any{key, time.Now().UnixMilli(), valueb}
+	var keyID int
+	err = sqlx.UpsertKey(tx.tx, key, tx.sql.update, args, &keyID)
 	if err != nil {
 		return tx.dialect.TypedError(err)
 	}
-	_, err = tx.tx.Exec(tx.sql.update2, key, valueb)
-	return err
+	return nil
 }
 
 // getSQL returns the SQL queries for the specified dialect.
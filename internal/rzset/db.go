@@ -3,8 +3,6 @@
 package rzset
 
 import (
-	"database/sql"
-
 	"github.com/nalgeon/redka/internal/sqlx"
 )
 
@@ -20,16 +18,17 @@ import (
 // and to perform set operations like union or intersection.
 type DB struct {
 	dialect sqlx.Dialect
-	ro      *sql.DB
-	rw      *sql.DB
+	ro      sqlx.Tx
+	rw      sqlx.Tx
 	update  func(f func(tx *Tx) error) error
 }
 
 // New connects to the sorted set repository.
 // Does not create the database schema.
 func New(db *sqlx.DB) *DB {
+	db.Prepare(getSQL(db.Dialect))
 	actor := sqlx.NewTransactor(db, NewTx)
-	return &DB{dialect: db.Dialect, ro: db.RO, rw: db.RW, update: actor.Update}
+	return &DB{dialect: db.Dialect, ro: db.Reader(), rw: db.Writer(), update: actor.Update}
 }
 
 // Add adds or updates an element in a set.
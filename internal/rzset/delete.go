@@ -2,6 +2,9 @@ package rzset
 
 import (
 	"time"
+
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/sqlx"
 )
 
 // DeleteCmd removes elements from a set.
@@ -53,11 +56,21 @@ func (c DeleteCmd) Run() (int, error) {
 
 func (c DeleteCmd) run(tx *Tx) (n int, err error) {
 	now := time.Now().UnixMilli()
+	change, err := sqlx.Watch(tx.tx, c.key)
+	if err != nil {
+		return 0, err
+	}
 
+	var event string
+	var start, stop any
 	if c.byRank != nil {
 		n, err = c.deleteRank(tx, now)
+		event = "zremrangebyrank"
+		start, stop = c.byRank.start, c.byRank.stop
 	} else if c.byScore != nil {
 		n, err = c.deleteScore(tx, now)
+		event = "zremrangebyscore"
+		start, stop = c.byScore.start, c.byScore.stop
 	} else {
 		return 0, nil
 	}
@@ -66,7 +79,11 @@ func (c DeleteCmd) run(tx *Tx) (n int, err error) {
 	}
 
 	err = c.updateKey(tx, now, n)
-	return n, err
+	if err != nil {
+		return 0, err
+	}
+	change.Emit(event, core.TypeZSet, event, c.key, start, stop)
+	return n, nil
 }
 
 // deleteRank removes elements from a set by rank.
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nalgeon/redka/internal/core"
 	"github.com/nalgeon/redka/internal/sqlx"
 )
 
@@ -82,6 +83,7 @@ func (c InterCmd) Store() (int, error) {
 
 // run returns the intersection of multiple sets.
 func (c InterCmd) run(tx *Tx) ([]SetItem, error) {
+	sqlx.Touch(tx.tx, c.keys...)
 	// Prepare query arguments.
 	query := tx.sql.inter
 	if c.aggregate != sqlx.Sum {
@@ -124,7 +126,11 @@ func (c InterCmd) store(tx *Tx) (int, error) {
 	now := time.Now().UnixMilli()
 
 	// Delete the destination key if it exists.
-	_, err := tx.tx.Exec(tx.sql.deleteAll1, c.dest, now)
+	change, err := sqlx.Watch(tx.tx, c.dest)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.tx.Exec(tx.sql.deleteAll1, c.dest, now)
 	if err != nil {
 		return 0, err
 	}
@@ -135,7 +141,7 @@ func (c InterCmd) store(tx *Tx) (int, error) {
 
 	// Create the destination key.
 	var destID int
-	err = tx.tx.QueryRow(tx.sql.add1, c.dest, now).Scan(&destID)
+	err = sqlx.UpsertKey(tx.tx, c.dest, tx.sql.add1, []any{c.dest, now}, &destID)
 	if err != nil {
 		return 0, tx.dialect.TypedError(err)
 	}
@@ -155,5 +161,16 @@ func (c InterCmd) store(tx *Tx) (int, error) {
 
 	// Return the number of elements in the resulting set.
 	n, _ := res.RowsAffected()
+	change.Emit("zinterstore", core.TypeZSet, c.storeCmd("zinterstore")...)
 	return int(n), nil
 }
+
+// storeCmd returns the command that reproduces the store operation.
+func (c InterCmd) storeCmd(name string) []any {
+	cmd := make([]any, 0, 5+len(c.keys))
+	cmd = append(cmd, name, c.dest, len(c.keys))
+	for _, key := range c.keys {
+		cmd = append(cmd, key)
+	}
+	return append(cmd, "aggregate", c.aggregate)
+}
@@ -79,6 +79,7 @@ func (c RangeCmd) Count(count int) RangeCmd {
 // If the key does not exist or is not a sorted set,
 // returns a nil slice.
 func (c RangeCmd) Run() ([]SetItem, error) {
+	sqlx.Touch(c.tx.tx, c.key)
 	if c.byRank != nil {
 		return c.rangeRank()
 	}
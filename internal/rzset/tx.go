@@ -61,10 +61,15 @@ func (tx *Tx) Add(key string, elem any, score float64) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return false, err
+	}
 	err = tx.add(key, elem, score)
 	if err != nil {
 		return false, err
 	}
+	change.Emit("zadd", core.TypeZSet, "zadd", key, score, elem)
 	return existCount == 0, nil
 }
 
@@ -84,13 +89,23 @@ func (tx *Tx) AddMany(key string, items map[any]float64) (int, error) {
 	}
 
 	// Add the elements.
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
+	cmd := make([]any, 0, 2+2*len(items))
+	cmd = append(cmd, "zadd", key)
 	for elem, score := range items {
 		err := tx.add(key, elem, score)
 		if err != nil {
 			return 0, err
 		}
+		cmd = append(cmd, score, elem)
 	}
 
+	if len(items) > 0 {
+		change.Emit("zadd", core.TypeZSet, cmd...)
+	}
 	return len(items) - existCount, nil
 }
 
@@ -98,6 +113,7 @@ func (tx *Tx) AddMany(key string, items map[any]float64) (int, error) {
 // min and max (inclusive). Exclusive ranges are not supported.
 // Returns 0 if the key does not exist or is not a set.
 func (tx *Tx) Count(key string, min, max float64) (int, error) {
+	sqlx.Touch(tx.tx, key)
 	args := []any{
 		key, time.Now().UnixMilli(),
 		min, max,
@@ -123,6 +139,10 @@ func (tx *Tx) Delete(key string, elems ...any) (int, error) {
 	query, elemArgs := sqlx.ExpandIn(tx.sql.delete1, ":elems", elembs)
 	query = tx.dialect.Enumerate(query)
 	args := append([]any{key, now}, elemArgs...)
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
 	res, err := tx.tx.Exec(query, args...)
 	if err != nil {
 		return 0, err
@@ -139,6 +159,8 @@ func (tx *Tx) Delete(key string, elems ...any) (int, error) {
 		return 0, err
 	}
 
+	cmd := append([]any{"zrem", key}, elemArgs...)
+	change.Emit("zrem", core.TypeZSet, cmd...)
 	return int(n), nil
 }
 
@@ -153,6 +175,7 @@ func (tx *Tx) DeleteWith(key string) DeleteCmd {
 // If the element does not exist, returns ErrNotFound.
 // If the key does not exist or is not a set, returns ErrNotFound.
 func (tx *Tx) GetRank(key string, elem any) (rank int, score float64, err error) {
+	sqlx.Touch(tx.tx, key)
 	return tx.getRank(key, elem, sqlx.Asc)
 }
 
@@ -162,6 +185,7 @@ func (tx *Tx) GetRank(key string, elem any) (rank int, score float64, err error)
 // If the element does not exist, returns ErrNotFound.
 // If the key does not exist or is not a set, returns ErrNotFound.
 func (tx *Tx) GetRankRev(key string, elem any) (rank int, score float64, err error) {
+	sqlx.Touch(tx.tx, key)
 	return tx.getRank(key, elem, sqlx.Desc)
 }
 
@@ -169,6 +193,7 @@ func (tx *Tx) GetRankRev(key string, elem any) (rank int, score float64, err err
 // If the element does not exist, returns ErrNotFound.
 // If the key does not exist or is not a set, returns ErrNotFound.
 func (tx *Tx) GetScore(key string, elem any) (float64, error) {
+	sqlx.Touch(tx.tx, key)
 	elemb, err := core.ToBytes(elem)
 	if err != nil {
 		return 0, err
@@ -200,7 +225,11 @@ func (tx *Tx) Incr(key string, elem any, delta float64) (float64, error) {
 
 	args := []any{key, time.Now().UnixMilli()}
 	var keyID int
-	err = tx.tx.QueryRow(tx.sql.add1, args...).Scan(&keyID)
+	change, err := sqlx.Watch(tx.tx, key)
+	if err != nil {
+		return 0, err
+	}
+	err = sqlx.UpsertKey(tx.tx, key, tx.sql.add1, args, &keyID)
 	if err != nil {
 		return 0, tx.dialect.TypedError(err)
 	}
@@ -212,6 +241,7 @@ func (tx *Tx) Incr(key string, elem any, delta float64) (float64, error) {
 		return 0, err
 	}
 
+	change.Emit("zincr", core.TypeZSet, "zadd", key, score, elemb)
 	return score, nil
 }
 
@@ -232,6 +262,7 @@ func (tx *Tx) InterWith(keys ...string) InterCmd {
 // Len returns the number of elements in a set.
 // Returns 0 if the key does not exist or is not a set.
 func (tx *Tx) Len(key string) (int, error) {
+	sqlx.Touch(tx.tx, key)
 	var n int
 	args := []any{key, time.Now().UnixMilli()}
 	err := tx.tx.QueryRow(tx.sql.len, args...).Scan(&n)
@@ -263,6 +294,7 @@ func (tx *Tx) RangeWith(key string) RangeCmd {
 // If the key does not exist or is not a set, returns a nil slice.
 // Supports glob-style patterns. Set count = 0 for default page size.
 func (tx *Tx) Scan(key string, cursor int, pattern string, count int) (ScanResult, error) {
+	sqlx.Touch(tx.tx, key)
 	pattern = tx.dialect.GlobToLike(pattern)
 	if count == 0 {
 		count = scanPageSize
@@ -329,7 +361,7 @@ func (tx *Tx) add(key string, elem any, score float64) error {
 
 	args := []any{key, time.Now().UnixMilli()}
 	var keyID int
-	err = tx.tx.QueryRow(tx.sql.add1, args...).Scan(&keyID)
+	err = sqlx.UpsertKey(tx.tx, key, tx.sql.add1, args, &keyID)
 	if err != nil {
 		return tx.dialect.TypedError(err)
 	}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nalgeon/redka/internal/core"
 	"github.com/nalgeon/redka/internal/sqlx"
 )
 
@@ -84,6 +85,7 @@ func (c UnionCmd) Store() (int, error) {
 
 // run returns the union of multiple sets.
 func (c UnionCmd) run(tx *Tx) ([]SetItem, error) {
+	sqlx.Touch(tx.tx, c.keys...)
 	// Prepare query arguments.
 	now := time.Now().UnixMilli()
 	query := tx.sql.union
@@ -123,7 +125,11 @@ func (c UnionCmd) store(tx *Tx) (int, error) {
 	now := time.Now().UnixMilli()
 
 	// Delete the destination key if it exists.
-	_, err := tx.tx.Exec(tx.sql.deleteAll1, c.dest, now)
+	change, err := sqlx.Watch(tx.tx, c.dest)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.tx.Exec(tx.sql.deleteAll1, c.dest, now)
 	if err != nil {
 		return 0, err
 	}
@@ -134,7 +140,7 @@ func (c UnionCmd) store(tx *Tx) (int, error) {
 
 	// Create the destination key.
 	var destID int
-	err = tx.tx.QueryRow(tx.sql.add1, c.dest, now).Scan(&destID)
+	err = sqlx.UpsertKey(tx.tx, c.dest, tx.sql.add1, []any{c.dest, now}, &destID)
 	if err != nil {
 		return 0, tx.dialect.TypedError(err)
 	}
@@ -154,5 +160,16 @@ func (c UnionCmd) store(tx *Tx) (int, error) {
 
 	// Return the number of elements in the resulting set.
 	n, _ := res.RowsAffected()
+	change.Emit("zunionstore", core.TypeZSet, c.storeCmd("zunionstore")...)
 	return int(n), nil
 }
+
+// storeCmd returns the command that reproduces the store operation.
+func (c UnionCmd) storeCmd(name string) []any {
+	cmd := make([]any, 0, 5+len(c.keys))
+	cmd = append(cmd, name, c.dest, len(c.keys))
+	for _, key := range c.keys {
+		cmd = append(cmd, key)
+	}
+	return append(cmd, "aggregate", c.aggregate)
+}
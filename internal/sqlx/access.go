@@ -0,0 +1,56 @@
+package sqlx
+
+import "sync/atomic"
+
+// Maximum number of accessed keys waiting for the access
+// time update (see [Touch]). The accesses to other keys
+// are not recorded until the next update.
+const maxAccessedKeys = 10000
+
+// Touch records an access to the keys, so that the key access
+// time and counter are updated later in a single write
+// (see [DB.TakeAccessed]).
+// This way the reads don't have to write to the database.
+// Does nothing if the access tracking is disabled
+// (see [DB.TrackAccess]) or if tx does not belong
+// to a database handle.
+func Touch(tx Tx, keys ...string) {
+	if etx, ok := tx.(*eventTx); ok && etx.accessed != nil {
+		for _, key := range keys {
+			etx.accessed.add(key)
+		}
+	}
+}
+
+// Untouch discards the recorded accesses to the keys (see [Touch]),
+// so that the access time update does not override the access
+// time set explicitly. Does nothing if tx does not belong
+// to a database handle.
+func Untouch(tx Tx, keys ...string) {
+	if etx, ok := tx.(*eventTx); ok && etx.accessed != nil {
+		for _, key := range keys {
+			etx.accessed.keys.remove(key)
+		}
+	}
+}
+
+// accessedKeys is a set of keys accessed since
+// the last access time update (see [Touch]).
+//
+// accessedKeys is safe for concurrent use by multiple goroutines.
+type accessedKeys struct {
+	enabled atomic.Bool
+	keys    keySet
+}
+
+// newAccessedKeys creates a disabled set of accessed keys.
+func newAccessedKeys() *accessedKeys {
+	return &accessedKeys{keys: keySet{max: maxAccessedKeys}}
+}
+
+// add adds the key to the set if the tracking is enabled.
+func (a *accessedKeys) add(key string) {
+	if a.enabled.Load() {
+		a.keys.add(key)
+	}
+}
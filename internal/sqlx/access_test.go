@@ -0,0 +1,87 @@
+package sqlx
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+func TestTouch(t *testing.T) {
+	rw, ro := openMemDB(t)
+	opts := &Options{Dialect: DialectSqlite, Timeout: time.Second, Pragma: map[string]string{}}
+	db, err := Open(rw, ro, opts)
+	be.Err(t, err, nil)
+
+	t.Run("disabled", func(t *testing.T) {
+		Touch(db.Reader(), "name")
+		be.Equal(t, len(db.TakeAccessed()), 0)
+	})
+	t.Run("enabled", func(t *testing.T) {
+		db.TrackAccess(true)
+		defer db.TrackAccess(false)
+
+		Touch(db.Reader(), "name", "age")
+		Touch(db.Writer(), "name")
+		keys := db.TakeAccessed()
+		be.Equal(t, keys, map[string]int{"name": 2, "age": 1})
+		be.Equal(t, len(db.TakeAccessed()), 0)
+	})
+	t.Run("untouch", func(t *testing.T) {
+		db.TrackAccess(true)
+		defer db.TrackAccess(false)
+
+		Touch(db.Writer(), "name", "age")
+		Untouch(db.Writer(), "name", "missing")
+		be.Equal(t, db.TakeAccessed(), map[string]int{"age": 1})
+	})
+	t.Run("watch", func(t *testing.T) {
+		db.TrackAccess(true)
+		defer db.TrackAccess(false)
+
+		_, err := Watch(db.Writer(), "name")
+		be.Err(t, err, nil)
+		be.Equal(t, db.TakeAccessed(), map[string]int{"name": 1})
+	})
+	t.Run("limit", func(t *testing.T) {
+		db.TrackAccess(true)
+		defer db.TrackAccess(false)
+
+		for i := range maxAccessedKeys + 10 {
+			Touch(db.Reader(), fmt.Sprintf("key:%d", i))
+		}
+		// The keys already in the set are still counted.
+		Touch(db.Reader(), "key:0")
+		keys := db.TakeAccessed()
+		be.Equal(t, len(keys), maxAccessedKeys)
+		be.Equal(t, keys["key:0"], 2)
+	})
+}
+
+func TestSize(t *testing.T) {
+	rw, ro := openMemDB(t)
+	opts := &Options{Dialect: DialectSqlite, Timeout: time.Second, Pragma: map[string]string{}}
+	db, err := Open(rw, ro, opts)
+	be.Err(t, err, nil)
+
+	empty, err := db.Size()
+	be.Err(t, err, nil)
+	be.True(t, empty > 0)
+
+	_, err = rw.Exec(`
+	with recursive n(i) as (select 1 union all select i+1 from n where i < 1000)
+	insert into rkey (key, type, version, mtime, value)
+	select 'key:' || i, 1, 1, 0, randomblob(1000) from n`)
+	be.Err(t, err, nil)
+	full, err := db.Size()
+	be.Err(t, err, nil)
+	be.True(t, full > empty+1000*1000)
+
+	// The free pages do not count.
+	_, err = rw.Exec("delete from rkey")
+	be.Err(t, err, nil)
+	size, err := db.Size()
+	be.Err(t, err, nil)
+	be.True(t, size < full/2)
+}
@@ -2,12 +2,21 @@ package sqlx
 
 import (
 	"database/sql"
+	"fmt"
+	"log/slog"
 	"runtime"
+	"sync/atomic"
 	"time"
+
+	"github.com/nalgeon/redka/internal/core"
 )
 
-// Database schema version.
-// const schemaVersion = 1
+// ownQueries are the static queries of the package itself
+// (as opposed to the repository ones), see [DB.Prepare].
+var ownQueries = struct{ version, deleteExpired string }{
+	version:       sqlVersion,
+	deleteExpired: sqlDeleteExpired,
+}
 
 // Options is the configuration for the database.
 type Options struct {
@@ -21,6 +30,23 @@ type Options struct {
 	Timeout time.Duration
 	// Whether the database is read-only.
 	ReadOnly bool
+	// Whether to merge concurrent writable transactions
+	// into a single database transaction (group commit).
+	GroupCommit bool
+	// Whether to send the repository queries as plain text
+	// instead of using the prepared statements (see [DB.Prepare]).
+	NoPrepare bool
+	// Prefix for the names of the schema objects (tables, views,
+	// indexes and triggers), SQLite only. Lets several handles
+	// with different prefixes share a single database.
+	TablePrefix string
+	// Schema to create the schema objects in, Postgres only.
+	// The connections must have the search_path set to it
+	// (see [DataSource]).
+	Schema string
+	// Logger for the warnings (e.g. the queries that fail
+	// to prepare). If nil, uses a silent logger.
+	Logger *slog.Logger
 }
 
 // DB is a database handle.
@@ -29,37 +55,279 @@ type DB struct {
 	Dialect Dialect       // database dialect
 	RW      *sql.DB       // read-write handle
 	RO      *sql.DB       // read-only handle
-	Timeout time.Duration // transaction timeout
+	timeout *atomic.Int64 // transaction timeout in nanoseconds
+	trace   *Trace        // statement trace, if any
+
+	listeners *listeners               // committed event listeners
+	journal   *atomic.Pointer[Journal] // change event journal, if any
+	group     *groupCommit             // write coalescer, if enabled
+	stmts     *stmtCache               // prepared statements, if enabled
+	names     *names                   // schema object names, if prefixed
+	expired   *keySet                  // expired keys to delete later
+	accessed  *accessedKeys            // accessed keys to update later
+}
+
+// Timeout returns the transaction timeout.
+func (d *DB) Timeout() time.Duration {
+	return time.Duration(d.timeout.Load())
+}
+
+// SetTimeout changes the transaction timeout.
+// Affects transactions started after the call.
+func (d *DB) SetTimeout(timeout time.Duration) {
+	d.timeout.Store(int64(timeout))
+}
+
+// WithTrace returns a database handle that shares the connection
+// pools and settings with d, and records the executed statements
+// in the trace.
+func (d *DB) WithTrace(trace *Trace) *DB {
+	return &DB{
+		Dialect: d.Dialect,
+		RW:      d.RW,
+		RO:      d.RO,
+		timeout: d.timeout,
+		trace:   trace,
+
+		listeners: d.listeners,
+		journal:   d.journal,
+		group:     d.group,
+		stmts:     d.stmts,
+		names:     d.names,
+		expired:   d.expired,
+		accessed:  d.accessed,
+	}
+}
+
+// Listen registers a function that receives the change
+// events (see [Emit]) of each committed transaction.
+// The function is called synchronously after the commit,
+// so it should not block. Returns a function that
+// unregisters the listener.
+func (d *DB) Listen(f func(events []core.Event)) (cancel func()) {
+	return d.listeners.add(f, true)
+}
+
+// ListenWithoutVersions registers a function that receives
+// the change events like [DB.Listen], but without the key
+// versions (OldVersion and NewVersion are zero). Does not make
+// the writes look up the key versions, so it's cheaper.
+// Returns a function that unregisters the listener.
+func (d *DB) ListenWithoutVersions(f func(events []core.Event)) (cancel func()) {
+	return d.listeners.add(f, false)
+}
+
+// ListenCommits registers a function that receives the number
+// of change events (see [Emit]) of each committed transaction.
+// Like [DB.ListenWithoutVersions], does not make the writes look
+// up the key versions. Returns a function that unregisters
+// the listener.
+func (d *DB) ListenCommits(f func(n int)) (cancel func()) {
+	return d.ListenWithoutVersions(func(events []core.Event) {
+		f(len(events))
+	})
+}
+
+// SetJournal sets the journal that records the change events
+// (see [Emit]) of each writable transaction before it commits.
+// Nil journal disables the recording.
+func (d *DB) SetJournal(j Journal) {
+	if j == nil {
+		d.journal.Store(nil)
+		return
+	}
+	d.journal.Store(&j)
+}
+
+// Prepare prepares the static queries on the read-write
+// and read-only handles, so that the transactions don't have
+// to parse them on every call. queries is a pointer to a struct
+// with the query strings (usually the repository queries).
+// The queries with several statements and the templates for
+// dynamic queries (see [ExpandIn]) are not prepared. The queries
+// that fail to prepare are logged and sent as plain text.
+//
+// Other queries (e.g. the ones built dynamically) are sent
+// to the database as plain text. Preparing the same set of
+// queries again does nothing.
+//
+// All the queries (prepared or not) use the default schema object
+// names (rkey, rstring, ...). If the table prefix is set, the handle
+// renames the objects in the queries before executing them
+// (the static ones only once, see [names]).
+func (d *DB) Prepare(queries any) {
+	d.names.register(staticQueries(queries))
+	if d.stmts == nil {
+		return
+	}
+	if d.RW == d.RO {
+		d.stmts.prepare(queries, d.names, d.RW)
+		return
+	}
+	d.stmts.prepare(queries, d.names, d.RW, d.RO)
+}
+
+// Close closes the prepared statements and the connection pools.
+func (d *DB) Close() error {
+	var allErr error
+	if d.stmts != nil {
+		allErr = d.stmts.close()
+	}
+	if err := d.RW.Close(); allErr == nil {
+		allErr = err
+	}
+	if err := d.RO.Close(); allErr == nil {
+		allErr = err
+	}
+	return allErr
+}
+
+// TakeExpired returns the expired keys scheduled for deletion
+// by the read paths (see [ExpireLater]) and clears the schedule.
+func (d *DB) TakeExpired() []string {
+	return d.expired.take()
+}
+
+// TrackAccess enables or disables the access tracking (see [Touch]).
+// Disabled by default.
+func (d *DB) TrackAccess(enabled bool) {
+	d.accessed.enabled.Store(enabled)
+}
+
+// TakeAccessed returns the keys accessed since the last call
+// (see [Touch]) along with the number of accesses, and clears
+// the set. The caller should update the access time and the
+// access counter of the returned keys.
+func (d *DB) TakeAccessed() map[string]int {
+	return d.accessed.keys.takeCounts()
+}
+
+// Size returns the database size in bytes.
+//
+// With SQLite, it's the size of the database file without the free
+// pages (so deleting the data reduces the size right away). With table
+// prefix, it's the size of all the tables in the database.
+//
+// With Postgres, it's the total size of the key and value tables
+// (including indexes). Postgres keeps the space freed by deleted
+// rows for reuse, so deleting the data does not reduce the size.
+func (d *DB) Size() (int64, error) {
+	var query string
+	switch d.Dialect {
+	case DialectSqlite:
+		query = sqlSqliteSize
+	case DialectPostgres:
+		query = sqlPostgresSize
+	default:
+		return 0, ErrDialect
+	}
+	var size int64
+	err := d.RO.QueryRow(query).Scan(&size)
+	return size, err
+}
+
+// Reader returns the read-only handle
+// for executing statements outside of transactions.
+func (d *DB) Reader() Tx {
+	return &eventTx{
+		tx:        d.wrap(d.RO, d.RO),
+		listeners: d.listeners,
+		journal:   d.journal,
+		expired:   d.expired,
+		accessed:  d.accessed,
+		auto:      true,
+	}
+}
+
+// Writer returns the read-write handle
+// for executing statements outside of transactions.
+// Records and delivers the emitted events immediately.
+func (d *DB) Writer() Tx {
+	return &eventTx{
+		tx:        d.wrap(d.RW, d.RW),
+		listeners: d.listeners,
+		journal:   d.journal,
+		expired:   d.expired,
+		accessed:  d.accessed,
+		auto:      true,
+	}
+}
+
+// wrap returns a Tx that uses the prepared statements (if enabled),
+// records the statements in the trace (if the handle has one),
+// and renames the schema objects (if the table prefix is set).
+// pool is the connection pool tx belongs to.
+func (d *DB) wrap(tx Tx, pool *sql.DB) Tx {
+	if d.stmts != nil {
+		tx = newStmtTx(tx, pool, d.stmts)
+	}
+	if d.trace != nil {
+		tx = tracedTx{tx: tx, trace: d.trace}
+	}
+	if d.names != nil {
+		tx = renamedTx{tx: tx, names: d.names}
+	}
+	return tx
 }
 
 // Open creates a new database handle.
-// Creates the database schema if necessary.
+// Creates the database schema or migrates it to the latest
+// version if necessary (see [Migrate]).
 func Open(rw *sql.DB, ro *sql.DB, opts *Options) (*DB, error) {
+	if err := checkOptions(opts); err != nil {
+		return nil, err
+	}
+	var d *DB
 	switch opts.Dialect {
 	case DialectSqlite:
-		d, err := openSqlite(rw, ro, opts)
-		return (*DB)(d), err
+		sd, err := openSqlite(rw, ro, opts)
+		if err != nil {
+			return nil, err
+		}
+		d = (*DB)(sd)
 	case DialectPostgres:
-		d, err := openPostgres(rw, ro, opts)
-		return (*DB)(d), err
+		pd, err := openPostgres(rw, ro, opts)
+		if err != nil {
+			return nil, err
+		}
+		d = (*DB)(pd)
 	default:
 		return nil, ErrDialect
 	}
+	d.Prepare(&ownQueries)
+	return d, nil
 }
 
 // New creates a new database handle.
-// Like Open, but does not create the database schema.
+// Like Open, but does not create or migrate the database schema.
+// Returns ErrSchemaVersion if the existing schema is not
+// of the latest version.
 func New(rw *sql.DB, ro *sql.DB, opts *Options) (*DB, error) {
+	if err := checkOptions(opts); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(ro, opts); err != nil {
+		return nil, err
+	}
+	var d *DB
 	switch opts.Dialect {
 	case DialectSqlite:
-		d, err := newSqlite(rw, ro, opts)
-		return (*DB)(d), err
+		sd, err := newSqlite(rw, ro, opts)
+		if err != nil {
+			return nil, err
+		}
+		d = (*DB)(sd)
 	case DialectPostgres:
-		d, err := newPostgres(rw, ro, opts)
-		return (*DB)(d), err
+		pd, err := newPostgres(rw, ro, opts)
+		if err != nil {
+			return nil, err
+		}
+		d = (*DB)(pd)
 	default:
 		return nil, ErrDialect
 	}
+	d.Prepare(&ownQueries)
+	return d, nil
 }
 
 // DataSource returns a connection string
@@ -69,12 +337,27 @@ func DataSource(path string, readOnly bool, opts *Options) string {
 	case DialectSqlite:
 		return sqliteDataSource(path, readOnly, opts.Pragma)
 	case DialectPostgres:
-		return postgresDataSource(path, readOnly, opts.Pragma)
+		return postgresDataSource(path, readOnly, opts.Schema, opts.Pragma)
 	default:
 		return ""
 	}
 }
 
+// checkOptions checks that the options
+// are supported by the SQL dialect.
+func checkOptions(opts *Options) error {
+	if opts.TablePrefix != "" && opts.Dialect != DialectSqlite {
+		return fmt.Errorf("table prefix is not supported by %s (use schema)", opts.Dialect)
+	}
+	if opts.Schema != "" && opts.Dialect != DialectPostgres {
+		return fmt.Errorf("schema is not supported by %s (use table prefix)", opts.Dialect)
+	}
+	if err := checkIdent("table prefix", opts.TablePrefix); err != nil {
+		return err
+	}
+	return checkIdent("schema", opts.Schema)
+}
+
 // suggestNumConns calculates the optimal number
 // of parallel connections to the database.
 func suggestNumConns() int {
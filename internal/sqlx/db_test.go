@@ -1,6 +1,12 @@
 package sqlx
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/core"
+)
 
 func TestDataSource_Postgres(t *testing.T) {
 	t.Run("rw", func(t *testing.T) {
@@ -77,3 +83,46 @@ func TestDataSource_Sqlite(t *testing.T) {
 		}
 	})
 }
+
+func TestListen(t *testing.T) {
+	rw, ro := openMemDB(t)
+	opts := &Options{Dialect: DialectSqlite, Timeout: time.Second, Pragma: map[string]string{}}
+	db, err := Open(rw, ro, opts)
+	be.Err(t, err, nil)
+	act := NewTransactor(db, func(_ Dialect, tx Tx) Tx { return tx })
+	set := func() {
+		err := act.Update(func(tx Tx) error {
+			change, err := Watch(tx, "name")
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`
+			insert into rkey (key, type, version, mtime) values ('name', 1, 1, 0)
+			on conflict (key) do update set version = version + 1`)
+			change.Emit("set", core.TypeString)
+			return err
+		})
+		be.Err(t, err, nil)
+	}
+
+	t.Run("versions", func(t *testing.T) {
+		var events []core.Event
+		cancel := db.Listen(func(evs []core.Event) { events = append(events, evs...) })
+		defer cancel()
+		be.True(t, db.listeners.versioned())
+		set()
+		be.Equal(t, len(events), 1)
+		be.True(t, events[0].NewVersion > 0)
+	})
+	t.Run("without versions", func(t *testing.T) {
+		var events []core.Event
+		cancel := db.ListenWithoutVersions(func(evs []core.Event) { events = append(events, evs...) })
+		defer cancel()
+		be.True(t, !db.listeners.versioned())
+		set()
+		be.Equal(t, len(events), 1)
+		be.Equal(t, events[0].Name, "set")
+		be.Equal(t, events[0].OldVersion, 0)
+		be.Equal(t, events[0].NewVersion, 0)
+	})
+}
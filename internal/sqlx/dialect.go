@@ -25,16 +25,19 @@ var ErrDialect = errors.New("unknown SQL dialect")
 //   - sqlite3.Error (NOT NULL constraint failed: rkey.type)
 //   - *pq.Error (pq: null value in column "type" of relation "rkey" violates not-null constraint)
 func (d Dialect) ConstraintFailed(err error, constraint, table string, column string) bool {
-	var message string
+	errStr := strings.ToLower(err.Error())
 	switch d {
 	case DialectPostgres:
-		message = `"` + column + `" of relation "` + table +
+		message := `"` + column + `" of relation "` + table +
 			`" violates ` + strings.ReplaceAll(constraint, " ", "-") + ` constraint`
+		return strings.Contains(errStr, message)
 	case DialectSqlite:
-		message = constraint + " constraint failed: " + table + "." + column
+		// The table name may have a prefix (see [Options.TablePrefix]).
+		_, name, ok := strings.Cut(errStr, constraint+" constraint failed: ")
+		name, _, _ = strings.Cut(name, " ")
+		return ok && strings.HasSuffix(name, table+"."+column)
 	}
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, message)
+	return false
 }
 
 // Enumerate replaces ? placeholders with $1, $2, ... $n.
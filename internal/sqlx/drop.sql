@@ -10,4 +10,5 @@ drop table if exists rlist;
 drop table if exists rset;
 drop table if exists rhash;
 drop table if exists rzset;
+drop table if exists rchange;
 drop table if exists rkey;
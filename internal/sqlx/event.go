@@ -0,0 +1,260 @@
+package sqlx
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nalgeon/redka/internal/core"
+)
+
+// sqlVersion selects the version of an existing (not expired) key.
+const sqlVersion = `
+select version from rkey
+where key = $1 and (etime is null or etime > $2)`
+
+// Emit records a change event in the transaction.
+// The listeners receive the events after the transaction
+// commits, and never receive the events of a rolled back
+// transaction. The journal (if any) records the events
+// in the transaction itself, right before the commit.
+//
+// If tx is not a transaction (but a database handle in
+// autocommit mode), the journal records the event and the
+// listeners receive it immediately. Returns the journal error,
+// if any (always nil for transactions, since the journal
+// errors are reported on commit).
+//
+// Does nothing if there are no listeners and no journal.
+func Emit(tx Tx, ev core.Event) error {
+	if etx, ok := tx.(*eventTx); ok && etx.tracking() {
+		return etx.emit(ev)
+	}
+	return nil
+}
+
+// Change is a pending change to a key.
+// Create it with [Watch] before writing to the key,
+// and record it with [Change.Emit] after the write.
+type Change struct {
+	tx      *eventTx
+	key     string
+	version int
+}
+
+// Watch prepares the key for writing. Records the access to the key
+// (see [Touch]) and reads the current version of the key, so that
+// the change event can report the version before the change.
+// The write itself replaces the expired key (see [UpsertKey]).
+// Returns a no-op change if there are no listeners and no journal.
+// Reads the version only if there are listeners that need
+// it, since the journal and the other listeners do not.
+// Returns the error of reading the version, if any.
+func Watch(tx Tx, key string) (Change, error) {
+	Touch(tx, key)
+	etx, ok := tx.(*eventTx)
+	if !ok || !etx.tracking() {
+		return Change{}, nil
+	}
+	change := Change{tx: etx, key: key}
+	if etx.listeners.versioned() {
+		version, err := etx.version(key)
+		if err != nil {
+			return Change{}, err
+		}
+		change.version = version
+	}
+	return change, nil
+}
+
+// Emit records the change event in the transaction
+// with the key versions before and after the change.
+// cmd is the command that reproduces the change (see [core.Event]).
+// See [Emit] for the delivery rules.
+func (c Change) Emit(name string, typ core.TypeID, cmd ...any) {
+	if c.tx == nil {
+		return
+	}
+	ev := core.Event{
+		Name:       name,
+		Key:        c.key,
+		Type:       typ,
+		OldVersion: c.version,
+		Cmd:        cmd,
+	}
+	if c.tx.listeners.versioned() {
+		// The write has succeeded, so a failed read
+		// only leaves the new version unknown (zero).
+		ev.NewVersion, _ = c.tx.version(c.key)
+	}
+	// Change is only used in transactions,
+	// so emit never fails.
+	_ = c.tx.emit(ev)
+}
+
+// Journal records the change events in the transaction
+// that made the changes. If it returns an error,
+// the transaction is rolled back.
+type Journal func(tx Tx, events []core.Event) error
+
+// listeners is a set of functions
+// that receive the committed events.
+type listeners struct {
+	mu     sync.RWMutex
+	funcs  map[int]listener
+	lastID int
+	n      atomic.Int32 // number of listeners
+	nver   atomic.Int32 // number of listeners that need the key versions
+}
+
+// listener is a function that receives the committed events.
+type listener struct {
+	f        func(events []core.Event)
+	versions bool // whether the events should have the key versions
+}
+
+// add registers a listener and returns
+// a function that unregisters it.
+func (l *listeners) add(f func(events []core.Event), versions bool) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.funcs == nil {
+		l.funcs = map[int]listener{}
+	}
+	l.lastID++
+	id := l.lastID
+	l.funcs[id] = listener{f: f, versions: versions}
+	l.count()
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.funcs, id)
+		l.count()
+	}
+}
+
+// count updates the listener counters.
+// Must be called with the lock held.
+func (l *listeners) count() {
+	var nver int32
+	for _, lst := range l.funcs {
+		if lst.versions {
+			nver++
+		}
+	}
+	l.n.Store(int32(len(l.funcs)))
+	l.nver.Store(nver)
+}
+
+// active reports whether there are any listeners.
+func (l *listeners) active() bool {
+	return l.n.Load() > 0
+}
+
+// versioned reports whether there are any
+// listeners that need the key versions.
+func (l *listeners) versioned() bool {
+	return l.nver.Load() > 0
+}
+
+// deliver sends the events to all listeners
+// in the order of registration.
+func (l *listeners) deliver(events []core.Event) {
+	if len(events) == 0 {
+		return
+	}
+	l.mu.RLock()
+	ids := make([]int, 0, len(l.funcs))
+	for id := range l.funcs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	funcs := make([]func(events []core.Event), len(ids))
+	for i, id := range ids {
+		funcs[i] = l.funcs[id].f
+	}
+	l.mu.RUnlock()
+	for _, f := range funcs {
+		f(events)
+	}
+}
+
+// eventTx is a Tx that collects the change events.
+// In autocommit mode, records and delivers the events immediately.
+type eventTx struct {
+	tx        Tx
+	listeners *listeners
+	journal   *atomic.Pointer[Journal]
+	expired   *keySet       // expired keys to delete later
+	accessed  *accessedKeys // accessed keys to update later
+	auto      bool
+	events    []core.Event
+}
+
+// tracking reports whether the transaction
+// should collect the change events.
+func (t *eventTx) tracking() bool {
+	return t.journal.Load() != nil || t.listeners.active()
+}
+
+func (t *eventTx) Query(query string, args ...any) (*sql.Rows, error) {
+	return t.tx.Query(query, args...)
+}
+
+func (t *eventTx) QueryRow(query string, args ...any) *sql.Row {
+	return t.tx.QueryRow(query, args...)
+}
+
+func (t *eventTx) Exec(query string, args ...any) (sql.Result, error) {
+	return t.tx.Exec(query, args...)
+}
+
+// version returns the current version of the key,
+// or 0 if the key does not exist.
+func (t *eventTx) version(key string) (int, error) {
+	var version int
+	now := time.Now().UnixMilli()
+	err := t.tx.QueryRow(sqlVersion, key, now).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// emit records the event or delivers it
+// immediately in autocommit mode.
+func (t *eventTx) emit(ev core.Event) error {
+	if !t.auto {
+		t.events = append(t.events, ev)
+		return nil
+	}
+	events := []core.Event{ev}
+	if journal := t.journal.Load(); journal != nil {
+		if err := (*journal)(t.tx, events); err != nil {
+			return err
+		}
+	}
+	t.listeners.deliver(events)
+	return nil
+}
+
+// flush records the collected events in the journal.
+// Must be called before the transaction commits.
+func (t *eventTx) flush() error {
+	journal := t.journal.Load()
+	if journal == nil || len(t.events) == 0 {
+		return nil
+	}
+	return (*journal)(t.tx, t.events)
+}
+
+// commit delivers the collected events.
+// Must be called after the transaction commits.
+func (t *eventTx) commit() {
+	events := t.events
+	t.events = nil
+	t.listeners.deliver(events)
+}
@@ -0,0 +1,126 @@
+package sqlx
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nalgeon/redka/internal/core"
+)
+
+// sqlDeleteExpired deletes the key if it has expired.
+const sqlDeleteExpired = `
+delete from rkey
+where key = $1 and etime <= $2
+returning type, version`
+
+// Maximum number of expired keys waiting for deletion
+// (see [ExpireLater]). The keys over the limit are left
+// to the background expiration.
+const maxExpiredKeys = 1000
+
+// DeleteIfExpired deletes the key if it has expired
+// (lazy expiration) and records the "expired" change event.
+// Reports whether the key was deleted.
+func DeleteIfExpired(tx Tx, key string) (bool, error) {
+	now := time.Now().UnixMilli()
+	var k core.Key
+	err := tx.QueryRow(sqlDeleteExpired, key, now).Scan(&k.Type, &k.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	err = Emit(tx, core.Event{
+		Name:       "expired",
+		Key:        key,
+		Type:       k.Type,
+		OldVersion: k.Version,
+		Cmd:        []any{"del", key},
+	})
+	return true, err
+}
+
+// UpsertKey runs the query that creates or updates the key
+// and scans the returned row into dest. The query should update
+// only the key that has not expired (where rkey.etime is null
+// or rkey.etime > excluded.mtime), so it returns no rows for
+// the expired key. In that case, deletes the expired key (see
+// [DeleteIfExpired]) and runs the query again, so that the write
+// starts with a new key instead of the expired one.
+func UpsertKey(tx Tx, key string, query string, args []any, dest ...any) error {
+	err := tx.QueryRow(query, args...).Scan(dest...)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if _, err := DeleteIfExpired(tx, key); err != nil {
+		return err
+	}
+	return tx.QueryRow(query, args...).Scan(dest...)
+}
+
+// ExpireLater schedules the deletion of the expired key.
+// Use it in the read paths, which can't delete the key
+// themselves (see [DB.TakeExpired]). Does nothing if tx
+// does not belong to a database handle.
+func ExpireLater(tx Tx, key string) {
+	if etx, ok := tx.(*eventTx); ok && etx.expired != nil {
+		etx.expired.add(key)
+	}
+}
+
+// keySet is a set of keys waiting for processing
+// (see [ExpireLater] and [Touch]). Counts how many
+// times each key was added.
+//
+// keySet is safe for concurrent use by multiple goroutines.
+type keySet struct {
+	mu   sync.Mutex
+	keys map[string]int // key -> number of additions
+	max  int            // maximum number of keys in the set
+}
+
+// add adds the key to the set, unless the set is full.
+// If the key is already in the set, increments its count.
+func (s *keySet) add(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[key]; !ok && len(s.keys) >= s.max {
+		return
+	}
+	if s.keys == nil {
+		s.keys = map[string]int{}
+	}
+	s.keys[key]++
+}
+
+// remove removes the key from the set.
+func (s *keySet) remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+}
+
+// take removes all keys from the set and returns them.
+func (s *keySet) take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.keys))
+	for key := range s.keys {
+		keys = append(keys, key)
+	}
+	s.keys = nil
+	return keys
+}
+
+// takeCounts removes all keys from the set
+// and returns them along with their counts.
+func (s *keySet) takeCounts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := s.keys
+	s.keys = nil
+	return keys
+}
@@ -0,0 +1,122 @@
+package sqlx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/core"
+)
+
+func TestDeleteIfExpired(t *testing.T) {
+	rw, ro := openMemDB(t)
+	opts := &Options{Dialect: DialectSqlite, Timeout: time.Second, Pragma: map[string]string{}}
+	db, err := Open(rw, ro, opts)
+	be.Err(t, err, nil)
+	past := time.Now().Add(-time.Second).UnixMilli()
+	_, err = rw.Exec(`
+	insert into rkey (key, type, version, etime, mtime)
+	values ('name', 1, 3, $1, 0), ('age', 1, 1, null, 0)`, past)
+	be.Err(t, err, nil)
+
+	var events []core.Event
+	db.Listen(func(evs []core.Event) { events = append(events, evs...) })
+
+	deleted, err := DeleteIfExpired(db.Writer(), "name")
+	be.Err(t, err, nil)
+	be.True(t, deleted)
+	be.Equal(t, len(events), 1)
+	be.Equal(t, events[0].Name, "expired")
+	be.Equal(t, events[0].OldVersion, 3)
+
+	deleted, err = DeleteIfExpired(db.Writer(), "age")
+	be.Err(t, err, nil)
+	be.True(t, !deleted)
+	deleted, err = DeleteIfExpired(db.Writer(), "missing")
+	be.Err(t, err, nil)
+	be.True(t, !deleted)
+
+	var n int
+	_ = rw.QueryRow("select count(*) from rkey").Scan(&n)
+	be.Equal(t, n, 1)
+}
+
+func TestUpsertKey(t *testing.T) {
+	const upsert = `
+	insert into rkey (key, type, version, mtime)
+	values ($1, 1, 1, $2)
+	on conflict (key) do update set
+		version = rkey.version + 1,
+		mtime = excluded.mtime
+	where rkey.etime is null or rkey.etime > excluded.mtime
+	returning version`
+
+	t.Run("expired", func(t *testing.T) {
+		rw, ro := openMemDB(t)
+		opts := &Options{Dialect: DialectSqlite, Timeout: time.Second, Pragma: map[string]string{}}
+		db, err := Open(rw, ro, opts)
+		be.Err(t, err, nil)
+		past := time.Now().Add(-time.Second).UnixMilli()
+		_, err = rw.Exec(`
+		insert into rkey (key, type, version, etime, mtime)
+		values ('name', 1, 3, $1, 0)`, past)
+		be.Err(t, err, nil)
+
+		// The expired key is replaced with a new one.
+		var events []core.Event
+		db.Listen(func(evs []core.Event) { events = append(events, evs...) })
+		var version int
+		now := time.Now().UnixMilli()
+		err = UpsertKey(db.Writer(), "name", upsert, []any{"name", now}, &version)
+		be.Err(t, err, nil)
+		be.Equal(t, version, 1)
+		be.Equal(t, len(events), 1)
+		be.Equal(t, events[0].Name, "expired")
+	})
+	t.Run("existing", func(t *testing.T) {
+		rw, ro := openMemDB(t)
+		opts := &Options{Dialect: DialectSqlite, Timeout: time.Second, Pragma: map[string]string{}}
+		db, err := Open(rw, ro, opts)
+		be.Err(t, err, nil)
+		_, err = rw.Exec(`
+		insert into rkey (key, type, version, etime, mtime)
+		values ('name', 1, 3, null, 0)`)
+		be.Err(t, err, nil)
+
+		// The key is updated without deleting it.
+		var events []core.Event
+		db.Listen(func(evs []core.Event) { events = append(events, evs...) })
+		var version int
+		now := time.Now().UnixMilli()
+		err = UpsertKey(db.Writer(), "name", upsert, []any{"name", now}, &version)
+		be.Err(t, err, nil)
+		be.Equal(t, version, 4)
+		be.Equal(t, len(events), 0)
+	})
+	t.Run("error", func(t *testing.T) {
+		// No schema, so the upsert fails.
+		rw, _ := openMemDB(t)
+		var version int
+		err := UpsertKey(rw, "name", upsert, []any{"name", 0}, &version)
+		be.Err(t, err)
+	})
+}
+
+func TestExpireLater(t *testing.T) {
+	rw, ro := openMemDB(t)
+	opts := &Options{Dialect: DialectSqlite, Timeout: time.Second, Pragma: map[string]string{}}
+	db, err := Open(rw, ro, opts)
+	be.Err(t, err, nil)
+
+	ExpireLater(db.Reader(), "name")
+	ExpireLater(db.Reader(), "name")
+	ExpireLater(db.Reader(), "age")
+	keys := db.TakeExpired()
+	be.Equal(t, len(keys), 2)
+	be.Equal(t, len(db.TakeExpired()), 0)
+
+	for i := range maxExpiredKeys + 10 {
+		ExpireLater(db.Reader(), string(rune('a'+i)))
+	}
+	be.Equal(t, len(db.TakeExpired()), maxExpiredKeys)
+}
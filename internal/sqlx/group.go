@@ -0,0 +1,204 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// Maximum number of writable transactions
+// merged into a single group transaction.
+const maxGroupSize = 256
+
+// errGroupPanic is the result of the requests
+// in a group transaction that panicked.
+var errGroupPanic = errors.New("group transaction panicked")
+
+// groupCommit merges concurrent writable transactions
+// into a single database transaction (group commit).
+//
+// The first caller becomes the leader. While the leader runs
+// its group transaction, other callers queue up. When the leader
+// is done with its own request, it hands the leadership over to
+// the next queued caller, which runs all the queued requests in
+// the next group transaction, and so on.
+//
+// Each request runs in its own savepoint, so a failed request
+// rolls back only its own changes. The group commits as a whole,
+// so if the commit fails, all the requests in the group fail.
+// If a transaction function panics, its changes are rolled back,
+// and the panic is re-raised in the goroutine that called update.
+//
+// The leader delivers the change events to the listeners (see
+// [DB.Listen]) while holding the leadership, so a listener that
+// writes to the database deadlocks: its write waits for the
+// leader, which waits for the listener.
+type groupCommit struct {
+	mu      sync.Mutex
+	queue   []*groupReq
+	leading bool // whether some caller is the leader
+}
+
+// groupReq is a writable transaction request.
+type groupReq struct {
+	ctx  context.Context
+	db   *DB               // caller database handle (may have a trace)
+	f    func(tx Tx) error // transaction function
+	etx  *eventTx          // transaction with the request events
+	err  error             // request result
+	pnc  any               // panic of the transaction function, if any
+	done bool              // set before wake is closed
+	wake chan struct{}     // closed when done or promoted to leader
+}
+
+// update executes the function as part of a group transaction.
+// Blocks until the group transaction that includes the function
+// commits or rolls back, and returns the function result.
+func (g *groupCommit) update(ctx context.Context, db *DB, f func(tx Tx) error) error {
+	req := &groupReq{ctx: ctx, db: db, f: f, wake: make(chan struct{})}
+	g.mu.Lock()
+	g.queue = append(g.queue, req)
+	leader := !g.leading
+	g.leading = true
+	g.mu.Unlock()
+
+	if !leader {
+		// Wait until another leader executes the request,
+		// or until promoted to leader.
+		<-req.wake
+		if req.done {
+			return req.result()
+		}
+	}
+
+	// Hand off the leadership even if something panics,
+	// so that the queued callers don't wait forever.
+	defer g.handOff()
+	// The own request is in the queue until done,
+	// so the queue is never empty here.
+	for !req.done {
+		g.exec(req, g.next())
+	}
+	return req.result()
+}
+
+// result returns the request result. Re-raises the panic
+// of the transaction function, if any, so that it happens
+// in the caller's goroutine rather than in the leader's.
+func (r *groupReq) result() error {
+	if r.pnc != nil {
+		panic(r.pnc)
+	}
+	return r.err
+}
+
+// next removes the next group of requests from the queue.
+func (g *groupCommit) next() []*groupReq {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n := min(len(g.queue), maxGroupSize)
+	batch := g.queue[:n:n]
+	g.queue = g.queue[n:]
+	return batch
+}
+
+// handOff promotes the next queued caller to leader,
+// or releases the leadership if the queue is empty.
+func (g *groupCommit) handOff() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.queue) == 0 {
+		g.leading = false
+		return
+	}
+	close(g.queue[0].wake)
+}
+
+// exec executes the requests in a single transaction.
+// leader is the request of the caller executing the group.
+func (g *groupCommit) exec(leader *groupReq, batch []*groupReq) {
+	// Wake up the callers even if something (e.g. a listener)
+	// panics, so that they don't wait forever. If the group
+	// transaction itself panics, the requests fail.
+	err := errGroupPanic
+	defer func() {
+		for _, req := range batch {
+			if req.err == nil {
+				req.err = err
+			}
+			req.done = true
+			if req != leader {
+				close(req.wake)
+			}
+		}
+	}()
+	err = g.execTx(batch)
+	if err != nil {
+		return
+	}
+	for _, req := range batch {
+		if req.err == nil {
+			// Deliver the events only after a successful commit.
+			req.etx.commit()
+		}
+	}
+}
+
+// execTx executes the requests in a single transaction.
+// Sets the results of the individual requests and returns
+// the error that applies to the whole group (if any).
+func (g *groupCommit) execTx(batch []*groupReq) error {
+	db := batch[0].db
+	ctx, cancel := context.WithTimeout(context.Background(), db.Timeout())
+	defer cancel()
+	sqlTx, err := db.RW.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sqlTx.Rollback() }()
+
+	nOK := 0
+	for _, req := range batch {
+		if req.err = req.ctx.Err(); req.err != nil {
+			// The caller has given up waiting.
+			continue
+		}
+		req.err = g.execReq(sqlTx, req, len(batch) > 1)
+		if req.err == nil {
+			nOK++
+		}
+	}
+	if nOK == 0 {
+		// Nothing to commit.
+		return nil
+	}
+	return sqlTx.Commit()
+}
+
+// execReq executes the request within the transaction.
+// If isolated is true, executes it in a savepoint, so
+// that if it fails, only its own changes are rolled back.
+func (g *groupCommit) execReq(sqlTx *sql.Tx, req *groupReq, isolated bool) error {
+	req.etx = &eventTx{tx: req.db.wrap(sqlTx, req.db.RW), listeners: req.db.listeners, journal: req.db.journal, expired: req.db.expired, accessed: req.db.accessed}
+	run := func() (err error) {
+		defer func() {
+			// Roll back the request and re-raise
+			// the panic in the caller (see [groupReq.result]).
+			if p := recover(); p != nil {
+				req.pnc = p
+				err = errGroupPanic
+			}
+		}()
+		if err := req.f(req.etx); err != nil {
+			return err
+		}
+		// Record the change events in the same transaction,
+		// so that the journal is always consistent with the data.
+		return req.etx.flush()
+	}
+	if !isolated {
+		return run()
+	}
+	return Savepoint(req.etx, run)
+}
@@ -0,0 +1,240 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/core"
+)
+
+func TestGroupCommit(t *testing.T) {
+	t.Run("group", func(t *testing.T) {
+		db, act := openGroupDB(t)
+		var mu sync.Mutex
+		txs := map[*sql.Tx]int{}
+
+		errs := runGroup(t, db, act, 5, func(i int, tx Tx) error {
+			mu.Lock()
+			txs[tx.(*eventTx).tx.(*stmtTx).sqlTx]++
+			mu.Unlock()
+			return insertRow(tx, i)
+		})
+		for _, err := range errs {
+			be.Err(t, err, nil)
+		}
+		// All the queued requests run in one transaction.
+		be.Equal(t, len(txs), 1)
+		be.Equal(t, countRows(t, db), 6)
+	})
+	t.Run("failed request", func(t *testing.T) {
+		db, act := openGroupDB(t)
+		var events []string
+		cancel := db.Listen(func(evs []core.Event) {
+			for _, ev := range evs {
+				events = append(events, ev.Key)
+			}
+		})
+		defer cancel()
+
+		errFailed := errors.New("failed")
+		errs := runGroup(t, db, act, 3, func(i int, tx Tx) error {
+			if err := insertRow(tx, i); err != nil {
+				return err
+			}
+			_ = Emit(tx, core.Event{Name: "set", Key: fmt.Sprint(i)})
+			if i == 2 {
+				return errFailed
+			}
+			return nil
+		})
+		be.Err(t, errs[0], nil)
+		be.Err(t, errs[1], nil)
+		be.Err(t, errs[2], errFailed)
+		be.Err(t, errs[3], nil)
+
+		// Only the failed request is rolled back.
+		be.Equal(t, countRows(t, db), 3)
+		var n int
+		_ = db.RO.QueryRow("select count(*) from t where id = 2").Scan(&n)
+		be.Equal(t, n, 0)
+		// The events of the failed request are not delivered.
+		slices.Sort(events)
+		be.Equal(t, events, []string{"1", "3"})
+	})
+	t.Run("single request", func(t *testing.T) {
+		db, act := openGroupDB(t)
+		errFailed := errors.New("failed")
+		err := act.Update(func(tx Tx) error {
+			_ = insertRow(tx, 1)
+			return errFailed
+		})
+		be.Err(t, err, errFailed)
+		be.Equal(t, countRows(t, db), 0)
+
+		err = act.Update(func(tx Tx) error {
+			return insertRow(tx, 1)
+		})
+		be.Err(t, err, nil)
+		be.Equal(t, countRows(t, db), 1)
+	})
+	t.Run("canceled request", func(t *testing.T) {
+		db, act := openGroupDB(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := act.UpdateContext(ctx, func(tx Tx) error {
+			return insertRow(tx, 1)
+		})
+		be.Err(t, err, context.Canceled)
+		be.Equal(t, countRows(t, db), 0)
+	})
+	t.Run("panicked request", func(t *testing.T) {
+		db, act := openGroupDB(t)
+		errs := runGroup(t, db, act, 3, func(i int, tx Tx) error {
+			if err := insertRow(tx, i); err != nil {
+				return err
+			}
+			if i == 2 {
+				panic("oops")
+			}
+			return nil
+		})
+		be.Err(t, errs[0], nil)
+		be.Err(t, errs[1], nil)
+		// The panic is raised in the caller's goroutine.
+		be.Err(t, errs[2], "panic: oops")
+		be.Err(t, errs[3], nil)
+
+		// Only the panicked request is rolled back.
+		be.Equal(t, countRows(t, db), 3)
+
+		// The leadership is released.
+		err := act.Update(func(tx Tx) error {
+			return insertRow(tx, 4)
+		})
+		be.Err(t, err, nil)
+		be.Equal(t, countRows(t, db), 4)
+	})
+	t.Run("panicked listener", func(t *testing.T) {
+		db, act := openGroupDB(t)
+		cancel := db.Listen(func(evs []core.Event) {
+			for _, ev := range evs {
+				if ev.Key == "1" {
+					panic("oops")
+				}
+			}
+		})
+		errs := runGroup(t, db, act, 3, func(i int, tx Tx) error {
+			if err := insertRow(tx, i); err != nil {
+				return err
+			}
+			_ = Emit(tx, core.Event{Name: "set", Key: fmt.Sprint(i)})
+			return nil
+		})
+		cancel()
+
+		// The panic is raised in the leader's goroutine,
+		// and the other callers are released.
+		var panics int
+		for _, err := range errs {
+			if err != nil {
+				be.Err(t, err, "panic: oops")
+				panics++
+			}
+		}
+		be.Equal(t, panics, 1)
+		be.Equal(t, countRows(t, db), 4)
+
+		// The leadership is released.
+		err := act.Update(func(tx Tx) error {
+			return insertRow(tx, 4)
+		})
+		be.Err(t, err, nil)
+		be.Equal(t, countRows(t, db), 5)
+	})
+}
+
+// openGroupDB opens an in-memory database with the group commit
+// enabled and an empty test table.
+func openGroupDB(t *testing.T) (*DB, *Transactor[Tx]) {
+	t.Helper()
+	rw, ro := openMemDB(t)
+	opts := &Options{Dialect: DialectSqlite, Timeout: 5 * time.Second, GroupCommit: true}
+	db, err := New(rw, ro, opts)
+	be.Err(t, err, nil)
+	_, err = db.RW.Exec("create table t (id integer primary key)")
+	be.Err(t, err, nil)
+	act := NewTransactor(db, func(_ Dialect, tx Tx) Tx { return tx })
+	return db, act
+}
+
+// runGroup runs a blocking request, queues n more requests
+// while it's running, then releases the blocking one.
+// Returns the results of all requests (the blocking one first).
+// A request that panics returns the "panic: value" error.
+func runGroup(t *testing.T, db *DB, act *Transactor[Tx], n int, f func(i int, tx Tx) error) []error {
+	t.Helper()
+	errs := make([]error, n+1)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errs[0] = act.Update(func(tx Tx) error {
+			close(started)
+			<-release
+			return insertRow(tx, 0)
+		})
+	}()
+	<-started
+
+	for i := 1; i <= n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					errs[i] = fmt.Errorf("panic: %v", p)
+				}
+			}()
+			errs[i] = act.Update(func(tx Tx) error {
+				return f(i, tx)
+			})
+		}()
+	}
+	// Wait until all the requests are queued.
+	for {
+		db.group.mu.Lock()
+		queued := len(db.group.queue)
+		db.group.mu.Unlock()
+		if queued == n {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	return errs
+}
+
+func insertRow(tx Tx, id int) error {
+	_, err := tx.Exec("insert into t (id) values ($1)", id)
+	return err
+}
+
+func countRows(t *testing.T, db *DB) int {
+	t.Helper()
+	var n int
+	err := db.RO.QueryRow("select count(*) from t").Scan(&n)
+	be.Err(t, err, nil)
+	return n
+}
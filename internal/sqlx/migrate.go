@@ -0,0 +1,389 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+)
+
+//go:embed sqlite-v2.sql
+var sqliteMigrateV2 string
+
+//go:embed sqlite-v3.sql
+var sqliteMigrateV3 string
+
+//go:embed sqlite-v4.sql
+var sqliteMigrateV4 string
+
+//go:embed sqlite-v5.sql
+var sqliteMigrateV5 string
+
+//go:embed sqlite-v6.sql
+var sqliteMigrateV6 string
+
+//go:embed postgres-v2.sql
+var postgresMigrateV2 string
+
+//go:embed postgres-v3.sql
+var postgresMigrateV3 string
+
+// postgresMigrateLock is the key of the Postgres advisory lock
+// that serializes concurrent migrations ("redka" in hex).
+const postgresMigrateLock = 0x7265646b61
+
+// ErrSchemaVersion is returned when the database schema
+// version is not supported by this version of Redka.
+var ErrSchemaVersion = errors.New("unsupported schema version")
+
+// Migration is a schema migration step.
+type Migration struct {
+	Version int    // schema version after the step
+	Name    string // short description
+	Script  string // SQL script
+}
+
+// SQLite migration steps, ordered by version.
+// Add new steps to the end of the list.
+var sqliteMigrations = []Migration{
+	{Version: 2, Name: "store string values in rkey", Script: sqliteMigrateV2},
+	{Version: 3, Name: "count keys in rkey_count", Script: sqliteMigrateV3},
+	{Version: 4, Name: "add access time to rkey", Script: sqliteMigrateV4},
+	{Version: 5, Name: "add access counter to rkey", Script: sqliteMigrateV5},
+	{Version: 6, Name: "drop the rkey_count key counter", Script: sqliteMigrateV6},
+}
+
+// Postgres migration steps, ordered by version.
+// Add new steps to the end of the list.
+var postgresMigrations = []Migration{
+	{Version: 2, Name: "add access time to rkey", Script: postgresMigrateV2},
+	{Version: 3, Name: "add access counter to rkey", Script: postgresMigrateV3},
+}
+
+// schema is the database schema of an SQL dialect.
+type schema struct {
+	create     string      // creates the latest schema (idempotent)
+	migrations []Migration // steps from the version 1 to the latest
+	// Returns the schema version, or 0 if there is no schema.
+	version func(db Tx) (int, error)
+	// Sets the schema version.
+	setVersion func(tx Tx, version int) error
+	// Starts a migration transaction and locks the database,
+	// so that concurrent migrations run one at a time.
+	begin func(tx Tx) error
+}
+
+// latest returns the latest schema version.
+func (s *schema) latest() int {
+	if len(s.migrations) == 0 {
+		return 1
+	}
+	return s.migrations[len(s.migrations)-1].Version
+}
+
+// pending returns the current schema version
+// and the migration steps to reach the latest one.
+func (s *schema) pending(db Tx) (int, []Migration, error) {
+	version, err := s.version(db)
+	if err != nil {
+		return 0, nil, err
+	}
+	if version > s.latest() {
+		return 0, nil, fmt.Errorf("%w: database version %d is newer than %d",
+			ErrSchemaVersion, version, s.latest())
+	}
+	if version == 0 {
+		step := Migration{Version: s.latest(), Name: "create schema", Script: s.create}
+		return 0, []Migration{step}, nil
+	}
+	pending := []Migration{}
+	for _, step := range s.migrations {
+		if step.Version > version {
+			pending = append(pending, step)
+		}
+	}
+	return version, pending, nil
+}
+
+// getSchema returns the database schema for the options.
+func getSchema(opts *Options) (*schema, error) {
+	switch opts.Dialect {
+	case DialectSqlite:
+		if opts.TablePrefix != "" {
+			return prefixedSqliteSchema(newNames(opts.TablePrefix)), nil
+		}
+		return &schema{
+			create:     sqliteSchema,
+			migrations: sqliteMigrations,
+			version:    sqliteVersion,
+			setVersion: sqliteSetVersion,
+			begin:      sqliteBegin,
+		}, nil
+	case DialectPostgres:
+		return &schema{
+			create:     postgresSchema,
+			migrations: postgresMigrations,
+			version:    postgresVersion,
+			setVersion: postgresSetVersion,
+			begin:      postgresBegin,
+		}, nil
+	default:
+		return nil, ErrDialect
+	}
+}
+
+// prefixedSqliteSchema returns the SQLite schema with
+// the prefixed object names. Stores the version in the
+// version table instead of the user_version pragma, since
+// the pragma is shared by all the tables in the database.
+func prefixedSqliteSchema(n *names) *schema {
+	migrations := make([]Migration, len(sqliteMigrations))
+	for i, step := range sqliteMigrations {
+		step.Script = n.rename(step.Script)
+		migrations[i] = step
+	}
+	return &schema{
+		create:     n.rename(sqliteSchema),
+		migrations: migrations,
+		version: func(db Tx) (int, error) {
+			var exists bool
+			err := db.QueryRow(
+				"select count(*) > 0 from sqlite_schema where type = 'table' and name = $1",
+				n.rename("rversion"),
+			).Scan(&exists)
+			if err != nil || !exists {
+				return 0, err
+			}
+			var version int
+			err = db.QueryRow(n.rename("select version from rversion")).Scan(&version)
+			if err == sql.ErrNoRows {
+				return 0, nil
+			}
+			return version, err
+		},
+		setVersion: func(tx Tx, version int) error {
+			_, err := tx.Exec(n.rename(`
+			create table if not exists rversion (version integer not null) strict;
+			delete from rversion;`))
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(n.rename("insert into rversion (version) values ($1)"), version)
+			return err
+		},
+		begin: sqliteBegin,
+	}
+}
+
+// PendingMigrations returns the migration steps the database
+// needs to reach the latest schema version. If the database has
+// no schema, returns a single step that creates it. If the schema
+// is up to date, returns an empty slice.
+//
+// Returns ErrSchemaVersion if the database schema
+// is newer than the latest supported version.
+func PendingMigrations(db *sql.DB, opts *Options) ([]Migration, error) {
+	s, err := getSchema(opts)
+	if err != nil {
+		return nil, err
+	}
+	_, pending, err := s.pending(db)
+	return pending, err
+}
+
+// Migrate upgrades the database schema to the latest version
+// (or creates it if there is none). Uses the dialect, the table prefix
+// and the schema from the options. Applies each step in
+// a separate transaction along with the new version number,
+// so a failed step leaves the database at the previous version.
+// Each step locks the database and re-reads the version first,
+// so concurrent migrations do not apply the same step twice.
+// Returns the applied steps (see [PendingMigrations]).
+func Migrate(db *sql.DB, opts *Options) ([]Migration, error) {
+	s, err := getSchema(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Dialect == DialectPostgres {
+		if err := postgresCreateSchema(db, opts.Schema); err != nil {
+			return nil, err
+		}
+	}
+	version, pending, err := s.pending(db)
+	if err != nil {
+		return nil, err
+	}
+	applied := []Migration{}
+	from := version
+	for _, step := range pending {
+		ok, err := migrateStep(db, s, from, step)
+		if err != nil {
+			return nil, fmt.Errorf("migrate to version %d (%s): %w", step.Version, step.Name, err)
+		}
+		if ok {
+			applied = append(applied, step)
+		}
+		from = step.Version
+	}
+	if version != 0 {
+		// Create the missing schema objects, if any.
+		step := Migration{Version: s.latest(), Script: s.create}
+		_, err = migrateStep(db, s, s.latest(), step)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return applied, nil
+}
+
+// migrateStep applies the migration step to the database
+// at the from version in a transaction. Locks the database
+// and re-reads the version first. If another process has
+// already applied the step, does nothing and returns false.
+func migrateStep(db *sql.DB, s *schema, from int, step Migration) (bool, error) {
+	// The transaction is started manually (see [schema.begin]),
+	// so all the queries must go through the same connection.
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+	tx := connTx{ctx: ctx, conn: conn}
+
+	if err := s.begin(tx); err != nil {
+		return false, err
+	}
+	// Does nothing after a successful commit.
+	defer func() { _, _ = tx.Exec("rollback") }()
+
+	version, err := s.version(tx)
+	if err != nil {
+		return false, err
+	}
+	if version != from {
+		if version >= step.Version {
+			// Already applied by another process.
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: database version changed from %d to %d",
+			ErrSchemaVersion, from, version)
+	}
+	if _, err := tx.Exec(step.Script); err != nil {
+		return false, err
+	}
+	if err := s.setVersion(tx, step.Version); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec("commit"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// connTx runs the queries on a single connection.
+// Used for the transactions started with a custom
+// statement (like begin immediate), which [sql.DB]
+// does not support.
+type connTx struct {
+	ctx  context.Context
+	conn *sql.Conn
+}
+
+func (tx connTx) Query(query string, args ...any) (*sql.Rows, error) {
+	return tx.conn.QueryContext(tx.ctx, query, args...)
+}
+
+func (tx connTx) QueryRow(query string, args ...any) *sql.Row {
+	return tx.conn.QueryRowContext(tx.ctx, query, args...)
+}
+
+func (tx connTx) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.conn.ExecContext(tx.ctx, query, args...)
+}
+
+// checkVersion checks that the database schema
+// (if any) has the latest version.
+func checkVersion(db *sql.DB, opts *Options) error {
+	s, err := getSchema(opts)
+	if err != nil {
+		return err
+	}
+	version, err := s.version(db)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != s.latest() {
+		return fmt.Errorf("%w: database version %d, expected %d (run the migration)",
+			ErrSchemaVersion, version, s.latest())
+	}
+	return nil
+}
+
+// sqliteVersion returns the SQLite schema version.
+func sqliteVersion(db Tx) (int, error) {
+	var version int
+	err := db.QueryRow("pragma user_version").Scan(&version)
+	return version, err
+}
+
+// sqliteSetVersion sets the SQLite schema version.
+func sqliteSetVersion(tx Tx, version int) error {
+	_, err := tx.Exec(fmt.Sprintf("pragma user_version = %d", version))
+	return err
+}
+
+// sqliteBegin starts an immediate SQLite transaction,
+// which takes the write lock right away.
+func sqliteBegin(tx Tx) error {
+	_, err := tx.Exec("begin immediate")
+	return err
+}
+
+// postgresVersion returns the Postgres schema version.
+// The databases created before the version table
+// was introduced have the version 1.
+func postgresVersion(db Tx) (int, error) {
+	var hasVersion, hasKeys bool
+	err := db.QueryRow(`
+	select
+		to_regclass('rversion') is not null,
+		to_regclass('rkey') is not null`,
+	).Scan(&hasVersion, &hasKeys)
+	if err != nil {
+		return 0, err
+	}
+	if !hasVersion {
+		if hasKeys {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	var version int
+	err = db.QueryRow("select version from rversion").Scan(&version)
+	if err == sql.ErrNoRows {
+		return 1, nil
+	}
+	return version, err
+}
+
+// postgresSetVersion sets the Postgres schema version.
+func postgresSetVersion(tx Tx, version int) error {
+	if _, err := tx.Exec("delete from rversion"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("insert into rversion (version) values ($1)", version)
+	return err
+}
+
+// postgresBegin starts a Postgres transaction and takes
+// the migration advisory lock, which is released on commit
+// or rollback.
+func postgresBegin(tx Tx) error {
+	if _, err := tx.Exec("begin"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("select pg_advisory_xact_lock($1)", postgresMigrateLock)
+	return err
+}
@@ -0,0 +1,171 @@
+package sqlx
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+func TestMigrate(t *testing.T) {
+	opts := &Options{Dialect: DialectSqlite, Timeout: time.Second, Pragma: map[string]string{}}
+
+	t.Run("empty", func(t *testing.T) {
+		rw, ro := openMemDB(t)
+		pending, err := PendingMigrations(rw, opts)
+		be.Err(t, err, nil)
+		be.Equal(t, len(pending), 1)
+		be.Equal(t, pending[0].Version, 6)
+		be.Equal(t, pending[0].Name, "create schema")
+
+		_, err = Open(rw, ro, opts)
+		be.Err(t, err, nil)
+		version, _ := sqliteVersion(rw)
+		be.Equal(t, version, 6)
+		pending, err = PendingMigrations(rw, opts)
+		be.Err(t, err, nil)
+		be.Equal(t, len(pending), 0)
+	})
+	t.Run("older", func(t *testing.T) {
+		rw, ro := openMemDB(t)
+		_, err := rw.Exec(sqliteSchemaV1)
+		be.Err(t, err, nil)
+
+		pending, err := PendingMigrations(rw, opts)
+		be.Err(t, err, nil)
+		be.Equal(t, len(pending), 5)
+		be.Equal(t, pending[0].Version, 2)
+		be.Equal(t, pending[1].Version, 3)
+		be.Equal(t, pending[2].Version, 4)
+		be.Equal(t, pending[3].Version, 5)
+		be.Equal(t, pending[4].Version, 6)
+
+		// New does not migrate the schema.
+		_, err = New(rw, ro, opts)
+		be.Err(t, err, ErrSchemaVersion)
+
+		applied, err := Migrate(rw, opts)
+		be.Err(t, err, nil)
+		be.Equal(t, applied, pending)
+		version, _ := sqliteVersion(rw)
+		be.Equal(t, version, 6)
+		_, err = New(rw, ro, opts)
+		be.Err(t, err, nil)
+	})
+	t.Run("newer", func(t *testing.T) {
+		rw, ro := openMemDB(t)
+		_, err := rw.Exec("pragma user_version = 100")
+		be.Err(t, err, nil)
+
+		_, err = PendingMigrations(rw, opts)
+		be.Err(t, err, ErrSchemaVersion)
+		_, err = Open(rw, ro, opts)
+		be.Err(t, err, ErrSchemaVersion)
+		_, err = New(rw, ro, opts)
+		be.Err(t, err, ErrSchemaVersion)
+	})
+	t.Run("failed step", func(t *testing.T) {
+		rw, _ := openMemDB(t)
+		_, err := rw.Exec(sqliteSchemaV1)
+		be.Err(t, err, nil)
+
+		s, _ := getSchema(opts)
+		step := Migration{Version: 2, Script: "alter table rkey add column value blob; select * from missing"}
+		_, err = migrateStep(rw, s, 1, step)
+		be.True(t, err != nil)
+
+		// The failed step is rolled back along with the version.
+		version, _ := sqliteVersion(rw)
+		be.Equal(t, version, 1)
+		var n int
+		_ = rw.QueryRow("select count(*) from pragma_table_info('rkey') where name = 'value'").Scan(&n)
+		be.Equal(t, n, 0)
+	})
+	t.Run("applied step", func(t *testing.T) {
+		rw, _ := openMemDB(t)
+		_, err := rw.Exec(sqliteSchemaV1)
+		be.Err(t, err, nil)
+		_, err = rw.Exec("pragma user_version = 3")
+		be.Err(t, err, nil)
+
+		// Another process has migrated the database
+		// after the version was read.
+		s, _ := getSchema(opts)
+		step := Migration{Version: 2, Script: "select * from missing"}
+		ok, err := migrateStep(rw, s, 1, step)
+		be.Err(t, err, nil)
+		be.Equal(t, ok, false)
+		version, _ := sqliteVersion(rw)
+		be.Equal(t, version, 3)
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "redka.db")
+		const n = 4
+		applied := make([][]Migration, n)
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := range n {
+			db, err := sql.Open("sqlite3", DataSource(path, false, opts))
+			be.Err(t, err, nil)
+			t.Cleanup(func() { _ = db.Close() })
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				applied[i], errs[i] = Migrate(db, opts)
+			}()
+		}
+		wg.Wait()
+
+		// Only one of the migrations creates the schema.
+		var steps int
+		for i := range n {
+			be.Err(t, errs[i], nil)
+			steps += len(applied[i])
+		}
+		be.Equal(t, steps, 1)
+	})
+	t.Run("table prefix", func(t *testing.T) {
+		rw, ro := openMemDB(t)
+		_, err := rw.Exec("pragma user_version = 42")
+		be.Err(t, err, nil)
+
+		for _, prefix := range []string{"one_", "two_"} {
+			popts := *opts
+			popts.TablePrefix = prefix
+			pending, err := PendingMigrations(rw, &popts)
+			be.Err(t, err, nil)
+			be.Equal(t, len(pending), 1)
+			be.True(t, strings.Contains(pending[0].Script, prefix+"rkey"))
+
+			_, err = Open(rw, ro, &popts)
+			be.Err(t, err, nil)
+			_, err = New(rw, ro, &popts)
+			be.Err(t, err, nil)
+			s, _ := getSchema(&popts)
+			version, _ := s.version(rw)
+			be.Equal(t, version, 6)
+		}
+
+		// The prefixed schemas leave the user version alone.
+		version, _ := sqliteVersion(rw)
+		be.Equal(t, version, 42)
+		var n int
+		_ = rw.QueryRow("select count(*) from sqlite_schema where name like '%rkey'").Scan(&n)
+		be.Equal(t, n, 2)
+
+		// Every schema object has a prefix.
+		_ = rw.QueryRow(`select count(*) from sqlite_schema
+			where name not like 'one%' and name not like 'two%'
+			and name not like 'sqlite%'`).Scan(&n)
+		be.Equal(t, n, 0)
+	})
+	t.Run("unknown dialect", func(t *testing.T) {
+		rw, _ := openMemDB(t)
+		_, err := Migrate(rw, &Options{Dialect: DialectUnknown})
+		be.Err(t, err, ErrDialect)
+	})
+}
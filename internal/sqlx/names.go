@@ -0,0 +1,195 @@
+package sqlx
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// schemaNames are the names of the schema objects (tables, views,
+// indexes and triggers) that the table prefix applies to. Includes
+// the objects that only the older schema versions have, since
+// the migrations refer to them.
+var schemaNames = []string{
+	// Tables.
+	"rkey", "rstring", "rlist", "rset", "rhash", "rzset",
+	"rchange", "rversion", "rkey_count",
+	// Views.
+	"vkey", "vstring", "vlist", "vset", "vhash", "vzset",
+	// Indexes.
+	"rkey_key_idx", "rkey_etime_idx", "rkey_atime_idx",
+	"rlist_pk_idx", "rset_pk_idx", "rhash_pk_idx", "rzset_pk_idx",
+	"rzset_score_idx", "rchange_time_idx",
+	// Triggers.
+	"rkey_on_insert", "rkey_on_delete",
+	"rlist_on_update", "rlist_on_delete",
+	"rset_on_insert", "rhash_on_insert", "rzset_on_insert",
+}
+
+// identRegexp matches a valid unquoted SQL identifier.
+var identRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// names prefixes the schema object names in the SQL queries,
+// so that several Redka instances can share a single database
+// (see [Options.TablePrefix]). The nil *names keeps the names as is.
+//
+// Only renames the unquoted identifiers listed in [schemaNames].
+// Keeps the string literals, quoted identifiers, comments,
+// column names (rkey.id, but not tbl.rkey) and aliases (as rkey)
+// as is.
+//
+// names is safe for concurrent use by multiple goroutines.
+type names struct {
+	prefixed map[string]string // name -> prefixed name
+	static   sync.Map          // static query -> renamed query
+}
+
+// newNames creates a renamer for the given prefix.
+// Returns nil if the prefix is empty.
+func newNames(prefix string) *names {
+	if prefix == "" {
+		return nil
+	}
+	prefixed := make(map[string]string, len(schemaNames))
+	for _, name := range schemaNames {
+		prefixed[name] = prefix + name
+	}
+	return &names{prefixed: prefixed}
+}
+
+// register renames the static queries (see [DB.Prepare]) once,
+// so that executing them does not rename them again.
+func (n *names) register(queries []string) {
+	if n == nil {
+		return
+	}
+	for _, query := range queries {
+		n.static.LoadOrStore(query, n.replace(query))
+	}
+}
+
+// rename returns the query with the prefixed schema object names.
+func (n *names) rename(query string) string {
+	if n == nil {
+		return query
+	}
+	if renamed, ok := n.static.Load(query); ok {
+		return renamed.(string)
+	}
+	return n.replace(query)
+}
+
+// replace scans the query and replaces the schema object names
+// with the prefixed ones.
+func (n *names) replace(query string) string {
+	var b strings.Builder
+	last := 0       // end of the query part copied to b
+	prev := ""      // previous identifier or keyword
+	var before byte // last non-space byte before the token
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// String literal or quoted identifier.
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				return n.flush(&b, query, last)
+			}
+			i += end + 2
+		case c == '[':
+			// Quoted identifier (SQLite).
+			end := strings.IndexByte(query[i+1:], ']')
+			if end < 0 {
+				return n.flush(&b, query, last)
+			}
+			i += end + 2
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return n.flush(&b, query, last)
+			}
+			i += end
+			continue
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return n.flush(&b, query, last)
+			}
+			i += end + 4
+			continue
+		case isIdentByte(c):
+			start := i
+			for i < len(query) && isIdentByte(query[i]) {
+				i++
+			}
+			word := query[start:i]
+			// Skip the numbers, parameters ($1, :keys),
+			// qualified column names (t.rkey) and aliases (as rkey).
+			skip := c >= '0' && c <= '9' || before == '$' || before == ':' ||
+				before == '.' || strings.EqualFold(prev, "as")
+			if name, ok := n.prefixed[word]; ok && !skip {
+				b.WriteString(query[last:start])
+				b.WriteString(name)
+				last = i
+			}
+			prev = word
+		default:
+			i++
+		}
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			continue
+		}
+		if !isIdentByte(c) {
+			// An alias follows "as" directly.
+			prev = ""
+		}
+		before = c
+	}
+	return n.flush(&b, query, last)
+}
+
+// flush returns the renamed query: the part in b
+// followed by the rest of the query starting at last.
+func (n *names) flush(b *strings.Builder, query string, last int) string {
+	if last == 0 {
+		return query
+	}
+	b.WriteString(query[last:])
+	return b.String()
+}
+
+// isIdentByte reports whether c may be a part
+// of an unquoted identifier.
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// checkIdent checks that the option value is a valid
+// SQL identifier (or a part of it).
+func checkIdent(option, value string) error {
+	if value == "" || identRegexp.MatchString(value) {
+		return nil
+	}
+	return fmt.Errorf("invalid %s: %q", option, value)
+}
+
+// renamedTx is a Tx that prefixes the schema object names
+// in the queries before executing them.
+type renamedTx struct {
+	tx    Tx
+	names *names
+}
+
+func (t renamedTx) Query(query string, args ...any) (*sql.Rows, error) {
+	return t.tx.Query(t.names.rename(query), args...)
+}
+
+func (t renamedTx) QueryRow(query string, args ...any) *sql.Row {
+	return t.tx.QueryRow(t.names.rename(query), args...)
+}
+
+func (t renamedTx) Exec(query string, args ...any) (sql.Result, error) {
+	return t.tx.Exec(t.names.rename(query), args...)
+}
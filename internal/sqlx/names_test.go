@@ -0,0 +1,70 @@
+package sqlx
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+)
+
+func TestNamesRename(t *testing.T) {
+	n := newNames("app_")
+	tests := []struct{ query, want string }{
+		{
+			"select value from rkey where key = $1",
+			"select value from app_rkey where key = $1",
+		},
+		{
+			"select rkey.version, rhash.value from rhash join rkey on kid = rkey.id",
+			"select app_rkey.version, app_rhash.value from app_rhash join app_rkey on kid = app_rkey.id",
+		},
+		{
+			"create index if not exists rzset_score_idx on rzset (kid, score, elem)",
+			"create index if not exists app_rzset_score_idx on app_rzset (kid, score, elem)",
+		},
+		{
+			"drop trigger if exists rlist_on_update; select * from vkey",
+			"drop trigger if exists app_rlist_on_update; select * from app_vkey",
+		},
+		{
+			// Columns and other names are not renamed.
+			"select version, value from things where reset = 1",
+			"select version, value from things where reset = 1",
+		},
+		{
+			// Neither are literals, comments, parameters,
+			// qualified columns and aliases.
+			"select 'rkey', \"rkey\", t.rkey, count(*) as rkey from rkey t -- rkey\n" +
+				"where key = :rkey /* rkey */ and etime > $1",
+			"select 'rkey', \"rkey\", t.rkey, count(*) as rkey from app_rkey t -- rkey\n" +
+				"where key = :rkey /* rkey */ and etime > $1",
+		},
+	}
+	for _, test := range tests {
+		be.Equal(t, n.rename(test.query), test.want)
+	}
+
+	// The static queries are renamed once.
+	query := "select count(*) from rkey"
+	n.register([]string{query})
+	renamed, ok := n.static.Load(query)
+	be.True(t, ok)
+	be.Equal(t, renamed.(string), "select count(*) from app_rkey")
+	be.Equal(t, n.rename(query), "select count(*) from app_rkey")
+
+	var nilNames *names
+	be.Equal(t, nilNames.rename("select * from rkey"), "select * from rkey")
+	be.True(t, newNames("") == nil)
+}
+
+func TestCheckOptions(t *testing.T) {
+	err := checkOptions(&Options{Dialect: DialectSqlite, TablePrefix: "app_"})
+	be.Err(t, err, nil)
+	err = checkOptions(&Options{Dialect: DialectPostgres, Schema: "app"})
+	be.Err(t, err, nil)
+	err = checkOptions(&Options{Dialect: DialectPostgres, TablePrefix: "app_"})
+	be.Err(t, err, "table prefix is not supported")
+	err = checkOptions(&Options{Dialect: DialectSqlite, Schema: "app"})
+	be.Err(t, err, "schema is not supported")
+	err = checkOptions(&Options{Dialect: DialectSqlite, TablePrefix: "app; drop table rkey; --"})
+	be.Err(t, err, "invalid table prefix")
+}
@@ -0,0 +1,11 @@
+-- Adds the key access time, so that the eviction
+-- can delete the least recently used keys.
+create table if not exists
+rversion (
+    version integer not null
+);
+
+alter table rkey add column if not exists atime bigint;
+
+create index if not exists
+rkey_atime_idx on rkey ((coalesce(atime, mtime)));
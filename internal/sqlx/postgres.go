@@ -3,32 +3,53 @@ package sqlx
 import (
 	"database/sql"
 	_ "embed"
+	"fmt"
 	"net/url"
 	"strings"
+	"sync/atomic"
 )
 
 //go:embed postgres.sql
 var postgresSchema string
 
+// sqlPostgresSize selects the total size of the key and value tables.
+const sqlPostgresSize = `
+select coalesce(sum(pg_total_relation_size(to_regclass(name))), 0)::bigint
+from unnest(array['rkey', 'rstring', 'rlist', 'rset', 'rhash', 'rzset']) as name`
+
 // A Postgres database handle.
 type postgres DB
 
 // openPostgres creates a new Postgres database handle.
 // Creates the database schema if necessary.
 func openPostgres(rw *sql.DB, ro *sql.DB, opts *Options) (*postgres, error) {
+	// The schema must exist before checking the search path.
+	if err := postgresCreateSchema(rw, opts.Schema); err != nil {
+		return nil, err
+	}
 	d, err := newPostgres(rw, ro, opts)
 	if err != nil {
 		return nil, err
 	}
-	err = d.createSchema()
+	err = d.createSchema(opts)
 	return d, err
 }
 
 // newPostgres creates a new Postgres database handle.
 // Like openPostgres, but does not create the database schema.
 func newPostgres(rw *sql.DB, ro *sql.DB, opts *Options) (*postgres, error) {
-	d := &postgres{Dialect: DialectPostgres, RW: rw, RO: ro, Timeout: opts.Timeout}
+	d := &postgres{Dialect: DialectPostgres, RW: rw, RO: ro, timeout: new(atomic.Int64), listeners: new(listeners), journal: new(atomic.Pointer[Journal]), expired: &keySet{max: maxExpiredKeys}, accessed: newAccessedKeys()}
+	(*DB)(d).SetTimeout(opts.Timeout)
+	if opts.GroupCommit {
+		d.group = new(groupCommit)
+	}
+	if !opts.NoPrepare {
+		d.stmts = newStmtCache(opts.Logger)
+	}
 	d.setNumConns(opts.ReadOnly)
+	if err := d.checkSchema(opts.Schema); err != nil {
+		return nil, err
+	}
 	return d, nil
 }
 
@@ -47,15 +68,46 @@ func (d *postgres) setNumConns(readOnly bool) {
 	}
 }
 
-// createSchema creates the database schema.
-func (d *postgres) createSchema() error {
-	_, err := d.RW.Exec(postgresSchema)
+// checkSchema checks that the connections use the given
+// schema (if any) to create and look up the schema objects.
+func (d *postgres) checkSchema(schema string) error {
+	if schema == "" {
+		return nil
+	}
+	for _, db := range []*sql.DB{d.RW, d.RO} {
+		var current sql.NullString
+		if err := db.QueryRow("select current_schema()").Scan(&current); err != nil {
+			return err
+		}
+		if !strings.EqualFold(current.String, schema) {
+			return fmt.Errorf("schema %s is not the first in the search_path (current schema: %q)",
+				schema, current.String)
+		}
+	}
+	return nil
+}
+
+// postgresCreateSchema creates the named schema
+// if it does not exist. Does nothing if the name is empty.
+func postgresCreateSchema(db *sql.DB, name string) error {
+	if name == "" {
+		return nil
+	}
+	_, err := db.Exec("create schema if not exists " + name)
+	return err
+}
+
+// createSchema creates the database schema, or migrates
+// the existing one to the latest version if necessary.
+func (d *postgres) createSchema(opts *Options) error {
+	_, err := Migrate(d.RW, opts)
 	return err
 }
 
 // postgresDataSource returns a Postgres connection string
-// for a read-only or read-write mode.
-func postgresDataSource(path string, readOnly bool, pragma map[string]string) string {
+// for a read-only or read-write mode. If the schema is set,
+// uses it as the search path.
+func postgresDataSource(path string, readOnly bool, schema string, pragma map[string]string) string {
 	// Parse the parameters.
 	source, query, _ := strings.Cut(path, "?")
 	params, _ := url.ParseQuery(query)
@@ -65,6 +117,11 @@ func postgresDataSource(path string, readOnly bool, pragma map[string]string) st
 		params.Set("default_transaction_read_only", "on")
 	}
 
+	// Create and look up the schema objects in the schema.
+	if schema != "" {
+		params.Set("search_path", schema)
+	}
+
 	// Apply the pragma settings.
 	for name, val := range pragma {
 		params.Add(name, val)
@@ -7,6 +7,9 @@
 -- 3 - set
 -- 4 - hash
 -- 5 - zset (sorted set)
+-- The access time (atime) and the access counter (freq)
+-- are only updated if the access tracking is enabled,
+-- and are null until the first update.
 create table if not exists
 rkey (
     id      serial primary key,
@@ -15,7 +18,9 @@ rkey (
     version integer not null,
     etime   bigint,
     mtime   bigint not null,
-    len     integer
+    len     integer,
+    atime   bigint,
+    freq    integer
 );
 
 create unique index if not exists
@@ -25,6 +30,9 @@ create index if not exists
 rkey_etime_idx on rkey (etime)
 where etime is not null;
 
+create index if not exists
+rkey_atime_idx on rkey ((coalesce(atime, mtime)));
+
 create or replace view
 vkey as
 select
@@ -249,3 +257,30 @@ select
 from rzset
 join rkey on rzset.kid = rkey.id and rkey.type = 5
 where rkey.etime is null or rkey.etime > (extract(epoch from now()) * 1000);
+
+-- ┌───────────────┐
+-- │ Change log    │
+-- └───────────────┘
+-- Filled only if the change log is enabled.
+-- cmd is a RESP-encoded command that reproduces the change.
+create table if not exists
+rchange (
+    seq   bigserial primary key,
+    time  bigint not null,
+    key   text not null,
+    type  integer not null,
+    op    text not null,
+    cmd   bytea not null
+);
+
+create index if not exists
+rchange_time_idx on rchange (time);
+
+-- ┌───────────────┐
+-- │ Schema        │
+-- └───────────────┘
+-- A single row with the schema version.
+create table if not exists
+rversion (
+    version integer not null
+);
@@ -0,0 +1,10 @@
+-- Moves the string values from rstring to rkey.
+alter table rkey add column value blob;
+
+update rkey set value = (
+    select value from rstring where kid = rkey.id
+)
+where type = 1;
+
+drop view if exists vstring;
+drop table rstring;
@@ -0,0 +1,26 @@
+-- Counts the keys in rkey_count, so that
+-- counting the keys does not scan the key table.
+create table if not exists
+rkey_count (
+    n integer not null
+) strict;
+
+insert into rkey_count (n)
+select count(*) from rkey
+where not exists (select 1 from rkey_count);
+
+create trigger if not exists
+rkey_on_insert
+after insert on rkey
+for each row
+begin
+    update rkey_count set n = n + 1;
+end;
+
+create trigger if not exists
+rkey_on_delete
+after delete on rkey
+for each row
+begin
+    update rkey_count set n = n - 1;
+end;
@@ -0,0 +1,6 @@
+-- Adds the key access time, so that the eviction
+-- can delete the least recently used keys.
+alter table rkey add column atime integer;
+
+create index if not exists
+rkey_atime_idx on rkey (coalesce(atime, mtime));
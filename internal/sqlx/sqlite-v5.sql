@@ -0,0 +1,2 @@
+-- Adds the key access counter (see OBJECT FREQ).
+alter table rkey add column freq integer;
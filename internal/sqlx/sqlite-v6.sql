@@ -0,0 +1,6 @@
+-- Drops the key counter, so that the writes to rkey
+-- do not run the per-row triggers. Counting the keys
+-- counts the rows in rkey instead.
+drop trigger if exists rkey_on_insert;
+drop trigger if exists rkey_on_delete;
+drop table if exists rkey_count;
@@ -5,11 +5,17 @@ import (
 	_ "embed"
 	"net/url"
 	"strings"
+	"sync/atomic"
 )
 
 //go:embed sqlite.sql
 var sqliteSchema string
 
+// sqlSqliteSize selects the database size without the free pages.
+const sqlSqliteSize = `
+select (page_count - freelist_count) * page_size
+from pragma_page_count(), pragma_freelist_count(), pragma_page_size()`
+
 // sqlitePragma is a set of default SQLite settings.
 var sqlitePragma = map[string]string{
 	"journal_mode": "wal",
@@ -29,14 +35,22 @@ func openSqlite(rw *sql.DB, ro *sql.DB, opts *Options) (*sqlite, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = d.createSchema()
+	err = d.createSchema(opts)
 	return d, err
 }
 
 // newSqlite creates a new SQLite database handle.
 // Like openSqlite, but does not create the database schema.
 func newSqlite(rw *sql.DB, ro *sql.DB, opts *Options) (*sqlite, error) {
-	d := &sqlite{Dialect: DialectSqlite, RW: rw, RO: ro, Timeout: opts.Timeout}
+	d := &sqlite{Dialect: DialectSqlite, RW: rw, RO: ro, timeout: new(atomic.Int64), listeners: new(listeners), journal: new(atomic.Pointer[Journal]), expired: &keySet{max: maxExpiredKeys}, accessed: newAccessedKeys()}
+	(*DB)(d).SetTimeout(opts.Timeout)
+	if opts.GroupCommit {
+		d.group = new(groupCommit)
+	}
+	if !opts.NoPrepare {
+		d.stmts = newStmtCache(opts.Logger)
+	}
+	d.names = newNames(opts.TablePrefix)
 	d.setNumConns(opts.ReadOnly)
 	err := d.applySettings(opts.Pragma)
 	return d, err
@@ -108,12 +122,23 @@ func (d *sqlite) applySettings(pragma map[string]string) error {
 	return nil
 }
 
-// createSchema creates the database schema.
-func (d *sqlite) createSchema() error {
-	_, err := d.RW.Exec(sqliteSchema)
+// createSchema creates the database schema, or migrates
+// the existing one to the latest version if necessary.
+func (d *sqlite) createSchema(opts *Options) error {
+	_, err := Migrate(d.RW, opts)
 	return err
 }
 
+// IsMemory reports whether the SQLite data source
+// refers to an in-memory database.
+func IsMemory(path string) bool {
+	source, query, _ := strings.Cut(path, "?")
+	params, _ := url.ParseQuery(query)
+	return source == ":memory:" ||
+		params.Get("mode") == "memory" ||
+		params.Get("vfs") == "memdb"
+}
+
 // sqliteDataSource returns an SQLite connection string
 // for a read-only or read-write mode.
 func sqliteDataSource(path string, readOnly bool, pragma map[string]string) string {
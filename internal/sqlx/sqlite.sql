@@ -1,5 +1,3 @@
-pragma user_version = 1;
-
 -- ┌───────────────┐
 -- │ Keys          │
 -- └───────────────┘
@@ -9,6 +7,11 @@ pragma user_version = 1;
 -- 3 - set
 -- 4 - hash
 -- 5 - zset (sorted set)
+-- String values are stored inline (the value column),
+-- so setting or getting a string touches a single table.
+-- The access time (atime) and the access counter (freq)
+-- are only updated if the access tracking is enabled,
+-- and are null until the first update.
 create table if not exists
 rkey (
     id       integer primary key,
@@ -17,7 +20,10 @@ rkey (
     version  integer not null,
     etime    integer,
     mtime    integer not null,
-    len      integer
+    len      integer,
+    value    blob,
+    atime    integer,
+    freq     integer
 ) strict;
 
 create unique index if not exists
@@ -27,6 +33,9 @@ create index if not exists
 rkey_etime_idx on rkey (etime)
 where etime is not null;
 
+create index if not exists
+rkey_atime_idx on rkey (coalesce(atime, mtime));
+
 create view if not exists
 vkey as
 select
@@ -39,26 +48,14 @@ where rkey.etime is null or rkey.etime > unixepoch('subsec');
 -- ┌───────────────┐
 -- │ Strings       │
 -- └───────────────┘
-create table if not exists
-rstring (
-    kid    integer not null,
-    value  blob not null,
-
-    foreign key (kid) references rkey (id)
-    on delete cascade
-) strict;
-
-create unique index if not exists
-rstring_pk_idx on rstring (kid);
-
 create view if not exists
 vstring as
 select
-    rkey.id as kid, rkey.key, rstring.value,
+    id as kid, key, value,
     datetime(etime/1000, 'unixepoch') as etime,
     datetime(mtime/1000, 'unixepoch') as mtime
-from rstring join rkey on rstring.kid = rkey.id and rkey.type = 1
-where rkey.etime is null or rkey.etime > unixepoch('subsec');
+from rkey
+where type = 1 and (etime is null or etime > unixepoch('subsec'));
 
 -- ┌───────────────┐
 -- │ Lists         │
@@ -224,3 +221,21 @@ select
     datetime(mtime/1000, 'unixepoch') as mtime
 from rzset join rkey on rzset.kid = rkey.id and rkey.type = 5
 where rkey.etime is null or rkey.etime > unixepoch('subsec');
+
+-- ┌───────────────┐
+-- │ Change log    │
+-- └───────────────┘
+-- Filled only if the change log is enabled.
+-- cmd is a RESP-encoded command that reproduces the change.
+create table if not exists
+rchange (
+    seq   integer primary key autoincrement,
+    time  integer not null,
+    key   text not null,
+    type  integer not null,
+    op    text not null,
+    cmd   blob not null
+) strict;
+
+create index if not exists
+rchange_time_idx on rchange (time);
@@ -0,0 +1,221 @@
+package sqlx
+
+import (
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+// Schema version 1 (before inlining the string values).
+//
+//go:embed testdata/sqlite-v1.sql
+var sqliteSchemaV1 string
+
+func TestMigrateV2(t *testing.T) {
+	rw, ro := openMemDB(t)
+	_, err := rw.Exec(sqliteSchemaV1)
+	be.Err(t, err, nil)
+	_, err = rw.Exec(`
+	insert into rkey (id, key, type, version, mtime, len)
+	values (1, 'name', 1, 1, 0, null), (2, 'person', 4, 1, 0, 0);
+	insert into rstring (kid, value) values (1, cast('alice' as blob));
+	insert into rhash (kid, field, value) values (2, 'age', cast('25' as blob));`)
+	be.Err(t, err, nil)
+
+	opts := &Options{Dialect: DialectSqlite, Timeout: time.Second, Pragma: map[string]string{}}
+	_, err = openSqlite(rw, ro, opts)
+	be.Err(t, err, nil)
+
+	var version int
+	_ = rw.QueryRow("pragma user_version").Scan(&version)
+	be.Equal(t, version, 6)
+
+	// The string values are moved to the key table.
+	var name string
+	err = rw.QueryRow("select value from rkey where key = 'name'").Scan(&name)
+	be.Err(t, err, nil)
+	be.Equal(t, name, "alice")
+	err = rw.QueryRow("select value from vstring where key = 'name'").Scan(&name)
+	be.Err(t, err, nil)
+	be.Equal(t, name, "alice")
+	var n int
+	_ = rw.QueryRow("select count(*) from sqlite_schema where name = 'rstring'").Scan(&n)
+	be.Equal(t, n, 0)
+
+	// The key counter is dropped.
+	_ = rw.QueryRow("select count(*) from sqlite_schema where name like 'rkey_%' and type in ('table', 'trigger')").Scan(&n)
+	be.Equal(t, n, 0)
+
+	// The keys have the access time and counter.
+	_ = rw.QueryRow("select count(*) from rkey where atime is null and freq is null").Scan(&n)
+	be.Equal(t, n, 2)
+
+	// Other types are not affected.
+	var age string
+	err = rw.QueryRow("select value from vhash where key = 'person'").Scan(&age)
+	be.Err(t, err, nil)
+	be.Equal(t, age, "25")
+
+	// Opening the migrated database again does nothing.
+	_, err = openSqlite(rw, ro, opts)
+	be.Err(t, err, nil)
+	err = rw.QueryRow("select value from rkey where key = 'name'").Scan(&name)
+	be.Err(t, err, nil)
+	be.Equal(t, name, "alice")
+}
+
+// String queries for the schema version 1.
+const (
+	benchSetV1 = `
+	insert into
+	rkey   (key, type, version, etime, mtime)
+	values ( $1,    1,       1,    $2,    $3)
+	on conflict (key) do update set
+		type = case when rkey.type = excluded.type then rkey.type else null end,
+		version = rkey.version + 1,
+		etime = excluded.etime,
+		mtime = excluded.mtime`
+	benchSetValueV1 = `
+	insert into rstring (kid, value)
+	values ((select id from rkey where key = $1), $2)
+	on conflict (kid) do update
+	set value = excluded.value`
+	benchGetV1 = `
+	select value
+	from rstring join rkey on kid = rkey.id and type = 1
+	where key = $1 and (etime is null or etime > $2)`
+)
+
+// String queries for the schema version 2.
+const (
+	benchSetV2 = `
+	insert into
+	rkey   (key, type, version, etime, mtime, value)
+	values ( $1,    1,       1,    $2,    $3,    $4)
+	on conflict (key) do update set
+		type = case when rkey.type = excluded.type then rkey.type else null end,
+		version = rkey.version + 1,
+		etime = excluded.etime,
+		mtime = excluded.mtime,
+		value = excluded.value`
+	benchGetV2 = `
+	select value from rkey
+	where key = $1 and type = 1 and (etime is null or etime > $2)`
+)
+
+// Compares the string layouts of the schema versions.
+func BenchmarkSchema(b *testing.B) {
+	const nKeys = 10000
+	value := []byte("the quick brown fox jumps over the lazy dog")
+
+	b.Run("v1/set", func(b *testing.B) {
+		db := openSchemaDB(b, sqliteSchemaV1)
+		b.ResetTimer()
+		for i := range b.N {
+			key := fmt.Sprintf("key:%d", i%nKeys)
+			now := time.Now().UnixMilli()
+			err := runTx(db, func(tx *sql.Tx) error {
+				if _, err := tx.Exec(benchSetV1, key, nil, now); err != nil {
+					return err
+				}
+				_, err := tx.Exec(benchSetValueV1, key, value)
+				return err
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("v2/set", func(b *testing.B) {
+		db := openSchemaDB(b, sqliteSchema)
+		b.ResetTimer()
+		for i := range b.N {
+			key := fmt.Sprintf("key:%d", i%nKeys)
+			now := time.Now().UnixMilli()
+			err := runTx(db, func(tx *sql.Tx) error {
+				_, err := tx.Exec(benchSetV2, key, nil, now, value)
+				return err
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("v1/get", func(b *testing.B) {
+		db := openSchemaDB(b, sqliteSchemaV1)
+		for i := range nKeys {
+			key := fmt.Sprintf("key:%d", i)
+			_, _ = db.Exec(benchSetV1, key, nil, 0)
+			_, _ = db.Exec(benchSetValueV1, key, value)
+		}
+		b.ResetTimer()
+		for i := range b.N {
+			key := fmt.Sprintf("key:%d", i%nKeys)
+			var val []byte
+			err := db.QueryRow(benchGetV1, key, time.Now().UnixMilli()).Scan(&val)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("v2/get", func(b *testing.B) {
+		db := openSchemaDB(b, sqliteSchema)
+		for i := range nKeys {
+			key := fmt.Sprintf("key:%d", i)
+			_, _ = db.Exec(benchSetV2, key, nil, 0, value)
+		}
+		b.ResetTimer()
+		for i := range b.N {
+			key := fmt.Sprintf("key:%d", i%nKeys)
+			var val []byte
+			err := db.QueryRow(benchGetV2, key, time.Now().UnixMilli()).Scan(&val)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// openMemDB opens read-write and read-only handles
+// to an empty in-memory database.
+func openMemDB(tb testing.TB) (*sql.DB, *sql.DB) {
+	tb.Helper()
+	path := fmt.Sprintf("file:/%s.db?vfs=memdb", tb.Name())
+	opts := &Options{Dialect: DialectSqlite}
+	rw, err := sql.Open("sqlite3", DataSource(path, false, opts))
+	be.Err(tb, err, nil)
+	ro, err := sql.Open("sqlite3", DataSource(path, true, opts))
+	be.Err(tb, err, nil)
+	tb.Cleanup(func() {
+		_ = rw.Close()
+		_ = ro.Close()
+	})
+	return rw, ro
+}
+
+// openSchemaDB opens an in-memory database with the given schema.
+func openSchemaDB(tb testing.TB, schema string) *sql.DB {
+	tb.Helper()
+	rw, _ := openMemDB(tb)
+	rw.SetMaxOpenConns(1)
+	_, err := rw.Exec(schema)
+	be.Err(tb, err, nil)
+	return rw
+}
+
+// runTx executes the function in a transaction.
+func runTx(db *sql.DB, f func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := f(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
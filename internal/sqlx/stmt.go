@@ -0,0 +1,197 @@
+package sqlx
+
+import (
+	"database/sql"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// templateParams are the parameters that [ExpandIn] replaces
+// in the repository queries. A query with any of them is a template
+// for a dynamic query, so there is no point in preparing it.
+var templateParams = []string{":keys", ":fields", ":elems"}
+
+// stmtCache is a cache of prepared statements
+// for the static repository queries (see [DB.Prepare]).
+// Keeps a separate set of statements for each connection pool.
+//
+// stmtCache is safe for concurrent use by multiple goroutines.
+type stmtCache struct {
+	mu    sync.RWMutex
+	sets  map[any]struct{}                 // registered query sets
+	stmts map[*sql.DB]map[string]*sql.Stmt // statements by pool and query
+	log   *slog.Logger
+}
+
+// newStmtCache creates an empty statement cache.
+// Logs the queries that fail to prepare to log.
+func newStmtCache(log *slog.Logger) *stmtCache {
+	if log == nil {
+		log = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return &stmtCache{
+		sets:  map[any]struct{}{},
+		stmts: map[*sql.DB]map[string]*sql.Stmt{},
+		log:   log,
+	}
+}
+
+// prepare prepares the queries from the set (with the schema object
+// names renamed by n) on the given pools, unless the set has already
+// been registered.
+func (c *stmtCache) prepare(set any, n *names, pools ...*sql.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.sets[set]; ok {
+		return
+	}
+	c.sets[set] = struct{}{}
+
+	queries := staticQueries(set)
+	for i, query := range queries {
+		queries[i] = n.rename(query)
+	}
+	for _, pool := range pools {
+		stmts := c.stmts[pool]
+		if stmts == nil {
+			stmts = map[string]*sql.Stmt{}
+			c.stmts[pool] = stmts
+		}
+		for _, query := range queries {
+			if _, ok := stmts[query]; ok {
+				continue
+			}
+			// A query that fails to prepare still runs as plain
+			// text, which reports the error to the caller.
+			stmt, err := pool.Prepare(query)
+			if err != nil {
+				c.log.Warn("prepare statement", "query", query, "err", err)
+				continue
+			}
+			stmts[query] = stmt
+		}
+	}
+}
+
+// get returns the prepared statement for the query
+// on the given pool, or nil if there is none.
+func (c *stmtCache) get(pool *sql.DB, query string) *sql.Stmt {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stmts[pool][query]
+}
+
+// close closes the prepared statements on all pools.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var allErr error
+	for _, stmts := range c.stmts {
+		for _, stmt := range stmts {
+			if err := stmt.Close(); allErr == nil {
+				allErr = err
+			}
+		}
+	}
+	c.stmts = map[*sql.DB]map[string]*sql.Stmt{}
+	c.sets = map[any]struct{}{}
+	return allErr
+}
+
+// staticQueries returns the queries from the string
+// fields of the struct pointed to by set.
+// Skips the queries with several statements, since
+// the prepared statements only execute the first one,
+// and the templates for dynamic queries (see [templateParams]).
+func staticQueries(set any) []string {
+	val := reflect.Indirect(reflect.ValueOf(set))
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+	var queries []string
+	for i := range val.NumField() {
+		field := val.Field(i)
+		if field.Kind() != reflect.String {
+			continue
+		}
+		query := strings.TrimSpace(field.String())
+		if query == "" || strings.Contains(query, ";") || isTemplate(query) {
+			continue
+		}
+		queries = append(queries, field.String())
+	}
+	return queries
+}
+
+// isTemplate reports whether the query is
+// a template for a dynamic query.
+func isTemplate(query string) bool {
+	for _, param := range templateParams {
+		if strings.Contains(query, param) {
+			return true
+		}
+	}
+	return false
+}
+
+// stmtTx is a Tx that executes the queries using
+// the prepared statements from the cache, if any.
+// Other queries are sent to the database as plain text.
+type stmtTx struct {
+	tx      Tx                   // *sql.DB or *sql.Tx
+	sqlTx   *sql.Tx              // nil outside of transactions
+	pool    *sql.DB              // connection pool of tx
+	cache   *stmtCache           // prepared statements
+	txStmts map[string]*sql.Stmt // statements bound to sqlTx
+}
+
+// newStmtTx creates a Tx that uses the prepared statements
+// from the cache. pool is the connection pool tx belongs to.
+func newStmtTx(tx Tx, pool *sql.DB, cache *stmtCache) *stmtTx {
+	sqlTx, _ := tx.(*sql.Tx)
+	return &stmtTx{tx: tx, sqlTx: sqlTx, pool: pool, cache: cache}
+}
+
+func (t *stmtTx) Query(query string, args ...any) (*sql.Rows, error) {
+	if stmt := t.stmt(query); stmt != nil {
+		return stmt.Query(args...)
+	}
+	return t.tx.Query(query, args...)
+}
+
+func (t *stmtTx) QueryRow(query string, args ...any) *sql.Row {
+	if stmt := t.stmt(query); stmt != nil {
+		return stmt.QueryRow(args...)
+	}
+	return t.tx.QueryRow(query, args...)
+}
+
+func (t *stmtTx) Exec(query string, args ...any) (sql.Result, error) {
+	if stmt := t.stmt(query); stmt != nil {
+		return stmt.Exec(args...)
+	}
+	return t.tx.Exec(query, args...)
+}
+
+// stmt returns the prepared statement for the query,
+// or nil if the query is not in the cache. In a transaction,
+// returns the statement bound to the transaction.
+func (t *stmtTx) stmt(query string) *sql.Stmt {
+	stmt := t.cache.get(t.pool, query)
+	if stmt == nil || t.sqlTx == nil {
+		return stmt
+	}
+	if txStmt, ok := t.txStmts[query]; ok {
+		return txStmt
+	}
+	// The transaction closes its statements when it ends.
+	txStmt := t.sqlTx.Stmt(stmt)
+	if t.txStmts == nil {
+		t.txStmts = map[string]*sql.Stmt{}
+	}
+	t.txStmts[query] = txStmt
+	return txStmt
+}
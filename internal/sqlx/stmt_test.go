@@ -0,0 +1,82 @@
+package sqlx
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+)
+
+func TestStaticQueries(t *testing.T) {
+	set := &struct {
+		get    string
+		empty  string
+		multi  string
+		limit  int
+		insert string
+		many   string
+	}{
+		get:    "select id from t where id = $1",
+		multi:  "delete from t; vacuum;",
+		limit:  10,
+		insert: "insert into t (id) values ($1)",
+		many:   "select id from t where id in (:keys)",
+	}
+	queries := staticQueries(set)
+	be.Equal(t, queries, []string{set.get, set.insert})
+}
+
+func TestStmtCache(t *testing.T) {
+	db, act := openGroupDB(t)
+	db.group = nil
+	set := &struct{ insert, count string }{
+		insert: "insert into t (id) values ($1)",
+		count:  "select count(*) from t",
+	}
+	nSets := len(db.stmts.sets)
+	db.Prepare(set)
+	db.Prepare(set)
+	be.Equal(t, len(db.stmts.sets), nSets+1)
+	be.True(t, db.stmts.get(db.RW, set.insert) != nil)
+	be.True(t, db.stmts.get(db.RO, set.count) != nil)
+
+	err := act.Update(func(tx Tx) error {
+		for id := range 3 {
+			if _, err := tx.Exec(set.insert, id); err != nil {
+				return err
+			}
+		}
+		// The transaction binds the statement only once.
+		stx := tx.(*eventTx).tx.(*stmtTx)
+		be.Equal(t, len(stx.txStmts), 1)
+		return nil
+	})
+	be.Err(t, err, nil)
+
+	var n int
+	err = db.Reader().QueryRow(set.count).Scan(&n)
+	be.Err(t, err, nil)
+	be.Equal(t, n, 3)
+}
+
+func TestStmtCachePrepare(t *testing.T) {
+	rw, ro := openMemDB(t)
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	cache := newStmtCache(log)
+	set := &struct{ count, missing string }{
+		count:   "select count(*) from sqlite_schema",
+		missing: "select count(*) from missing",
+	}
+	cache.prepare(set, newNames(""), rw, ro)
+	be.True(t, cache.get(rw, set.count) != nil)
+	be.True(t, cache.get(ro, set.count) != nil)
+	be.True(t, cache.get(rw, set.missing) == nil)
+	be.Equal(t, strings.Count(buf.String(), "prepare statement"), 2)
+
+	err := cache.close()
+	be.Err(t, err, nil)
+	be.True(t, cache.get(rw, set.count) == nil)
+}
@@ -0,0 +1,69 @@
+package sqlx
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// Trace records the slowest statement executed through
+// a traced database handle. See [DB.WithTrace].
+//
+// Trace is safe for concurrent use by multiple goroutines.
+type Trace struct {
+	mu    sync.Mutex
+	query string
+	dur   time.Duration
+}
+
+// Slowest returns the slowest statement recorded
+// since the last reset, and its duration.
+func (t *Trace) Slowest() (string, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.query, t.dur
+}
+
+// Reset clears the recorded statement.
+func (t *Trace) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.query, t.dur = "", 0
+}
+
+// observe records the statement if it's the slowest so far.
+func (t *Trace) observe(query string, dur time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if dur > t.dur || t.query == "" {
+		t.query, t.dur = query, dur
+	}
+}
+
+// tracedTx is a Tx that records the statements in a trace.
+// The durations do not include reading the result rows.
+type tracedTx struct {
+	tx    Tx
+	trace *Trace
+}
+
+func (t tracedTx) Query(query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := t.tx.Query(query, args...)
+	t.trace.observe(query, time.Since(start))
+	return rows, err
+}
+
+func (t tracedTx) QueryRow(query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := t.tx.QueryRow(query, args...)
+	t.trace.observe(query, time.Since(start))
+	return row
+}
+
+func (t tracedTx) Exec(query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	res, err := t.tx.Exec(query, args...)
+	t.trace.observe(query, time.Since(start))
+	return res, err
+}
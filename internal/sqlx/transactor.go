@@ -2,7 +2,7 @@ package sqlx
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 )
 
 // Transactor is a domain transaction manager.
@@ -19,19 +19,26 @@ func NewTransactor[T any](db *DB, newTx func(Dialect, Tx) T) *Transactor[T] {
 
 // Update executes a function within a writable transaction.
 func (t *Transactor[T]) Update(f func(tx T) error) error {
-	ctx, cancel := context.WithTimeout(context.Background(), t.db.Timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), t.db.Timeout())
 	defer cancel()
 	return t.UpdateContext(ctx, f)
 }
 
 // UpdateContext executes a function within a writable transaction.
+// If the group commit is enabled (see [Options.GroupCommit]),
+// the transaction may be merged with the concurrent ones.
 func (t *Transactor[T]) UpdateContext(ctx context.Context, f func(tx T) error) error {
+	if t.db.group != nil {
+		return t.db.group.update(ctx, t.db, func(tx Tx) error {
+			return f(t.newTx(t.db.Dialect, tx))
+		})
+	}
 	return t.execTx(ctx, true, f)
 }
 
 // View executes a function within a read-only transaction.
 func (t *Transactor[T]) View(f func(tx T) error) error {
-	ctx, cancel := context.WithTimeout(context.Background(), t.db.Timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), t.db.Timeout())
 	defer cancel()
 	return t.ViewContext(ctx, f)
 }
@@ -43,14 +50,11 @@ func (t *Transactor[T]) ViewContext(ctx context.Context, f func(tx T) error) err
 
 // execTx executes a function within a transaction.
 func (t *Transactor[T]) execTx(ctx context.Context, writable bool, f func(tx T) error) error {
-	var sqlTx *sql.Tx
-	var err error
+	pool := t.db.RO
 	if writable {
-		sqlTx, err = t.db.RW.BeginTx(ctx, nil)
-	} else {
-		sqlTx, err = t.db.RO.BeginTx(ctx, nil)
+		pool = t.db.RW
 	}
-
+	sqlTx, err := pool.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -58,10 +62,56 @@ func (t *Transactor[T]) execTx(ctx context.Context, writable bool, f func(tx T)
 
 	// Create a domain transaction from the database transaction,
 	// then execute the function with it.
-	tx := t.newTx(t.db.Dialect, sqlTx)
+	etx := &eventTx{tx: t.db.wrap(sqlTx, pool), listeners: t.db.listeners, journal: t.db.journal, expired: t.db.expired, accessed: t.db.accessed}
+	tx := t.newTx(t.db.Dialect, etx)
 	err = f(tx)
 	if err != nil {
 		return err
 	}
-	return sqlTx.Commit()
+
+	// Record the change events in the same transaction,
+	// so that the journal is always consistent with the data.
+	err = etx.flush()
+	if err != nil {
+		return err
+	}
+	err = sqlTx.Commit()
+	if err != nil {
+		return err
+	}
+
+	// Deliver the change events only after a successful commit.
+	etx.commit()
+	return nil
+}
+
+// Savepoint executes a function within a savepoint of the transaction.
+// If the function returns an error, rolls back the changes it made
+// (along with their change events) and returns the error.
+// The rest of the transaction is not affected.
+func Savepoint(tx Tx, f func() error) error {
+	if _, err := tx.Exec("savepoint redka"); err != nil {
+		return err
+	}
+	etx, _ := tx.(*eventTx)
+	var nEvents int
+	if etx != nil {
+		nEvents = len(etx.events)
+	}
+
+	err := f()
+	if err != nil {
+		if _, rerr := tx.Exec("rollback to savepoint redka"); rerr != nil {
+			return errors.Join(err, rerr)
+		}
+		if etx != nil {
+			etx.events = etx.events[:nEvents]
+		}
+	}
+	// Rolling back to a savepoint does not remove it,
+	// so release it in any case.
+	if _, rerr := tx.Exec("release savepoint redka"); rerr != nil {
+		return errors.Join(err, rerr)
+	}
+	return err
 }
@@ -1,6 +1,7 @@
 package testx
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/nalgeon/redka"
@@ -15,20 +16,69 @@ var connStrings = map[string]string{
 	"sqlite3":  "file:/redka.db?vfs=memdb",
 }
 
+// replicaConnStrings are connection strings for secondary
+// test databases (e.g. replicas of the main one).
+var replicaConnStrings = map[string]string{
+	"sqlite3": "file:/redka-replica.db?vfs=memdb",
+}
+
 // OpenDB returns a database handle for testing.
 // Uses the driver specified in the build tag.
 func OpenDB(tb testing.TB) *redka.DB {
 	tb.Helper()
+	return OpenDBWith(tb, nil)
+}
+
+// OpenDBWith returns a database handle for testing
+// with the given options (the driver name is ignored).
+// Uses the driver specified in the build tag.
+func OpenDBWith(tb testing.TB, opts *redka.Options) *redka.DB {
+	tb.Helper()
 
 	// Get the database connection string.
 	connStr := connStrings[driver]
 	if connStr == "" {
 		tb.Fatalf("unknown driver: %s", driver)
 	}
+	return openDB(tb, connStr, opts)
+}
+
+// OpenReplicaDB returns a secondary database handle for testing,
+// separate from the one returned by OpenDB. Skips the test
+// if the driver does not support secondary databases.
+func OpenReplicaDB(tb testing.TB) *redka.DB {
+	tb.Helper()
+	connStr := replicaConnStrings[driver]
+	if connStr == "" {
+		tb.Skipf("secondary database is not supported: %s", driver)
+	}
+	return openDB(tb, connStr, nil)
+}
+
+// OpenFileDBWith returns a database handle for testing
+// with the given options. Unlike OpenDBWith, uses a temporary
+// database file with SQLite, so that the readers do not block
+// the writers. Uses the driver specified in the build tag.
+func OpenFileDBWith(tb testing.TB, opts *redka.Options) *redka.DB {
+	tb.Helper()
+	if driver != "sqlite3" {
+		return OpenDBWith(tb, opts)
+	}
+	connStr := "file:" + filepath.Join(tb.TempDir(), "redka.db")
+	return openDB(tb, connStr, opts)
+}
+
+// openDB opens and clears the test database.
+func openDB(tb testing.TB, connStr string, opts *redka.Options) *redka.DB {
+	tb.Helper()
 
 	// Open the database.
-	opts := &redka.Options{DriverName: driver}
-	db, err := redka.Open(connStr, opts)
+	var dbOpts redka.Options
+	if opts != nil {
+		dbOpts = *opts
+	}
+	dbOpts.DriverName = driver
+	db, err := redka.Open(connStr, &dbOpts)
 	if err != nil {
 		tb.Fatal(err)
 	}
@@ -44,3 +94,8 @@ func OpenDB(tb testing.TB) *redka.DB {
 
 	return db
 }
+
+// Driver returns the name of the test database driver.
+func Driver() string {
+	return driver
+}
@@ -0,0 +1,48 @@
+package redka
+
+import (
+	"database/sql"
+
+	"github.com/nalgeon/redka/internal/sqlx"
+)
+
+// ErrSchemaVersion is returned when the database schema version
+// is not supported: either the schema is newer than this version
+// of Redka, or it's older and the database is opened in
+// read-only mode (which does not migrate the schema).
+var ErrSchemaVersion = sqlx.ErrSchemaVersion
+
+// Migration is a database schema migration step.
+type Migration = sqlx.Migration
+
+// Migrate upgrades the schema of the database at the given path
+// to the latest version and returns the applied migration steps.
+// If dryRun is true, returns the pending steps without applying
+// them. If the database has no schema, the only step creates it.
+//
+// Each step runs in a separate transaction. If a step fails,
+// the database stays at the version before the step. Each step
+// locks the database first, so concurrent migrations (e.g. from
+// several processes) apply each step only once.
+//
+// [Open] and [OpenDB] migrate the schema automatically,
+// so use Migrate to upgrade the database in advance
+// or to review the pending steps.
+//
+// The opts parameter is optional. If nil, uses default options.
+func Migrate(path string, opts *Options, dryRun bool) ([]Migration, error) {
+	opts = applyOptions(defaultOptions, opts)
+	sopts := newSQLOptions(opts)
+	// Dry run does not change the database,
+	// so a read-only connection is enough.
+	dataSource := sqlx.DataSource(path, dryRun, sopts)
+	db, err := sql.Open(opts.DriverName, dataSource)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+	if dryRun {
+		return sqlx.PendingMigrations(db, sopts)
+	}
+	return sqlx.Migrate(db, sopts)
+}
@@ -0,0 +1,36 @@
+package redka_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+)
+
+func TestMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "redka.db")
+	opts := &redka.Options{DriverName: "sqlite3"}
+
+	// The new database needs the schema.
+	steps, err := redka.Migrate(path, opts, false)
+	be.Err(t, err, nil)
+	be.Equal(t, len(steps), 1)
+	be.Equal(t, steps[0].Name, "create schema")
+
+	db, err := redka.Open(path, opts)
+	be.Err(t, err, nil)
+	_ = db.Str().Set("name", "alice")
+	_ = db.Close()
+
+	// The existing database is up to date.
+	steps, err = redka.Migrate(path, opts, true)
+	be.Err(t, err, nil)
+	be.Equal(t, len(steps), 0)
+
+	db, err = redka.OpenRead(path, opts)
+	be.Err(t, err, nil)
+	defer func() { _ = db.Close() }()
+	name, _ := db.Str().Get("name")
+	be.Equal(t, name.String(), "alice")
+}
@@ -0,0 +1,107 @@
+package redka
+
+import (
+	"io"
+	"slices"
+
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/ndjson"
+)
+
+// ExportOptions selects the keys to export with [DB.ExportJSON].
+type ExportOptions struct {
+	// Glob-style pattern to filter keys by name.
+	// If empty, exports keys with any name.
+	Pattern string
+	// Key types to export. If empty, exports keys of any type.
+	Types []TypeID
+}
+
+// ExportJSON writes the keys to w as newline-delimited JSON,
+// one object per key with the key name, type, remaining time
+// to live (in milliseconds) and value:
+//
+//	{"key":"name","type":"string","value":"alice"}
+//	{"key":"session","type":"string","ttl":60000,"value":"token"}
+//	{"key":"hash","type":"hash","value":{"age":"25","name":"alice"}}
+//	{"key":"zset","type":"zset","value":[{"elem":"a","score":1}]}
+//
+// If the key name or value is not valid UTF-8, the object has
+// "encoding":"base64" and all its strings are base64-encoded.
+//
+// Exports the keys matching opts (all keys if opts is nil).
+// Writes the keys one by one as it reads them, so the export
+// does not load the whole database into memory. The export is
+// taken in a single read transaction, so it is consistent and
+// does not block the writers. Returns the number of exported keys.
+func (db *DB) ExportJSON(w io.Writer, opts *ExportOptions) (int, error) {
+	pattern, ktype := "*", core.TypeAny
+	var types []TypeID
+	if opts != nil {
+		if opts.Pattern != "" {
+			pattern = opts.Pattern
+		}
+		if len(opts.Types) == 1 {
+			// Filter by a single type in the database.
+			ktype = opts.Types[0]
+		} else {
+			types = opts.Types
+		}
+	}
+
+	count := 0
+	err := db.View(func(tx *Tx) error {
+		wr := ndjson.NewWriter(w)
+		scanner := tx.Key().Scanner(pattern, ktype, 0)
+		for scanner.Scan() {
+			key := scanner.Key()
+			if len(types) > 0 && !slices.Contains(types, key.Type) {
+				continue
+			}
+			entry, err := exportEntry(tx, key)
+			if err == core.ErrNotFound {
+				// The key has no value.
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := wr.Write(entry); err != nil {
+				return err
+			}
+			count++
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return wr.Flush()
+	})
+	return count, err
+}
+
+// ImportJSON loads the keys written by [DB.ExportJSON] from r.
+// Replaces the existing keys with the same names and skips
+// the keys that have already expired.
+//
+// Reads the keys one by one and writes them in batches, each batch
+// in a single transaction, so the import does not load the whole
+// file into memory. If the import fails, the batches written before
+// the failure stay in the database. Returns the number of imported keys.
+func (db *DB) ImportJSON(r io.Reader) (int, error) {
+	rd := ndjson.NewReader(r)
+	im := importer{db: db}
+	for {
+		entry, err := rd.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return im.count, err
+		}
+		if err := im.add(entry); err != nil {
+			return im.count, err
+		}
+	}
+	err := im.flush()
+	return im.count, err
+}
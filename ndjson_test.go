@@ -0,0 +1,89 @@
+package redka_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestExportImportJSON(t *testing.T) {
+	db := testx.OpenDB(t)
+	at := time.Now().Add(time.Hour)
+	_, _ = db.Str().SetWith("name", "alice").At(at).Run()
+	_ = db.Str().Set("bin", []byte{0xff, 0x00})
+	_, _ = db.List().PushBack("list", "a")
+	_, _ = db.List().PushBack("list", "b")
+	_, _ = db.Set().Add("set", "b", "a")
+	_, _ = db.Hash().Set("user:1", "name", "alice")
+	_, _ = db.ZSet().Add("user:2", "a", math.Inf(1))
+
+	t.Run("all", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := db.ExportJSON(&buf, nil)
+		be.Err(t, err, nil)
+		be.Equal(t, n, 6)
+
+		other := testx.OpenReplicaDB(t)
+		n, err = other.ImportJSON(&buf)
+		be.Err(t, err, nil)
+		be.Equal(t, n, 6)
+
+		name, _ := other.Str().Get("name")
+		be.Equal(t, name.String(), "alice")
+		key, _ := other.Key().Get("name")
+		be.True(t, key.ETime != nil)
+		be.True(t, *key.ETime > time.Now().UnixMilli())
+		be.True(t, *key.ETime <= at.UnixMilli()+1000)
+		bin, _ := other.Str().Get("bin")
+		be.Equal(t, bin.Bytes(), []byte{0xff, 0x00})
+		list, _ := other.List().Range("list", 0, -1)
+		be.Equal(t, len(list), 2)
+		be.Equal(t, list[0].String(), "a")
+		be.Equal(t, list[1].String(), "b")
+		set, _ := other.Set().Len("set")
+		be.Equal(t, set, 2)
+		hname, _ := other.Hash().Get("user:1", "name")
+		be.Equal(t, hname.String(), "alice")
+		score, _ := other.ZSet().GetScore("user:2", "a")
+		be.Equal(t, score, math.Inf(1))
+	})
+	t.Run("pattern", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := db.ExportJSON(&buf, &redka.ExportOptions{Pattern: "user:*"})
+		be.Err(t, err, nil)
+		be.Equal(t, n, 2)
+	})
+	t.Run("types", func(t *testing.T) {
+		var buf bytes.Buffer
+		opts := &redka.ExportOptions{Types: []redka.TypeID{redka.TypeList, redka.TypeSet}}
+		n, err := db.ExportJSON(&buf, opts)
+		be.Err(t, err, nil)
+		be.Equal(t, n, 2)
+		be.Equal(t, buf.String(), ""+
+			`{"key":"list","type":"list","value":["a","b"]}`+"\n"+
+			`{"key":"set","type":"set","value":["a","b"]}`+"\n")
+	})
+	t.Run("pattern and type", func(t *testing.T) {
+		var buf bytes.Buffer
+		opts := &redka.ExportOptions{Pattern: "user:*", Types: []redka.TypeID{redka.TypeHash}}
+		n, err := db.ExportJSON(&buf, opts)
+		be.Err(t, err, nil)
+		be.Equal(t, n, 1)
+		be.Equal(t, buf.String(),
+			`{"key":"user:1","type":"hash","value":{"name":"alice"}}`+"\n")
+	})
+	t.Run("invalid", func(t *testing.T) {
+		other := testx.OpenReplicaDB(t)
+		in := `{"key":"name","type":"string","value":"alice"}` + "\n" +
+			`{"key":"stream","type":"stream","value":[]}` + "\n"
+		n, err := other.ImportJSON(strings.NewReader(in))
+		be.Err(t, err, "unsupported type")
+		be.Equal(t, n, 0)
+	})
+}
@@ -0,0 +1,301 @@
+package redka
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/sqlx"
+)
+
+// ErrNotifyFlags is returned when setting
+// invalid keyspace notification flags.
+var ErrNotifyFlags = errors.New("invalid notify-keyspace-events flags")
+
+// Keyspace notification channel prefixes (same as in Redis).
+const (
+	keyspacePrefix = "__keyspace@0__:"
+	keyeventPrefix = "__keyevent@0__:"
+)
+
+// Keyspace notification flags (same as in Redis).
+const (
+	notifyKeyspace = 1 << iota // K
+	notifyKeyevent             // E
+	notifyGeneric              // g
+	notifyString               // $
+	notifyList                 // l
+	notifySet                  // s
+	notifyHash                 // h
+	notifyZSet                 // z
+	notifyExpired              // x
+	notifyEvicted              // e
+	notifyStream               // t
+	notifyModule               // d
+
+	// A - alias for g$lshzxetd
+	notifyAll = notifyGeneric | notifyString | notifyList | notifySet |
+		notifyHash | notifyZSet | notifyExpired | notifyEvicted |
+		notifyStream | notifyModule
+)
+
+// Flag characters in the order of formatting.
+// Redka does not emit the key miss (m) and new key (n)
+// events, so it does not accept these flags.
+var notifyFlagChars = []struct {
+	char byte
+	flag uint32
+}{
+	{'g', notifyGeneric},
+	{'$', notifyString},
+	{'l', notifyList},
+	{'s', notifySet},
+	{'h', notifyHash},
+	{'z', notifyZSet},
+	{'x', notifyExpired},
+	{'e', notifyEvicted},
+	{'t', notifyStream},
+	{'d', notifyModule},
+	{'K', notifyKeyspace},
+	{'E', notifyKeyevent},
+}
+
+// Notification is a keyspace notification,
+// same as the message Redis publishes to the
+// keyspace and keyevent pub/sub channels.
+//
+// For example, SET name alice produces two notifications
+// (if enabled with the K and E flags):
+//
+//	Channel: __keyspace@0__:name, Message: set
+//	Channel: __keyevent@0__:set,  Message: name
+type Notification struct {
+	Channel string
+	Message string
+}
+
+// notifier delivers the keyspace notifications
+// of the committed changes to the subscribers.
+type notifier struct {
+	sdb    *sqlx.DB
+	flags  atomic.Uint32
+	mu     sync.Mutex
+	funcs  map[int]func(n Notification)
+	lastID int
+	cancel func() // unregisters the change listener
+}
+
+// newNotifier creates a notifier with notifications disabled.
+func newNotifier(sdb *sqlx.DB) *notifier {
+	return &notifier{sdb: sdb}
+}
+
+// setFlags changes the enabled notification classes.
+func (n *notifier) setFlags(flags uint32) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.flags.Store(flags)
+	n.listen()
+}
+
+// subscribe adds a notification subscriber and
+// returns a function that removes it.
+func (n *notifier) subscribe(f func(n Notification)) func() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.funcs == nil {
+		n.funcs = map[int]func(n Notification){}
+	}
+	n.lastID++
+	id := n.lastID
+	n.funcs[id] = f
+	n.listen()
+	return func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		delete(n.funcs, id)
+		n.listen()
+	}
+}
+
+// listen starts or stops listening to the database changes,
+// so that the changes are not tracked unless someone
+// is interested in the notifications. The notifications
+// do not need the key versions, so the writes don't look them up.
+func (n *notifier) listen() {
+	enabled := notifyEnabled(n.flags.Load()) && len(n.funcs) > 0
+	if enabled && n.cancel == nil {
+		n.cancel = n.sdb.ListenWithoutVersions(n.deliver)
+	}
+	if !enabled && n.cancel != nil {
+		n.cancel()
+		n.cancel = nil
+	}
+}
+
+// deliver sends the notifications for the
+// events of the enabled classes to the subscribers.
+func (n *notifier) deliver(events []core.Event) {
+	flags := n.flags.Load()
+	if !notifyEnabled(flags) {
+		return
+	}
+	n.mu.Lock()
+	ids := make([]int, 0, len(n.funcs))
+	for id := range n.funcs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	funcs := make([]func(n Notification), len(ids))
+	for i, id := range ids {
+		funcs[i] = n.funcs[id]
+	}
+	n.mu.Unlock()
+
+	for _, ev := range events {
+		if ev.Key == "" {
+			// Database-wide changes (like flushdb)
+			// do not produce notifications.
+			continue
+		}
+		if flags&eventClass(ev) == 0 {
+			continue
+		}
+		if flags&notifyKeyspace != 0 {
+			notif := Notification{Channel: keyspacePrefix + ev.Key, Message: ev.Name}
+			for _, f := range funcs {
+				f(notif)
+			}
+		}
+		if flags&notifyKeyevent != 0 {
+			notif := Notification{Channel: keyeventPrefix + ev.Name, Message: ev.Key}
+			for _, f := range funcs {
+				f(notif)
+			}
+		}
+	}
+}
+
+// eventClass returns the notification class of the event.
+func eventClass(ev core.Event) uint32 {
+	switch ev.Name {
+	case "expired":
+		return notifyExpired
+	case "evicted":
+		return notifyEvicted
+	case "del", "expire", "persist", "rename_from", "rename_to":
+		return notifyGeneric
+	}
+	switch ev.Type {
+	case core.TypeString:
+		return notifyString
+	case core.TypeList:
+		return notifyList
+	case core.TypeSet:
+		return notifySet
+	case core.TypeHash:
+		return notifyHash
+	case core.TypeZSet:
+		return notifyZSet
+	}
+	return notifyGeneric
+}
+
+// notifyEnabled reports whether the flags enable any notifications.
+// Both the channel type (K or E) and at least one class must be set.
+func notifyEnabled(flags uint32) bool {
+	return flags&(notifyKeyspace|notifyKeyevent) != 0 &&
+		flags&^(notifyKeyspace|notifyKeyevent) != 0
+}
+
+// parseNotifyFlags parses the notify-keyspace-events flags.
+func parseNotifyFlags(s string) (uint32, error) {
+	var flags uint32
+	for i := 0; i < len(s); i++ {
+		if s[i] == 'A' {
+			flags |= notifyAll
+			continue
+		}
+		idx := -1
+		for j, fc := range notifyFlagChars {
+			if fc.char == s[i] {
+				idx = j
+				break
+			}
+		}
+		if idx == -1 {
+			return 0, ErrNotifyFlags
+		}
+		flags |= notifyFlagChars[idx].flag
+	}
+	return flags, nil
+}
+
+// formatNotifyFlags formats the flags the same way Redis does.
+func formatNotifyFlags(flags uint32) string {
+	var b strings.Builder
+	if flags&notifyAll == notifyAll {
+		b.WriteByte('A')
+	}
+	for _, fc := range notifyFlagChars {
+		if flags&notifyAll == notifyAll && fc.flag&notifyAll != 0 {
+			continue
+		}
+		if flags&fc.flag != 0 {
+			b.WriteByte(fc.char)
+		}
+	}
+	return b.String()
+}
+
+// NotifyEvents returns the enabled keyspace notification
+// classes in the Redis notify-keyspace-events format
+// (e.g. "AKE"). Empty string means notifications are disabled.
+func (db *DB) NotifyEvents() string {
+	return formatNotifyFlags(db.notify.flags.Load())
+}
+
+// SetNotifyEvents changes the enabled keyspace notification
+// classes. Uses the Redis notify-keyspace-events format:
+//
+//	K - keyspace events (__keyspace@0__:<key>)
+//	E - keyevent events (__keyevent@0__:<event>)
+//	g - generic commands (del, expire, rename, ...)
+//	$ - string commands
+//	l - list commands
+//	s - set commands
+//	h - hash commands
+//	z - sorted set commands
+//	x - expired events
+//	e - evicted events
+//	A - alias for g$lshzxe
+//
+// The string must contain K or E (or both) and at least one class,
+// otherwise notifications are disabled. Empty string disables them.
+// Returns ErrNotifyFlags if the string contains unknown flags,
+// including the Redis key miss (m) and new key (n) classes,
+// which Redka does not support.
+func (db *DB) SetNotifyEvents(flags string) error {
+	parsed, err := parseNotifyFlags(flags)
+	if err != nil {
+		return err
+	}
+	db.notify.setFlags(parsed)
+	return nil
+}
+
+// OnNotify registers a function that receives the keyspace
+// notifications of the enabled classes (see [DB.SetNotifyEvents]).
+//
+// Notifications are delivered after the transaction commits,
+// and never for rolled back transactions. The function is called
+// synchronously by the goroutine that committed the changes,
+// so it should not block (e.g. use a buffered channel and
+// drop the notifications if it's full).
+// Returns a function that unregisters the hook.
+func (db *DB) OnNotify(f func(n Notification)) (cancel func()) {
+	return db.notify.subscribe(f)
+}
@@ -0,0 +1,133 @@
+package redka_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestNotify(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		got := recordNotify(db)
+		be.Equal(t, db.NotifyEvents(), "")
+
+		_ = db.Str().Set("name", "alice")
+		be.Equal(t, len(got()), 0)
+	})
+	t.Run("keyspace and keyevent", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		got := recordNotify(db)
+		err := db.SetNotifyEvents("KEA")
+		be.Err(t, err, nil)
+		be.Equal(t, db.NotifyEvents(), "AKE")
+
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.Key().Delete("name")
+		be.Equal(t, got(), []redka.Notification{
+			{Channel: "__keyspace@0__:name", Message: "set"},
+			{Channel: "__keyevent@0__:set", Message: "name"},
+			{Channel: "__keyspace@0__:name", Message: "del"},
+			{Channel: "__keyevent@0__:del", Message: "name"},
+		})
+	})
+	t.Run("filter classes", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		got := recordNotify(db)
+		_ = db.SetNotifyEvents("Eh")
+
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.Hash().Set("person", "age", 25)
+		be.Equal(t, got(), []redka.Notification{
+			{Channel: "__keyevent@0__:hset", Message: "person"},
+		})
+	})
+	t.Run("transaction", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		got := recordNotify(db)
+		_ = db.SetNotifyEvents("KA")
+
+		var errRollback = errors.New("rollback")
+		err := db.Update(func(tx *redka.Tx) error {
+			_ = tx.Str().Set("name", "alice")
+			be.Equal(t, len(got()), 0)
+			return errRollback
+		})
+		be.Equal(t, err, errRollback)
+		be.Equal(t, len(got()), 0)
+
+		err = db.Update(func(tx *redka.Tx) error {
+			_ = tx.Str().Set("name", "alice")
+			_, _ = tx.List().PushBack("queue", "task")
+			return nil
+		})
+		be.Err(t, err, nil)
+		be.Equal(t, got(), []redka.Notification{
+			{Channel: "__keyspace@0__:name", Message: "set"},
+			{Channel: "__keyspace@0__:queue", Message: "rpush"},
+		})
+	})
+	t.Run("cancel", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		_ = db.SetNotifyEvents("KA")
+		var count atomic.Int32
+		cancel := db.OnNotify(func(n redka.Notification) {
+			count.Add(1)
+		})
+
+		_ = db.Str().Set("name", "alice")
+		be.Equal(t, count.Load(), int32(1))
+
+		cancel()
+		_ = db.Str().Set("name", "bob")
+		be.Equal(t, count.Load(), int32(1))
+	})
+	t.Run("expired", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		got := recordNotify(db)
+		_ = db.SetNotifyEvents("Ex")
+
+		_ = db.Str().SetExpire("name", "alice", time.Millisecond)
+		db.SetExpireInterval(5 * time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		be.Equal(t, got(), []redka.Notification{
+			{Channel: "__keyevent@0__:expired", Message: "name"},
+		})
+	})
+	t.Run("invalid flags", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		_ = db.SetNotifyEvents("KEA")
+		err := db.SetNotifyEvents("KEQ")
+		be.Err(t, err, redka.ErrNotifyFlags)
+		be.Equal(t, db.NotifyEvents(), "AKE")
+
+		// Key miss and new key events are not supported.
+		err = db.SetNotifyEvents("KEm")
+		be.Err(t, err, redka.ErrNotifyFlags)
+		err = db.SetNotifyEvents("KEn")
+		be.Err(t, err, redka.ErrNotifyFlags)
+	})
+}
+
+// recordNotify collects the database notifications.
+func recordNotify(db *redka.DB) func() []redka.Notification {
+	var mu sync.Mutex
+	var notifs []redka.Notification
+	db.OnNotify(func(n redka.Notification) {
+		mu.Lock()
+		defer mu.Unlock()
+		notifs = append(notifs, n)
+	})
+	return func() []redka.Notification {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(notifs)
+	}
+}
@@ -0,0 +1,335 @@
+package redka
+
+import (
+	"io"
+	"slices"
+	"time"
+
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/rdb"
+)
+
+// Maximum number of values (strings or collection elements)
+// written in a single transaction when importing keys.
+const importBatchSize = 10000
+
+// ImportRDB loads the keys from a Redis RDB file (versions 9 to 12,
+// i.e. Redis 5.0 to 7.4) into the database. Supports strings, lists,
+// sets, hashes and sorted sets with their expiration times. Fails
+// on streams and module types.
+//
+// Redka has a single database, so keys from all Redis databases
+// end up in it. Replaces the existing keys with the same names
+// and skips the keys that have already expired.
+//
+// Writes the keys in batches, each batch in a single transaction.
+// If the import fails, the batches written before the failure
+// stay in the database. Returns the number of imported keys.
+func (db *DB) ImportRDB(r io.Reader) (int, error) {
+	rd := rdb.NewReader(r)
+	im := importer{db: db}
+	for {
+		entry, err := rd.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return im.count, err
+		}
+		if err := im.add(entry); err != nil {
+			return im.count, err
+		}
+	}
+	err := im.flush()
+	return im.count, err
+}
+
+// ExportRDB writes the database contents to w as a Redis RDB
+// file (version 9), which Redis 5.0 and later can load.
+// The keys are written to Redis database 0, along with
+// their expiration times.
+//
+// The export is taken in a single read transaction, so it
+// is consistent and does not block the writers.
+// Returns the number of exported keys.
+func (db *DB) ExportRDB(w io.Writer) (int, error) {
+	count := 0
+	err := db.View(func(tx *Tx) error {
+		wr := rdb.NewWriter(w)
+		scanner := tx.Key().Scanner("*", core.TypeAny, 0)
+		for scanner.Scan() {
+			entry, err := exportEntry(tx, scanner.Key())
+			if err == core.ErrNotFound {
+				// The key has no value.
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := wr.Write(entry); err != nil {
+				return err
+			}
+			count++
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return wr.Close()
+	})
+	return count, err
+}
+
+// Dump serializes the key value in the format of the Redis DUMP
+// command (see [Tx.Dump]). Returns ErrNotFound if the key does not exist.
+func (db *DB) Dump(key string) ([]byte, error) {
+	var payload []byte
+	err := db.View(func(tx *Tx) error {
+		var err error
+		payload, err = tx.Dump(key)
+		return err
+	})
+	return payload, err
+}
+
+// Restore creates the key from the value serialized with Dump
+// (see [Tx.Restore]).
+func (db *DB) Restore(key string, payload []byte, at time.Time, replace bool) error {
+	return db.Update(func(tx *Tx) error {
+		return tx.Restore(key, payload, at, replace)
+	})
+}
+
+// Dump serializes the key value in the format of the Redis
+// DUMP command. The payload includes the RDB version and
+// the checksum, but not the expiration time. Redis 5.0 and
+// later can restore it with the RESTORE command.
+// Returns ErrNotFound if the key does not exist.
+func (tx *Tx) Dump(key string) ([]byte, error) {
+	k, err := tx.Key().Get(key)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := exportEntry(tx, k)
+	if err != nil {
+		return nil, err
+	}
+	return rdb.Dump(entry)
+}
+
+// Restore creates the key from the value serialized with Dump
+// (or with the Redis DUMP command). Sets the expiration time
+// if at is not zero. Does nothing (except deleting the existing
+// key if replace is true) if at is in the past.
+//
+// If the key exists, replaces it if replace is true,
+// or returns ErrKeyExists otherwise. Returns an error if
+// the payload is invalid or its checksum does not match.
+func (tx *Tx) Restore(key string, payload []byte, at time.Time, replace bool) error {
+	entry, err := rdb.Load(payload)
+	if err != nil {
+		return err
+	}
+	if !replace {
+		exists, err := tx.Key().Exists(key)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return core.ErrKeyExists
+		}
+	}
+	entry.Key = key
+	if !at.IsZero() {
+		if !at.After(time.Now()) {
+			// Already expired.
+			_, err := tx.Key().Delete(key)
+			return err
+		}
+		entry.ETime = at.UnixMilli()
+	}
+	return importEntry(tx, entry)
+}
+
+// exportEntry returns the RDB entry for the key.
+// Returns ErrNotFound if the key has no value (e.g. it
+// has expired since the scan).
+func exportEntry(tx *Tx, key core.Key) (rdb.Entry, error) {
+	entry := rdb.Entry{Key: key.Key, Type: key.Type}
+	if key.ETime != nil {
+		entry.ETime = *key.ETime
+	}
+
+	switch key.Type {
+	case core.TypeString:
+		val, err := tx.Str().Get(key.Key)
+		if err != nil {
+			return rdb.Entry{}, err
+		}
+		entry.Str = val.Bytes()
+
+	case core.TypeList:
+		vals, err := tx.List().Range(key.Key, 0, -1)
+		if err != nil {
+			return rdb.Entry{}, err
+		}
+		entry.Elems = valuesToBytes(vals)
+
+	case core.TypeSet:
+		vals, err := tx.Set().Items(key.Key)
+		if err != nil {
+			return rdb.Entry{}, err
+		}
+		entry.Elems = valuesToBytes(vals)
+
+	case core.TypeHash:
+		items, err := tx.Hash().Items(key.Key)
+		if err != nil {
+			return rdb.Entry{}, err
+		}
+		fields := make([]string, 0, len(items))
+		for field := range items {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+		entry.Fields = make([]rdb.Field, len(fields))
+		for i, field := range fields {
+			entry.Fields[i] = rdb.Field{Name: []byte(field), Value: items[field].Bytes()}
+		}
+
+	case core.TypeZSet:
+		n, err := tx.ZSet().Len(key.Key)
+		if err != nil {
+			return rdb.Entry{}, err
+		}
+		if n == 0 {
+			return rdb.Entry{}, core.ErrNotFound
+		}
+		items, err := tx.ZSet().Range(key.Key, 0, n-1)
+		if err != nil {
+			return rdb.Entry{}, err
+		}
+		entry.Items = make([]rdb.Item, len(items))
+		for i, item := range items {
+			entry.Items[i] = rdb.Item{Elem: item.Elem.Bytes(), Score: item.Score}
+		}
+
+	default:
+		return rdb.Entry{}, core.ErrNotFound
+	}
+
+	if entry.Len() == 0 {
+		return rdb.Entry{}, core.ErrNotFound
+	}
+	return entry, nil
+}
+
+// valuesToBytes converts the values to byte slices.
+func valuesToBytes(vals []core.Value) [][]byte {
+	elems := make([][]byte, len(vals))
+	for i, val := range vals {
+		elems[i] = val.Bytes()
+	}
+	return elems
+}
+
+// importer writes the entries to the database
+// in batches, each batch in a single transaction.
+type importer struct {
+	db    *DB
+	batch []rdb.Entry
+	size  int // number of values in the batch
+	count int // number of imported keys
+}
+
+// add adds the entry to the batch, skipping it if it
+// has already expired. Writes the batch if it's full.
+func (im *importer) add(e rdb.Entry) error {
+	if e.ETime != 0 && e.ETime <= time.Now().UnixMilli() {
+		return nil
+	}
+	im.batch = append(im.batch, e)
+	im.size += e.Len()
+	if im.size < importBatchSize {
+		return nil
+	}
+	return im.flush()
+}
+
+// flush writes the batch to the database.
+func (im *importer) flush() error {
+	if len(im.batch) == 0 {
+		return nil
+	}
+	err := im.db.Update(func(tx *Tx) error {
+		for _, entry := range im.batch {
+			if err := importEntry(tx, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	im.count += len(im.batch)
+	im.batch, im.size = im.batch[:0], 0
+	return nil
+}
+
+// importEntry replaces the key with the entry.
+func importEntry(tx *Tx, e rdb.Entry) error {
+	if _, err := tx.Key().Delete(e.Key); err != nil {
+		return err
+	}
+	if e.Len() == 0 {
+		return nil
+	}
+
+	var err error
+	switch e.Type {
+	case core.TypeString:
+		cmd := tx.Str().SetWith(e.Key, e.Str)
+		if e.ETime != 0 {
+			// Strings set the expiration time along with the value.
+			cmd = cmd.At(time.UnixMilli(e.ETime))
+		}
+		_, err = cmd.Run()
+		return err
+
+	case core.TypeList:
+		for _, elem := range e.Elems {
+			if _, err = tx.List().PushBack(e.Key, elem); err != nil {
+				return err
+			}
+		}
+
+	case core.TypeSet:
+		elems := make([]any, len(e.Elems))
+		for i, elem := range e.Elems {
+			elems[i] = elem
+		}
+		_, err = tx.Set().Add(e.Key, elems...)
+
+	case core.TypeHash:
+		items := make(map[string]any, len(e.Fields))
+		for _, field := range e.Fields {
+			items[string(field.Name)] = field.Value
+		}
+		_, err = tx.Hash().SetMany(e.Key, items)
+
+	case core.TypeZSet:
+		items := make(map[any]float64, len(e.Items))
+		for _, item := range e.Items {
+			items[string(item.Elem)] = item.Score
+		}
+		_, err = tx.ZSet().AddMany(e.Key, items)
+	}
+	if err != nil {
+		return err
+	}
+
+	if e.ETime != 0 {
+		return tx.Key().ExpireAt(e.Key, time.UnixMilli(e.ETime))
+	}
+	return nil
+}
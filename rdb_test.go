@@ -0,0 +1,147 @@
+package redka_test
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestImportRDB(t *testing.T) {
+	db := testx.OpenDB(t)
+	_, _ = db.Hash().Set("name", "first", "alice")
+	_ = db.Str().Set("other", "value")
+
+	f, err := os.Open("internal/rdb/testdata/v9.rdb")
+	be.Err(t, err, nil)
+	defer f.Close()
+
+	n, err := db.ImportRDB(f)
+	be.Err(t, err, nil)
+	// All keys except the expired one.
+	be.Equal(t, n, 19)
+
+	t.Run("string", func(t *testing.T) {
+		// Replaces the existing key of another type.
+		name, _ := db.Str().Get("name")
+		be.Equal(t, name.String(), "alice")
+		age, _ := db.Str().Get("age")
+		be.Equal(t, age.MustInt(), 25)
+		lzf, _ := db.Str().Get("lzf")
+		be.Equal(t, lzf.String(), strings.Repeat("abc", 10))
+	})
+	t.Run("expire", func(t *testing.T) {
+		key, _ := db.Key().Get("session")
+		be.Equal(t, *key.ETime, int64(4102444800000))
+		_, err := db.Key().Get("expired")
+		be.Err(t, err, redka.ErrNotFound)
+	})
+	t.Run("list", func(t *testing.T) {
+		list, _ := db.List().Range("list", 0, -1)
+		be.Equal(t, len(list), 9)
+		be.Equal(t, list[0].String(), "a")
+		be.Equal(t, list[8].String(), "10000000000")
+	})
+	t.Run("set", func(t *testing.T) {
+		n, _ := db.Set().Len("intset")
+		be.Equal(t, n, 3)
+		ok, _ := db.Set().Exists("set", "sql")
+		be.True(t, ok)
+	})
+	t.Run("hash", func(t *testing.T) {
+		items, _ := db.Hash().Items("hash")
+		be.Equal(t, len(items), 2)
+		be.Equal(t, items["age"].String(), "25")
+	})
+	t.Run("zset", func(t *testing.T) {
+		score, _ := db.ZSet().GetScore("zset", "b")
+		be.Equal(t, score, 2.5)
+		score, _ = db.ZSet().GetScore("zset-old", "b")
+		be.True(t, math.IsInf(score, 1))
+	})
+	t.Run("keep other keys", func(t *testing.T) {
+		other, _ := db.Str().Get("other")
+		be.Equal(t, other.String(), "value")
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := db.ImportRDB(strings.NewReader("REDIS0008"))
+		be.True(t, err != nil)
+	})
+}
+
+func TestExportRDB(t *testing.T) {
+	db := testx.OpenDB(t)
+	at := time.UnixMilli(4102444800000)
+	_, _ = db.Str().SetWith("name", "alice").At(at).Run()
+	_ = db.Str().Set("age", 25)
+	_, _ = db.List().PushBack("list", "a")
+	_, _ = db.List().PushBack("list", "b")
+	_, _ = db.Set().Add("set", "a", "b")
+	_, _ = db.Hash().Set("hash", "f1", "v1")
+	_ = db.Key().ExpireAt("hash", at)
+	_, _ = db.ZSet().Add("zset", "a", 1.5)
+
+	var buf bytes.Buffer
+	n, err := db.ExportRDB(&buf)
+	be.Err(t, err, nil)
+	be.Equal(t, n, 6)
+
+	// The export can be imported back.
+	other := testx.OpenReplicaDB(t)
+	n, err = other.ImportRDB(&buf)
+	be.Err(t, err, nil)
+	be.Equal(t, n, 6)
+
+	var got []string
+	_, err = other.Snapshot(func(cmd [][]byte) error {
+		got = append(got, string(bytes.Join(cmd, []byte(" "))))
+		return nil
+	})
+	be.Err(t, err, nil)
+	slices.Sort(got)
+	be.Equal(t, got, []string{
+		"hset hash f1 v1",
+		"pexpireat hash 4102444800000",
+		"rpush list a",
+		"rpush list b",
+		"sadd set a b",
+		"set age 25",
+		"set name alice pxat 4102444800000",
+		"zadd zset 1.5 a",
+	})
+}
+
+func TestDumpRestore(t *testing.T) {
+	db := testx.OpenDB(t)
+	_, _ = db.Hash().Set("person", "name", "alice")
+
+	payload, err := db.Dump("person")
+	be.Err(t, err, nil)
+
+	t.Run("restore", func(t *testing.T) {
+		at := time.UnixMilli(4102444800000)
+		err := db.Restore("copy", payload, at, false)
+		be.Err(t, err, nil)
+		name, _ := db.Hash().Get("copy", "name")
+		be.Equal(t, name.String(), "alice")
+		key, _ := db.Key().Get("copy")
+		be.Equal(t, *key.ETime, at.UnixMilli())
+	})
+	t.Run("exists", func(t *testing.T) {
+		err := db.Restore("person", payload, time.Time{}, false)
+		be.Err(t, err, redka.ErrKeyExists)
+		err = db.Restore("person", payload, time.Time{}, true)
+		be.Err(t, err, nil)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := db.Dump("missing")
+		be.Err(t, err, redka.ErrNotFound)
+	})
+}
@@ -26,9 +26,11 @@ import (
 	"database/sql"
 	"io"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/rchange"
 	"github.com/nalgeon/redka/internal/rhash"
 	"github.com/nalgeon/redka/internal/rkey"
 	"github.com/nalgeon/redka/internal/rlist"
@@ -54,9 +56,11 @@ const (
 
 // Common errors returned by data structure methods.
 var (
-	ErrKeyType   = core.ErrKeyType   // key type mismatch
-	ErrNotFound  = core.ErrNotFound  // key or element not found
-	ErrValueType = core.ErrValueType // invalid value type
+	ErrKeyExists    = core.ErrKeyExists    // key already exists
+	ErrKeyType      = core.ErrKeyType      // key type mismatch
+	ErrNotFound     = core.ErrNotFound     // key or element not found
+	ErrNotSupported = core.ErrNotSupported // operation not supported by the database
+	ErrValueType    = core.ErrValueType    // invalid value type
 )
 
 // Key represents a key data structure.
@@ -70,6 +74,32 @@ type Key = core.Key
 // It can be converted to other scalar types.
 type Value = core.Value
 
+// Trace records the slowest SQL statement
+// executed through a traced database. See [DB.WithTrace].
+type Trace = sqlx.Trace
+
+// Stats describes the database usage.
+type Stats struct {
+	// Number of expired keys deleted by the background manager.
+	ExpiredKeys int64
+	// Number of background expiration cycles.
+	ExpireCycles int64
+	// Number of expiration cycles stopped by the time budget
+	// (see [Options.ExpireBudget]) rather than by running out
+	// of expired keys. If it grows quickly, the background manager
+	// does not keep up with the expiring keys.
+	ExpireCappedCycles int64
+	// Total time spent in the expiration cycles.
+	ExpireTime time.Duration
+	// Number of keys deleted by the eviction policy
+	// to keep the database under the limits.
+	EvictedKeys int64
+	// Read-write connection pool statistics.
+	RW sql.DBStats
+	// Read-only connection pool statistics.
+	RO sql.DBStats
+}
+
 // Options is the configuration for the database.
 type Options struct {
 	// SQL driver name.
@@ -84,8 +114,88 @@ type Options struct {
 	// Logger for the database. If nil, uses a silent logger.
 	Logger *slog.Logger
 
+	// If true, merges concurrent writable transactions
+	// (including the ones made by the repository methods)
+	// into a single database transaction (group commit).
+	// Each transaction runs in its own savepoint, so a failed
+	// transaction does not affect the others. If the group commit
+	// fails, all transactions in the group fail. Helps with many
+	// concurrent writers, especially with SQLite. With group commit,
+	// a change hook (see [DB.OnChange]) that writes to the database
+	// deadlocks, because the hook runs before the next group starts.
+	GroupCommit bool
+	// If true, sends the SQL queries as plain text with each call
+	// instead of preparing them once per connection. Use it with
+	// the connection poolers that do not support the prepared
+	// statements (e.g. PgBouncer in transaction pooling mode).
+	NoPrepare bool
+
+	// Prefix for the names of the tables, views, indexes and
+	// triggers (SQLite only). Use it to keep several Redka
+	// instances in a single database, or to keep Redka tables
+	// apart from the application ones. For example, with the
+	// "cache_" prefix the keys are stored in the cache_rkey table.
+	TablePrefix string
+	// Schema to keep the tables in (PostgreSQL only).
+	// Open and OpenRead set the search_path to the schema,
+	// and Open creates it if necessary. With OpenDB and
+	// OpenReadDB, the search_path of the connections must
+	// start with the schema (e.g. set it in the connection string).
+	Schema string
+
+	// How often the background manager deletes expired keys.
+	// If zero, uses the default interval of 1 second.
+	ExpireInterval time.Duration
+	// How many expired keys the background manager deletes
+	// in a single transaction. If zero, uses the default of 100.
+	ExpireBatchSize int
+	// Maximum time the background manager spends deleting
+	// expired keys in a single cycle. The keys left over are
+	// deleted in the next cycles. If zero, uses the default
+	// of 25 milliseconds.
+	ExpireBudget time.Duration
+
+	// Maximum number of keys. If zero, the number is not limited.
+	MaxKeys int
+	// Maximum database size in bytes (see [DB.Size]).
+	// If zero, the size is not limited.
+	MaxBytes int64
+	// Which keys to delete when the database is over the limits
+	// (MaxKeys or MaxBytes). The background manager checks the limits
+	// along with deleting the expired keys, and [DB.Evict] checks them
+	// before a write. If empty, uses [NoEviction], so the database
+	// does not delete the keys, but rejects the writes instead.
+	EvictionPolicy EvictionPolicy
+	// If true, records the key access time and counter (see
+	// [rkey.DB.Info]) regardless of the eviction policy. The reads
+	// are recorded in memory, and the background manager writes
+	// them to the database in a single transaction, so the reads
+	// don't turn into writes. The LRU eviction policies enable
+	// the tracking automatically.
+	TrackAccess bool
+
+	// If true, records the changes made by the write operations
+	// in the change log (see [DB.Changes]).
+	ChangeLog bool
+	// Maximum number of entries in the change log.
+	// The background manager deletes the oldest entries
+	// over the limit. If zero, the number is not limited.
+	ChangeLogMaxLen int
+	// Maximum age of the entries in the change log.
+	// The background manager deletes the older entries.
+	// If zero, the age is not limited.
+	ChangeLogMaxAge time.Duration
+
+	// Function that writes a database snapshot to a file
+	// (see [DB.Backup]). If nil, uses VACUUM INTO with SQLite
+	// and returns [ErrNotSupported] with other databases.
+	// Set it to back up a PostgreSQL database (e.g. with pg_dump).
+	Backup func(ctx context.Context, path string) error
+
 	// If true, opens the database in read-only mode.
 	readOnly bool
+	// If true, the database is in memory (SQLite only).
+	inMemory bool
 }
 
 // Application options defaults.
@@ -93,6 +203,12 @@ var defaultOptions = Options{
 	DriverName: "sqlite3",
 	Timeout:    5 * time.Second,
 	Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+
+	ExpireInterval:  defaultExpireInterval,
+	ExpireBatchSize: defaultExpireBatchSize,
+	ExpireBudget:    defaultExpireBudget,
+
+	EvictionPolicy: NoEviction,
 }
 
 // DB is a Redis-like repository backed by a relational database.
@@ -109,10 +225,25 @@ type DB struct {
 	setDB    *rset.DB
 	stringDB *rstring.DB
 	zsetDB   *rzset.DB
+	changeDB *rchange.DB
 	bg       *time.Ticker
+	bgEvery  *atomic.Int64 // background manager interval in nanoseconds
+	expire   *expirer      // background expiration
+	evict    *evictor      // eviction over the limits
+	notify   *notifier     // keyspace notifications
+	changes  changeLimits  // change log retention limits
+	chlog    bool          // whether the change log is enabled
+	backup   func(ctx context.Context, path string) error
+	inMemory bool // whether the database is in memory (SQLite only)
 	log      *slog.Logger
 }
 
+// changeLimits are the change log retention limits.
+type changeLimits struct {
+	maxLen int
+	maxAge time.Duration
+}
+
 // Open opens a new or existing database at the given path.
 // Creates the database schema if necessary.
 //
@@ -124,6 +255,10 @@ type DB struct {
 func Open(path string, opts *Options) (*DB, error) {
 	// Apply the default options if necessary.
 	opts = applyOptions(defaultOptions, opts)
+	if err := checkOptions(opts); err != nil {
+		return nil, err
+	}
+	opts.inMemory = sqlx.IsMemory(path)
 	sopts := newSQLOptions(opts)
 
 	// Open the read-write database handle.
@@ -153,7 +288,11 @@ func Open(path string, opts *Options) (*DB, error) {
 func OpenRead(path string, opts *Options) (*DB, error) {
 	// Apply the default options if necessary.
 	opts = applyOptions(defaultOptions, opts)
+	if err := checkOptions(opts); err != nil {
+		return nil, err
+	}
 	opts.readOnly = true
+	opts.inMemory = sqlx.IsMemory(path)
 	sopts := newSQLOptions(opts)
 
 	// Open the read-only database handle.
@@ -176,6 +315,9 @@ func OpenRead(path string, opts *Options) (*DB, error) {
 // The opts parameter is optional. If nil, uses default options.
 func OpenDB(rw *sql.DB, ro *sql.DB, opts *Options) (*DB, error) {
 	opts = applyOptions(defaultOptions, opts)
+	if err := checkOptions(opts); err != nil {
+		return nil, err
+	}
 	sopts := newSQLOptions(opts)
 	sdb, err := sqlx.Open(rw, ro, sopts)
 	if err != nil {
@@ -187,6 +329,9 @@ func OpenDB(rw *sql.DB, ro *sql.DB, opts *Options) (*DB, error) {
 // OpenReadDB connects to an existing SQL database in read-only mode.
 func OpenReadDB(db *sql.DB, opts *Options) (*DB, error) {
 	opts = applyOptions(defaultOptions, opts)
+	if err := checkOptions(opts); err != nil {
+		return nil, err
+	}
 	opts.readOnly = true
 	sopts := newSQLOptions(opts)
 	sdb, err := sqlx.New(db, db, sopts)
@@ -207,14 +352,67 @@ func new(sdb *sqlx.DB, opts *Options) (*DB, error) {
 		setDB:    rset.New(sdb),
 		stringDB: rstring.New(sdb),
 		zsetDB:   rzset.New(sdb),
+		changeDB: rchange.New(sdb),
+		bgEvery:  &atomic.Int64{},
+		notify:   newNotifier(sdb),
+		changes:  changeLimits{maxLen: opts.ChangeLogMaxLen, maxAge: opts.ChangeLogMaxAge},
+		chlog:    opts.ChangeLog,
+		backup:   opts.Backup,
+		inMemory: opts.inMemory,
 		log:      opts.Logger,
 	}
+	rdb.expire = newExpirer(rdb.keyDB, sdb.TakeExpired, opts.ExpireBatchSize, opts.ExpireBudget, opts.Logger)
+	track := sdb.TrackAccess
+	if opts.readOnly {
+		// There is no background manager to record the accesses.
+		track = func(bool) {}
+	}
+	rdb.evict = newEvictor(rdb.keyDB, sdb.Size, sdb.TakeAccessed, track, sdb.ListenCommits,
+		sdb.Dialect == sqlx.DialectPostgres, opts.ExpireBatchSize, opts.ExpireBudget, opts.Logger)
+	rdb.evict.setMaxKeys(opts.MaxKeys)
+	rdb.evict.setMaxBytes(opts.MaxBytes)
+	rdb.evict.access.Store(opts.TrackAccess)
+	if err := rdb.evict.setPolicy(opts.EvictionPolicy); err != nil {
+		return nil, err
+	}
+	rdb.bgEvery.Store(int64(opts.ExpireInterval))
+	if opts.ChangeLog && !opts.readOnly {
+		// Record the changes in the same transaction
+		// as the write operations themselves.
+		sdb.SetJournal(func(tx sqlx.Tx, events []core.Event) error {
+			return rchange.NewTx(sdb.Dialect, tx).Record(events)
+		})
+	}
 	if !opts.readOnly {
 		rdb.bg = rdb.startBgManager()
 	}
 	return rdb, nil
 }
 
+// Changes returns the change log repository.
+// The change log records the changes made by the write
+// operations, along with the commands that reproduce them.
+// Use the change log to replicate or audit the changes.
+//
+// The log is only filled if the database was opened with
+// [Options.ChangeLog]. Each change is recorded in the same
+// transaction as the write operation itself, so the log
+// never contains rolled back changes and never misses
+// committed ones. Deleting all keys (FLUSHDB) is the only
+// exception: it's recorded right after the keys are deleted.
+//
+// With PostgreSQL, concurrent transactions may commit
+// in a different order than they got their sequence numbers.
+func (db *DB) Changes() *rchange.DB {
+	return db.changeDB
+}
+
+// ChangeLog reports whether the change log is enabled
+// (see [Options.ChangeLog]).
+func (db *DB) ChangeLog() bool {
+	return db.chlog
+}
+
 // Hash returns the hash repository.
 // A hash (hashmap) is a field-value map associated with a key.
 // Use the hash repository to work with individual hashmaps
@@ -267,6 +465,159 @@ func (db *DB) Log() *slog.Logger {
 	return db.log
 }
 
+// Timeout returns the timeout for database operations.
+func (db *DB) Timeout() time.Duration {
+	return db.sdb.Timeout()
+}
+
+// SetTimeout changes the timeout for database operations.
+// Affects operations started after the call.
+func (db *DB) SetTimeout(timeout time.Duration) {
+	db.sdb.SetTimeout(timeout)
+}
+
+// ExpireInterval returns how often the database
+// deletes expired keys in the background.
+func (db *DB) ExpireInterval() time.Duration {
+	return time.Duration(db.bgEvery.Load())
+}
+
+// SetExpireInterval changes how often the database
+// deletes expired keys in the background.
+// The interval must be positive.
+func (db *DB) SetExpireInterval(interval time.Duration) {
+	db.bgEvery.Store(int64(interval))
+	if db.bg != nil {
+		db.bg.Reset(interval)
+	}
+}
+
+// MaxKeys returns the maximum number of keys
+// (see [Options.MaxKeys]).
+func (db *DB) MaxKeys() int {
+	return int(db.evict.maxKeys.Load())
+}
+
+// SetMaxKeys changes the maximum number of keys.
+// Zero means no limit.
+func (db *DB) SetMaxKeys(n int) {
+	db.evict.setMaxKeys(n)
+}
+
+// MaxBytes returns the maximum database size in bytes
+// (see [Options.MaxBytes]).
+func (db *DB) MaxBytes() int64 {
+	return db.evict.maxBytes.Load()
+}
+
+// SetMaxBytes changes the maximum database size in bytes.
+// Zero means no limit.
+func (db *DB) SetMaxBytes(n int64) {
+	db.evict.setMaxBytes(n)
+}
+
+// EvictionPolicy returns the eviction policy
+// (see [Options.EvictionPolicy]).
+func (db *DB) EvictionPolicy() EvictionPolicy {
+	return db.evict.getPolicy()
+}
+
+// SetEvictionPolicy changes the eviction policy.
+// Returns an error if the policy is unknown.
+func (db *DB) SetEvictionPolicy(policy EvictionPolicy) error {
+	return db.evict.setPolicy(policy)
+}
+
+// TrackAccess reports whether the key access time and counter
+// are recorded regardless of the eviction policy
+// (see [Options.TrackAccess]).
+func (db *DB) TrackAccess() bool {
+	return db.evict.access.Load()
+}
+
+// SetTrackAccess enables or disables the access tracking
+// regardless of the eviction policy. The LRU policies
+// track the access anyway.
+func (db *DB) SetTrackAccess(enabled bool) {
+	db.evict.setTrackAccess(enabled)
+}
+
+// Evict makes sure the database is under the limits
+// (see [Options.MaxKeys] and [Options.MaxBytes]) before a write.
+// If the database might be over the limits, measures it, and if
+// it's over the limits, deletes the keys according to the eviction
+// policy right away. Returns [ErrOOM] if the database is still over
+// the limits (e.g. with the [NoEviction] policy). Does nothing
+// if there are no limits.
+//
+// Measuring counts the keys in the table, so Evict only does it
+// when the last measure found the database over the limits, or when
+// the changes committed since then might have pushed it over the
+// limits (assuming each change adds a new key of the average size).
+// The background manager also checks the limits every
+// [DB.ExpireInterval]. The Redka server calls Evict before
+// the commands that may increase the database size.
+// Do not call it inside a transaction.
+func (db *DB) Evict() error {
+	if db.evict.check() {
+		return ErrOOM
+	}
+	return nil
+}
+
+// Size returns the database size in bytes. With SQLite,
+// it's the size of the database file without the free pages.
+// With PostgreSQL, it's the total size of the Redka tables
+// (including indexes), which does not shrink after deleting
+// the keys until the tables are compacted (VACUUM FULL).
+func (db *DB) Size() (int64, error) {
+	return db.sdb.Size()
+}
+
+// Stats returns the database usage statistics.
+func (db *DB) Stats() Stats {
+	return Stats{
+		ExpiredKeys:        db.expire.keys.Load(),
+		ExpireCycles:       db.expire.cycles.Load(),
+		ExpireCappedCycles: db.expire.capped.Load(),
+		ExpireTime:         time.Duration(db.expire.dur.Load()),
+		EvictedKeys:        db.evict.keys.Load(),
+		RW:                 db.sdb.RW.Stats(),
+		RO:                 db.sdb.RO.Stats(),
+	}
+}
+
+// WithTrace returns a database that shares the connections
+// and settings with db, and records the slowest executed SQL
+// statement in the trace. Use it to find out which statements
+// make a particular operation slow.
+//
+// Do not close the returned database; close db instead.
+func (db *DB) WithTrace(trace *Trace) *DB {
+	sdb := db.sdb.WithTrace(trace)
+	return &DB{
+		sdb:      sdb,
+		act:      sqlx.NewTransactor(sdb, newTx),
+		hashDB:   rhash.New(sdb),
+		keyDB:    rkey.New(sdb),
+		listDB:   rlist.New(sdb),
+		setDB:    rset.New(sdb),
+		stringDB: rstring.New(sdb),
+		zsetDB:   rzset.New(sdb),
+		changeDB: rchange.New(sdb),
+		bg:       db.bg,
+		bgEvery:  db.bgEvery,
+		expire:   db.expire,
+		evict:    db.evict,
+		notify:   db.notify,
+		changes:  db.changes,
+		chlog:    db.chlog,
+		backup:   db.backup,
+		inMemory: db.inMemory,
+		log:      db.log,
+	}
+}
+
 // Update executes a function within a writable transaction.
 func (db *DB) Update(f func(tx *Tx) error) error {
 	return db.act.Update(f)
@@ -293,69 +644,75 @@ func (db *DB) Close() error {
 	if db.bg != nil {
 		db.bg.Stop()
 	}
-	var allErr error
-	if err := db.sdb.RW.Close(); err != nil {
-		allErr = err
-	}
-	if err := db.sdb.RO.Close(); allErr == nil {
-		allErr = err
-	}
-	return allErr
+	return db.sdb.Close()
 }
 
 // startBgManager starts the goroutine than runs
-// in the background and deletes expired keys.
-// Triggers every 60 seconds, deletes up all expired keys.
+// in the background, deletes expired keys, evicts keys
+// over the limits and trims the change log according
+// to the retention limits. Triggers every [DB.ExpireInterval]
+// (1 second by default). Deletes the keys in small batches
+// within the time budget, so that the concurrent writers
+// don't time out (see [expirer] and [evictor]).
 func (db *DB) startBgManager() *time.Ticker {
-	// TODO: needs further investigation. Deleting all keys may be expensive
-	// and lead to timeouts for concurrent write operations.
-	// Adaptive limits based on the number of changed keys may be a solution.
-	// (see https://redis.io/docs/management/config-file/ > SNAPSHOTTING)
-	// And it doesn't help that SQLite's drivers do not support DELETE LIMIT,
-	// so we have to use DELETE IN (SELECT ...), which is more expensive.
-	const interval = 60 * time.Second
-	const nKeys = 0
-
-	ticker := time.NewTicker(interval)
+	ticker := time.NewTicker(db.ExpireInterval())
 	go func() {
 		for range ticker.C {
-			count, err := db.keyDB.DeleteExpired(nKeys)
-			if err != nil {
-				db.log.Error("bg: delete expired keys", "error", err)
-			} else {
-				db.log.Info("bg: delete expired keys", "count", count)
-			}
+			db.expire.cycle()
+			db.evict.cycle()
+			db.trimChanges()
 		}
 	}()
 	return ticker
 }
 
+// trimChanges deletes the change log entries
+// over the retention limits, if any.
+func (db *DB) trimChanges() {
+	if db.changes.maxLen == 0 && db.changes.maxAge == 0 {
+		return
+	}
+	count, err := db.changeDB.Trim(db.changes.maxLen, db.changes.maxAge)
+	if err != nil {
+		db.log.Error("bg: trim change log", "error", err)
+	} else {
+		db.log.Info("bg: trim change log", "count", count)
+	}
+}
+
 // Tx is a Redis-like database transaction.
 // Same as [DB], Tx provides access to data structures like keys,
 // strings, and hashes. The difference is that you call Tx methods
 // within a transaction managed by [DB.Update] or [DB.View].
 type Tx struct {
-	tx     sqlx.Tx
-	hashTx *rhash.Tx
-	keyTx  *rkey.Tx
-	listTx *rlist.Tx
-	setTx  *rset.Tx
-	strTx  *rstring.Tx
-	zsetTx *rzset.Tx
+	tx       sqlx.Tx
+	changeTx *rchange.Tx
+	hashTx   *rhash.Tx
+	keyTx    *rkey.Tx
+	listTx   *rlist.Tx
+	setTx    *rset.Tx
+	strTx    *rstring.Tx
+	zsetTx   *rzset.Tx
 }
 
 // newTx creates a new database transaction.
 func newTx(dialect sqlx.Dialect, tx sqlx.Tx) *Tx {
 	return &Tx{tx: tx,
-		hashTx: rhash.NewTx(dialect, tx),
-		keyTx:  rkey.NewTx(dialect, tx),
-		listTx: rlist.NewTx(dialect, tx),
-		setTx:  rset.NewTx(dialect, tx),
-		strTx:  rstring.NewTx(dialect, tx),
-		zsetTx: rzset.NewTx(dialect, tx),
+		changeTx: rchange.NewTx(dialect, tx),
+		hashTx:   rhash.NewTx(dialect, tx),
+		keyTx:    rkey.NewTx(dialect, tx),
+		listTx:   rlist.NewTx(dialect, tx),
+		setTx:    rset.NewTx(dialect, tx),
+		strTx:    rstring.NewTx(dialect, tx),
+		zsetTx:   rzset.NewTx(dialect, tx),
 	}
 }
 
+// Changes returns the change log transaction.
+func (tx *Tx) Changes() *rchange.Tx {
+	return tx.changeTx
+}
+
 // Hash returns the hash transaction.
 func (tx *Tx) Hash() *rhash.Tx {
 	return tx.hashTx
@@ -386,6 +743,18 @@ func (tx *Tx) ZSet() *rzset.Tx {
 	return tx.zsetTx
 }
 
+// Savepoint executes a function within a savepoint of the transaction.
+// If the function returns an error, Savepoint rolls back the changes
+// made by the function and returns the error, and the rest of the
+// transaction can continue. Useful for running a batch of independent
+// operations in a single transaction, so that a failed operation
+// does not affect the others.
+func (tx *Tx) Savepoint(f func(tx *Tx) error) error {
+	return sqlx.Savepoint(tx.tx, func() error {
+		return f(tx)
+	})
+}
+
 // applyOptions applies custom options to the
 // default options and returns the result.
 func applyOptions(opts Options, custom *Options) *Options {
@@ -404,9 +773,39 @@ func applyOptions(opts Options, custom *Options) *Options {
 	if custom.Logger != nil {
 		opts.Logger = custom.Logger
 	}
+	if custom.ExpireInterval != 0 {
+		opts.ExpireInterval = custom.ExpireInterval
+	}
+	if custom.ExpireBatchSize != 0 {
+		opts.ExpireBatchSize = custom.ExpireBatchSize
+	}
+	if custom.ExpireBudget != 0 {
+		opts.ExpireBudget = custom.ExpireBudget
+	}
+	if custom.EvictionPolicy != "" {
+		opts.EvictionPolicy = custom.EvictionPolicy
+	}
+	opts.MaxKeys = custom.MaxKeys
+	opts.MaxBytes = custom.MaxBytes
+	opts.TrackAccess = custom.TrackAccess
+	opts.GroupCommit = custom.GroupCommit
+	opts.NoPrepare = custom.NoPrepare
+	opts.TablePrefix = custom.TablePrefix
+	opts.Schema = custom.Schema
+	opts.ChangeLog = custom.ChangeLog
+	opts.ChangeLogMaxLen = custom.ChangeLogMaxLen
+	opts.ChangeLogMaxAge = custom.ChangeLogMaxAge
+	opts.Backup = custom.Backup
 	return &opts
 }
 
+// checkOptions checks the options before opening
+// the database, so that an invalid option does not
+// leave the database handles open.
+func checkOptions(opts *Options) error {
+	return checkPolicy(opts.EvictionPolicy)
+}
+
 // newSQLOptions creates SQL options from options.
 // Infers the SQL dialect from the driver name.
 func newSQLOptions(opts *Options) *sqlx.Options {
@@ -415,5 +814,11 @@ func newSQLOptions(opts *Options) *sqlx.Options {
 		Pragma:   opts.Pragma,
 		Timeout:  opts.Timeout,
 		ReadOnly: opts.readOnly,
+
+		GroupCommit: opts.GroupCommit,
+		NoPrepare:   opts.NoPrepare,
+		TablePrefix: opts.TablePrefix,
+		Schema:      opts.Schema,
+		Logger:      opts.Logger,
 	}
 }
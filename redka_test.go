@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -57,8 +59,13 @@ func ExampleOpenRead() {
 	// Error handling is omitted for brevity.
 	// In real code, always check for errors.
 
+	// Keep the database in a temporary directory.
+	dir, _ := os.MkdirTemp("", "redka")
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "redka.db")
+
 	// Open a writable database.
-	db, err := redka.Open("redka.db", nil)
+	db, err := redka.Open(path, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -66,7 +73,7 @@ func ExampleOpenRead() {
 	_ = db.Close()
 
 	// Open a read-only database.
-	db, err = redka.OpenRead("redka.db", nil)
+	db, err = redka.OpenRead(path, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -418,6 +425,40 @@ func TestRollback(t *testing.T) {
 	be.Equal(t, age.MustInt(), 25)
 }
 
+func TestSavepoint(t *testing.T) {
+	db := testx.OpenDB(t)
+
+	var keys []string
+	cancel := db.OnChange(func(ev redka.ChangeEvent) {
+		keys = append(keys, ev.Key)
+	})
+	defer cancel()
+
+	var errRollback = errors.New("rollback")
+
+	err := db.Update(func(tx *redka.Tx) error {
+		_ = tx.Str().Set("name", "alice")
+		err := tx.Savepoint(func(tx *redka.Tx) error {
+			_ = tx.Str().Set("name", "bob")
+			_ = tx.Str().Set("age", 50)
+			return errRollback
+		})
+		be.Equal(t, err, errRollback)
+		return tx.Savepoint(func(tx *redka.Tx) error {
+			return tx.Str().Set("city", "paris")
+		})
+	})
+	be.Err(t, err, nil)
+
+	name, _ := db.Str().Get("name")
+	be.Equal(t, name.String(), "alice")
+	exists, _ := db.Key().Exists("age")
+	be.Equal(t, exists, false)
+	city, _ := db.Str().Get("city")
+	be.Equal(t, city.String(), "paris")
+	be.Equal(t, keys, []string{"name", "city"})
+}
+
 func TestTimeout(t *testing.T) {
 	opts := &redka.Options{Timeout: time.Nanosecond}
 	db, err := redka.Open("file:/redka.db?vfs=memdb", opts)
@@ -426,3 +467,191 @@ func TestTimeout(t *testing.T) {
 	err = db.Str().Set("name", "alice")
 	be.Err(t, err, context.DeadlineExceeded)
 }
+
+func TestWithTrace(t *testing.T) {
+	db := testx.OpenDB(t)
+
+	trace := new(redka.Trace)
+	tdb := db.WithTrace(trace)
+	query, dur := trace.Slowest()
+	be.Equal(t, query, "")
+	be.Equal(t, dur, time.Duration(0))
+
+	err := tdb.Str().Set("name", "alice")
+	be.Err(t, err, nil)
+	query, _ = trace.Slowest()
+	be.True(t, query != "")
+
+	trace.Reset()
+	err = tdb.View(func(tx *redka.Tx) error {
+		_, err := tx.Str().Get("name")
+		return err
+	})
+	be.Err(t, err, nil)
+	query, _ = trace.Slowest()
+	be.True(t, query != "")
+
+	// The traced database shares the data and settings.
+	name, err := db.Str().Get("name")
+	be.Err(t, err, nil)
+	be.Equal(t, name.String(), "alice")
+	db.SetTimeout(time.Second)
+	be.Equal(t, tdb.Timeout(), time.Second)
+
+	// The traced database keeps the change log setting.
+	cdb := testx.OpenDBWith(t, &redka.Options{ChangeLog: true})
+	be.True(t, cdb.WithTrace(trace).ChangeLog())
+}
+
+func TestStats(t *testing.T) {
+	db := testx.OpenDB(t)
+	stats := db.Stats()
+	be.Equal(t, stats.ExpiredKeys, int64(0))
+
+	_ = db.Str().SetExpire("name", "alice", time.Millisecond)
+	_ = db.Str().Set("age", 25)
+	db.SetExpireInterval(5 * time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	stats = db.Stats()
+	be.Equal(t, stats.ExpiredKeys, int64(1))
+	be.True(t, stats.RW.OpenConnections > 0)
+	be.True(t, stats.RO.OpenConnections > 0)
+
+	// The traced database shares the stats.
+	tdb := db.WithTrace(new(redka.Trace))
+	be.Equal(t, tdb.Stats().ExpiredKeys, int64(1))
+}
+
+func TestNoPrepare(t *testing.T) {
+	db := testx.OpenDBWith(t, &redka.Options{NoPrepare: true})
+	err := db.Str().Set("name", "alice")
+	be.Err(t, err, nil)
+	err = db.Update(func(tx *redka.Tx) error {
+		_, err := tx.Str().Incr("age", 25)
+		return err
+	})
+	be.Err(t, err, nil)
+	name, err := db.Str().Get("name")
+	be.Err(t, err, nil)
+	be.Equal(t, name.String(), "alice")
+	age, err := db.Str().Get("age")
+	be.Err(t, err, nil)
+	be.Equal(t, age.String(), "25")
+}
+
+// Run with -tags=sqlite3 or -tags=postgres
+// to compare the dialects.
+func BenchmarkPrepare(b *testing.B) {
+	for _, mode := range []string{"prepared", "plain"} {
+		opts := &redka.Options{NoPrepare: mode == "plain"}
+		b.Run(mode+"/get", func(b *testing.B) {
+			db := testx.OpenDBWith(b, opts)
+			_ = db.Str().Set("name", "alice")
+			b.ResetTimer()
+			for range b.N {
+				_, err := db.Str().Get("name")
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(mode+"/set", func(b *testing.B) {
+			db := testx.OpenDBWith(b, opts)
+			b.ResetTimer()
+			for i := range b.N {
+				err := db.Str().Set("name", i)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(mode+"/hset", func(b *testing.B) {
+			db := testx.OpenDBWith(b, opts)
+			b.ResetTimer()
+			for i := range b.N {
+				_, err := db.Hash().Set("person", "age", i)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(mode+"/zadd", func(b *testing.B) {
+			db := testx.OpenDBWith(b, opts)
+			b.ResetTimer()
+			for i := range b.N {
+				_, err := db.ZSet().Add("rating", i%100, float64(i))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestIsolated(t *testing.T) {
+	// isolated returns the options that keep the instance
+	// apart from the others in the same database.
+	isolated := func(name string) *redka.Options {
+		if testx.Driver() == "postgres" {
+			return &redka.Options{Schema: name}
+		}
+		return &redka.Options{TablePrefix: name + "_"}
+	}
+	db := testx.OpenDB(t)
+	db1 := testx.OpenDBWith(t, isolated("one"))
+	db2 := testx.OpenDBWith(t, isolated("two"))
+
+	t.Run("strings", func(t *testing.T) {
+		_ = db1.Str().Set("name", "alice")
+		_ = db2.Str().Set("name", "bob")
+		name, _ := db1.Str().Get("name")
+		be.Equal(t, name.String(), "alice")
+		name, _ = db2.Str().Get("name")
+		be.Equal(t, name.String(), "bob")
+		_, err := db.Str().Get("name")
+		be.Err(t, err, redka.ErrNotFound)
+	})
+	t.Run("collections", func(t *testing.T) {
+		_, _ = db1.Hash().Set("person", "age", 25)
+		_, _ = db1.List().PushBack("queue", "task")
+		_, _ = db1.Set().Add("tags", "go")
+		_, _ = db1.ZSet().Add("rating", "alice", 11)
+
+		_, err := db2.Hash().Get("person", "age")
+		be.Err(t, err, redka.ErrNotFound)
+		n, _ := db2.List().Len("queue")
+		be.Equal(t, n, 0)
+		n, _ = db2.Set().Len("tags")
+		be.Equal(t, n, 0)
+		n, _ = db2.ZSet().Len("rating")
+		be.Equal(t, n, 0)
+
+		// The triggers update the prefixed key table.
+		n, _ = db1.Hash().Len("person")
+		be.Equal(t, n, 1)
+		n, _ = db1.List().Len("queue")
+		be.Equal(t, n, 1)
+		n, _ = db1.Set().Len("tags")
+		be.Equal(t, n, 1)
+		n, _ = db1.ZSet().Len("rating")
+		be.Equal(t, n, 1)
+	})
+	t.Run("type mismatch", func(t *testing.T) {
+		_, err := db1.Hash().Set("name", "first", "alice")
+		be.Err(t, err, redka.ErrKeyType)
+	})
+	t.Run("keys", func(t *testing.T) {
+		keys, err := db2.Key().Keys("*")
+		be.Err(t, err, nil)
+		be.Equal(t, len(keys), 1)
+		be.Equal(t, keys[0].Key, "name")
+
+		err = db1.Key().DeleteAll()
+		be.Err(t, err, nil)
+		n, _ := db1.Key().Len()
+		be.Equal(t, n, 0)
+		n, _ = db2.Key().Len()
+		be.Equal(t, n, 1)
+	})
+}
@@ -0,0 +1,306 @@
+package redsrv
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+	"github.com/tidwall/redcon"
+)
+
+// ClientInfo describes a connected client.
+type ClientInfo = redis.ClientInfo
+
+// Clients is a registry of connected clients.
+// The server adds a client when it accepts a connection,
+// and removes it when the connection is closed.
+//
+// Clients is safe for concurrent use by multiple goroutines.
+type Clients struct {
+	mu     sync.Mutex
+	lastID int64
+	conns  map[redcon.Conn]*client
+	byID   map[int64]*client
+}
+
+// client is a connected client.
+type client struct {
+	id         int64
+	conn       redcon.Conn
+	addr       string
+	laddr      string
+	name       string
+	created    time.Time
+	lastActive time.Time
+	lastCmd    string
+	inMulti    bool
+	queued     int
+	noEvict    bool
+	replyOff   bool // CLIENT REPLY OFF
+	skipNext   bool // CLIENT REPLY SKIP
+	skipCur    bool // skip the reply to the current command
+	killed     bool // CLIENT KILL, the connection is to be closed
+}
+
+// newClients creates an empty client registry.
+func newClients() *Clients {
+	return &Clients{
+		conns: map[redcon.Conn]*client{},
+		byID:  map[int64]*client{},
+	}
+}
+
+// Get returns the client with the given ID.
+func (cs *Clients) Get(id int64) (ClientInfo, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	c, ok := cs.byID[id]
+	if !ok {
+		return ClientInfo{}, false
+	}
+	return c.info(time.Now()), true
+}
+
+// List returns all connected clients ordered by ID.
+func (cs *Clients) List() []ClientInfo {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	now := time.Now()
+	infos := make([]ClientInfo, 0, len(cs.byID))
+	for _, c := range cs.byID {
+		infos = append(infos, c.info(now))
+	}
+	slices.SortFunc(infos, func(a, b ClientInfo) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return infos
+}
+
+// Len returns the number of connected clients.
+func (cs *Clients) Len() int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return len(cs.byID)
+}
+
+// Kill marks the client connection for closing and removes
+// the client from the registry. Returns false if there is
+// no such client.
+//
+// The connection is closed by its own handler (see [Clients.killed]),
+// since closing it here would race with the handler writing
+// the replies. Kill only interrupts the pending read,
+// so that an idle connection closes right away.
+func (cs *Clients) Kill(id int64) bool {
+	cs.mu.Lock()
+	c, ok := cs.byID[id]
+	if ok {
+		c.killed = true
+		delete(cs.byID, c.id)
+	}
+	cs.mu.Unlock()
+	if !ok {
+		return false
+	}
+	if nc := c.conn.NetConn(); nc != nil {
+		_ = nc.SetReadDeadline(time.Now())
+	}
+	return true
+}
+
+// SetName sets the client connection name.
+func (cs *Clients) SetName(id int64, name string) {
+	cs.update(id, func(c *client) { c.name = name })
+}
+
+// SetNoEvict sets the client no-evict mode.
+func (cs *Clients) SetNoEvict(id int64, on bool) {
+	cs.update(id, func(c *client) { c.noEvict = on })
+}
+
+// SetReply sets the client reply mode.
+func (cs *Clients) SetReply(id int64, mode redis.ReplyMode) {
+	cs.update(id, func(c *client) {
+		c.replyOff = mode == redis.ReplyOff
+		c.skipNext = mode == redis.ReplySkip
+		c.skipCur = false
+	})
+}
+
+// add registers a new client connection.
+// Returns the existing client if the connection is already
+// registered (even if the client is killed).
+func (cs *Clients) add(conn redcon.Conn) *client {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if c, ok := cs.conns[conn]; ok {
+		return c
+	}
+	cs.lastID++
+	now := time.Now()
+	c := &client{
+		id:         cs.lastID,
+		conn:       conn,
+		addr:       conn.RemoteAddr(),
+		created:    now,
+		lastActive: now,
+	}
+	if nc := conn.NetConn(); nc != nil {
+		c.laddr = nc.LocalAddr().String()
+	}
+	cs.conns[conn] = c
+	cs.byID[c.id] = c
+	return c
+}
+
+// remove unregisters a closed client connection.
+func (cs *Clients) remove(conn redcon.Conn) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	c, ok := cs.conns[conn]
+	if !ok {
+		return
+	}
+	delete(cs.conns, conn)
+	delete(cs.byID, c.id)
+}
+
+// begin marks the start of the command processing.
+// Returns false if the reply to the command should be discarded.
+func (cs *Clients) begin(c *client, name string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	c.lastCmd = name
+	if c.skipNext {
+		c.skipNext = false
+		c.skipCur = true
+	}
+	return c.replies()
+}
+
+// end marks the end of the command processing.
+func (cs *Clients) end(c *client, state *connState) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	c.lastActive = time.Now()
+	c.inMulti = state.inMulti
+	c.queued = 0
+	if state.inMulti {
+		c.queued = len(state.cmds)
+	}
+	c.skipCur = false
+}
+
+// killed reports whether the client is killed,
+// so its connection should be closed.
+func (cs *Clients) killed(c *client) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return c.killed
+}
+
+// replies reports whether the client should receive
+// the reply to the current command.
+func (cs *Clients) replies(c *client) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return c.replies()
+}
+
+// update changes the client with the given ID, if any.
+func (cs *Clients) update(id int64, f func(c *client)) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if c, ok := cs.byID[id]; ok {
+		f(c)
+	}
+}
+
+// info returns the client description at the given time.
+func (c *client) info(now time.Time) ClientInfo {
+	return ClientInfo{
+		ID:        c.id,
+		Addr:      c.addr,
+		LocalAddr: c.laddr,
+		Name:      c.name,
+		User:      redis.DefaultUser,
+		Age:       now.Sub(c.created),
+		Idle:      now.Sub(c.lastActive),
+		LastCmd:   c.lastCmd,
+		InMulti:   c.inMulti,
+		Queued:    c.queued,
+		NoEvict:   c.noEvict,
+	}
+}
+
+// replies reports whether the client should receive
+// the reply to the current command.
+func (c *client) replies() bool {
+	return !c.replyOff && !c.skipCur
+}
+
+// mutedConn is a connection that discards the replies
+// while the client has them turned off.
+type mutedConn struct {
+	redcon.Conn
+	clients *Clients
+	client  *client
+}
+
+func (c mutedConn) WriteError(msg string) {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteError(msg)
+	}
+}
+func (c mutedConn) WriteString(str string) {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteString(str)
+	}
+}
+func (c mutedConn) WriteBulk(bulk []byte) {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteBulk(bulk)
+	}
+}
+func (c mutedConn) WriteBulkString(bulk string) {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteBulkString(bulk)
+	}
+}
+func (c mutedConn) WriteInt(num int) {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteInt(num)
+	}
+}
+func (c mutedConn) WriteInt64(num int64) {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteInt64(num)
+	}
+}
+func (c mutedConn) WriteUint64(num uint64) {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteUint64(num)
+	}
+}
+func (c mutedConn) WriteArray(count int) {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteArray(count)
+	}
+}
+func (c mutedConn) WriteNull() {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteNull()
+	}
+}
+func (c mutedConn) WriteRaw(data []byte) {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteRaw(data)
+	}
+}
+func (c mutedConn) WriteAny(v any) {
+	if c.clients.replies(c.client) {
+		c.Conn.WriteAny(v)
+	}
+}
@@ -0,0 +1,163 @@
+package redsrv
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/testx"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+	"github.com/tidwall/redcon"
+)
+
+func TestClients(t *testing.T) {
+	t.Run("add", func(t *testing.T) {
+		clients := newClients()
+		c1 := clients.add(new(fakeConn))
+		c2 := clients.add(new(fakeConn))
+		be.Equal(t, c1.id, int64(1))
+		be.Equal(t, c2.id, int64(2))
+		be.Equal(t, clients.Len(), 2)
+
+		info, ok := clients.Get(2)
+		be.True(t, ok)
+		be.Equal(t, info.ID, int64(2))
+		be.Equal(t, info.User, redis.DefaultUser)
+	})
+	t.Run("add twice", func(t *testing.T) {
+		clients := newClients()
+		conn := new(fakeConn)
+		c1 := clients.add(conn)
+		c2 := clients.add(conn)
+		be.Equal(t, c1, c2)
+		be.Equal(t, clients.Len(), 1)
+	})
+	t.Run("remove", func(t *testing.T) {
+		clients := newClients()
+		conn := new(fakeConn)
+		clients.add(conn)
+		clients.remove(conn)
+		be.Equal(t, clients.Len(), 0)
+		_, ok := clients.Get(1)
+		be.Equal(t, ok, false)
+	})
+	t.Run("list", func(t *testing.T) {
+		clients := newClients()
+		for range 5 {
+			clients.add(new(fakeConn))
+		}
+		infos := clients.List()
+		be.Equal(t, len(infos), 5)
+		for i, info := range infos {
+			be.Equal(t, info.ID, int64(i+1))
+		}
+	})
+	t.Run("kill", func(t *testing.T) {
+		clients := newClients()
+		conn := new(fakeConn)
+		c := clients.add(conn)
+		be.True(t, clients.Kill(1))
+		// The connection is closed by its own handler.
+		be.Equal(t, conn.closed, false)
+		be.True(t, clients.killed(c))
+		be.Equal(t, clients.Len(), 0)
+		be.Equal(t, clients.Kill(1), false)
+
+		// The killed connection stays killed until it's closed.
+		be.Equal(t, clients.add(conn), c)
+		clients.remove(conn)
+		be.True(t, clients.add(conn) != c)
+	})
+}
+
+func TestClientTracking(t *testing.T) {
+	db := testx.OpenDB(t)
+	srv := newTestState(db)
+	clients := srv.clients
+	mux := createHandlers(db, srv)
+	conn := new(fakeConn)
+	serve := func(args ...string) string {
+		conn.parts = nil
+		mux.ServeRESP(conn, buildCommand(args...))
+		return conn.out()
+	}
+
+	t.Run("register", func(t *testing.T) {
+		be.Equal(t, serve("client", "id"), "1")
+		be.Equal(t, clients.Len(), 1)
+	})
+	t.Run("last command", func(t *testing.T) {
+		serve("echo", "hello")
+		info, _ := clients.Get(1)
+		be.Equal(t, info.LastCmd, "echo")
+	})
+	t.Run("multi", func(t *testing.T) {
+		serve("multi")
+		serve("set", "name", "alice")
+		info, _ := clients.Get(1)
+		be.True(t, info.InMulti)
+		be.Equal(t, info.Queued, 1)
+
+		serve("exec")
+		info, _ = clients.Get(1)
+		be.Equal(t, info.InMulti, false)
+		be.Equal(t, info.Queued, 0)
+	})
+	t.Run("setname", func(t *testing.T) {
+		be.Equal(t, serve("client", "setname", "api"), "OK")
+		info, _ := clients.Get(1)
+		be.Equal(t, info.Name, "api")
+	})
+	t.Run("reply off", func(t *testing.T) {
+		be.Equal(t, serve("client", "reply", "off"), "")
+		be.Equal(t, serve("echo", "hello"), "")
+		be.Equal(t, serve("get", "name", "age"), "")
+		be.Equal(t, serve("client", "reply", "on"), "OK")
+		be.Equal(t, serve("echo", "hello"), "hello")
+	})
+	t.Run("reply skip", func(t *testing.T) {
+		be.Equal(t, serve("client", "reply", "skip"), "")
+		be.Equal(t, serve("echo", "one"), "")
+		be.Equal(t, serve("echo", "two"), "two")
+	})
+	t.Run("kill", func(t *testing.T) {
+		other := new(fakeConn)
+		mux.ServeRESP(other, buildCommand("echo", "hello"))
+		be.Equal(t, clients.Len(), 2)
+
+		be.Equal(t, serve("client", "kill", "id", "2"), "1")
+		be.Equal(t, other.closed, false)
+
+		// The killed client closes its connection
+		// instead of running the next command.
+		other.parts = nil
+		mux.ServeRESP(other, buildCommand("echo", "hello"))
+		be.True(t, other.closed)
+		be.Equal(t, other.out(), "")
+	})
+	t.Run("kill self", func(t *testing.T) {
+		be.Equal(t, serve("client", "kill", "id", "1", "skipme", "no"), "1")
+		be.True(t, conn.closed)
+	})
+}
+
+func TestClientKillIdle(t *testing.T) {
+	db := testx.OpenDB(t)
+	srv := startTestServer(t, db)
+	killer := dialTestServer(t, srv)
+	victim := dialTestServer(t, srv)
+	id := doString(t, victim, "client", "id")
+
+	// The idle connection is closed right away.
+	be.Equal(t, doString(t, killer, "client", "kill", "id", id), "1")
+	_, err := victim.do("ping")
+	be.True(t, err != nil)
+	be.Equal(t, doString(t, killer, "ping"), "PONG")
+}
+
+func buildCommand(args ...string) redcon.Command {
+	cmd := redcon.Command{Args: make([][]byte, len(args))}
+	for i, arg := range args {
+		cmd.Args[i] = []byte(arg)
+	}
+	return cmd
+}
@@ -0,0 +1,334 @@
+package redsrv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Configuration errors.
+var (
+	ErrConfigImmutable = errors.New("ERR can't set immutable config")
+	ErrConfigNoFile    = errors.New("ERR the server is running without a config file")
+	ErrConfigUnknown   = errors.New("ERR unknown option")
+	ErrConfigValue     = errors.New("ERR invalid config value")
+)
+
+// ConfigParam is a runtime configuration parameter.
+// Create parameters with [IntParam], [BoolParam],
+// [EnumParam], [StringParam] or [ReadOnlyParam].
+type ConfigParam struct {
+	name string
+	get  func() string
+	set  func(value string) error // nil for immutable parameters
+	def  string                   // value at registration time
+}
+
+// Name returns the parameter name.
+func (p ConfigParam) Name() string {
+	return p.name
+}
+
+// IntParam creates an integer parameter with
+// values in the [min, max] range.
+func IntParam(name string, min, max int, get func() int, set func(int)) ConfigParam {
+	return ConfigParam{
+		name: name,
+		get: func() string {
+			return strconv.Itoa(get())
+		},
+		set: func(value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < min || n > max {
+				return fmt.Errorf("%w '%s' for '%s' (must be in range %d-%d)",
+					ErrConfigValue, value, name, min, max)
+			}
+			set(n)
+			return nil
+		},
+	}
+}
+
+// BoolParam creates a boolean parameter
+// with "yes" and "no" values.
+func BoolParam(name string, get func() bool, set func(bool)) ConfigParam {
+	return ConfigParam{
+		name: name,
+		get: func() string {
+			if get() {
+				return "yes"
+			}
+			return "no"
+		},
+		set: func(value string) error {
+			switch strings.ToLower(value) {
+			case "yes":
+				set(true)
+			case "no":
+				set(false)
+			default:
+				return fmt.Errorf("%w '%s' for '%s' (must be yes or no)",
+					ErrConfigValue, value, name)
+			}
+			return nil
+		},
+	}
+}
+
+// EnumParam creates a string parameter with
+// a fixed set of allowed values.
+func EnumParam(name string, values []string, get func() string, set func(string)) ConfigParam {
+	return ConfigParam{
+		name: name,
+		get:  get,
+		set: func(value string) error {
+			value = strings.ToLower(value)
+			if !slices.Contains(values, value) {
+				return fmt.Errorf("%w '%s' for '%s' (must be one of %s)",
+					ErrConfigValue, value, name, strings.Join(values, ", "))
+			}
+			set(value)
+			return nil
+		},
+	}
+}
+
+// StringParam creates a string parameter
+// validated by the set function.
+func StringParam(name string, get func() string, set func(string) error) ConfigParam {
+	return ConfigParam{
+		name: name,
+		get:  get,
+		set: func(value string) error {
+			if err := set(value); err != nil {
+				return fmt.Errorf("%w '%s' for '%s' (%v)",
+					ErrConfigValue, value, name, err)
+			}
+			return nil
+		},
+	}
+}
+
+// ReadOnlyParam creates a parameter that can't be changed at runtime.
+func ReadOnlyParam(name string, get func() string) ConfigParam {
+	return ConfigParam{name: name, get: get}
+}
+
+// Config is a runtime configuration registry.
+// Parameters are registered by the server and the embedding
+// application, and are changed with the CONFIG SET command
+// or the [Config.Set] method.
+//
+// Config is safe for concurrent use by multiple goroutines.
+type Config struct {
+	mu     sync.Mutex
+	params map[string]ConfigParam
+	resets []func()
+	path   string // config file path
+}
+
+// newConfig creates an empty configuration registry.
+func newConfig() *Config {
+	return &Config{params: map[string]ConfigParam{}}
+}
+
+// Register adds parameters to the registry.
+// Remembers the current parameter values as defaults.
+// Replaces the existing parameters with the same names.
+func (c *Config) Register(params ...ConfigParam) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range params {
+		p.name = strings.ToLower(p.name)
+		p.def = p.get()
+		c.params[p.name] = p
+	}
+}
+
+// OnResetStat registers a function to be called
+// on CONFIG RESETSTAT to reset the server statistics.
+func (c *Config) OnResetStat(f func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resets = append(c.resets, f)
+}
+
+// Get returns the values of the parameters
+// with names matching a glob-style pattern.
+func (c *Config) Get(pattern string) map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pattern = strings.ToLower(pattern)
+	values := map[string]string{}
+	for name, p := range c.params {
+		if ok, _ := path.Match(pattern, name); ok {
+			values[name] = p.get()
+		}
+	}
+	return values
+}
+
+// Set validates and applies new parameter values.
+// Either all of the values are applied, or none of them.
+func (c *Config) Set(params map[string]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.set(params)
+}
+
+// ResetStat resets the server statistics.
+func (c *Config) ResetStat() {
+	c.mu.Lock()
+	resets := slices.Clone(c.resets)
+	c.mu.Unlock()
+	for _, reset := range resets {
+		reset()
+	}
+}
+
+// Load reads the configuration file and applies its parameters.
+// The file consists of "name value" lines, empty lines and
+// comments starting with #. Remembers the file path for
+// CONFIG REWRITE.
+func (c *Config) Load(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	params := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, value, ok := parseConfigLine(scanner.Text())
+		if ok {
+			params[name] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.set(params); err != nil {
+		return err
+	}
+	c.path = filename
+	return nil
+}
+
+// Rewrite writes the current configuration to the file
+// the configuration was loaded from. Keeps the comments and
+// the order of the existing lines, updates the values of the
+// existing parameters and appends the changed ones.
+func (c *Config) Rewrite() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.path == "" {
+		return ErrConfigNoFile
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	// Update the existing lines.
+	var lines []string
+	written := map[string]bool{}
+	content := strings.TrimSuffix(string(data), "\n")
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		name, _, ok := parseConfigLine(line)
+		p, known := c.params[name]
+		if !ok || !known || p.set == nil {
+			lines = append(lines, line)
+			continue
+		}
+		if written[name] {
+			// Drop the duplicate lines.
+			continue
+		}
+		lines = append(lines, formatConfigLine(name, p.get()))
+		written[name] = true
+	}
+
+	// Append the changed parameters.
+	var names []string
+	for name, p := range c.params {
+		if p.set != nil && !written[name] && p.get() != p.def {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		lines = append(lines, formatConfigLine(name, c.params[name].get()))
+	}
+
+	// Replace the file atomically.
+	tmp := c.path + ".tmp"
+	content = strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// set applies the parameter values or rolls them back on failure.
+func (c *Config) set(params map[string]string) error {
+	// Validate the names before applying anything.
+	for name := range params {
+		p, ok := c.params[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("%w '%s'", ErrConfigUnknown, name)
+		}
+		if p.set == nil {
+			return fmt.Errorf("%w '%s'", ErrConfigImmutable, name)
+		}
+	}
+
+	// Apply the values, restoring the old ones on failure.
+	old := map[string]string{}
+	for name, value := range params {
+		p := c.params[strings.ToLower(name)]
+		prev := p.get()
+		if err := p.set(value); err != nil {
+			for pname, pvalue := range old {
+				_ = c.params[pname].set(pvalue)
+			}
+			return err
+		}
+		old[p.name] = prev
+	}
+	return nil
+}
+
+// parseConfigLine parses a "name value" configuration line.
+// Returns false for empty lines and comments.
+func parseConfigLine(line string) (name, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	name, value, _ = strings.Cut(line, " ")
+	value = strings.TrimSpace(value)
+	if unq, err := strconv.Unquote(value); err == nil {
+		value = unq
+	}
+	return strings.ToLower(name), value, true
+}
+
+// formatConfigLine formats a "name value" configuration line.
+func formatConfigLine(name, value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\"'#") {
+		value = strconv.Quote(value)
+	}
+	return name + " " + value
+}
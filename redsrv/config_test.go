@@ -0,0 +1,182 @@
+package redsrv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestConfig(t *testing.T) {
+	t.Run("get", func(t *testing.T) {
+		config, _ := newTestConfig()
+		be.Equal(t, config.Get("*"), map[string]string{
+			"level": "info", "limit": "10", "verbose": "no", "version": "1",
+		})
+		be.Equal(t, config.Get("l*"), map[string]string{
+			"level": "info", "limit": "10",
+		})
+		be.Equal(t, config.Get("LIMIT"), map[string]string{"limit": "10"})
+		be.Equal(t, config.Get("unknown"), map[string]string{})
+	})
+	t.Run("set", func(t *testing.T) {
+		config, vals := newTestConfig()
+		err := config.Set(map[string]string{
+			"level": "WARN", "limit": "20", "verbose": "yes",
+		})
+		be.Err(t, err, nil)
+		be.Equal(t, vals.level, "warn")
+		be.Equal(t, vals.limit, 20)
+		be.Equal(t, vals.verbose, true)
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		config, vals := newTestConfig()
+		err := config.Set(map[string]string{"limit": "200"})
+		be.True(t, errors.Is(err, ErrConfigValue))
+		err = config.Set(map[string]string{"level": "trace"})
+		be.True(t, errors.Is(err, ErrConfigValue))
+		err = config.Set(map[string]string{"verbose": "maybe"})
+		be.True(t, errors.Is(err, ErrConfigValue))
+		be.Equal(t, vals.limit, 10)
+		be.Equal(t, vals.level, "info")
+		be.Equal(t, vals.verbose, false)
+	})
+	t.Run("rollback", func(t *testing.T) {
+		config, vals := newTestConfig()
+		for range 10 {
+			// Map iteration order is random, so repeat
+			// to make sure the rollback works in any order.
+			err := config.Set(map[string]string{"level": "warn", "limit": "-1"})
+			be.True(t, errors.Is(err, ErrConfigValue))
+			be.Equal(t, vals.level, "info")
+			be.Equal(t, vals.limit, 10)
+		}
+	})
+	t.Run("unknown", func(t *testing.T) {
+		config, vals := newTestConfig()
+		err := config.Set(map[string]string{"limit": "20", "unknown": "1"})
+		be.True(t, errors.Is(err, ErrConfigUnknown))
+		be.Equal(t, vals.limit, 10)
+	})
+	t.Run("immutable", func(t *testing.T) {
+		config, _ := newTestConfig()
+		err := config.Set(map[string]string{"version": "2"})
+		be.True(t, errors.Is(err, ErrConfigImmutable))
+	})
+	t.Run("resetstat", func(t *testing.T) {
+		config, _ := newTestConfig()
+		var nResets int
+		config.OnResetStat(func() { nResets++ })
+		config.ResetStat()
+		config.ResetStat()
+		be.Equal(t, nResets, 2)
+	})
+}
+
+func TestConfigFile(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		path := writeConfigFile(t, "# test config\nlimit 42\n\nlevel \"debug\"\n")
+		config, vals := newTestConfig()
+		err := config.Load(path)
+		be.Err(t, err, nil)
+		be.Equal(t, vals.limit, 42)
+		be.Equal(t, vals.level, "debug")
+	})
+	t.Run("load unknown", func(t *testing.T) {
+		path := writeConfigFile(t, "limit 42\nunknown 1\n")
+		config, vals := newTestConfig()
+		err := config.Load(path)
+		be.True(t, errors.Is(err, ErrConfigUnknown))
+		be.Equal(t, vals.limit, 10)
+	})
+	t.Run("rewrite", func(t *testing.T) {
+		path := writeConfigFile(t, "# test config\nlimit 42\nlimit 43\n")
+		config, _ := newTestConfig()
+		err := config.Load(path)
+		be.Err(t, err, nil)
+		err = config.Set(map[string]string{"limit": "50", "verbose": "yes"})
+		be.Err(t, err, nil)
+
+		err = config.Rewrite()
+		be.Err(t, err, nil)
+		data, err := os.ReadFile(path)
+		be.Err(t, err, nil)
+		be.Equal(t, string(data), "# test config\nlimit 50\nverbose yes\n")
+	})
+	t.Run("rewrite without file", func(t *testing.T) {
+		config, _ := newTestConfig()
+		err := config.Rewrite()
+		be.Equal(t, err, ErrConfigNoFile)
+	})
+}
+
+func TestServerConfig(t *testing.T) {
+	db := testx.OpenDB(t)
+	config := newConfig()
+	slowlog := newSlowLog()
+	batcher := newBatcher()
+	registerConfig(config, db, slowlog, newSaver(db), batcher)
+
+	be.Equal(t, config.Get("databases"), map[string]string{"databases": "1"})
+	err := config.Set(map[string]string{
+		"db-timeout":              "1500",
+		"expire-interval":         "30",
+		"maxbytes":                "1048576",
+		"maxkeys":                 "1000",
+		"maxmemory-policy":        "allkeys-lru",
+		"pipeline-batch-size":     "100",
+		"slowlog-log-slower-than": "-1",
+		"slowlog-max-len":         "64",
+		"track-access":            "yes",
+	})
+	be.Err(t, err, nil)
+	be.Equal(t, db.Timeout(), 1500*time.Millisecond)
+	be.Equal(t, db.ExpireInterval(), 30*time.Second)
+	be.Equal(t, db.MaxBytes(), int64(1048576))
+	be.Equal(t, db.MaxKeys(), 1000)
+	be.Equal(t, db.EvictionPolicy(), redka.AllKeysLRU)
+	be.Equal(t, batcher.Size(), 100)
+	be.Equal(t, slowlog.Threshold(), -time.Microsecond)
+	be.Equal(t, slowlog.MaxLen(), 64)
+	be.True(t, db.TrackAccess())
+}
+
+type testValues struct {
+	level   string
+	limit   int
+	verbose bool
+}
+
+func newTestConfig() (*Config, *testValues) {
+	vals := &testValues{level: "info", limit: 10}
+	config := newConfig()
+	config.Register(
+		EnumParam("level", []string{"debug", "info", "warn"},
+			func() string { return vals.level },
+			func(v string) { vals.level = v },
+		),
+		IntParam("limit", 0, 100,
+			func() int { return vals.limit },
+			func(v int) { vals.limit = v },
+		),
+		BoolParam("verbose",
+			func() bool { return vals.verbose },
+			func(v bool) { vals.verbose = v },
+		),
+		ReadOnlyParam("version", func() string { return "1" }),
+	)
+	return config, vals
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "redka.conf")
+	err := os.WriteFile(path, []byte(content), 0o644)
+	be.Err(t, err, nil)
+	return path
+}
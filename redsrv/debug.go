@@ -11,7 +11,9 @@ import (
 
 // DebugServer is a debug server with pprof endpoints.
 type DebugServer struct {
-	srv *http.Server
+	name string
+	srv  *http.Server
+	mux  *http.ServeMux
 }
 
 // NewDebug creates a new debug server.
@@ -23,16 +25,39 @@ func NewDebug(host string, port int) *DebugServer {
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	return &DebugServer{
+		name: "debug",
 		srv: &http.Server{
 			Addr:    net.JoinHostPort(host, strconv.Itoa(port)),
 			Handler: mux,
 		},
+		mux: mux,
 	}
 }
 
+// NewMetricsServer creates a new server that only
+// exposes the metrics at the /metrics endpoint.
+func NewMetricsServer(addr string, metrics http.Handler) *DebugServer {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	return &DebugServer{
+		name: "metrics",
+		srv: &http.Server{
+			Addr:    addr,
+			Handler: mux,
+		},
+		mux: mux,
+	}
+}
+
+// Handle registers an additional handler for the given pattern.
+// Must be called before the server is started.
+func (s *DebugServer) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
 // Start starts the debug server.
 func (s *DebugServer) Start() error {
-	slog.Info("starting debug server", "addr", s.srv.Addr)
+	slog.Info("starting "+s.name+" server", "addr", s.srv.Addr)
 	err := s.srv.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("serve: %w", err)
@@ -46,6 +71,6 @@ func (s *DebugServer) Stop() error {
 	if err != nil {
 		return fmt.Errorf("close: %w", err)
 	}
-	slog.Debug("debug server stopped", "addr", s.srv.Addr)
+	slog.Debug(s.name+" server stopped", "addr", s.srv.Addr)
 	return nil
 }
@@ -11,8 +11,8 @@ import (
 )
 
 // createHandlers returns the server command handlers.
-func createHandlers(db *redka.DB) redcon.HandlerFunc {
-	return logging(parse(multi(handle(db))), db.Log())
+func createHandlers(db *redka.DB, srv srvState) redcon.HandlerFunc {
+	return pipeline(logging(track(measure(slowlog(parse(readonly(oom(monitor(subscribe(multi(handle(db)), srv), srv), db), srv), srv), srv), srv.metrics), srv.clients), db.Log()), db, srv.batcher)
 }
 
 // logging logs the command processing time.
@@ -25,20 +25,232 @@ func logging(next redcon.HandlerFunc, log *slog.Logger) redcon.HandlerFunc {
 	}
 }
 
+// track updates the client registry, discards the replies
+// if the client has turned them off, and closes the connection
+// if the client is killed.
+func track(next redcon.HandlerFunc, clients *Clients) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		state := getState(conn)
+		if state.client == nil {
+			// Normally the client is registered when the server
+			// accepts the connection, but make sure it is.
+			state.client = clients.add(conn)
+		}
+		if clients.killed(state.client) {
+			// Killed before running the command (see [Clients.Kill]).
+			_ = conn.Close()
+			return
+		}
+		if !clients.begin(state.client, normName(cmd)) {
+			conn = mutedConn{Conn: conn, clients: clients, client: state.client}
+		}
+		next(conn, cmd)
+		clients.end(state.client, state)
+		if clients.killed(state.client) {
+			// Killed while running the command, e.g. by itself.
+			_ = conn.Close()
+		}
+	}
+}
+
+// measure updates the command metrics.
+func measure(next redcon.HandlerFunc, metrics *Metrics) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		econn := &errConn{Conn: conn}
+		start := time.Now()
+		next(econn, cmd)
+		dur := time.Since(start)
+		getState(conn).afterCommit(func() {
+			metrics.observe(normName(cmd), dur, econn.failed)
+		})
+	}
+}
+
+// slowlog records the commands that take longer than
+// the configured threshold, along with the slowest
+// SQL statement they executed.
+func slowlog(next redcon.HandlerFunc, srv srvState) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		state := getState(conn)
+		if state.trace != nil {
+			state.trace.Reset()
+		}
+
+		start := time.Now()
+		next(conn, cmd)
+		dur := time.Since(start)
+		if !srv.slowlog.isSlow(dur) {
+			return
+		}
+
+		entry := SlowLogEntry{
+			Time:     start,
+			Duration: dur,
+			Args:     slowLogArgs(cmd.Args),
+		}
+		if info, ok := srv.clients.Get(state.clientID()); ok {
+			entry.ClientAddr = info.Addr
+			entry.ClientName = info.Name
+		}
+		if state.trace != nil {
+			entry.Query, entry.QueryTime = state.trace.Slowest()
+		}
+		state.afterCommit(func() { srv.slowlog.add(entry) })
+	}
+}
+
 // parse parses the command arguments.
-func parse(next redcon.HandlerFunc) redcon.HandlerFunc {
+func parse(next redcon.HandlerFunc, srv redis.Server) redcon.HandlerFunc {
 	return func(conn redcon.Conn, cmd redcon.Command) {
-		pcmd, err := command.Parse(cmd.Args)
+		state := getState(conn)
+		pcmd, err := command.Parse(cmd.Args, srv, state.clientID())
 		if err != nil {
 			conn.WriteError(pcmd.Error(err))
 			return
 		}
-		state := getState(conn)
 		state.push(pcmd)
 		next(conn, cmd)
 	}
 }
 
+// readonly rejects the write commands if the server is a replica.
+// Write commands sent in MULTI are rejected without queuing.
+func readonly(next redcon.HandlerFunc, srv srvState) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		if srv.repl == nil || !srv.repl.isReplica() {
+			next(conn, cmd)
+			return
+		}
+		info, ok := command.Table.Get(normName(cmd))
+		if ok && info.HasFlag(redis.FlagWrite) {
+			getState(conn).pop()
+			conn.WriteError(redis.ErrReadOnly.Error())
+			return
+		}
+		next(conn, cmd)
+	}
+}
+
+// oom rejects the commands that may increase the database size
+// (denyoom) if the database is over the limits and the eviction
+// policy fails to free up space (see [redka.DB.Evict]).
+// Commands sent in MULTI are rejected without queuing.
+// Pipeline batches check the limits before they start (see [runBatch]).
+func oom(next redcon.HandlerFunc, db *redka.DB) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		state := getState(conn)
+		if state.tx != nil {
+			next(conn, cmd)
+			return
+		}
+		info, ok := command.Table.Get(normName(cmd))
+		if !ok || !info.HasFlag(redis.FlagDenyOOM) {
+			next(conn, cmd)
+			return
+		}
+		if err := db.Evict(); err != nil {
+			state.pop()
+			conn.WriteError(redis.ErrOOM.Error())
+			return
+		}
+		next(conn, cmd)
+	}
+}
+
+// monitor handles the MONITOR command and streams the processed
+// commands to the monitoring clients. Commands queued in MULTI
+// are streamed when the transaction is executed.
+func monitor(next redcon.HandlerFunc, srv srvState) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		name := normName(cmd)
+		state := getState(conn)
+		if name == "monitor" {
+			state.pop()
+			if state.inMulti {
+				conn.WriteError(redis.ErrMonitorInMulti.Error())
+				return
+			}
+			dconn := conn.Detach()
+			dconn.WriteString("OK")
+			if err := dconn.Flush(); err != nil {
+				_ = dconn.Close()
+				return
+			}
+			srv.monitors.add(dconn)
+			return
+		}
+
+		if !srv.monitors.active() {
+			next(conn, cmd)
+			return
+		}
+
+		line := formatMonitorLine(time.Now(), conn.RemoteAddr(), cmd.Args)
+		queued := state.inMulti && name != "multi" && name != "exec" && name != "discard"
+		next(conn, cmd)
+		if queued {
+			state.monitored = append(state.monitored, line)
+			return
+		}
+		if name == "exec" {
+			for _, qline := range state.monitored {
+				srv.monitors.feed(qline)
+			}
+		}
+		if name == "exec" || name == "discard" {
+			state.monitored = nil
+		}
+		state.afterCommit(func() { srv.monitors.feed(line) })
+	}
+}
+
+// subscribe handles the SUBSCRIBE and PSUBSCRIBE commands,
+// which switch the connection into the subscriber mode.
+// Once subscribed, the connection is detached and served
+// by the message broker until the client disconnects.
+func subscribe(next redcon.HandlerFunc, srv srvState) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		name := normName(cmd)
+		if name != "subscribe" && name != "psubscribe" &&
+			name != "unsubscribe" && name != "punsubscribe" {
+			next(conn, cmd)
+			return
+		}
+
+		state := getState(conn)
+		state.pop()
+		if state.inMulti {
+			conn.WriteError(redis.ErrSubscribeInMulti.Error())
+			return
+		}
+
+		channels := cmd.Args[1:]
+		switch name {
+		case "subscribe", "psubscribe":
+			for _, channel := range channels {
+				srv.broker.subscribe(conn, string(channel), name == "psubscribe")
+			}
+		case "unsubscribe", "punsubscribe":
+			// The connection is not subscribed to anything
+			// (otherwise the broker would handle the command),
+			// so reply with zero subscriptions.
+			if len(channels) == 0 {
+				conn.WriteArray(3)
+				conn.WriteBulkString(name)
+				conn.WriteNull()
+				conn.WriteInt(0)
+				return
+			}
+			for _, channel := range channels {
+				conn.WriteArray(3)
+				conn.WriteBulkString(name)
+				conn.WriteBulk(channel)
+				conn.WriteInt(0)
+			}
+		}
+	}
+}
+
 // multi handles the MULTI, EXEC, and DISCARD commands and delegates
 // the rest to the next handler either in multi or single mode.
 func multi(next redcon.HandlerFunc) redcon.HandlerFunc {
@@ -81,14 +293,18 @@ func multi(next redcon.HandlerFunc) redcon.HandlerFunc {
 	}
 }
 
-// handle processes the command in either multi or single mode.
+// handle processes the command in either multi, batch or single mode.
 func handle(db *redka.DB) redcon.HandlerFunc {
 	return func(conn redcon.Conn, cmd redcon.Command) {
 		state := getState(conn)
-		if state.inMulti {
-			handleMulti(conn, state, db)
-		} else {
-			handleSingle(conn, state, db)
+		tdb := state.traced(db)
+		switch {
+		case state.inMulti:
+			handleMulti(conn, state, tdb)
+		case state.tx != nil:
+			handleBatch(conn, state, tdb)
+		default:
+			handleSingle(conn, state, tdb)
 		}
 		state.clear()
 	}
@@ -112,6 +328,21 @@ func handleMulti(conn redcon.Conn, state *connState, db *redka.DB) {
 	}
 }
 
+// handleBatch processes a single command as part of a pipeline
+// batch (see [pipeline]). Runs the command in a savepoint of the batch
+// transaction, so that if it fails, the other commands are not affected.
+func handleBatch(conn redcon.Conn, state *connState, db *redka.DB) {
+	pcmd := state.pop()
+	err := state.tx.Savepoint(func(tx *redka.Tx) error {
+		_, err := pcmd.Run(conn, redis.RedkaTx(tx))
+		return err
+	})
+	if err != nil {
+		db.Log().Warn("run batch command", "client", conn.RemoteAddr(),
+			"name", pcmd.Name(), "err", err)
+	}
+}
+
 // handleSingle processes a single command.
 func handleSingle(conn redcon.Conn, state *connState, db *redka.DB) {
 	pcmd := state.pop()
@@ -1,19 +1,26 @@
 package redsrv
 
 import (
+	"io"
+	"log/slog"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
 	"github.com/nalgeon/redka/internal/testx"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
 	"github.com/tidwall/redcon"
 )
 
 func TestHandlers(t *testing.T) {
 	db := testx.OpenDB(t)
 
-	mux := createHandlers(db)
+	mux := createHandlers(db, newTestState(db))
 	tests := []struct {
 		cmd  redcon.Command
 		want string
@@ -25,6 +32,13 @@ func TestHandlers(t *testing.T) {
 			},
 			want: "hello",
 		},
+		{
+			cmd: redcon.Command{
+				Raw:  []byte("GET name age"),
+				Args: [][]byte{[]byte("GET"), []byte("name"), []byte("age")},
+			},
+			want: "ERR wrong number of arguments (get)",
+		},
 	}
 	for _, test := range tests {
 		conn := new(fakeConn)
@@ -35,15 +49,72 @@ func TestHandlers(t *testing.T) {
 	}
 }
 
+func TestOOM(t *testing.T) {
+	db := testx.OpenDBWith(t, &redka.Options{
+		ExpireInterval: 5 * time.Millisecond,
+		MaxKeys:        1,
+	})
+	_ = db.Str().Set("name", "alice")
+	_ = db.Str().Set("age", 25)
+	time.Sleep(20 * time.Millisecond)
+
+	mux := createHandlers(db, newTestState(db))
+	serve := func(cmds ...redcon.Command) string {
+		conn := new(fakeConn)
+		for _, cmd := range cmds {
+			mux.ServeRESP(conn, cmd)
+		}
+		return conn.out()
+	}
+
+	// The commands that may increase the database size are rejected.
+	be.Equal(t, serve(buildCommand("set", "city", "paris")), redis.ErrOOM.Error())
+	be.Equal(t, serve(
+		buildCommand("multi"),
+		buildCommand("set", "city", "paris"),
+		buildCommand("get", "name"),
+		buildCommand("exec"),
+	), "OK,"+redis.ErrOOM.Error()+",QUEUED,1,alice")
+
+	// Other commands are allowed.
+	be.Equal(t, serve(buildCommand("get", "name")), "alice")
+	be.Equal(t, serve(buildCommand("del", "name")), "1")
+
+	// Once the database is under the limits, the writes are allowed.
+	time.Sleep(20 * time.Millisecond)
+	be.Equal(t, serve(buildCommand("set", "city", "paris")), "OK")
+}
+
+func newTestState(db *redka.DB) srvState {
+	clients := newClients()
+	broker := newBroker(slog.Default())
+	broker.listen(db)
+	return srvState{
+		config:   newConfig(),
+		clients:  clients,
+		slowlog:  newSlowLog(),
+		monitors: newMonitors(slog.Default()),
+		metrics:  newMetrics(db, clients),
+		broker:   broker,
+		saver:    newSaver(db),
+		batcher:  newBatcher(),
+	}
+}
+
 type fakeConn struct {
-	parts []string
-	ctx   any
+	mu       sync.Mutex
+	parts    []string
+	ctx      any
+	closed   bool
+	dconn    *fakeDetachedConn
+	pipeline []redcon.Command
 }
 
 func (c *fakeConn) RemoteAddr() string {
 	return ""
 }
 func (c *fakeConn) Close() error {
+	c.closed = true
 	return nil
 }
 func (c *fakeConn) WriteError(msg string) {
@@ -87,20 +158,54 @@ func (c *fakeConn) SetContext(v interface{}) {
 }
 func (c *fakeConn) SetReadBuffer(bytes int) {}
 func (c *fakeConn) Detach() redcon.DetachedConn {
-	return nil
+	c.dconn = &fakeDetachedConn{
+		fakeConn: c,
+		in:       make(chan redcon.Command),
+		done:     make(chan struct{}),
+	}
+	return c.dconn
 }
 func (c *fakeConn) ReadPipeline() []redcon.Command {
-	return nil
+	cmds := c.pipeline
+	c.pipeline = nil
+	return cmds
 }
 func (c *fakeConn) PeekPipeline() []redcon.Command {
-	return nil
+	return c.pipeline
 }
 func (c *fakeConn) NetConn() net.Conn {
 	return nil
 }
 func (c *fakeConn) append(str string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.parts = append(c.parts, str)
 }
 func (c *fakeConn) out() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return strings.Join(c.parts, ",")
 }
+
+type fakeDetachedConn struct {
+	*fakeConn
+	in   chan redcon.Command
+	done chan struct{}
+	once sync.Once
+}
+
+func (c *fakeDetachedConn) Close() error {
+	c.once.Do(func() { close(c.done) })
+	return nil
+}
+func (c *fakeDetachedConn) ReadCommand() (redcon.Command, error) {
+	select {
+	case cmd := <-c.in:
+		return cmd, nil
+	case <-c.done:
+		return redcon.Command{}, io.EOF
+	}
+}
+func (c *fakeDetachedConn) Flush() error {
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/nalgeon/redka/redsrv/internal/command/hash"
 	"github.com/nalgeon/redka/redsrv/internal/command/key"
 	"github.com/nalgeon/redka/redsrv/internal/command/list"
+	"github.com/nalgeon/redka/redsrv/internal/command/pubsub"
 	"github.com/nalgeon/redka/redsrv/internal/command/server"
 	"github.com/nalgeon/redka/redsrv/internal/command/set"
 	str "github.com/nalgeon/redka/redsrv/internal/command/string"
@@ -17,15 +18,27 @@ import (
 )
 
 // Parse parses a text representation of a command into a Cmd.
-func Parse(args [][]byte) (redis.Cmd, error) {
+// The server state and the ID of the client that sent the command
+// are used by the server and connection management commands.
+func Parse(args [][]byte, srv redis.Server, clientID int64) (redis.Cmd, error) {
 	name := strings.ToLower(string(args[0]))
 	b := redis.NewBaseCmd(args)
+
+	// Reject calls with the wrong number of arguments
+	// before parsing the command.
+	if info, ok := Table.Get(name); ok && !info.CheckArity(len(args)) {
+		cmd, _ := server.ParseUnknown(b)
+		return cmd, redis.ErrInvalidArgNum
+	}
+
 	switch name {
 	// server
+	case "bgsave":
+		return server.ParseBgSave(b, srv.Saver())
 	case "command":
-		return server.ParseOK(b)
+		return server.ParseCommand(b, Table)
 	case "config":
-		return server.ParseConfig(b)
+		return server.ParseConfig(b, srv.Config())
 	case "dbsize":
 		return server.ParseDBSize(b)
 	case "flushdb":
@@ -34,10 +47,26 @@ func Parse(args [][]byte) (redis.Cmd, error) {
 		return key.ParseFlushDB(b)
 	case "info":
 		return server.ParseOK(b)
+	case "lastsave":
+		return server.ParseLastSave(b, srv.Saver())
 	case "lolwut":
 		return server.ParseLolwut(b)
+	case "memory":
+		return server.ParseMemory(b)
+	case "redka.changes":
+		return server.ParseChanges(b, srv.Replication())
+	case "redka.snapshot":
+		return server.ParseSnapshot(b, srv.Replication())
+	case "role":
+		return server.ParseRole(b, srv.Replication())
+	case "save":
+		return server.ParseSave(b, srv.Saver())
+	case "slowlog":
+		return server.ParseSlowLog(b, srv.SlowLog())
 
 	// connection
+	case "client":
+		return conn.ParseClient(b, srv.Clients(), clientID)
 	case "echo":
 		return conn.ParseEcho(b)
 	case "ping":
@@ -45,9 +74,15 @@ func Parse(args [][]byte) (redis.Cmd, error) {
 	case "select":
 		return conn.ParseSelect(b)
 
+	// pubsub
+	case "publish":
+		return pubsub.ParsePublish(b, srv.PubSub())
+
 	// key
 	case "del":
 		return key.ParseDel(b)
+	case "dump":
+		return key.ParseDump(b)
 	case "exists":
 		return key.ParseExists(b)
 	case "expire":
@@ -56,6 +91,8 @@ func Parse(args [][]byte) (redis.Cmd, error) {
 		return key.ParseExpireAt(b, 1000)
 	case "keys":
 		return key.ParseKeys(b)
+	case "object":
+		return key.ParseObject(b)
 	case "persist":
 		return key.ParsePersist(b)
 	case "pexpire":
@@ -68,6 +105,8 @@ func Parse(args [][]byte) (redis.Cmd, error) {
 		return key.ParseRename(b)
 	case "renamenx":
 		return key.ParseRenameNX(b)
+	case "restore":
+		return key.ParseRestore(b)
 	case "scan":
 		return key.ParseScan(b)
 	case "ttl":
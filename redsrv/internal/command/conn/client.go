@@ -0,0 +1,308 @@
+package conn
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+var (
+	ErrInvalidName = errors.New("ERR Client names cannot contain spaces, newlines or special characters")
+	ErrNoClient    = errors.New("ERR No such client")
+)
+
+// Container command for client connection commands.
+// CLIENT ID
+// CLIENT INFO
+// CLIENT LIST [TYPE normal|master|replica|pubsub] [ID client-id [client-id ...]]
+// CLIENT GETNAME
+// CLIENT SETNAME connection-name
+// CLIENT KILL ip:port
+// CLIENT KILL [ID client-id] [ADDR ip:port] [USER username] [SKIPME yes|no]
+// CLIENT NO-EVICT ON|OFF
+// CLIENT REPLY ON|OFF|SKIP
+// https://redis.io/commands/client
+type Client struct {
+	redis.BaseCmd
+	clients redis.Clients
+	id      int64 // the client that sent the command
+	subcmd  string
+	name    string
+	noEvict bool
+	reply   redis.ReplyMode
+	list    clientFilter
+	kill    clientFilter
+}
+
+// clientFilter selects the clients to list or kill.
+type clientFilter struct {
+	legacy bool // CLIENT KILL ip:port
+	none   bool // matches no clients
+	ids    []int64
+	addr   string
+	user   string
+	skipMe bool
+}
+
+// match reports whether the client matches the filter.
+func (f clientFilter) match(c redis.ClientInfo) bool {
+	if f.none {
+		return false
+	}
+	if len(f.ids) > 0 && !slices.Contains(f.ids, c.ID) {
+		return false
+	}
+	if f.addr != "" && f.addr != c.Addr {
+		return false
+	}
+	if f.user != "" && f.user != c.User {
+		return false
+	}
+	return true
+}
+
+func ParseClient(b redis.BaseCmd, clients redis.Clients, id int64) (Client, error) {
+	// Extract the subcommand.
+	cmd := Client{BaseCmd: b, clients: clients, id: id}
+	if len(cmd.Args()) == 0 {
+		return Client{}, redis.ErrInvalidArgNum
+	}
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
+
+	// Parse the subcommand.
+	var err error
+	args := cmd.Args()[1:]
+	switch cmd.subcmd {
+	case "id", "info", "getname":
+		if len(args) != 0 {
+			err = redis.ErrInvalidArgNum
+		}
+	case "list":
+		cmd.list, err = parseClientList(args)
+	case "setname":
+		cmd.name, err = parseClientName(args)
+	case "kill":
+		cmd.kill, err = parseClientKill(args)
+	case "no-evict":
+		cmd.noEvict, err = parseClientNoEvict(args)
+	case "reply":
+		cmd.reply, err = parseClientReply(args)
+	default:
+		err = redis.ErrUnknownSubcmd
+	}
+
+	// Return the resulting command.
+	if err != nil {
+		return Client{}, err
+	}
+	return cmd, nil
+}
+
+func (c Client) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	switch c.subcmd {
+	case "id":
+		w.WriteInt64(c.id)
+		return c.id, nil
+	case "info":
+		info, _ := c.clients.Get(c.id)
+		w.WriteBulkString(info.String() + "\n")
+		return info, nil
+	case "list":
+		return c.runList(w)
+	case "getname":
+		info, _ := c.clients.Get(c.id)
+		if info.Name == "" {
+			w.WriteNull()
+			return "", nil
+		}
+		w.WriteBulkString(info.Name)
+		return info.Name, nil
+	case "setname":
+		c.clients.SetName(c.id, c.name)
+		w.WriteString("OK")
+		return true, nil
+	case "kill":
+		return c.runKill(w)
+	case "no-evict":
+		c.clients.SetNoEvict(c.id, c.noEvict)
+		w.WriteString("OK")
+		return true, nil
+	case "reply":
+		c.clients.SetReply(c.id, c.reply)
+		if c.reply == redis.ReplyOn {
+			w.WriteString("OK")
+		}
+		return true, nil
+	default:
+		w.WriteString("OK")
+		return true, nil
+	}
+}
+
+// runList writes the descriptions of the matching clients.
+func (c Client) runList(w redis.Writer) (any, error) {
+	var b strings.Builder
+	var infos []redis.ClientInfo
+	for _, info := range c.clients.List() {
+		if !c.list.match(info) {
+			continue
+		}
+		infos = append(infos, info)
+		b.WriteString(info.String())
+		b.WriteByte('\n')
+	}
+	w.WriteBulkString(b.String())
+	return infos, nil
+}
+
+// runKill closes the connections of the matching clients.
+func (c Client) runKill(w redis.Writer) (any, error) {
+	var nkilled int
+	for _, info := range c.clients.List() {
+		if !c.kill.match(info) {
+			continue
+		}
+		if c.kill.skipMe && info.ID == c.id {
+			continue
+		}
+		if c.clients.Kill(info.ID) {
+			nkilled++
+		}
+	}
+
+	if !c.kill.legacy {
+		w.WriteInt(nkilled)
+		return nkilled, nil
+	}
+	if nkilled == 0 {
+		w.WriteError(c.Error(ErrNoClient))
+		return false, ErrNoClient
+	}
+	w.WriteString("OK")
+	return true, nil
+}
+
+// parseClientList parses the CLIENT LIST filters.
+func parseClientList(args [][]byte) (clientFilter, error) {
+	var filter clientFilter
+	for i := 0; i < len(args); i++ {
+		opt := strings.ToLower(string(args[i]))
+		if i == len(args)-1 {
+			return clientFilter{}, redis.ErrSyntaxError
+		}
+		switch opt {
+		case "type":
+			i++
+			switch strings.ToLower(string(args[i])) {
+			case "normal":
+			case "master", "replica", "slave", "pubsub":
+				// Redka only has normal clients.
+				filter.none = true
+			default:
+				return clientFilter{}, redis.ErrSyntaxError
+			}
+		case "id":
+			// IDs take the rest of the arguments.
+			for i++; i < len(args); i++ {
+				id, err := strconv.ParseInt(string(args[i]), 10, 64)
+				if err != nil || id <= 0 {
+					return clientFilter{}, redis.ErrInvalidInt
+				}
+				filter.ids = append(filter.ids, id)
+			}
+		default:
+			return clientFilter{}, redis.ErrSyntaxError
+		}
+	}
+	return filter, nil
+}
+
+// parseClientName parses the CLIENT SETNAME arguments.
+func parseClientName(args [][]byte) (string, error) {
+	if len(args) != 1 {
+		return "", redis.ErrInvalidArgNum
+	}
+	name := string(args[0])
+	for _, ch := range name {
+		// Same as Redis: no spaces, newlines or non-printable characters.
+		if ch < '!' || ch > '~' {
+			return "", ErrInvalidName
+		}
+	}
+	return name, nil
+}
+
+// parseClientKill parses the CLIENT KILL arguments,
+// either the legacy address or the filters.
+func parseClientKill(args [][]byte) (clientFilter, error) {
+	if len(args) == 0 {
+		return clientFilter{}, redis.ErrInvalidArgNum
+	}
+	if len(args) == 1 {
+		return clientFilter{legacy: true, addr: string(args[0])}, nil
+	}
+	if len(args)%2 != 0 {
+		return clientFilter{}, redis.ErrSyntaxError
+	}
+
+	filter := clientFilter{skipMe: true}
+	for i := 0; i < len(args); i += 2 {
+		opt, val := strings.ToLower(string(args[i])), string(args[i+1])
+		switch opt {
+		case "id":
+			id, err := strconv.ParseInt(val, 10, 64)
+			if err != nil || id <= 0 {
+				return clientFilter{}, redis.ErrInvalidInt
+			}
+			filter.ids = append(filter.ids, id)
+		case "addr":
+			filter.addr = val
+		case "user":
+			filter.user = val
+		case "skipme":
+			switch strings.ToLower(val) {
+			case "yes":
+				filter.skipMe = true
+			case "no":
+				filter.skipMe = false
+			default:
+				return clientFilter{}, redis.ErrSyntaxError
+			}
+		default:
+			return clientFilter{}, redis.ErrSyntaxError
+		}
+	}
+	return filter, nil
+}
+
+// parseClientNoEvict parses the CLIENT NO-EVICT arguments.
+func parseClientNoEvict(args [][]byte) (bool, error) {
+	if len(args) != 1 {
+		return false, redis.ErrInvalidArgNum
+	}
+	switch strings.ToLower(string(args[0])) {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	default:
+		return false, redis.ErrSyntaxError
+	}
+}
+
+// parseClientReply parses the CLIENT REPLY arguments.
+func parseClientReply(args [][]byte) (redis.ReplyMode, error) {
+	if len(args) != 1 {
+		return "", redis.ErrInvalidArgNum
+	}
+	mode := redis.ReplyMode(strings.ToLower(string(args[0])))
+	switch mode {
+	case redis.ReplyOn, redis.ReplyOff, redis.ReplySkip:
+		return mode, nil
+	default:
+		return "", redis.ErrSyntaxError
+	}
+}
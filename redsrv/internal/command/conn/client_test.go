@@ -0,0 +1,312 @@
+package conn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// fakeClients is an in-memory client registry for testing.
+type fakeClients struct {
+	clients []redis.ClientInfo
+	reply   redis.ReplyMode
+}
+
+func newFakeClients() *fakeClients {
+	return &fakeClients{clients: []redis.ClientInfo{
+		{ID: 1, Addr: "127.0.0.1:5001", User: "default", Age: 10 * time.Second, LastCmd: "client"},
+		{ID: 2, Addr: "127.0.0.1:5002", User: "default", Name: "worker", LastCmd: "get"},
+		{ID: 3, Addr: "127.0.0.1:5003", User: "default", InMulti: true, Queued: 2, LastCmd: "set"},
+	}}
+}
+
+func (c *fakeClients) Get(id int64) (redis.ClientInfo, bool) {
+	for _, info := range c.clients {
+		if info.ID == id {
+			return info, true
+		}
+	}
+	return redis.ClientInfo{}, false
+}
+
+func (c *fakeClients) List() []redis.ClientInfo {
+	return c.clients
+}
+
+func (c *fakeClients) Kill(id int64) bool {
+	for i, info := range c.clients {
+		if info.ID == id {
+			c.clients = append(c.clients[:i:i], c.clients[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (c *fakeClients) SetName(id int64, name string) {
+	c.update(id, func(info *redis.ClientInfo) { info.Name = name })
+}
+
+func (c *fakeClients) SetNoEvict(id int64, on bool) {
+	c.update(id, func(info *redis.ClientInfo) { info.NoEvict = on })
+}
+
+func (c *fakeClients) SetReply(id int64, mode redis.ReplyMode) {
+	c.reply = mode
+}
+
+func (c *fakeClients) update(id int64, f func(info *redis.ClientInfo)) {
+	for i := range c.clients {
+		if c.clients[i].ID == id {
+			f(&c.clients[i])
+		}
+	}
+}
+
+func TestClientParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want Client
+		err  error
+	}{
+		{
+			cmd:  "client",
+			want: Client{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "client id",
+			want: Client{subcmd: "id"},
+			err:  nil,
+		},
+		{
+			cmd:  "client ID 1",
+			want: Client{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "client list",
+			want: Client{subcmd: "list"},
+			err:  nil,
+		},
+		{
+			cmd:  "client list type normal id 1 2",
+			want: Client{subcmd: "list", list: clientFilter{ids: []int64{1, 2}}},
+			err:  nil,
+		},
+		{
+			cmd:  "client list type pubsub",
+			want: Client{subcmd: "list", list: clientFilter{none: true}},
+			err:  nil,
+		},
+		{
+			cmd:  "client list type",
+			want: Client{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "client list id one",
+			want: Client{},
+			err:  redis.ErrInvalidInt,
+		},
+		{
+			cmd:  "client setname worker",
+			want: Client{subcmd: "setname", name: "worker"},
+			err:  nil,
+		},
+		{
+			cmd:  "client setname work\ter",
+			want: Client{},
+			err:  ErrInvalidName,
+		},
+		{
+			cmd:  "client kill 127.0.0.1:5001",
+			want: Client{subcmd: "kill", kill: clientFilter{legacy: true, addr: "127.0.0.1:5001"}},
+			err:  nil,
+		},
+		{
+			cmd:  "client kill id 2 skipme no",
+			want: Client{subcmd: "kill", kill: clientFilter{ids: []int64{2}}},
+			err:  nil,
+		},
+		{
+			cmd:  "client kill user default",
+			want: Client{subcmd: "kill", kill: clientFilter{user: "default", skipMe: true}},
+			err:  nil,
+		},
+		{
+			cmd:  "client kill id 1 addr",
+			want: Client{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "client kill laddr 127.0.0.1:6379",
+			want: Client{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "client no-evict on",
+			want: Client{subcmd: "no-evict", noEvict: true},
+			err:  nil,
+		},
+		{
+			cmd:  "client no-evict maybe",
+			want: Client{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "client reply SKIP",
+			want: Client{subcmd: "reply", reply: redis.ReplySkip},
+			err:  nil,
+		},
+		{
+			cmd:  "client reply never",
+			want: Client{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "client pause 100",
+			want: Client{},
+			err:  redis.ErrUnknownSubcmd,
+		},
+	}
+
+	parse := func(b redis.BaseCmd) (Client, error) {
+		return ParseClient(b, newFakeClients(), 1)
+	}
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			be.Equal(t, err, test.err)
+			if err == nil {
+				be.Equal(t, cmd.subcmd, test.want.subcmd)
+				be.Equal(t, cmd.name, test.want.name)
+				be.Equal(t, cmd.noEvict, test.want.noEvict)
+				be.Equal(t, cmd.reply, test.want.reply)
+				be.Equal(t, cmd.list, test.want.list)
+				be.Equal(t, cmd.kill, test.want.kill)
+			} else {
+				be.Equal(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestClientExec(t *testing.T) {
+	run := func(t *testing.T, clients *fakeClients, text string) (any, string) {
+		t.Helper()
+		parse := func(b redis.BaseCmd) (Client, error) {
+			return ParseClient(b, clients, 1)
+		}
+		cmd := redis.MustParse(parse, text)
+		conn := redis.NewFakeConn()
+		res, _ := cmd.Run(conn, getRedka(t))
+		return res, conn.Out()
+	}
+
+	t.Run("id", func(t *testing.T) {
+		res, out := run(t, newFakeClients(), "client id")
+		be.Equal(t, res.(int64), int64(1))
+		be.Equal(t, out, "1")
+	})
+	t.Run("info", func(t *testing.T) {
+		_, out := run(t, newFakeClients(), "client info")
+		be.Equal(t, out, "id=1 addr=127.0.0.1:5001 laddr= name= age=10 idle=0 "+
+			"flags=N db=0 multi=-1 user=default cmd=client\n")
+	})
+	t.Run("list", func(t *testing.T) {
+		res, out := run(t, newFakeClients(), "client list")
+		be.Equal(t, len(res.([]redis.ClientInfo)), 3)
+		be.Equal(t, out,
+			"id=1 addr=127.0.0.1:5001 laddr= name= age=10 idle=0 flags=N db=0 multi=-1 user=default cmd=client\n"+
+				"id=2 addr=127.0.0.1:5002 laddr= name=worker age=0 idle=0 flags=N db=0 multi=-1 user=default cmd=get\n"+
+				"id=3 addr=127.0.0.1:5003 laddr= name= age=0 idle=0 flags=x db=0 multi=2 user=default cmd=set\n")
+	})
+	t.Run("list by id", func(t *testing.T) {
+		res, _ := run(t, newFakeClients(), "client list id 2 3 4")
+		infos := res.([]redis.ClientInfo)
+		be.Equal(t, len(infos), 2)
+		be.Equal(t, infos[0].ID, int64(2))
+		be.Equal(t, infos[1].ID, int64(3))
+	})
+	t.Run("list by type", func(t *testing.T) {
+		res, out := run(t, newFakeClients(), "client list type replica")
+		be.Equal(t, len(res.([]redis.ClientInfo)), 0)
+		be.Equal(t, out, "")
+	})
+	t.Run("setname", func(t *testing.T) {
+		clients := newFakeClients()
+		_, out := run(t, clients, "client setname api")
+		be.Equal(t, out, "OK")
+		_, out = run(t, clients, "client getname")
+		be.Equal(t, out, "api")
+	})
+	t.Run("getname none", func(t *testing.T) {
+		_, out := run(t, newFakeClients(), "client getname")
+		be.Equal(t, out, "(nil)")
+	})
+	t.Run("kill legacy", func(t *testing.T) {
+		clients := newFakeClients()
+		_, out := run(t, clients, "client kill 127.0.0.1:5002")
+		be.Equal(t, out, "OK")
+		be.Equal(t, len(clients.clients), 2)
+	})
+	t.Run("kill legacy none", func(t *testing.T) {
+		clients := newFakeClients()
+		_, out := run(t, clients, "client kill 127.0.0.1:6000")
+		be.Equal(t, out, "ERR No such client (client)")
+		be.Equal(t, len(clients.clients), 3)
+	})
+	t.Run("kill by id", func(t *testing.T) {
+		clients := newFakeClients()
+		res, out := run(t, clients, "client kill id 3")
+		be.Equal(t, res, 1)
+		be.Equal(t, out, "1")
+		_, ok := clients.Get(3)
+		be.Equal(t, ok, false)
+	})
+	t.Run("kill by addr", func(t *testing.T) {
+		clients := newFakeClients()
+		res, _ := run(t, clients, "client kill addr 127.0.0.1:5002")
+		be.Equal(t, res, 1)
+		_, ok := clients.Get(2)
+		be.Equal(t, ok, false)
+	})
+	t.Run("kill by user", func(t *testing.T) {
+		clients := newFakeClients()
+		res, _ := run(t, clients, "client kill user default")
+		be.Equal(t, res, 2)
+		be.Equal(t, len(clients.clients), 1)
+		be.Equal(t, clients.clients[0].ID, int64(1))
+	})
+	t.Run("kill skipme no", func(t *testing.T) {
+		clients := newFakeClients()
+		res, _ := run(t, clients, "client kill user default skipme no")
+		be.Equal(t, res, 3)
+		be.Equal(t, len(clients.clients), 0)
+	})
+	t.Run("kill unknown user", func(t *testing.T) {
+		clients := newFakeClients()
+		res, _ := run(t, clients, "client kill user alice")
+		be.Equal(t, res, 0)
+		be.Equal(t, len(clients.clients), 3)
+	})
+	t.Run("no-evict", func(t *testing.T) {
+		clients := newFakeClients()
+		_, out := run(t, clients, "client no-evict on")
+		be.Equal(t, out, "OK")
+		info, _ := clients.Get(1)
+		be.True(t, info.NoEvict)
+	})
+	t.Run("reply", func(t *testing.T) {
+		clients := newFakeClients()
+		_, out := run(t, clients, "client reply off")
+		be.Equal(t, out, "")
+		be.Equal(t, clients.reply, redis.ReplyOff)
+		_, out = run(t, clients, "client reply on")
+		be.Equal(t, out, "OK")
+		be.Equal(t, clients.reply, redis.ReplyOn)
+	})
+}
@@ -0,0 +1,37 @@
+package key
+
+import (
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Returns a serialized representation of the value stored at a key.
+// DUMP key
+// https://redis.io/commands/dump
+type Dump struct {
+	redis.BaseCmd
+	key string
+}
+
+func ParseDump(b redis.BaseCmd) (Dump, error) {
+	cmd := Dump{BaseCmd: b}
+	if len(cmd.Args()) != 1 {
+		return Dump{}, redis.ErrInvalidArgNum
+	}
+	cmd.key = string(cmd.Args()[0])
+	return cmd, nil
+}
+
+func (cmd Dump) Run(w redis.Writer, red redis.Redka) (any, error) {
+	payload, err := red.Dump().Dump(cmd.key)
+	if err == core.ErrNotFound {
+		w.WriteNull()
+		return nil, nil
+	}
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteBulk(payload)
+	return payload, nil
+}
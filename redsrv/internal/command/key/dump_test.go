@@ -0,0 +1,71 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestDumpParse(t *testing.T) {
+	tests := []struct {
+		cmd string
+		key string
+		err error
+	}{
+		{
+			cmd: "dump",
+			key: "",
+			err: redis.ErrInvalidArgNum,
+		},
+		{
+			cmd: "dump name",
+			key: "name",
+			err: nil,
+		},
+		{
+			cmd: "dump name age",
+			key: "",
+			err: redis.ErrInvalidArgNum,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseDump, test.cmd)
+			be.Equal(t, err, test.err)
+			if err == nil {
+				be.Equal(t, cmd.key, test.key)
+			} else {
+				be.Equal(t, cmd, Dump{})
+			}
+		})
+	}
+}
+
+func TestDumpExec(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		red := getRedka(t)
+		_ = red.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(ParseDump, "dump name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		want := "\x00\x05alice\x09\x00"
+		be.Equal(t, string(res.([]byte)[:len(want)]), want)
+		be.Equal(t, len(res.([]byte)), len(want)+8)
+		be.Equal(t, conn.Out(), string(res.([]byte)))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		red := getRedka(t)
+
+		cmd := redis.MustParse(ParseDump, "dump name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, res, nil)
+		be.Equal(t, conn.Out(), "(nil)")
+	})
+}
@@ -0,0 +1,73 @@
+package key
+
+import (
+	"strings"
+
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Container command for object introspection commands.
+// OBJECT ENCODING key
+// OBJECT FREQ key
+// OBJECT IDLETIME key
+// https://redis.io/commands/object
+//
+// ENCODING returns a Redka-specific encoding: "sql-blob" for strings
+// and "sql-rows" for other types. FREQ returns the number of recorded
+// accesses instead of the Redis logarithmic counter. FREQ and IDLETIME
+// rely on the access tracking (the track-access parameter or an LRU
+// eviction policy). Without it, FREQ is zero and IDLETIME is the time
+// since the last modification. The access time is updated in the
+// background, so IDLETIME may lag behind by a second or so.
+type Object struct {
+	redis.BaseCmd
+	subcmd string
+	key    string
+}
+
+func ParseObject(b redis.BaseCmd) (Object, error) {
+	// Extract the subcommand.
+	cmd := Object{BaseCmd: b}
+	if len(cmd.Args()) == 0 {
+		return Object{}, redis.ErrInvalidArgNum
+	}
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
+
+	// Parse the subcommand.
+	args := cmd.Args()[1:]
+	switch cmd.subcmd {
+	case "encoding", "freq", "idletime":
+		if len(args) != 1 {
+			return Object{}, redis.ErrInvalidArgNum
+		}
+		cmd.key = string(args[0])
+	default:
+		return Object{}, redis.ErrUnknownSubcmd
+	}
+	return cmd, nil
+}
+
+func (cmd Object) Run(w redis.Writer, red redis.Redka) (any, error) {
+	info, err := red.Key().Info(cmd.key)
+	if err == core.ErrNotFound {
+		w.WriteNull()
+		return nil, nil
+	}
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	switch cmd.subcmd {
+	case "encoding":
+		w.WriteBulkString(info.Encoding)
+		return info.Encoding, nil
+	case "freq":
+		w.WriteInt(info.Freq)
+		return info.Freq, nil
+	default:
+		idle := max(int(info.IdleTime().Seconds()), 0)
+		w.WriteInt(idle)
+		return idle, nil
+	}
+}
@@ -0,0 +1,124 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/testx"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestObjectParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want Object
+		err  error
+	}{
+		{
+			cmd:  "object",
+			want: Object{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "object encoding",
+			want: Object{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "object encoding name",
+			want: Object{subcmd: "encoding", key: "name"},
+			err:  nil,
+		},
+		{
+			cmd:  "object FREQ name",
+			want: Object{subcmd: "freq", key: "name"},
+			err:  nil,
+		},
+		{
+			cmd:  "object idletime name",
+			want: Object{subcmd: "idletime", key: "name"},
+			err:  nil,
+		},
+		{
+			cmd:  "object idletime name age",
+			want: Object{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "object refcount name",
+			want: Object{},
+			err:  redis.ErrUnknownSubcmd,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseObject, test.cmd)
+			be.Equal(t, err, test.err)
+			if err == nil {
+				be.Equal(t, cmd.subcmd, test.want.subcmd)
+				be.Equal(t, cmd.key, test.want.key)
+			} else {
+				be.Equal(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestObjectExec(t *testing.T) {
+	t.Run("encoding", func(t *testing.T) {
+		red := getRedka(t)
+		_ = red.Str().Set("name", "alice")
+		_, _ = red.List().PushBack("list", "a")
+
+		tests := []struct {
+			key  string
+			want string
+		}{
+			{key: "name", want: "sql-blob"},
+			{key: "list", want: "sql-rows"},
+		}
+		for _, test := range tests {
+			cmd := redis.MustParse(ParseObject, "object encoding "+test.key)
+			conn := redis.NewFakeConn()
+			res, err := cmd.Run(conn, red)
+			be.Err(t, err, nil)
+			be.Equal(t, res.(string), test.want)
+			be.Equal(t, conn.Out(), test.want)
+		}
+	})
+	t.Run("freq", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		_ = db.Str().Set("name", "alice")
+		_, _ = db.Key().Touch("name")
+		_, _ = db.Key().Touch("name")
+
+		cmd := redis.MustParse(ParseObject, "object freq name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, redis.RedkaDB(db))
+		be.Err(t, err, nil)
+		be.Equal(t, res.(int), 2)
+		be.Equal(t, conn.Out(), "2")
+	})
+	t.Run("idletime", func(t *testing.T) {
+		red := getRedka(t)
+		_ = red.Str().Set("name", "alice")
+
+		cmd := redis.MustParse(ParseObject, "object idletime name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, res.(int), 0)
+		be.Equal(t, conn.Out(), "0")
+	})
+	t.Run("key not found", func(t *testing.T) {
+		red := getRedka(t)
+
+		cmd := redis.MustParse(ParseObject, "object encoding name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, res, nil)
+		be.Equal(t, conn.Out(), "(nil)")
+	})
+}
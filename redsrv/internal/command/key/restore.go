@@ -0,0 +1,87 @@
+package key
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/rdb"
+	"github.com/nalgeon/redka/redsrv/internal/parser"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Creates a key from the serialized representation of a value.
+// RESTORE key ttl serialized-value [REPLACE] [ABSTTL] [IDLETIME seconds]
+// https://redis.io/commands/restore
+type Restore struct {
+	redis.BaseCmd
+	key      string
+	ttl      int
+	payload  []byte
+	replace  bool
+	absTTL   bool
+	idleTime int
+}
+
+func ParseRestore(b redis.BaseCmd) (Restore, error) {
+	cmd := Restore{BaseCmd: b}
+	err := parser.New(
+		parser.String(&cmd.key),
+		parser.Int(&cmd.ttl),
+		parser.Bytes(&cmd.payload),
+		parser.Flag("replace", &cmd.replace),
+		parser.Flag("absttl", &cmd.absTTL),
+		parser.Named("idletime", parser.Int(&cmd.idleTime)),
+	).Required(3).Run(cmd.Args())
+	if err != nil {
+		return Restore{}, err
+	}
+	if cmd.ttl < 0 {
+		return Restore{}, redis.ErrInvalidExpireTime
+	}
+	if cmd.idleTime < 0 {
+		return Restore{}, redis.ErrSyntaxError
+	}
+	return cmd, nil
+}
+
+func (cmd Restore) Run(w redis.Writer, red redis.Redka) (any, error) {
+	// Zero ttl means the key does not expire.
+	var at time.Time
+	if cmd.absTTL && cmd.ttl > 0 {
+		at = time.UnixMilli(int64(cmd.ttl))
+	} else if cmd.ttl > 0 {
+		at = time.Now().Add(time.Duration(cmd.ttl) * time.Millisecond)
+	}
+
+	err := red.Dump().Restore(cmd.key, cmd.payload, at, cmd.replace)
+	if err != nil {
+		w.WriteError(cmd.Error(restoreError(err)))
+		return nil, err
+	}
+	if cmd.idleTime > 0 {
+		atime := time.Now().Add(-time.Duration(cmd.idleTime) * time.Second)
+		err = red.Key().SetAccessTime(cmd.key, atime)
+		// The key does not exist if the ttl has already passed.
+		if err != nil && !errors.Is(err, core.ErrNotFound) {
+			w.WriteError(cmd.Error(err))
+			return nil, err
+		}
+	}
+	w.WriteString("OK")
+	return true, nil
+}
+
+// restoreError translates the restore error to a command error.
+func restoreError(err error) error {
+	switch {
+	case errors.Is(err, core.ErrKeyExists):
+		return redis.ErrBusyKey
+	case errors.Is(err, rdb.ErrVersion), errors.Is(err, rdb.ErrChecksum):
+		return redis.ErrDumpPayload
+	case errors.Is(err, rdb.ErrFormat):
+		return redis.ErrBadDataFormat
+	default:
+		return err
+	}
+}
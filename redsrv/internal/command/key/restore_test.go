@@ -0,0 +1,207 @@
+package key
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestRestoreParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want Restore
+		err  error
+	}{
+		{
+			cmd:  "restore",
+			want: Restore{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "restore name 0",
+			want: Restore{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "restore name 0 payload",
+			want: Restore{key: "name", ttl: 0, payload: []byte("payload")},
+			err:  nil,
+		},
+		{
+			cmd:  "restore name 5000 payload replace absttl",
+			want: Restore{key: "name", ttl: 5000, payload: []byte("payload"), replace: true, absTTL: true},
+			err:  nil,
+		},
+		{
+			cmd:  "restore name 0 payload idletime 10",
+			want: Restore{key: "name", ttl: 0, payload: []byte("payload"), idleTime: 10},
+			err:  nil,
+		},
+		{
+			cmd:  "restore name -1 payload",
+			want: Restore{},
+			err:  redis.ErrInvalidExpireTime,
+		},
+		{
+			cmd:  "restore name 0 payload idletime -1",
+			want: Restore{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "restore name 0 payload freq 5",
+			want: Restore{},
+			err:  redis.ErrSyntaxError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseRestore, test.cmd)
+			be.Equal(t, err, test.err)
+			if err == nil {
+				be.Equal(t, cmd.key, test.want.key)
+				be.Equal(t, cmd.ttl, test.want.ttl)
+				be.Equal(t, cmd.payload, test.want.payload)
+				be.Equal(t, cmd.replace, test.want.replace)
+				be.Equal(t, cmd.absTTL, test.want.absTTL)
+				be.Equal(t, cmd.idleTime, test.want.idleTime)
+			} else {
+				be.Equal(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestRestoreExec(t *testing.T) {
+	// DUMP of the value set with SET mykey 10 (from Redis docs).
+	payload := []byte("\x00\xc0\n\t\x00\xbem\x06\x89Z(\x00\n")
+
+	t.Run("create", func(t *testing.T) {
+		red := getRedka(t)
+
+		cmd := mustParseRestore("restore", "age", "0", string(payload))
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, res, true)
+		be.Equal(t, conn.Out(), "OK")
+
+		age, _ := red.Str().Get("age")
+		be.Equal(t, age.String(), "10")
+		key, _ := red.Key().Get("age")
+		be.Equal(t, key.ETime, (*int64)(nil))
+	})
+
+	t.Run("dump and restore", func(t *testing.T) {
+		red := getRedka(t)
+		_, _ = red.List().PushBack("list", "a")
+		_, _ = red.List().PushBack("list", "b")
+		dump, _ := red.Dump().Dump("list")
+
+		cmd := mustParseRestore("restore", "copy", "60000", string(dump))
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+
+		list, _ := red.List().Range("copy", 0, -1)
+		be.Equal(t, len(list), 2)
+		be.Equal(t, list[1].String(), "b")
+		key, _ := red.Key().Get("copy")
+		be.True(t, *key.ETime > time.Now().UnixMilli())
+	})
+
+	t.Run("idletime", func(t *testing.T) {
+		red := getRedka(t)
+
+		cmd := mustParseRestore("restore", "age", "0", string(payload), "idletime", "100")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, conn.Out(), "OK")
+
+		info, _ := red.Key().Info("age")
+		idle := info.IdleTime()
+		be.True(t, idle >= 100*time.Second && idle < 101*time.Second)
+	})
+
+	t.Run("absttl", func(t *testing.T) {
+		red := getRedka(t)
+
+		cmd := mustParseRestore("restore", "age", "4102444800000", string(payload), "absttl")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+
+		key, _ := red.Key().Get("age")
+		be.Equal(t, *key.ETime, int64(4102444800000))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		red := getRedka(t)
+
+		cmd := mustParseRestore("restore", "age", "1000", string(payload), "absttl")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, conn.Out(), "OK")
+
+		exists, _ := red.Key().Exists("age")
+		be.Equal(t, exists, false)
+	})
+
+	t.Run("busy key", func(t *testing.T) {
+		red := getRedka(t)
+		_ = red.Str().Set("age", 25)
+
+		cmd := mustParseRestore("restore", "age", "0", string(payload))
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Equal(t, err, core.ErrKeyExists)
+		be.Equal(t, conn.Out(), redis.ErrBusyKey.Error()+" (restore)")
+
+		age, _ := red.Str().Get("age")
+		be.Equal(t, age.String(), "25")
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		red := getRedka(t)
+		_, _ = red.Hash().Set("age", "value", 25)
+
+		cmd := mustParseRestore("restore", "age", "0", string(payload), "replace")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+
+		age, _ := red.Str().Get("age")
+		be.Equal(t, age.String(), "10")
+	})
+
+	t.Run("invalid checksum", func(t *testing.T) {
+		red := getRedka(t)
+		invalid := append([]byte{}, payload...)
+		invalid[len(invalid)-1] ^= 0xff
+
+		cmd := mustParseRestore("restore", "age", "0", string(invalid))
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.True(t, err != nil)
+		be.Equal(t, conn.Out(), redis.ErrDumpPayload.Error()+" (restore)")
+	})
+}
+
+// mustParseRestore parses the RESTORE command
+// with arbitrary (binary) arguments.
+func mustParseRestore(args ...string) Restore {
+	bargs := make([][]byte, len(args))
+	for i, arg := range args {
+		bargs[i] = []byte(arg)
+	}
+	cmd, err := ParseRestore(redis.NewBaseCmd(bargs))
+	if err != nil {
+		panic(err)
+	}
+	return cmd
+}
@@ -0,0 +1,39 @@
+// Package pubsub implements the publish/subscribe commands.
+package pubsub
+
+import (
+	"github.com/nalgeon/redka/redsrv/internal/parser"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Publish posts a message to a channel.
+// PUBLISH channel message
+// https://redis.io/commands/publish
+//
+// SUBSCRIBE, PSUBSCRIBE and the corresponding unsubscribe commands
+// switch the connection into the subscriber mode, so they are
+// handled by the server instead of the command package.
+type Publish struct {
+	redis.BaseCmd
+	pubsub  redis.PubSub
+	channel string
+	message string
+}
+
+func ParsePublish(b redis.BaseCmd, pubsub redis.PubSub) (Publish, error) {
+	cmd := Publish{BaseCmd: b, pubsub: pubsub}
+	err := parser.New(
+		parser.String(&cmd.channel),
+		parser.String(&cmd.message),
+	).Required(2).Run(cmd.Args())
+	if err != nil {
+		return Publish{}, err
+	}
+	return cmd, nil
+}
+
+func (c Publish) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	n := c.pubsub.Publish(c.channel, c.message)
+	w.WriteInt(n)
+	return n, nil
+}
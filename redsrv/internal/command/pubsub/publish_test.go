@@ -0,0 +1,77 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestPublishParse(t *testing.T) {
+	tests := []struct {
+		cmd     string
+		channel string
+		message string
+		err     error
+	}{
+		{
+			cmd: "publish",
+			err: redis.ErrInvalidArgNum,
+		},
+		{
+			cmd: "publish news",
+			err: redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:     "publish news hello",
+			channel: "news",
+			message: "hello",
+			err:     nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			ps := new(fakePubSub)
+			parse := func(b redis.BaseCmd) (Publish, error) {
+				return ParsePublish(b, ps)
+			}
+			cmd, err := redis.Parse(parse, test.cmd)
+			be.Equal(t, err, test.err)
+			if err == nil {
+				be.Equal(t, cmd.channel, test.channel)
+				be.Equal(t, cmd.message, test.message)
+			} else {
+				be.Equal(t, cmd, Publish{})
+			}
+		})
+	}
+}
+
+func TestPublishExec(t *testing.T) {
+	t.Run("subscribers", func(t *testing.T) {
+		ps := &fakePubSub{subs: map[string]int{"news": 2}}
+		parse := func(b redis.BaseCmd) (Publish, error) {
+			return ParsePublish(b, ps)
+		}
+		cmd := redis.MustParse(parse, "publish news hello")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, getRedka(t))
+		be.Err(t, err, nil)
+		be.Equal(t, res, 2)
+		be.Equal(t, conn.Out(), "2")
+		be.Equal(t, ps.messages, []string{"news:hello"})
+	})
+	t.Run("no subscribers", func(t *testing.T) {
+		ps := new(fakePubSub)
+		parse := func(b redis.BaseCmd) (Publish, error) {
+			return ParsePublish(b, ps)
+		}
+		cmd := redis.MustParse(parse, "publish news hello")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, getRedka(t))
+		be.Err(t, err, nil)
+		be.Equal(t, res, 0)
+		be.Equal(t, conn.Out(), "0")
+	})
+}
@@ -0,0 +1,25 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/nalgeon/redka/internal/testx"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func getRedka(tb testing.TB) redis.Redka {
+	tb.Helper()
+	db := testx.OpenDB(tb)
+	return redis.RedkaDB(db)
+}
+
+// fakePubSub records the published messages.
+type fakePubSub struct {
+	subs     map[string]int // number of subscribers per channel
+	messages []string
+}
+
+func (ps *fakePubSub) Publish(channel, message string) int {
+	ps.messages = append(ps.messages, channel+":"+message)
+	return ps.subs[channel]
+}
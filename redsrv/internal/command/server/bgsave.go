@@ -0,0 +1,43 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Asynchronously saves the database to disk.
+// BGSAVE [SCHEDULE]
+// https://redis.io/commands/bgsave
+//
+// SCHEDULE is accepted for compatibility, but does not
+// change the behavior: BGSAVE fails if a background save
+// is already in progress.
+type BgSave struct {
+	redis.BaseCmd
+	saver redis.Saver
+}
+
+func ParseBgSave(b redis.BaseCmd, saver redis.Saver) (BgSave, error) {
+	cmd := BgSave{BaseCmd: b, saver: saver}
+	switch len(cmd.Args()) {
+	case 0:
+	case 1:
+		if !strings.EqualFold(string(cmd.Args()[0]), "schedule") {
+			return BgSave{}, redis.ErrSyntaxError
+		}
+	default:
+		return BgSave{}, redis.ErrSyntaxError
+	}
+	return cmd, nil
+}
+
+func (cmd BgSave) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	err := cmd.saver.BgSave()
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return false, err
+	}
+	w.WriteString("Background saving started")
+	return true, nil
+}
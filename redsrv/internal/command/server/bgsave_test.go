@@ -0,0 +1,57 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestBgSaveParse(t *testing.T) {
+	tests := []struct {
+		cmd string
+		err error
+	}{
+		{cmd: "bgsave", err: nil},
+		{cmd: "bgsave schedule", err: nil},
+		{cmd: "bgsave SCHEDULE", err: nil},
+		{cmd: "bgsave now", err: redis.ErrSyntaxError},
+		{cmd: "bgsave schedule now", err: redis.ErrSyntaxError},
+	}
+
+	saver := new(fakeSaver)
+	parse := func(b redis.BaseCmd) (BgSave, error) {
+		return ParseBgSave(b, saver)
+	}
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			be.Equal(t, err, test.err)
+			if err != nil {
+				be.Equal(t, cmd, BgSave{})
+			}
+		})
+	}
+}
+
+func TestBgSaveExec(t *testing.T) {
+	t.Run("bgsave", func(t *testing.T) {
+		saver := new(fakeSaver)
+		cmd := redis.MustParse(func(b redis.BaseCmd) (BgSave, error) { return ParseBgSave(b, saver) }, "bgsave")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, redis.Redka{})
+		be.Err(t, err, nil)
+		be.Equal(t, res, true)
+		be.Equal(t, conn.Out(), "Background saving started")
+		be.Equal(t, saver.saves, 1)
+	})
+	t.Run("in progress", func(t *testing.T) {
+		saver := &fakeSaver{saving: true}
+		cmd := redis.MustParse(func(b redis.BaseCmd) (BgSave, error) { return ParseBgSave(b, saver) }, "bgsave")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, redis.Redka{})
+		be.Equal(t, err, redis.ErrBgSaveInProgress)
+		be.Equal(t, res, false)
+		be.Equal(t, conn.Out(), redis.ErrBgSaveInProgress.Error()+" (bgsave)")
+	})
+}
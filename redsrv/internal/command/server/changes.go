@@ -0,0 +1,60 @@
+package server
+
+import (
+	"github.com/nalgeon/redka/redsrv/internal/parser"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Returns the change log entries with sequence numbers
+// greater than since, no more than count entries.
+// Each entry is an array of the sequence number, the time
+// (unix milliseconds), the key, the operation name and
+// the command that reproduces the change.
+// Requires the change log to be enabled.
+// REDKA.CHANGES since count
+type Changes struct {
+	redis.BaseCmd
+	repl  redis.Replication
+	since int
+	count int
+}
+
+func ParseChanges(b redis.BaseCmd, repl redis.Replication) (Changes, error) {
+	cmd := Changes{BaseCmd: b, repl: repl}
+	err := parser.New(
+		parser.Int(&cmd.since),
+		parser.Int(&cmd.count),
+	).Required(2).Run(cmd.Args())
+	if err != nil {
+		return Changes{}, err
+	}
+	if cmd.since < 0 || cmd.count <= 0 {
+		return Changes{}, redis.ErrOutOfRange
+	}
+	return cmd, nil
+}
+
+func (cmd Changes) Run(w redis.Writer, red redis.Redka) (any, error) {
+	if !cmd.repl.ChangeLog() {
+		w.WriteError(cmd.Error(redis.ErrNoChangeLog))
+		return nil, redis.ErrNoChangeLog
+	}
+	changes, err := red.Changes().Range(int64(cmd.since), cmd.count)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteArray(len(changes))
+	for _, c := range changes {
+		w.WriteArray(5)
+		w.WriteInt64(c.Seq)
+		w.WriteInt64(c.Time.UnixMilli())
+		w.WriteBulkString(c.Key)
+		w.WriteBulkString(c.Op)
+		w.WriteArray(len(c.Cmd))
+		for _, arg := range c.Cmd {
+			w.WriteBulk(arg)
+		}
+	}
+	return changes, nil
+}
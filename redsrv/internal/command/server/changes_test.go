@@ -0,0 +1,145 @@
+package server
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/rchange"
+	"github.com/nalgeon/redka/internal/testx"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestChangesParse(t *testing.T) {
+	repl := &fakeReplication{changeLog: true}
+	parse := func(b redis.BaseCmd) (Changes, error) {
+		return ParseChanges(b, repl)
+	}
+
+	tests := []struct {
+		cmd  string
+		want Changes
+		err  error
+	}{
+		{
+			cmd:  "redka.changes",
+			want: Changes{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "redka.changes 0",
+			want: Changes{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "redka.changes 0 10",
+			want: Changes{since: 0, count: 10},
+			err:  nil,
+		},
+		{
+			cmd:  "redka.changes 42 1",
+			want: Changes{since: 42, count: 1},
+			err:  nil,
+		},
+		{
+			cmd:  "redka.changes zero 10",
+			want: Changes{},
+			err:  redis.ErrInvalidInt,
+		},
+		{
+			cmd:  "redka.changes -1 10",
+			want: Changes{},
+			err:  redis.ErrOutOfRange,
+		},
+		{
+			cmd:  "redka.changes 0 0",
+			want: Changes{},
+			err:  redis.ErrOutOfRange,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			be.Equal(t, err, test.err)
+			if err == nil {
+				be.Equal(t, cmd.since, test.want.since)
+				be.Equal(t, cmd.count, test.want.count)
+			} else {
+				be.Equal(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestChangesExec(t *testing.T) {
+	db := testx.OpenDBWith(t, &redka.Options{ChangeLog: true})
+	_, _ = db.Changes().Truncate(math.MaxInt64)
+	red := redis.RedkaDB(db)
+	repl := &fakeReplication{changeLog: true}
+	parse := func(b redis.BaseCmd) (Changes, error) {
+		return ParseChanges(b, repl)
+	}
+
+	_ = red.Str().Set("name", "alice")
+	_, _ = red.Key().Delete("name")
+
+	t.Run("all", func(t *testing.T) {
+		cmd := redis.MustParse(parse, "redka.changes 0 10")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+
+		changes := res.([]rchange.Change)
+		be.Equal(t, len(changes), 2)
+		be.Equal(t, changes[0].String(), "set name alice")
+		be.Equal(t, changes[1].String(), "del name")
+
+		c := changes[0]
+		seq := strconv.FormatInt(c.Seq, 10)
+		at := strconv.FormatInt(c.Time.UnixMilli(), 10)
+		prefix := "2,5," + seq + "," + at + ",name,set,3,set,name,alice,"
+		be.Equal(t, strings.HasPrefix(conn.Out(), prefix), true)
+	})
+	t.Run("since", func(t *testing.T) {
+		last, _ := db.Changes().Last()
+		cmd := redis.MustParse(parse, "redka.changes "+strconv.FormatInt(last-1, 10)+" 10")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+
+		changes := res.([]rchange.Change)
+		be.Equal(t, len(changes), 1)
+		be.Equal(t, changes[0].String(), "del name")
+	})
+	t.Run("count", func(t *testing.T) {
+		cmd := redis.MustParse(parse, "redka.changes 0 1")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, len(res.([]rchange.Change)), 1)
+	})
+	t.Run("none", func(t *testing.T) {
+		last, _ := db.Changes().Last()
+		cmd := redis.MustParse(parse, "redka.changes "+strconv.FormatInt(last, 10)+" 10")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, len(res.([]rchange.Change)), 0)
+		be.Equal(t, conn.Out(), "0")
+	})
+	t.Run("no change log", func(t *testing.T) {
+		repl := &fakeReplication{changeLog: false}
+		parse := func(b redis.BaseCmd) (Changes, error) {
+			return ParseChanges(b, repl)
+		}
+		cmd := redis.MustParse(parse, "redka.changes 0 10")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Err(t, err, redis.ErrNoChangeLog)
+		be.Equal(t, conn.Out(), redis.ErrNoChangeLog.Error()+" (redka.changes)")
+	})
+}
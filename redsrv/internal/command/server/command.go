@@ -0,0 +1,216 @@
+package server
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/nalgeon/redka/redsrv/internal/parser"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Returns detailed information about the server commands.
+// COMMAND
+// COMMAND COUNT
+// COMMAND DOCS [command-name [command-name ...]]
+// COMMAND GETKEYS command [arg [arg ...]]
+// COMMAND INFO [command-name [command-name ...]]
+// COMMAND LIST [FILTERBY <MODULE module-name | ACLCAT category | PATTERN pattern>]
+// https://redis.io/commands/command
+type Command struct {
+	redis.BaseCmd
+	table   *redis.CommandTable
+	subcmd  string
+	names   []string
+	aclcat  string
+	pattern string
+	module  string
+	args    [][]byte
+}
+
+func ParseCommand(b redis.BaseCmd, table *redis.CommandTable) (Command, error) {
+	cmd := Command{BaseCmd: b, table: table}
+	if len(cmd.Args()) == 0 {
+		// COMMAND without arguments is the same as COMMAND INFO.
+		cmd.subcmd = "info"
+		return cmd, nil
+	}
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
+
+	// Parse the subcommand.
+	var err error
+	args := cmd.Args()[1:]
+	switch cmd.subcmd {
+	case "count":
+		if len(args) != 0 {
+			err = redis.ErrInvalidArgNum
+		}
+	case "docs", "info":
+		err = parser.New(
+			parser.Strings(&cmd.names),
+		).Required(0).Run(args)
+	case "getkeys":
+		if len(args) == 0 {
+			err = redis.ErrInvalidArgNum
+		}
+		cmd.args = args
+	case "list":
+		err = parser.New(
+			parser.Named("filterby", parser.OneOf(
+				parser.Named("module", parser.String(&cmd.module)),
+				parser.Named("aclcat", parser.String(&cmd.aclcat)),
+				parser.Named("pattern", parser.String(&cmd.pattern)),
+			)),
+		).Required(0).Run(args)
+	default:
+		err = redis.ErrUnknownSubcmd
+	}
+
+	// Return the resulting command.
+	if err != nil {
+		return Command{}, err
+	}
+	return cmd, nil
+}
+
+func (c Command) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	switch c.subcmd {
+	case "count":
+		n := c.table.Len()
+		w.WriteInt(n)
+		return n, nil
+	case "docs":
+		return c.runDocs(w)
+	case "getkeys":
+		return c.runGetKeys(w)
+	case "list":
+		return c.runList(w)
+	default:
+		return c.runInfo(w)
+	}
+}
+
+// runDocs returns the documentation for the commands.
+func (c Command) runDocs(w redis.Writer) (any, error) {
+	cmds := c.lookup(false)
+	w.WriteArray(len(cmds) * 2)
+	for _, info := range cmds {
+		w.WriteBulkString(info.Name)
+		w.WriteArray(6)
+		w.WriteBulkString("summary")
+		w.WriteBulkString(info.Summary)
+		w.WriteBulkString("since")
+		w.WriteBulkString(info.Since)
+		w.WriteBulkString("group")
+		w.WriteBulkString(info.Group)
+	}
+	return cmds, nil
+}
+
+// runGetKeys extracts the keys from the command arguments.
+func (c Command) runGetKeys(w redis.Writer) (any, error) {
+	info, ok := c.table.Get(string(c.args[0]))
+	if !ok {
+		err := redis.ErrUnknownCmd
+		w.WriteError(c.Error(err))
+		return nil, err
+	}
+	keys, err := info.Keys(c.args)
+	if err != nil {
+		w.WriteError(c.Error(err))
+		return nil, err
+	}
+	w.WriteArray(len(keys))
+	for _, key := range keys {
+		w.WriteBulkString(key)
+	}
+	return keys, nil
+}
+
+// runInfo returns the details of the commands.
+func (c Command) runInfo(w redis.Writer) (any, error) {
+	if len(c.names) == 0 {
+		cmds := c.table.All()
+		w.WriteArray(len(cmds))
+		for _, info := range cmds {
+			writeCommandInfo(w, info)
+		}
+		return cmds, nil
+	}
+
+	// Unknown commands are reported as nil.
+	cmds := c.lookup(true)
+	w.WriteArray(len(cmds))
+	for _, info := range cmds {
+		if info.Name == "" {
+			w.WriteNull()
+			continue
+		}
+		writeCommandInfo(w, info)
+	}
+	return cmds, nil
+}
+
+// runList returns the names of the commands matching the filter.
+func (c Command) runList(w redis.Writer) (any, error) {
+	names := []string{}
+	if c.module == "" {
+		for _, info := range c.table.All() {
+			if c.aclcat != "" && !slices.Contains(info.ACL, "@"+strings.ToLower(c.aclcat)) {
+				continue
+			}
+			if c.pattern != "" {
+				if ok, _ := path.Match(c.pattern, info.Name); !ok {
+					continue
+				}
+			}
+			names = append(names, info.Name)
+		}
+	}
+	w.WriteArray(len(names))
+	for _, name := range names {
+		w.WriteBulkString(name)
+	}
+	return names, nil
+}
+
+// lookup returns the commands requested by name.
+// If no names are given, returns all commands.
+// If withUnknown is true, unknown commands are returned as
+// empty values; otherwise, they are skipped.
+func (c Command) lookup(withUnknown bool) []redis.CommandInfo {
+	if len(c.names) == 0 {
+		return c.table.All()
+	}
+	cmds := make([]redis.CommandInfo, 0, len(c.names))
+	for _, name := range c.names {
+		info, ok := c.table.Get(name)
+		if !ok && !withUnknown {
+			continue
+		}
+		cmds = append(cmds, info)
+	}
+	return cmds
+}
+
+// writeCommandInfo writes the command details
+// in the format of the COMMAND INFO reply.
+func writeCommandInfo(w redis.Writer, info redis.CommandInfo) {
+	w.WriteArray(10)
+	w.WriteBulkString(info.Name)
+	w.WriteInt(info.Arity)
+	w.WriteArray(len(info.Flags))
+	for _, flag := range info.Flags {
+		w.WriteString(flag)
+	}
+	w.WriteInt(info.FirstKey)
+	w.WriteInt(info.LastKey)
+	w.WriteInt(info.Step)
+	w.WriteArray(len(info.ACL))
+	for _, cat := range info.ACL {
+		w.WriteString(cat)
+	}
+	w.WriteArray(0) // tips
+	w.WriteArray(0) // key specifications
+	w.WriteArray(0) // subcommands
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+var testTable = redis.NewCommandTable([]redis.CommandInfo{
+	{
+		Name: "get", Arity: 2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@string", "@fast"},
+		Group: "string", Since: "1.0.0",
+		Summary: "Returns the string value of a key.",
+	},
+	{
+		Name: "mset", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: -1, Step: 2,
+		ACL:   []string{"@write", "@string", "@slow"},
+		Group: "string", Since: "1.0.1",
+		Summary: "Atomically creates or modifies the string values of one or more keys.",
+	},
+	{
+		Name: "ping", Arity: -1,
+		Flags: []string{redis.FlagFast, redis.FlagStale},
+		ACL:   []string{"@fast", "@connection"},
+		Group: "connection", Since: "1.0.0",
+		Summary: "Returns the server's liveliness response.",
+	},
+	{
+		Name: "zinterstore", Arity: -4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagMovableKeys},
+		FirstKey: 1, LastKey: 1, Step: 1, NumKeys: 2,
+		ACL:   []string{"@write", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "2.0.0",
+		Summary: "Stores the intersect of multiple sorted sets in a key.",
+	},
+})
+
+func parseCommand(b redis.BaseCmd) (Command, error) {
+	return ParseCommand(b, testTable)
+}
+
+func TestCommandParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want Command
+		err  error
+	}{
+		{
+			cmd:  "command",
+			want: Command{subcmd: "info"},
+			err:  nil,
+		},
+		{
+			cmd:  "command count",
+			want: Command{subcmd: "count"},
+			err:  nil,
+		},
+		{
+			cmd:  "command count get",
+			want: Command{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "command info get ping",
+			want: Command{subcmd: "info", names: []string{"get", "ping"}},
+			err:  nil,
+		},
+		{
+			cmd:  "command DOCS get",
+			want: Command{subcmd: "docs", names: []string{"get"}},
+			err:  nil,
+		},
+		{
+			cmd:  "command getkeys",
+			want: Command{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "command list filterby pattern z*",
+			want: Command{subcmd: "list", pattern: "z*"},
+			err:  nil,
+		},
+		{
+			cmd:  "command list filterby",
+			want: Command{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "command reset",
+			want: Command{},
+			err:  redis.ErrUnknownSubcmd,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parseCommand, test.cmd)
+			be.Equal(t, err, test.err)
+			if err == nil {
+				be.Equal(t, cmd.subcmd, test.want.subcmd)
+				be.Equal(t, cmd.names, test.want.names)
+				be.Equal(t, cmd.pattern, test.want.pattern)
+			} else {
+				be.Equal(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestCommandExec(t *testing.T) {
+	red := getRedka(t)
+
+	tests := []struct {
+		cmd string
+		out string
+	}{
+		{
+			cmd: "command count",
+			out: "4",
+		},
+		{
+			cmd: "command info get unknown",
+			out: "2,10,get,2,2,readonly,fast,1,1,1,3,@read,@string,@fast,0,0,0,(nil)",
+		},
+		{
+			cmd: "command docs ping",
+			out: "2,ping,6,summary,Returns the server's liveliness response.," +
+				"since,1.0.0,group,connection",
+		},
+		{
+			cmd: "command list",
+			out: "4,get,mset,ping,zinterstore",
+		},
+		{
+			cmd: "command list filterby aclcat string",
+			out: "2,get,mset",
+		},
+		{
+			cmd: "command list filterby pattern *s*",
+			out: "2,mset,zinterstore",
+		},
+		{
+			cmd: "command list filterby module json",
+			out: "0",
+		},
+		{
+			cmd: "command getkeys mset k1 v1 k2 v2",
+			out: "2,k1,k2",
+		},
+		{
+			cmd: "command getkeys zinterstore dest 2 k1 k2 aggregate sum",
+			out: "3,dest,k1,k2",
+		},
+		{
+			cmd: "command getkeys ping",
+			out: redis.ErrNoKeys.Error() + " (command)",
+		},
+		{
+			cmd: "command getkeys get",
+			out: redis.ErrInvalidArgNum.Error() + " (command)",
+		},
+		{
+			cmd: "command getkeys unknown key",
+			out: redis.ErrUnknownCmd.Error() + " (command)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			conn := redis.NewFakeConn()
+			cmd := redis.MustParse(parseCommand, test.cmd)
+			_, _ = cmd.Run(conn, red)
+			be.Equal(t, conn.Out(), test.out)
+		})
+	}
+}
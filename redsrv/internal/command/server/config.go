@@ -1,32 +1,45 @@
 package server
 
 import (
+	"strings"
+
 	"github.com/nalgeon/redka/redsrv/internal/redis"
 )
 
 // Container command for runtime configuration commands.
-// CONFIG
+// CONFIG GET parameter [parameter ...]
+// CONFIG SET parameter value [parameter value ...]
+// CONFIG RESETSTAT
+// CONFIG REWRITE
 // https://redis.io/commands/config
 type Config struct {
 	redis.BaseCmd
+	config redis.Config
 	subcmd string
 	get    ConfigGet
+	set    ConfigSet
 }
 
-func ParseConfig(b redis.BaseCmd) (Config, error) {
+func ParseConfig(b redis.BaseCmd, config redis.Config) (Config, error) {
 	// Extract the subcommand.
-	cmd := Config{BaseCmd: b}
+	cmd := Config{BaseCmd: b, config: config}
 	if len(cmd.Args()) == 0 {
 		return Config{}, redis.ErrInvalidArgNum
 	}
-	cmd.subcmd = string(cmd.Args()[0])
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
 
 	// Parse the subcommand.
 	var err error
 	args := cmd.Args()[1:]
 	switch cmd.subcmd {
 	case "get":
-		cmd.get, err = ParseConfigGet(args)
+		cmd.get, err = ParseConfigGet(args, config)
+	case "set":
+		cmd.set, err = ParseConfigSet(args, config)
+	case "resetstat", "rewrite":
+		if len(args) != 0 {
+			err = redis.ErrInvalidArgNum
+		}
 	default:
 		err = redis.ErrUnknownSubcmd
 	}
@@ -42,6 +55,24 @@ func (c Config) Run(w redis.Writer, red redis.Redka) (any, error) {
 	switch c.subcmd {
 	case "get":
 		return c.get.Run(w, red)
+	case "set":
+		res, err := c.set.Run(w, red)
+		if err != nil {
+			w.WriteError(c.Error(err))
+		}
+		return res, err
+	case "resetstat":
+		c.config.ResetStat()
+		w.WriteString("OK")
+		return true, nil
+	case "rewrite":
+		err := c.config.Rewrite()
+		if err != nil {
+			w.WriteError(c.Error(err))
+			return nil, err
+		}
+		w.WriteString("OK")
+		return true, nil
 	default:
 		w.WriteString("OK")
 		return true, nil
@@ -1,12 +1,60 @@
 package server
 
 import (
+	"errors"
+	"path"
 	"testing"
 
 	"github.com/nalgeon/be"
 	"github.com/nalgeon/redka/redsrv/internal/redis"
 )
 
+var errUnknownParam = errors.New("ERR unknown option")
+
+// fakeConfig is an in-memory configuration for testing.
+type fakeConfig struct {
+	params  map[string]string
+	nResets int
+}
+
+func newFakeConfig() *fakeConfig {
+	return &fakeConfig{params: map[string]string{
+		"databases":       "1",
+		"db-timeout":      "5000",
+		"expire-interval": "60",
+	}}
+}
+
+func (c *fakeConfig) Get(pattern string) map[string]string {
+	values := map[string]string{}
+	for name, value := range c.params {
+		if ok, _ := path.Match(pattern, name); ok {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+func (c *fakeConfig) Set(params map[string]string) error {
+	for name := range params {
+		if _, ok := c.params[name]; !ok {
+			return errUnknownParam
+		}
+	}
+	for name, value := range params {
+		c.params[name] = value
+	}
+	return nil
+}
+
+func (c *fakeConfig) ResetStat() {
+	c.nResets++
+}
+
+func (c *fakeConfig) Rewrite() error {
+	return errors.New("ERR the server is running without a config file")
+}
+
 func TestConfigParse(t *testing.T) {
 	tests := []struct {
 		cmd  string
@@ -28,16 +76,49 @@ func TestConfigParse(t *testing.T) {
 			want: Config{subcmd: "get"},
 			err:  nil,
 		},
+		{
+			cmd:  "config GET *",
+			want: Config{subcmd: "get"},
+			err:  nil,
+		},
 		{
 			cmd:  "config set parameter value",
+			want: Config{subcmd: "set"},
+			err:  nil,
+		},
+		{
+			cmd:  "config set parameter",
+			want: Config{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "config set parameter 1 parameter 2",
+			want: Config{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "config resetstat",
+			want: Config{subcmd: "resetstat"},
+			err:  nil,
+		},
+		{
+			cmd:  "config rewrite now",
+			want: Config{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "config help",
 			want: Config{},
 			err:  redis.ErrUnknownSubcmd,
 		},
 	}
 
+	parse := func(b redis.BaseCmd) (Config, error) {
+		return ParseConfig(b, newFakeConfig())
+	}
 	for _, test := range tests {
 		t.Run(test.cmd, func(t *testing.T) {
-			cmd, err := redis.Parse(ParseConfig, test.cmd)
+			cmd, err := redis.Parse(parse, test.cmd)
 			be.Equal(t, err, test.err)
 			if err == nil {
 				be.Equal(t, cmd.subcmd, test.want.subcmd)
@@ -51,12 +132,88 @@ func TestConfigParse(t *testing.T) {
 func TestConfigExec(t *testing.T) {
 	t.Run("config get", func(t *testing.T) {
 		red := getRedka(t)
+		config := newFakeConfig()
+		parse := func(b redis.BaseCmd) (Config, error) {
+			return ParseConfig(b, config)
+		}
+
+		cmd := redis.MustParse(parse, "config get d* expire-interval")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, conn.Out(),
+			"6,databases,1,db-timeout,5000,expire-interval,60")
+	})
+	t.Run("config get none", func(t *testing.T) {
+		red := getRedka(t)
+		config := newFakeConfig()
+		parse := func(b redis.BaseCmd) (Config, error) {
+			return ParseConfig(b, config)
+		}
 
-		cmd := redis.MustParse(ParseConfig, "config get *")
+		cmd := redis.MustParse(parse, "config get unknown")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, conn.Out(), "0")
+	})
+	t.Run("config set", func(t *testing.T) {
+		red := getRedka(t)
+		config := newFakeConfig()
+		parse := func(b redis.BaseCmd) (Config, error) {
+			return ParseConfig(b, config)
+		}
+
+		cmd := redis.MustParse(parse, "config set db-timeout 1000 expire-interval 10")
 		conn := redis.NewFakeConn()
 		res, err := cmd.Run(conn, red)
 		be.Err(t, err, nil)
 		be.Equal(t, res, true)
-		be.Equal(t, conn.Out(), "2,databases,1")
+		be.Equal(t, conn.Out(), "OK")
+		be.Equal(t, config.params["db-timeout"], "1000")
+		be.Equal(t, config.params["expire-interval"], "10")
+	})
+	t.Run("config set unknown", func(t *testing.T) {
+		red := getRedka(t)
+		config := newFakeConfig()
+		parse := func(b redis.BaseCmd) (Config, error) {
+			return ParseConfig(b, config)
+		}
+
+		cmd := redis.MustParse(parse, "config set db-timeout 1000 unknown 1")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Equal(t, err, errUnknownParam)
+		be.Equal(t, res, nil)
+		be.Equal(t, conn.Out(), errUnknownParam.Error()+" (config)")
+		be.Equal(t, config.params["db-timeout"], "5000")
+	})
+	t.Run("config resetstat", func(t *testing.T) {
+		red := getRedka(t)
+		config := newFakeConfig()
+		parse := func(b redis.BaseCmd) (Config, error) {
+			return ParseConfig(b, config)
+		}
+
+		cmd := redis.MustParse(parse, "config resetstat")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, conn.Out(), "OK")
+		be.Equal(t, config.nResets, 1)
+	})
+	t.Run("config rewrite", func(t *testing.T) {
+		red := getRedka(t)
+		config := newFakeConfig()
+		parse := func(b redis.BaseCmd) (Config, error) {
+			return ParseConfig(b, config)
+		}
+
+		cmd := redis.MustParse(parse, "config rewrite")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, red)
+		be.Err(t, err)
+		be.Equal(t, conn.Out(),
+			"ERR the server is running without a config file (config)")
 	})
 }
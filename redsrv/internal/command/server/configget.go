@@ -1,6 +1,8 @@
 package server
 
 import (
+	"slices"
+
 	"github.com/nalgeon/redka/redsrv/internal/redis"
 )
 
@@ -8,14 +10,15 @@ import (
 // CONFIG GET parameter [parameter ...]
 // https://redis.io/commands/config-get
 type ConfigGet struct {
+	config redis.Config
 	params []string
 }
 
-func ParseConfigGet(args [][]byte) (ConfigGet, error) {
+func ParseConfigGet(args [][]byte, config redis.Config) (ConfigGet, error) {
 	if len(args) < 1 {
 		return ConfigGet{}, redis.ErrInvalidArgNum
 	}
-	cmd := ConfigGet{params: make([]string, len(args))}
+	cmd := ConfigGet{config: config, params: make([]string, len(args))}
 	for i, arg := range args {
 		cmd.params[i] = string(arg)
 	}
@@ -23,8 +26,24 @@ func ParseConfigGet(args [][]byte) (ConfigGet, error) {
 }
 
 func (c ConfigGet) Run(w redis.Writer, _ redis.Redka) (any, error) {
-	w.WriteArray(2)
-	w.WriteString("databases")
-	w.WriteInt(1)
-	return true, nil
+	// Collect the parameters matching any of the patterns.
+	values := map[string]string{}
+	for _, pattern := range c.params {
+		for name, value := range c.config.Get(pattern) {
+			values[name] = value
+		}
+	}
+
+	// Write the name-value pairs ordered by name.
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	w.WriteArray(len(names) * 2)
+	for _, name := range names {
+		w.WriteBulkString(name)
+		w.WriteBulkString(values[name])
+	}
+	return values, nil
 }
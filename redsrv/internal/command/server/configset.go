@@ -0,0 +1,39 @@
+package server
+
+import (
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Sets configuration parameters in-flight.
+// Either all of the parameters are set, or none of them.
+// CONFIG SET parameter value [parameter value ...]
+// https://redis.io/commands/config-set
+type ConfigSet struct {
+	config redis.Config
+	params map[string]string
+}
+
+func ParseConfigSet(args [][]byte, config redis.Config) (ConfigSet, error) {
+	if len(args) < 2 || len(args)%2 != 0 {
+		return ConfigSet{}, redis.ErrInvalidArgNum
+	}
+	cmd := ConfigSet{config: config, params: make(map[string]string, len(args)/2)}
+	for i := 0; i < len(args); i += 2 {
+		name := string(args[i])
+		if _, ok := cmd.params[name]; ok {
+			// The same parameter can't be set twice.
+			return ConfigSet{}, redis.ErrSyntaxError
+		}
+		cmd.params[name] = string(args[i+1])
+	}
+	return cmd, nil
+}
+
+func (c ConfigSet) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	err := c.config.Set(c.params)
+	if err != nil {
+		return nil, err
+	}
+	w.WriteString("OK")
+	return true, nil
+}
@@ -0,0 +1,25 @@
+package server
+
+import "github.com/nalgeon/redka/redsrv/internal/redis"
+
+// Returns the Unix timestamp of the last successful save to disk.
+// LASTSAVE
+// https://redis.io/commands/lastsave
+type LastSave struct {
+	redis.BaseCmd
+	saver redis.Saver
+}
+
+func ParseLastSave(b redis.BaseCmd, saver redis.Saver) (LastSave, error) {
+	cmd := LastSave{BaseCmd: b, saver: saver}
+	if len(cmd.Args()) != 0 {
+		return LastSave{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd LastSave) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	at := cmd.saver.LastSave().Unix()
+	w.WriteInt64(at)
+	return at, nil
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestLastSaveParse(t *testing.T) {
+	saver := new(fakeSaver)
+	parse := func(b redis.BaseCmd) (LastSave, error) {
+		return ParseLastSave(b, saver)
+	}
+
+	_, err := redis.Parse(parse, "lastsave")
+	be.Err(t, err, nil)
+
+	cmd, err := redis.Parse(parse, "lastsave now")
+	be.Equal(t, err, redis.ErrInvalidArgNum)
+	be.Equal(t, cmd, LastSave{})
+}
+
+func TestLastSaveExec(t *testing.T) {
+	saver := &fakeSaver{lastSave: time.Unix(1700000000, 0)}
+	cmd := redis.MustParse(func(b redis.BaseCmd) (LastSave, error) { return ParseLastSave(b, saver) }, "lastsave")
+	conn := redis.NewFakeConn()
+	res, err := cmd.Run(conn, redis.Redka{})
+	be.Err(t, err, nil)
+	be.Equal(t, res.(int64), int64(1700000000))
+	be.Equal(t, conn.Out(), "1700000000")
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Container command for memory diagnostics commands.
+// MEMORY USAGE key [SAMPLES count]
+// https://redis.io/commands/memory-usage
+//
+// Redka estimates the key size as the total length of the key
+// and its values plus a fixed overhead per key and per element.
+// SAMPLES is accepted but ignored, since the size is not sampled.
+type Memory struct {
+	redis.BaseCmd
+	subcmd string
+	key    string
+}
+
+func ParseMemory(b redis.BaseCmd) (Memory, error) {
+	// Extract the subcommand.
+	cmd := Memory{BaseCmd: b}
+	if len(cmd.Args()) == 0 {
+		return Memory{}, redis.ErrInvalidArgNum
+	}
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
+
+	// Parse the subcommand.
+	var err error
+	args := cmd.Args()[1:]
+	switch cmd.subcmd {
+	case "usage":
+		cmd.key, err = parseMemoryUsage(args)
+	default:
+		err = redis.ErrUnknownSubcmd
+	}
+
+	// Return the resulting command.
+	if err != nil {
+		return Memory{}, err
+	}
+	return cmd, nil
+}
+
+func (c Memory) Run(w redis.Writer, red redis.Redka) (any, error) {
+	info, err := red.Key().Info(c.key)
+	if err == core.ErrNotFound {
+		w.WriteNull()
+		return nil, nil
+	}
+	if err != nil {
+		w.WriteError(c.Error(err))
+		return nil, err
+	}
+	w.WriteInt(info.Size)
+	return info.Size, nil
+}
+
+// parseMemoryUsage parses the MEMORY USAGE arguments.
+func parseMemoryUsage(args [][]byte) (string, error) {
+	switch len(args) {
+	case 0:
+		return "", redis.ErrInvalidArgNum
+	case 1:
+		return string(args[0]), nil
+	case 3:
+		if strings.ToLower(string(args[1])) != "samples" {
+			return "", redis.ErrSyntaxError
+		}
+		if _, err := strconv.Atoi(string(args[2])); err != nil {
+			return "", redis.ErrInvalidInt
+		}
+		return string(args[0]), nil
+	default:
+		return "", redis.ErrSyntaxError
+	}
+}
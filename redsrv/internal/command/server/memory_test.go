@@ -0,0 +1,105 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestMemoryParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want Memory
+		err  error
+	}{
+		{
+			cmd:  "memory",
+			want: Memory{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "memory usage",
+			want: Memory{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "memory usage name",
+			want: Memory{subcmd: "usage", key: "name"},
+			err:  nil,
+		},
+		{
+			cmd:  "memory USAGE name samples 5",
+			want: Memory{subcmd: "usage", key: "name"},
+			err:  nil,
+		},
+		{
+			cmd:  "memory usage name samples five",
+			want: Memory{},
+			err:  redis.ErrInvalidInt,
+		},
+		{
+			cmd:  "memory usage name limit 5",
+			want: Memory{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "memory usage name samples",
+			want: Memory{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "memory stats",
+			want: Memory{},
+			err:  redis.ErrUnknownSubcmd,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(ParseMemory, test.cmd)
+			be.Equal(t, err, test.err)
+			if err == nil {
+				be.Equal(t, cmd.subcmd, test.want.subcmd)
+				be.Equal(t, cmd.key, test.want.key)
+			} else {
+				be.Equal(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestMemoryExec(t *testing.T) {
+	t.Run("usage", func(t *testing.T) {
+		red := getRedka(t)
+		_ = red.Str().Set("name", "alice")
+		_, _ = red.Hash().Set("person", "name", "alice")
+
+		tests := []struct {
+			key  string
+			want int
+		}{
+			// key + value + key overhead
+			{key: "name", want: 4 + 5 + 64},
+			// key + key overhead + field + value + elem overhead
+			{key: "person", want: 6 + 64 + 4 + 5 + 32},
+		}
+		for _, test := range tests {
+			cmd := redis.MustParse(ParseMemory, "memory usage "+test.key)
+			conn := redis.NewFakeConn()
+			res, err := cmd.Run(conn, red)
+			be.Err(t, err, nil)
+			be.Equal(t, res.(int), test.want)
+		}
+	})
+	t.Run("key not found", func(t *testing.T) {
+		red := getRedka(t)
+
+		cmd := redis.MustParse(ParseMemory, "memory usage name")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, red)
+		be.Err(t, err, nil)
+		be.Equal(t, res, nil)
+		be.Equal(t, conn.Out(), "(nil)")
+	})
+}
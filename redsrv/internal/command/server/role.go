@@ -0,0 +1,42 @@
+package server
+
+import "github.com/nalgeon/redka/redsrv/internal/redis"
+
+// Returns the replication role of the server.
+// ROLE
+// https://redis.io/commands/role
+//
+// The replication offset is the change log sequence number:
+// the last one for the primary, and the last applied one
+// for the replica. The primary does not track its replicas,
+// so the list of replicas is always empty.
+type Role struct {
+	redis.BaseCmd
+	repl redis.Replication
+}
+
+func ParseRole(b redis.BaseCmd, repl redis.Replication) (Role, error) {
+	cmd := Role{BaseCmd: b, repl: repl}
+	if len(cmd.Args()) != 0 {
+		return Role{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd Role) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	role := cmd.repl.Role()
+	if role.Replica {
+		w.WriteArray(5)
+		w.WriteBulkString("slave")
+		w.WriteBulkString(role.Host)
+		w.WriteInt(role.Port)
+		w.WriteBulkString(role.State)
+		w.WriteInt64(role.Seq)
+		return role, nil
+	}
+	w.WriteArray(3)
+	w.WriteBulkString("master")
+	w.WriteInt64(role.Seq)
+	w.WriteArray(0)
+	return role, nil
+}
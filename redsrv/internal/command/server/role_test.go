@@ -0,0 +1,44 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestRoleParse(t *testing.T) {
+	repl := new(fakeReplication)
+	parse := func(b redis.BaseCmd) (Role, error) {
+		return ParseRole(b, repl)
+	}
+
+	_, err := redis.Parse(parse, "role")
+	be.Err(t, err, nil)
+
+	cmd, err := redis.Parse(parse, "role now")
+	be.Equal(t, err, redis.ErrInvalidArgNum)
+	be.Equal(t, cmd, Role{})
+}
+
+func TestRoleExec(t *testing.T) {
+	t.Run("primary", func(t *testing.T) {
+		repl := &fakeReplication{role: redis.ReplicationRole{Seq: 42}}
+		cmd := redis.MustParse(func(b redis.BaseCmd) (Role, error) { return ParseRole(b, repl) }, "role")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, redis.Redka{})
+		be.Err(t, err, nil)
+		be.Equal(t, conn.Out(), "3,master,42,0")
+	})
+	t.Run("replica", func(t *testing.T) {
+		repl := &fakeReplication{role: redis.ReplicationRole{
+			Replica: true, Host: "localhost", Port: 6379,
+			State: redis.ReplConnected, Seq: 42,
+		}}
+		cmd := redis.MustParse(func(b redis.BaseCmd) (Role, error) { return ParseRole(b, repl) }, "role")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, redis.Redka{})
+		be.Err(t, err, nil)
+		be.Equal(t, conn.Out(), "5,slave,localhost,6379,connected,42")
+	})
+}
@@ -0,0 +1,32 @@
+package server
+
+import "github.com/nalgeon/redka/redsrv/internal/redis"
+
+// Synchronously saves the database to disk.
+// SAVE
+// https://redis.io/commands/save
+//
+// Redka writes a consistent snapshot of the SQLite database
+// (using VACUUM INTO) to the dbfilename file in the dir directory.
+type Save struct {
+	redis.BaseCmd
+	saver redis.Saver
+}
+
+func ParseSave(b redis.BaseCmd, saver redis.Saver) (Save, error) {
+	cmd := Save{BaseCmd: b, saver: saver}
+	if len(cmd.Args()) != 0 {
+		return Save{}, redis.ErrInvalidArgNum
+	}
+	return cmd, nil
+}
+
+func (cmd Save) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	err := cmd.saver.Save()
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return false, err
+	}
+	w.WriteString("OK")
+	return true, nil
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// fakeSaver is an in-memory snapshot writer for testing.
+type fakeSaver struct {
+	saving   bool
+	err      error
+	saves    int
+	lastSave time.Time
+}
+
+func (s *fakeSaver) Save() error {
+	if s.saving {
+		return redis.ErrBgSaveInProgress
+	}
+	if s.err != nil {
+		return s.err
+	}
+	s.saves++
+	return nil
+}
+
+func (s *fakeSaver) BgSave() error {
+	return s.Save()
+}
+
+func (s *fakeSaver) LastSave() time.Time {
+	return s.lastSave
+}
+
+func TestSaveParse(t *testing.T) {
+	saver := new(fakeSaver)
+	parse := func(b redis.BaseCmd) (Save, error) {
+		return ParseSave(b, saver)
+	}
+
+	_, err := redis.Parse(parse, "save")
+	be.Err(t, err, nil)
+
+	cmd, err := redis.Parse(parse, "save now")
+	be.Equal(t, err, redis.ErrInvalidArgNum)
+	be.Equal(t, cmd, Save{})
+}
+
+func TestSaveExec(t *testing.T) {
+	t.Run("save", func(t *testing.T) {
+		saver := new(fakeSaver)
+		cmd := redis.MustParse(func(b redis.BaseCmd) (Save, error) { return ParseSave(b, saver) }, "save")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, redis.Redka{})
+		be.Err(t, err, nil)
+		be.Equal(t, res, true)
+		be.Equal(t, conn.Out(), "OK")
+		be.Equal(t, saver.saves, 1)
+	})
+	t.Run("in progress", func(t *testing.T) {
+		saver := &fakeSaver{saving: true}
+		cmd := redis.MustParse(func(b redis.BaseCmd) (Save, error) { return ParseSave(b, saver) }, "save")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, redis.Redka{})
+		be.Equal(t, err, redis.ErrBgSaveInProgress)
+		be.Equal(t, res, false)
+		be.Equal(t, conn.Out(), redis.ErrBgSaveInProgress.Error()+" (save)")
+	})
+	t.Run("not supported", func(t *testing.T) {
+		saver := &fakeSaver{err: redka.ErrNotSupported}
+		cmd := redis.MustParse(func(b redis.BaseCmd) (Save, error) { return ParseSave(b, saver) }, "save")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, redis.Redka{})
+		be.Equal(t, err, redka.ErrNotSupported)
+		be.Equal(t, conn.Out(), redis.ErrNotSupported.Error()+" (save)")
+	})
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Default number of entries returned by SLOWLOG GET.
+const defaultSlowLogCount = 10
+
+// Container command for slow log commands.
+// SLOWLOG GET [count] [WITHSQL]
+// SLOWLOG LEN
+// SLOWLOG RESET
+// https://redis.io/commands/slowlog
+//
+// WITHSQL is a Redka extension. It adds the slowest SQL statement
+// executed by the command and its execution time in microseconds
+// to each entry.
+type SlowLog struct {
+	redis.BaseCmd
+	slowlog redis.SlowLog
+	subcmd  string
+	count   int
+	withSQL bool
+}
+
+func ParseSlowLog(b redis.BaseCmd, slowlog redis.SlowLog) (SlowLog, error) {
+	// Extract the subcommand.
+	cmd := SlowLog{BaseCmd: b, slowlog: slowlog, count: defaultSlowLogCount}
+	if len(cmd.Args()) == 0 {
+		return SlowLog{}, redis.ErrInvalidArgNum
+	}
+	cmd.subcmd = strings.ToLower(string(cmd.Args()[0]))
+
+	// Parse the subcommand.
+	var err error
+	args := cmd.Args()[1:]
+	switch cmd.subcmd {
+	case "get":
+		cmd.count, cmd.withSQL, err = parseSlowLogGet(args)
+	case "len", "reset":
+		if len(args) != 0 {
+			err = redis.ErrInvalidArgNum
+		}
+	default:
+		err = redis.ErrUnknownSubcmd
+	}
+
+	// Return the resulting command.
+	if err != nil {
+		return SlowLog{}, err
+	}
+	return cmd, nil
+}
+
+func (c SlowLog) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	switch c.subcmd {
+	case "get":
+		entries := c.slowlog.Entries(c.count)
+		w.WriteArray(len(entries))
+		for _, entry := range entries {
+			c.writeEntry(w, entry)
+		}
+		return entries, nil
+	case "len":
+		n := c.slowlog.Len()
+		w.WriteInt(n)
+		return n, nil
+	case "reset":
+		c.slowlog.Reset()
+		w.WriteString("OK")
+		return true, nil
+	default:
+		w.WriteString("OK")
+		return true, nil
+	}
+}
+
+// writeEntry writes a slow log entry in the Redis format.
+func (c SlowLog) writeEntry(w redis.Writer, entry redis.SlowLogEntry) {
+	if c.withSQL {
+		w.WriteArray(8)
+	} else {
+		w.WriteArray(6)
+	}
+	w.WriteInt64(entry.ID)
+	w.WriteInt64(entry.Time.Unix())
+	w.WriteInt64(entry.Duration.Microseconds())
+	w.WriteArray(len(entry.Args))
+	for _, arg := range entry.Args {
+		w.WriteBulkString(arg)
+	}
+	w.WriteBulkString(entry.ClientAddr)
+	w.WriteBulkString(entry.ClientName)
+	if c.withSQL {
+		w.WriteBulkString(entry.Query)
+		w.WriteInt64(entry.QueryTime.Microseconds())
+	}
+}
+
+// parseSlowLogGet parses the SLOWLOG GET arguments.
+func parseSlowLogGet(args [][]byte) (count int, withSQL bool, err error) {
+	count = defaultSlowLogCount
+	if len(args) > 0 && strings.EqualFold(string(args[len(args)-1]), "withsql") {
+		withSQL = true
+		args = args[:len(args)-1]
+	}
+	switch len(args) {
+	case 0:
+		return count, withSQL, nil
+	case 1:
+		count, err = strconv.Atoi(string(args[0]))
+		if err != nil || count < -1 {
+			return 0, false, redis.ErrInvalidInt
+		}
+		return count, withSQL, nil
+	default:
+		return 0, false, redis.ErrSyntaxError
+	}
+}
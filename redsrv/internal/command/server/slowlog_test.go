@@ -0,0 +1,166 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// fakeSlowLog is an in-memory slow log for testing.
+type fakeSlowLog struct {
+	entries []redis.SlowLogEntry // newest first
+}
+
+func newFakeSlowLog() *fakeSlowLog {
+	return &fakeSlowLog{entries: []redis.SlowLogEntry{
+		{
+			ID: 1, Time: time.Unix(1700000100, 0), Duration: 25 * time.Millisecond,
+			Args: []string{"get", "name"}, ClientAddr: "127.0.0.1:5001", ClientName: "api",
+			Query: "select value from rstring", QueryTime: 20 * time.Millisecond,
+		},
+		{
+			ID: 0, Time: time.Unix(1700000000, 0), Duration: 15 * time.Millisecond,
+			Args: []string{"set", "name", "alice"}, ClientAddr: "127.0.0.1:5002",
+		},
+	}}
+}
+
+func (l *fakeSlowLog) Entries(count int) []redis.SlowLogEntry {
+	if count < 0 || count > len(l.entries) {
+		count = len(l.entries)
+	}
+	return l.entries[:count]
+}
+
+func (l *fakeSlowLog) Len() int {
+	return len(l.entries)
+}
+
+func (l *fakeSlowLog) Reset() {
+	l.entries = nil
+}
+
+func TestSlowLogParse(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want SlowLog
+		err  error
+	}{
+		{
+			cmd:  "slowlog",
+			want: SlowLog{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "slowlog get",
+			want: SlowLog{subcmd: "get", count: 10},
+			err:  nil,
+		},
+		{
+			cmd:  "slowlog GET 5",
+			want: SlowLog{subcmd: "get", count: 5},
+			err:  nil,
+		},
+		{
+			cmd:  "slowlog get -1 withsql",
+			want: SlowLog{subcmd: "get", count: -1, withSQL: true},
+			err:  nil,
+		},
+		{
+			cmd:  "slowlog get withsql",
+			want: SlowLog{subcmd: "get", count: 10, withSQL: true},
+			err:  nil,
+		},
+		{
+			cmd:  "slowlog get five",
+			want: SlowLog{},
+			err:  redis.ErrInvalidInt,
+		},
+		{
+			cmd:  "slowlog get -2",
+			want: SlowLog{},
+			err:  redis.ErrInvalidInt,
+		},
+		{
+			cmd:  "slowlog get 1 2",
+			want: SlowLog{},
+			err:  redis.ErrSyntaxError,
+		},
+		{
+			cmd:  "slowlog len",
+			want: SlowLog{subcmd: "len", count: 10},
+			err:  nil,
+		},
+		{
+			cmd:  "slowlog reset now",
+			want: SlowLog{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "slowlog help",
+			want: SlowLog{},
+			err:  redis.ErrUnknownSubcmd,
+		},
+	}
+
+	parse := func(b redis.BaseCmd) (SlowLog, error) {
+		return ParseSlowLog(b, newFakeSlowLog())
+	}
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			be.Equal(t, err, test.err)
+			if err == nil {
+				be.Equal(t, cmd.subcmd, test.want.subcmd)
+				be.Equal(t, cmd.count, test.want.count)
+				be.Equal(t, cmd.withSQL, test.want.withSQL)
+			} else {
+				be.Equal(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestSlowLogExec(t *testing.T) {
+	run := func(t *testing.T, slowlog *fakeSlowLog, text string) (any, string) {
+		t.Helper()
+		parse := func(b redis.BaseCmd) (SlowLog, error) {
+			return ParseSlowLog(b, slowlog)
+		}
+		cmd := redis.MustParse(parse, text)
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, getRedka(t))
+		be.Err(t, err, nil)
+		return res, conn.Out()
+	}
+
+	t.Run("get", func(t *testing.T) {
+		res, out := run(t, newFakeSlowLog(), "slowlog get")
+		be.Equal(t, len(res.([]redis.SlowLogEntry)), 2)
+		be.Equal(t, out, "2,"+
+			"6,1,1700000100,25000,2,get,name,127.0.0.1:5001,api,"+
+			"6,0,1700000000,15000,3,set,name,alice,127.0.0.1:5002,")
+	})
+	t.Run("get count", func(t *testing.T) {
+		_, out := run(t, newFakeSlowLog(), "slowlog get 1")
+		be.Equal(t, out, "1,6,1,1700000100,25000,2,get,name,127.0.0.1:5001,api")
+	})
+	t.Run("get withsql", func(t *testing.T) {
+		_, out := run(t, newFakeSlowLog(), "slowlog get 1 withsql")
+		be.Equal(t, out, "1,8,1,1700000100,25000,2,get,name,127.0.0.1:5001,api,"+
+			"select value from rstring,20000")
+	})
+	t.Run("len", func(t *testing.T) {
+		res, out := run(t, newFakeSlowLog(), "slowlog len")
+		be.Equal(t, res, 2)
+		be.Equal(t, out, "2")
+	})
+	t.Run("reset", func(t *testing.T) {
+		slowlog := newFakeSlowLog()
+		_, out := run(t, slowlog, "slowlog reset")
+		be.Equal(t, out, "OK")
+		be.Equal(t, slowlog.Len(), 0)
+	})
+}
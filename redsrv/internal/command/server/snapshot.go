@@ -0,0 +1,65 @@
+package server
+
+import (
+	"github.com/nalgeon/redka/redsrv/internal/parser"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Returns a page of the commands that recreate the database contents.
+// The reply is an array of the next page cursor (0 if this is the last
+// page), the sequence number of the last change log entry included
+// in the snapshot, and the commands. All pages come from the same
+// read transaction, so the snapshot is consistent.
+// Replicas call REDKA.SNAPSHOT to load the initial data
+// before applying the changes (see REDKA.CHANGES).
+// Requires the change log to be enabled.
+// REDKA.SNAPSHOT cursor [COUNT count]
+type Snapshot struct {
+	redis.BaseCmd
+	repl   redis.Replication
+	cursor int64
+	count  int
+}
+
+func ParseSnapshot(b redis.BaseCmd, repl redis.Replication) (Snapshot, error) {
+	cmd := Snapshot{BaseCmd: b, repl: repl}
+	var cursor int
+	err := parser.New(
+		parser.Int(&cursor),
+		parser.Named("count", parser.Int(&cmd.count)),
+	).Required(1).Run(cmd.Args())
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if cursor < 0 {
+		return Snapshot{}, redis.ErrInvalidCursor
+	}
+	if cmd.count < 0 {
+		return Snapshot{}, redis.ErrOutOfRange
+	}
+	cmd.cursor = int64(cursor)
+	return cmd, nil
+}
+
+func (cmd Snapshot) Run(w redis.Writer, _ redis.Redka) (any, error) {
+	if !cmd.repl.ChangeLog() {
+		w.WriteError(cmd.Error(redis.ErrNoChangeLog))
+		return nil, redis.ErrNoChangeLog
+	}
+	page, err := cmd.repl.Snapshot(cmd.cursor, cmd.count)
+	if err != nil {
+		w.WriteError(cmd.Error(err))
+		return nil, err
+	}
+	w.WriteArray(3)
+	w.WriteInt64(page.Cursor)
+	w.WriteInt64(page.Seq)
+	w.WriteArray(len(page.Cmds))
+	for _, c := range page.Cmds {
+		w.WriteArray(len(c))
+		for _, arg := range c {
+			w.WriteBulk(arg)
+		}
+	}
+	return page, nil
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// fakeReplication is an in-memory replication state for testing.
+// Snapshot cursors are the indexes of the next commands.
+type fakeReplication struct {
+	role      redis.ReplicationRole
+	changeLog bool
+	seq       int64
+	cmds      [][][]byte
+}
+
+func (r *fakeReplication) Role() redis.ReplicationRole {
+	return r.role
+}
+
+func (r *fakeReplication) ChangeLog() bool {
+	return r.changeLog
+}
+
+func (r *fakeReplication) Snapshot(cursor int64, count int) (redis.SnapshotPage, error) {
+	if cursor > int64(len(r.cmds)) {
+		return redis.SnapshotPage{}, redis.ErrInvalidCursor
+	}
+	if count == 0 {
+		count = 10
+	}
+	start := int(cursor)
+	end := min(start+count, len(r.cmds))
+	page := redis.SnapshotPage{Seq: r.seq, Cmds: r.cmds[start:end]}
+	if end < len(r.cmds) {
+		page.Cursor = int64(end)
+	}
+	return page, nil
+}
+
+func TestSnapshotParse(t *testing.T) {
+	repl := new(fakeReplication)
+	parse := func(b redis.BaseCmd) (Snapshot, error) {
+		return ParseSnapshot(b, repl)
+	}
+
+	tests := []struct {
+		cmd  string
+		want Snapshot
+		err  error
+	}{
+		{
+			cmd:  "redka.snapshot",
+			want: Snapshot{},
+			err:  redis.ErrInvalidArgNum,
+		},
+		{
+			cmd:  "redka.snapshot 0",
+			want: Snapshot{cursor: 0, count: 0},
+			err:  nil,
+		},
+		{
+			cmd:  "redka.snapshot 42 count 100",
+			want: Snapshot{cursor: 42, count: 100},
+			err:  nil,
+		},
+		{
+			cmd:  "redka.snapshot now",
+			want: Snapshot{},
+			err:  redis.ErrInvalidInt,
+		},
+		{
+			cmd:  "redka.snapshot -1",
+			want: Snapshot{},
+			err:  redis.ErrInvalidCursor,
+		},
+		{
+			cmd:  "redka.snapshot 0 count -1",
+			want: Snapshot{},
+			err:  redis.ErrOutOfRange,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cmd, func(t *testing.T) {
+			cmd, err := redis.Parse(parse, test.cmd)
+			be.Equal(t, err, test.err)
+			if err == nil {
+				be.Equal(t, cmd.repl, redis.Replication(repl))
+				be.Equal(t, cmd.cursor, test.want.cursor)
+				be.Equal(t, cmd.count, test.want.count)
+			} else {
+				be.Equal(t, cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotExec(t *testing.T) {
+	parse := func(repl redis.Replication) func(redis.BaseCmd) (Snapshot, error) {
+		return func(b redis.BaseCmd) (Snapshot, error) { return ParseSnapshot(b, repl) }
+	}
+	cmds := [][][]byte{
+		{[]byte("set"), []byte("name"), []byte("alice")},
+		{[]byte("rpush"), []byte("list"), []byte("a"), []byte("b")},
+	}
+
+	t.Run("keys", func(t *testing.T) {
+		repl := &fakeReplication{changeLog: true, seq: 42, cmds: cmds}
+		cmd := redis.MustParse(parse(repl), "redka.snapshot 0")
+		conn := redis.NewFakeConn()
+		res, err := cmd.Run(conn, redis.Redka{})
+		be.Err(t, err, nil)
+		be.Equal(t, len(res.(redis.SnapshotPage).Cmds), 2)
+		be.Equal(t, conn.Out(), "3,0,42,2,3,set,name,alice,4,rpush,list,a,b")
+	})
+	t.Run("pages", func(t *testing.T) {
+		repl := &fakeReplication{changeLog: true, seq: 42, cmds: cmds}
+		cmd := redis.MustParse(parse(repl), "redka.snapshot 0 count 1")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, redis.Redka{})
+		be.Err(t, err, nil)
+		be.Equal(t, conn.Out(), "3,1,42,1,3,set,name,alice")
+
+		cmd = redis.MustParse(parse(repl), "redka.snapshot 1 count 1")
+		conn = redis.NewFakeConn()
+		_, err = cmd.Run(conn, redis.Redka{})
+		be.Err(t, err, nil)
+		be.Equal(t, conn.Out(), "3,0,42,1,4,rpush,list,a,b")
+	})
+	t.Run("empty", func(t *testing.T) {
+		repl := &fakeReplication{changeLog: true, seq: 0}
+		cmd := redis.MustParse(parse(repl), "redka.snapshot 0")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, redis.Redka{})
+		be.Err(t, err, nil)
+		be.Equal(t, conn.Out(), "3,0,0,0")
+	})
+	t.Run("invalid cursor", func(t *testing.T) {
+		repl := &fakeReplication{changeLog: true, seq: 42, cmds: cmds}
+		cmd := redis.MustParse(parse(repl), "redka.snapshot 10")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, redis.Redka{})
+		be.Err(t, err, redis.ErrInvalidCursor)
+		be.Equal(t, conn.Out(), redis.ErrInvalidCursor.Error()+" (redka.snapshot)")
+	})
+	t.Run("no change log", func(t *testing.T) {
+		repl := &fakeReplication{cmds: cmds}
+		cmd := redis.MustParse(parse(repl), "redka.snapshot 0")
+		conn := redis.NewFakeConn()
+		_, err := cmd.Run(conn, redis.Redka{})
+		be.Err(t, err, redis.ErrNoChangeLog)
+		be.Equal(t, conn.Out(), redis.ErrNoChangeLog.Error()+" (redka.snapshot)")
+	})
+}
@@ -0,0 +1,946 @@
+package command
+
+import "github.com/nalgeon/redka/redsrv/internal/redis"
+
+// Table describes the commands supported by the server.
+// Used to serve the COMMAND command and to validate the arity
+// of incoming commands before parsing them.
+var Table = redis.NewCommandTable([]redis.CommandInfo{
+	// server
+	{
+		Name: "bgsave", Arity: -1,
+		Flags: []string{redis.FlagAdmin, redis.FlagNoScript},
+		ACL:   []string{"@admin", "@slow", "@dangerous"},
+		Group: "server", Since: "1.0.0",
+		Summary: "Asynchronously saves the database(s) to disk.",
+	},
+	{
+		Name: "command", Arity: -1,
+		Flags: []string{redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@slow", "@connection"},
+		Group: "server", Since: "2.8.13",
+		Summary: "Returns detailed information about all commands.",
+	},
+	{
+		Name: "config", Arity: -2,
+		Flags: []string{redis.FlagAdmin, redis.FlagNoScript, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@admin", "@slow", "@dangerous"},
+		Group: "server", Since: "2.0.0",
+		Summary: "A container for server configuration commands.",
+	},
+	{
+		Name: "dbsize", Arity: 1,
+		Flags: []string{redis.FlagReadonly, redis.FlagFast},
+		ACL:   []string{"@keyspace", "@read", "@fast"},
+		Group: "server", Since: "1.0.0",
+		Summary: "Returns the number of keys in the database.",
+	},
+	{
+		Name: "flushall", Arity: -1,
+		Flags: []string{redis.FlagWrite},
+		ACL:   []string{"@keyspace", "@write", "@slow", "@dangerous"},
+		Group: "server", Since: "1.0.0",
+		Summary: "Removes all keys from all databases.",
+	},
+	{
+		Name: "flushdb", Arity: -1,
+		Flags: []string{redis.FlagWrite},
+		ACL:   []string{"@keyspace", "@write", "@slow", "@dangerous"},
+		Group: "server", Since: "1.0.0",
+		Summary: "Removes all keys from the current database.",
+	},
+	{
+		Name: "info", Arity: -1,
+		Flags: []string{redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@slow", "@dangerous"},
+		Group: "server", Since: "1.0.0",
+		Summary: "Returns information and statistics about the server.",
+	},
+	{
+		Name: "lastsave", Arity: 1,
+		Flags: []string{redis.FlagLoading, redis.FlagStale, redis.FlagFast},
+		ACL:   []string{"@admin", "@fast", "@dangerous"},
+		Group: "server", Since: "1.0.0",
+		Summary: "Returns the Unix timestamp of the last successful save to disk.",
+	},
+	{
+		Name: "lolwut", Arity: -1,
+		Flags: []string{redis.FlagReadonly, redis.FlagFast},
+		ACL:   []string{"@read", "@fast"},
+		Group: "server", Since: "5.0.0",
+		Summary: "Provides an answer to a yes/no question.",
+	},
+	{
+		Name: "memory", Arity: -2,
+		ACL:   []string{"@slow"},
+		Group: "server", Since: "4.0.0",
+		Summary: "A container for memory diagnostics commands.",
+	},
+	{
+		Name: "monitor", Arity: 1,
+		Flags: []string{redis.FlagAdmin, redis.FlagNoScript, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@admin", "@slow", "@dangerous"},
+		Group: "server", Since: "1.0.0",
+		Summary: "Listens for all requests received by the server in real-time.",
+	},
+	{
+		Name: "redka.changes", Arity: 3,
+		Flags:   []string{redis.FlagReadonly, redis.FlagLoading, redis.FlagStale},
+		ACL:     []string{"@admin", "@read", "@slow"},
+		Group:   "server",
+		Summary: "Returns the change log entries after the given sequence number.",
+	},
+	{
+		Name: "redka.snapshot", Arity: -2,
+		Flags:   []string{redis.FlagReadonly, redis.FlagAdmin, redis.FlagNoScript},
+		ACL:     []string{"@admin", "@read", "@slow", "@dangerous"},
+		Group:   "server",
+		Summary: "Returns a page of the commands that recreate the database contents.",
+	},
+	{
+		Name: "role", Arity: 1,
+		Flags: []string{redis.FlagNoScript, redis.FlagLoading, redis.FlagStale, redis.FlagFast},
+		ACL:   []string{"@admin", "@fast", "@dangerous"},
+		Group: "server", Since: "2.8.12",
+		Summary: "Returns the replication role.",
+	},
+	{
+		Name: "save", Arity: 1,
+		Flags: []string{redis.FlagAdmin, redis.FlagNoScript},
+		ACL:   []string{"@admin", "@slow", "@dangerous"},
+		Group: "server", Since: "1.0.0",
+		Summary: "Synchronously saves the database(s) to disk.",
+	},
+	{
+		Name: "slowlog", Arity: -2,
+		Flags: []string{redis.FlagAdmin, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@admin", "@slow", "@dangerous"},
+		Group: "server", Since: "2.2.12",
+		Summary: "A container for slow log commands.",
+	},
+
+	// connection
+	{
+		Name: "client", Arity: -2,
+		Flags: []string{redis.FlagNoScript, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@slow", "@connection"},
+		Group: "connection", Since: "2.4.0",
+		Summary: "A container for client connection commands.",
+	},
+	{
+		Name: "echo", Arity: -2,
+		Flags: []string{redis.FlagFast, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@fast", "@connection"},
+		Group: "connection", Since: "1.0.0",
+		Summary: "Returns the given string.",
+	},
+	{
+		Name: "ping", Arity: -1,
+		Flags: []string{redis.FlagFast, redis.FlagStale},
+		ACL:   []string{"@fast", "@connection"},
+		Group: "connection", Since: "1.0.0",
+		Summary: "Returns the server's liveliness response.",
+	},
+	{
+		Name: "select", Arity: 2,
+		Flags: []string{redis.FlagFast, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@fast", "@connection"},
+		Group: "connection", Since: "1.0.0",
+		Summary: "Changes the selected database.",
+	},
+
+	// transactions
+	{
+		Name: "discard", Arity: 1,
+		Flags: []string{redis.FlagNoScript, redis.FlagLoading, redis.FlagStale, redis.FlagFast},
+		ACL:   []string{"@fast", "@transaction"},
+		Group: "transactions", Since: "2.0.0",
+		Summary: "Discards a transaction.",
+	},
+	{
+		Name: "exec", Arity: 1,
+		Flags: []string{redis.FlagNoScript, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@slow", "@transaction"},
+		Group: "transactions", Since: "1.2.0",
+		Summary: "Executes all commands in a transaction.",
+	},
+	{
+		Name: "multi", Arity: 1,
+		Flags: []string{redis.FlagNoScript, redis.FlagLoading, redis.FlagStale, redis.FlagFast},
+		ACL:   []string{"@fast", "@transaction"},
+		Group: "transactions", Since: "1.2.0",
+		Summary: "Starts a transaction.",
+	},
+
+	// pubsub
+	{
+		Name: "psubscribe", Arity: -2,
+		Flags: []string{redis.FlagPubSub, redis.FlagNoScript, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@pubsub", "@slow"},
+		Group: "pubsub", Since: "2.0.0",
+		Summary: "Listens for messages published to channels that match one or more patterns.",
+	},
+	{
+		Name: "publish", Arity: 3,
+		Flags: []string{redis.FlagPubSub, redis.FlagLoading, redis.FlagStale, redis.FlagFast},
+		ACL:   []string{"@pubsub", "@fast"},
+		Group: "pubsub", Since: "2.0.0",
+		Summary: "Posts a message to a channel.",
+	},
+	{
+		Name: "punsubscribe", Arity: -1,
+		Flags: []string{redis.FlagPubSub, redis.FlagNoScript, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@pubsub", "@slow"},
+		Group: "pubsub", Since: "2.0.0",
+		Summary: "Stops listening to messages published to channels that match one or more patterns.",
+	},
+	{
+		Name: "subscribe", Arity: -2,
+		Flags: []string{redis.FlagPubSub, redis.FlagNoScript, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@pubsub", "@slow"},
+		Group: "pubsub", Since: "2.0.0",
+		Summary: "Listens for messages published to channels.",
+	},
+	{
+		Name: "unsubscribe", Arity: -1,
+		Flags: []string{redis.FlagPubSub, redis.FlagNoScript, redis.FlagLoading, redis.FlagStale},
+		ACL:   []string{"@pubsub", "@slow"},
+		Group: "pubsub", Since: "2.0.0",
+		Summary: "Stops listening to messages posted to channels.",
+	},
+
+	// key
+	{
+		Name: "del", Arity: -2,
+		Flags:    []string{redis.FlagWrite},
+		FirstKey: 1, LastKey: -1, Step: 1,
+		ACL:   []string{"@keyspace", "@write", "@slow"},
+		Group: "generic", Since: "1.0.0",
+		Summary: "Deletes one or more keys.",
+	},
+	{
+		Name: "dump", Arity: 2,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@keyspace", "@read", "@slow"},
+		Group: "generic", Since: "2.6.0",
+		Summary: "Returns a serialized representation of the value stored at a key.",
+	},
+	{
+		Name: "exists", Arity: -2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: -1, Step: 1,
+		ACL:   []string{"@keyspace", "@read", "@fast"},
+		Group: "generic", Since: "1.0.0",
+		Summary: "Determines whether one or more keys exist.",
+	},
+	{
+		Name: "expire", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@keyspace", "@write", "@fast"},
+		Group: "generic", Since: "1.0.0",
+		Summary: "Sets the expiration time of a key in seconds.",
+	},
+	{
+		Name: "expireat", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@keyspace", "@write", "@fast"},
+		Group: "generic", Since: "1.2.0",
+		Summary: "Sets the expiration time of a key to a Unix timestamp.",
+	},
+	{
+		Name: "keys", Arity: 2,
+		Flags: []string{redis.FlagReadonly},
+		ACL:   []string{"@keyspace", "@read", "@slow", "@dangerous"},
+		Group: "generic", Since: "1.0.0",
+		Summary: "Returns all key names that match a pattern.",
+	},
+	{
+		Name: "object", Arity: -2,
+		ACL:   []string{"@slow"},
+		Group: "generic", Since: "2.2.3",
+		Summary: "A container for object introspection commands.",
+	},
+	{
+		Name: "persist", Arity: 2,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@keyspace", "@write", "@fast"},
+		Group: "generic", Since: "2.2.0",
+		Summary: "Removes the expiration time of a key.",
+	},
+	{
+		Name: "pexpire", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@keyspace", "@write", "@fast"},
+		Group: "generic", Since: "2.6.0",
+		Summary: "Sets the expiration time of a key in milliseconds.",
+	},
+	{
+		Name: "pexpireat", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@keyspace", "@write", "@fast"},
+		Group: "generic", Since: "2.6.0",
+		Summary: "Sets the expiration time of a key to a Unix milliseconds timestamp.",
+	},
+	{
+		Name: "randomkey", Arity: 1,
+		Flags: []string{redis.FlagReadonly},
+		ACL:   []string{"@keyspace", "@read", "@slow"},
+		Group: "generic", Since: "1.0.0",
+		Summary: "Returns a random key name from the database.",
+	},
+	{
+		Name: "rename", Arity: 3,
+		Flags:    []string{redis.FlagWrite},
+		FirstKey: 1, LastKey: 2, Step: 1,
+		ACL:   []string{"@keyspace", "@write", "@slow"},
+		Group: "generic", Since: "1.0.0",
+		Summary: "Renames a key and overwrites the destination.",
+	},
+	{
+		Name: "renamenx", Arity: 3,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 2, Step: 1,
+		ACL:   []string{"@keyspace", "@write", "@fast"},
+		Group: "generic", Since: "1.0.0",
+		Summary: "Renames a key only when the target key name doesn't exist.",
+	},
+	{
+		Name: "restore", Arity: -4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@keyspace", "@write", "@slow", "@dangerous"},
+		Group: "generic", Since: "2.6.0",
+		Summary: "Creates a key from the serialized representation of a value.",
+	},
+	{
+		Name: "scan", Arity: -2,
+		Flags: []string{redis.FlagReadonly},
+		ACL:   []string{"@keyspace", "@read", "@slow"},
+		Group: "generic", Since: "2.8.0",
+		Summary: "Iterates over the key names in the database.",
+	},
+	{
+		Name: "ttl", Arity: 2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@keyspace", "@read", "@fast"},
+		Group: "generic", Since: "1.0.0",
+		Summary: "Returns the expiration time in seconds of a key.",
+	},
+	{
+		Name: "type", Arity: 2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@keyspace", "@read", "@fast"},
+		Group: "generic", Since: "1.0.0",
+		Summary: "Determines the type of value stored at a key.",
+	},
+
+	// list
+	{
+		Name: "lindex", Arity: 3,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@list", "@slow"},
+		Group: "list", Since: "1.0.0",
+		Summary: "Returns an element from a list by its index.",
+	},
+	{
+		Name: "linsert", Arity: 5,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@list", "@slow"},
+		Group: "list", Since: "2.2.0",
+		Summary: "Inserts an element before or after another element in a list.",
+	},
+	{
+		Name: "llen", Arity: 2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@list", "@fast"},
+		Group: "list", Since: "1.0.0",
+		Summary: "Returns the length of a list.",
+	},
+	{
+		Name: "lpop", Arity: -2,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@list", "@fast"},
+		Group: "list", Since: "1.0.0",
+		Summary: "Returns the first element of a list after removing it.",
+	},
+	{
+		Name: "lpush", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@list", "@fast"},
+		Group: "list", Since: "1.0.0",
+		Summary: "Prepends one or more elements to a list.",
+	},
+	{
+		Name: "lrange", Arity: 4,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@list", "@slow"},
+		Group: "list", Since: "1.0.0",
+		Summary: "Returns a range of elements from a list.",
+	},
+	{
+		Name: "lrem", Arity: 4,
+		Flags:    []string{redis.FlagWrite},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@list", "@slow"},
+		Group: "list", Since: "1.0.0",
+		Summary: "Removes elements from a list.",
+	},
+	{
+		Name: "lset", Arity: 4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@list", "@slow"},
+		Group: "list", Since: "1.0.0",
+		Summary: "Sets the value of an element in a list by its index.",
+	},
+	{
+		Name: "ltrim", Arity: 4,
+		Flags:    []string{redis.FlagWrite},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@list", "@slow"},
+		Group: "list", Since: "1.0.0",
+		Summary: "Removes elements from both ends a list.",
+	},
+	{
+		Name: "rpop", Arity: -2,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@list", "@fast"},
+		Group: "list", Since: "1.0.0",
+		Summary: "Returns and removes the last element of a list.",
+	},
+	{
+		Name: "rpoplpush", Arity: 3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: 2, Step: 1,
+		ACL:   []string{"@write", "@list", "@slow"},
+		Group: "list", Since: "1.2.0",
+		Summary: "Returns the last element of a list after removing and pushing it to another list.",
+	},
+	{
+		Name: "rpush", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@list", "@fast"},
+		Group: "list", Since: "1.0.0",
+		Summary: "Appends one or more elements to a list.",
+	},
+
+	// string
+	{
+		Name: "decr", Arity: 2,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@string", "@fast"},
+		Group: "string", Since: "1.0.0",
+		Summary: "Decrements the integer value of a key by one.",
+	},
+	{
+		Name: "decrby", Arity: 3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@string", "@fast"},
+		Group: "string", Since: "1.0.0",
+		Summary: "Decrements a number from the integer value of a key.",
+	},
+	{
+		Name: "get", Arity: 2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@string", "@fast"},
+		Group: "string", Since: "1.0.0",
+		Summary: "Returns the string value of a key.",
+	},
+	{
+		Name: "getset", Arity: 3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@string", "@fast"},
+		Group: "string", Since: "1.0.0",
+		Summary: "Returns the previous string value of a key after setting it to a new value.",
+	},
+	{
+		Name: "incr", Arity: 2,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@string", "@fast"},
+		Group: "string", Since: "1.0.0",
+		Summary: "Increments the integer value of a key by one.",
+	},
+	{
+		Name: "incrby", Arity: 3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@string", "@fast"},
+		Group: "string", Since: "1.0.0",
+		Summary: "Increments the integer value of a key by a number.",
+	},
+	{
+		Name: "incrbyfloat", Arity: 3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@string", "@fast"},
+		Group: "string", Since: "2.6.0",
+		Summary: "Increments the floating point value of a key by a number.",
+	},
+	{
+		Name: "mget", Arity: -2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: -1, Step: 1,
+		ACL:   []string{"@read", "@string", "@fast"},
+		Group: "string", Since: "1.0.0",
+		Summary: "Atomically returns the string values of one or more keys.",
+	},
+	{
+		Name: "mset", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: -1, Step: 2,
+		ACL:   []string{"@write", "@string", "@slow"},
+		Group: "string", Since: "1.0.1",
+		Summary: "Atomically creates or modifies the string values of one or more keys.",
+	},
+	{
+		Name: "psetex", Arity: 4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@string", "@slow"},
+		Group: "string", Since: "2.6.0",
+		Summary: "Sets both string value and expiration time in milliseconds of a key.",
+	},
+	{
+		Name: "set", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@string", "@slow"},
+		Group: "string", Since: "1.0.0",
+		Summary: "Sets the string value of a key.",
+	},
+	{
+		Name: "setex", Arity: 4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@string", "@slow"},
+		Group: "string", Since: "2.0.0",
+		Summary: "Sets the string value and expiration time of a key.",
+	},
+	{
+		Name: "setnx", Arity: 3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@string", "@fast"},
+		Group: "string", Since: "1.0.0",
+		Summary: "Set the string value of a key only when the key doesn't exist.",
+	},
+	{
+		Name: "strlen", Arity: 2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@string", "@fast"},
+		Group: "string", Since: "2.2.0",
+		Summary: "Returns the length of a string value.",
+	},
+
+	// hash
+	{
+		Name: "hdel", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@hash", "@fast"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Deletes one or more fields and their values from a hash.",
+	},
+	{
+		Name: "hexists", Arity: 3,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@hash", "@fast"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Determines whether a field exists in a hash.",
+	},
+	{
+		Name: "hget", Arity: 3,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@hash", "@fast"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Returns the value of a field in a hash.",
+	},
+	{
+		Name: "hgetall", Arity: 2,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@hash", "@slow"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Returns all fields and values in a hash.",
+	},
+	{
+		Name: "hincrby", Arity: 4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@hash", "@fast"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Increments the integer value of a field in a hash by a number.",
+	},
+	{
+		Name: "hincrbyfloat", Arity: 4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@hash", "@fast"},
+		Group: "hash", Since: "2.6.0",
+		Summary: "Increments the floating point value of a field by a number.",
+	},
+	{
+		Name: "hkeys", Arity: 2,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@hash", "@slow"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Returns all fields in a hash.",
+	},
+	{
+		Name: "hlen", Arity: 2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@hash", "@fast"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Returns the number of fields in a hash.",
+	},
+	{
+		Name: "hmget", Arity: -3,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@hash", "@fast"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Returns the values of all fields in a hash.",
+	},
+	{
+		Name: "hmset", Arity: -4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@hash", "@fast"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Sets the values of multiple fields.",
+	},
+	{
+		Name: "hscan", Arity: -3,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@hash", "@slow"},
+		Group: "hash", Since: "2.8.0",
+		Summary: "Iterates over fields and values of a hash.",
+	},
+	{
+		Name: "hset", Arity: -4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@hash", "@fast"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Creates or modifies the value of a field in a hash.",
+	},
+	{
+		Name: "hsetnx", Arity: 4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@hash", "@fast"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Sets the value of a field in a hash only when the field doesn't exist.",
+	},
+	{
+		Name: "hvals", Arity: 2,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@hash", "@slow"},
+		Group: "hash", Since: "2.0.0",
+		Summary: "Returns all values in a hash.",
+	},
+
+	// set
+	{
+		Name: "sadd", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@set", "@fast"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Adds one or more members to a set.",
+	},
+	{
+		Name: "scard", Arity: 2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@set", "@fast"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Returns the number of members in a set.",
+	},
+	{
+		Name: "sdiff", Arity: -2,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: -1, Step: 1,
+		ACL:   []string{"@read", "@set", "@slow"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Returns the difference of multiple sets.",
+	},
+	{
+		Name: "sdiffstore", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: -1, Step: 1,
+		ACL:   []string{"@write", "@set", "@slow"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Stores the difference of multiple sets in a key.",
+	},
+	{
+		Name: "sinter", Arity: -2,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: -1, Step: 1,
+		ACL:   []string{"@read", "@set", "@slow"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Returns the intersect of multiple sets.",
+	},
+	{
+		Name: "sinterstore", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: -1, Step: 1,
+		ACL:   []string{"@write", "@set", "@slow"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Stores the intersect of multiple sets in a key.",
+	},
+	{
+		Name: "sismember", Arity: 3,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@set", "@fast"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Determines whether a member belongs to a set.",
+	},
+	{
+		Name: "smembers", Arity: 2,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@set", "@slow"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Returns all members of a set.",
+	},
+	{
+		Name: "smove", Arity: 4,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 2, Step: 1,
+		ACL:   []string{"@write", "@set", "@fast"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Moves a member from one set to another.",
+	},
+	{
+		Name: "spop", Arity: -2,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@set", "@fast"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Returns one or more random members from a set after removing them.",
+	},
+	{
+		Name: "srandmember", Arity: -2,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@set", "@slow"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Get one or multiple random members from a set.",
+	},
+	{
+		Name: "srem", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@set", "@fast"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Removes one or more members from a set.",
+	},
+	{
+		Name: "sscan", Arity: -3,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@set", "@slow"},
+		Group: "set", Since: "2.8.0",
+		Summary: "Iterates over members of a set.",
+	},
+	{
+		Name: "sunion", Arity: -2,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: -1, Step: 1,
+		ACL:   []string{"@read", "@set", "@slow"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Returns the union of multiple sets.",
+	},
+	{
+		Name: "sunionstore", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM},
+		FirstKey: 1, LastKey: -1, Step: 1,
+		ACL:   []string{"@write", "@set", "@slow"},
+		Group: "set", Since: "1.0.0",
+		Summary: "Stores the union of multiple sets in a key.",
+	},
+
+	// sorted set
+	{
+		Name: "zadd", Arity: -4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@sortedset", "@fast"},
+		Group: "sorted-set", Since: "1.2.0",
+		Summary: "Adds one or more members to a sorted set, or updates their scores.",
+	},
+	{
+		Name: "zcard", Arity: 2,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@sortedset", "@fast"},
+		Group: "sorted-set", Since: "1.2.0",
+		Summary: "Returns the number of members in a sorted set.",
+	},
+	{
+		Name: "zcount", Arity: 4,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@sortedset", "@fast"},
+		Group: "sorted-set", Since: "2.0.0",
+		Summary: "Returns the count of members in a sorted set that have scores within a range.",
+	},
+	{
+		Name: "zincrby", Arity: 4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@sortedset", "@fast"},
+		Group: "sorted-set", Since: "1.2.0",
+		Summary: "Increments the score of a member in a sorted set.",
+	},
+	{
+		Name: "zinter", Arity: -3,
+		Flags:    []string{redis.FlagReadonly, redis.FlagMovableKeys},
+		FirstKey: 0, LastKey: 0, Step: 0, NumKeys: 1,
+		ACL:   []string{"@read", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "6.2.0",
+		Summary: "Returns the intersect of multiple sorted sets.",
+	},
+	{
+		Name: "zinterstore", Arity: -4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagMovableKeys},
+		FirstKey: 1, LastKey: 1, Step: 1, NumKeys: 2,
+		ACL:   []string{"@write", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "2.0.0",
+		Summary: "Stores the intersect of multiple sorted sets in a key.",
+	},
+	{
+		Name: "zrange", Arity: -4,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "1.2.0",
+		Summary: "Returns members in a sorted set within a range of indexes.",
+	},
+	{
+		Name: "zrangebyscore", Arity: -4,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "1.0.5",
+		Summary: "Returns members in a sorted set within a range of scores.",
+	},
+	{
+		Name: "zrank", Arity: -3,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@sortedset", "@fast"},
+		Group: "sorted-set", Since: "2.0.0",
+		Summary: "Returns the index of a member in a sorted set ordered by ascending scores.",
+	},
+	{
+		Name: "zrem", Arity: -3,
+		Flags:    []string{redis.FlagWrite, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@sortedset", "@fast"},
+		Group: "sorted-set", Since: "1.2.0",
+		Summary: "Removes one or more members from a sorted set.",
+	},
+	{
+		Name: "zremrangebyrank", Arity: 4,
+		Flags:    []string{redis.FlagWrite},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "2.0.0",
+		Summary: "Removes members in a sorted set within a range of indexes.",
+	},
+	{
+		Name: "zremrangebyscore", Arity: 4,
+		Flags:    []string{redis.FlagWrite},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@write", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "1.2.0",
+		Summary: "Removes members in a sorted set within a range of scores.",
+	},
+	{
+		Name: "zrevrange", Arity: -4,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "1.2.0",
+		Summary: "Returns members in a sorted set within a range of indexes in reverse order.",
+	},
+	{
+		Name: "zrevrangebyscore", Arity: -4,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "2.2.0",
+		Summary: "Returns members in a sorted set within a range of scores in reverse order.",
+	},
+	{
+		Name: "zrevrank", Arity: -3,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@sortedset", "@fast"},
+		Group: "sorted-set", Since: "2.0.0",
+		Summary: "Returns the index of a member in a sorted set ordered by descending scores.",
+	},
+	{
+		Name: "zscan", Arity: -3,
+		Flags:    []string{redis.FlagReadonly},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "2.8.0",
+		Summary: "Iterates over members and scores of a sorted set.",
+	},
+	{
+		Name: "zscore", Arity: 3,
+		Flags:    []string{redis.FlagReadonly, redis.FlagFast},
+		FirstKey: 1, LastKey: 1, Step: 1,
+		ACL:   []string{"@read", "@sortedset", "@fast"},
+		Group: "sorted-set", Since: "1.2.0",
+		Summary: "Returns the score of a member in a sorted set.",
+	},
+	{
+		Name: "zunion", Arity: -3,
+		Flags:    []string{redis.FlagReadonly, redis.FlagMovableKeys},
+		FirstKey: 0, LastKey: 0, Step: 0, NumKeys: 1,
+		ACL:   []string{"@read", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "6.2.0",
+		Summary: "Returns the union of multiple sorted sets.",
+	},
+	{
+		Name: "zunionstore", Arity: -4,
+		Flags:    []string{redis.FlagWrite, redis.FlagDenyOOM, redis.FlagMovableKeys},
+		FirstKey: 1, LastKey: 1, Step: 1, NumKeys: 2,
+		ACL:   []string{"@write", "@sortedset", "@slow"},
+		Group: "sorted-set", Since: "2.0.0",
+		Summary: "Stores the union of multiple sorted sets in a key.",
+	},
+})
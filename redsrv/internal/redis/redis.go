@@ -13,15 +13,26 @@ import (
 
 // Redis-like errors.
 var (
+	ErrBgSaveInProgress  = errors.New("ERR Background save already in progress")
+	ErrBadDataFormat     = errors.New("ERR Bad data format")
+	ErrBusyKey           = errors.New("BUSYKEY Target key name already exists.")
+	ErrDumpPayload       = errors.New("ERR DUMP payload version or checksum are wrong")
 	ErrInvalidArgNum     = errors.New("ERR wrong number of arguments")
 	ErrInvalidCursor     = errors.New("ERR invalid cursor")
 	ErrInvalidExpireTime = errors.New("ERR invalid expire time")
 	ErrInvalidFloat      = errors.New("ERR value is not a float")
 	ErrInvalidInt        = errors.New("ERR value is not an integer")
+	ErrMonitorInMulti    = errors.New("ERR MONITOR is not allowed in MULTI")
 	ErrNestedMulti       = errors.New("ERR MULTI calls can not be nested")
+	ErrNoChangeLog       = errors.New("ERR change log is disabled")
+	ErrNoKeys            = errors.New("ERR the command has no key arguments")
 	ErrNotFound          = errors.New("ERR no such key")
 	ErrNotInMulti        = errors.New("ERR EXEC without MULTI")
+	ErrNotSupported      = errors.New("ERR operation not supported by the database")
+	ErrOOM               = errors.New("OOM command not allowed when the database is over the limits")
 	ErrOutOfRange        = errors.New("ERR index out of range")
+	ErrReadOnly          = errors.New("READONLY You can't write against a read only replica.")
+	ErrSubscribeInMulti  = errors.New("ERR SUBSCRIBE is not allowed in MULTI")
 	ErrSyntaxError       = errors.New("ERR syntax error")
 	ErrUnknownCmd        = errors.New("ERR unknown command")
 	ErrUnknownSubcmd     = errors.New("ERR unknown subcommand")
@@ -77,6 +88,8 @@ func (cmd BaseCmd) Error(err error) string {
 	switch err {
 	case core.ErrNotFound:
 		err = ErrNotFound
+	case core.ErrNotSupported:
+		err = ErrNotSupported
 	}
 	return fmt.Sprintf("%s (%s)", err, cmd.Name())
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/nalgeon/redka"
 	"github.com/nalgeon/redka/internal/core"
+	"github.com/nalgeon/redka/internal/rchange"
 	"github.com/nalgeon/redka/internal/rhash"
 	"github.com/nalgeon/redka/internal/rkey"
 	"github.com/nalgeon/redka/internal/rset"
@@ -12,6 +13,18 @@ import (
 	"github.com/nalgeon/redka/internal/rzset"
 )
 
+// RChange is a change log repository.
+type RChange interface {
+	Range(since int64, count int) ([]rchange.Change, error)
+}
+
+// RDump serializes and deserializes key values
+// (the DUMP and RESTORE commands).
+type RDump interface {
+	Dump(key string) ([]byte, error)
+	Restore(key string, payload []byte, at time.Time, replace bool) error
+}
+
 // RHash is a hash repository.
 type RHash interface {
 	Delete(key string, fields ...string) (int, error)
@@ -40,6 +53,7 @@ type RKey interface {
 	Expire(key string, ttl time.Duration) error
 	ExpireAt(key string, at time.Time) error
 	Get(key string) (core.Key, error)
+	Info(key string) (rkey.Info, error)
 	Keys(pattern string) ([]core.Key, error)
 	Len() (int, error)
 	Persist(key string) error
@@ -48,6 +62,7 @@ type RKey interface {
 	RenameNotExists(key, newKey string) (bool, error)
 	Scan(cursor int, pattern string, ktype core.TypeID, count int) (rkey.ScanResult, error)
 	Scanner(pattern string, ktype core.TypeID, pageSize int) *rkey.Scanner
+	SetAccessTime(key string, at time.Time) error
 }
 
 // RList is a list repository.
@@ -126,38 +141,54 @@ type RZSet interface {
 // Redka is an abstraction for *redka.DB and *redka.Tx.
 // Used to execute commands in a unified way.
 type Redka struct {
-	hash RHash
-	key  RKey
-	list RList
-	set  RSet
-	str  RStr
-	zset RZSet
+	change RChange
+	dump   RDump
+	hash   RHash
+	key    RKey
+	list   RList
+	set    RSet
+	str    RStr
+	zset   RZSet
 }
 
 // RedkaDB creates a new Redka instance for a database.
 func RedkaDB(db *redka.DB) Redka {
 	return Redka{
-		hash: db.Hash(),
-		key:  db.Key(),
-		list: db.List(),
-		set:  db.Set(),
-		str:  db.Str(),
-		zset: db.ZSet(),
+		change: db.Changes(),
+		dump:   db,
+		hash:   db.Hash(),
+		key:    db.Key(),
+		list:   db.List(),
+		set:    db.Set(),
+		str:    db.Str(),
+		zset:   db.ZSet(),
 	}
 }
 
 // RedkaTx creates a new Redka instance for a transaction.
 func RedkaTx(tx *redka.Tx) Redka {
 	return Redka{
-		hash: tx.Hash(),
-		key:  tx.Key(),
-		list: tx.List(),
-		set:  tx.Set(),
-		str:  tx.Str(),
-		zset: tx.ZSet(),
+		change: tx.Changes(),
+		dump:   tx,
+		hash:   tx.Hash(),
+		key:    tx.Key(),
+		list:   tx.List(),
+		set:    tx.Set(),
+		str:    tx.Str(),
+		zset:   tx.ZSet(),
 	}
 }
 
+// Changes returns the change log repository.
+func (r Redka) Changes() RChange {
+	return r.change
+}
+
+// Dump returns the value serializer.
+func (r Redka) Dump() RDump {
+	return r.dump
+}
+
 // Hash returns the hash repository.
 func (r Redka) Hash() RHash {
 	return r.hash
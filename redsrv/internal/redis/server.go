@@ -0,0 +1,181 @@
+package redis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Server is an abstraction for the server state.
+// Used by the server management commands.
+type Server interface {
+	Config() Config
+	Clients() Clients
+	SlowLog() SlowLog
+	PubSub() PubSub
+	Replication() Replication
+	Saver() Saver
+}
+
+// Config is a runtime server configuration.
+type Config interface {
+	// Get returns the values of the parameters
+	// with names matching a glob-style pattern.
+	Get(pattern string) map[string]string
+	// Set validates and applies new parameter values.
+	// Either all of the values are applied, or none of them.
+	Set(params map[string]string) error
+	// ResetStat resets the server statistics.
+	ResetStat()
+	// Rewrite writes the current configuration
+	// to the file the server was started with.
+	Rewrite() error
+}
+
+// Clients is a registry of connected clients.
+type Clients interface {
+	// Get returns the client with the given ID.
+	Get(id int64) (ClientInfo, bool)
+	// List returns all connected clients ordered by ID.
+	List() []ClientInfo
+	// Kill closes the client connection.
+	// Returns false if there is no such client.
+	Kill(id int64) bool
+	// SetName sets the client connection name.
+	SetName(id int64, name string)
+	// SetNoEvict sets the client no-evict mode.
+	SetNoEvict(id int64, on bool)
+	// SetReply sets the client reply mode.
+	SetReply(id int64, mode ReplyMode)
+}
+
+// ReplyMode controls whether the server replies to the client.
+type ReplyMode string
+
+// Client reply modes.
+const (
+	ReplyOn   ReplyMode = "on"   // reply to every command
+	ReplyOff  ReplyMode = "off"  // don't reply at all
+	ReplySkip ReplyMode = "skip" // don't reply to the next command
+)
+
+// DefaultUser is the user all clients are authenticated as.
+const DefaultUser = "default"
+
+// ClientInfo describes a connected client.
+type ClientInfo struct {
+	ID        int64         // unique client ID
+	Addr      string        // client address
+	LocalAddr string        // server address the client is connected to
+	Name      string        // connection name set by CLIENT SETNAME
+	User      string        // authenticated user
+	Age       time.Duration // time since the connection was accepted
+	Idle      time.Duration // time since the last command
+	LastCmd   string        // last command name
+	InMulti   bool          // whether the client is in a MULTI block
+	Queued    int           // number of commands queued in MULTI
+	NoEvict   bool          // whether the client is excluded from eviction
+}
+
+// String returns the client description
+// in the CLIENT LIST format.
+func (c ClientInfo) String() string {
+	var flags strings.Builder
+	if c.InMulti {
+		flags.WriteByte('x')
+	}
+	if c.NoEvict {
+		flags.WriteByte('e')
+	}
+	if flags.Len() == 0 {
+		flags.WriteByte('N')
+	}
+	multi := -1
+	if c.InMulti {
+		multi = c.Queued
+	}
+	return fmt.Sprintf(
+		"id=%d addr=%s laddr=%s name=%s age=%d idle=%d flags=%s db=0 multi=%d user=%s cmd=%s",
+		c.ID, c.Addr, c.LocalAddr, c.Name, int(c.Age.Seconds()), int(c.Idle.Seconds()),
+		flags.String(), multi, c.User, c.LastCmd,
+	)
+}
+
+// SlowLog is a log of the commands that exceeded
+// the configured execution time.
+type SlowLog interface {
+	// Entries returns up to count latest entries, newest first.
+	// Returns all entries if count is negative.
+	Entries(count int) []SlowLogEntry
+	// Len returns the number of entries in the log.
+	Len() int
+	// Reset removes all entries from the log.
+	Reset()
+}
+
+// SlowLogEntry describes a slow command.
+type SlowLogEntry struct {
+	ID         int64         // unique entry ID
+	Time       time.Time     // time the command was processed
+	Duration   time.Duration // command execution time
+	Args       []string      // command name and arguments (truncated)
+	ClientAddr string        // client address
+	ClientName string        // client name set by CLIENT SETNAME
+	Query      string        // slowest SQL statement executed by the command
+	QueryTime  time.Duration // slowest SQL statement execution time
+}
+
+// PubSub is a publish/subscribe message broker.
+type PubSub interface {
+	// Publish sends the message to the channel subscribers.
+	// Returns the number of clients that received the message.
+	Publish(channel, message string) int
+}
+
+// Replication is the primary-replica replication state.
+type Replication interface {
+	// Role returns the replication role of the server.
+	Role() ReplicationRole
+	// ChangeLog reports whether the database change log is enabled.
+	ChangeLog() bool
+	// Snapshot returns the next page of the database snapshot,
+	// no more than count commands. Cursor 0 starts a new snapshot.
+	// Returns ErrInvalidCursor if there is no snapshot with
+	// the given cursor (e.g. it has expired).
+	Snapshot(cursor int64, count int) (SnapshotPage, error)
+}
+
+// SnapshotPage is a page of the database snapshot.
+// All pages of the snapshot come from the same read
+// transaction, so the snapshot is consistent.
+type SnapshotPage struct {
+	Cursor int64      // cursor of the next page (0 if this is the last page)
+	Seq    int64      // last change log entry included in the snapshot
+	Cmds   [][][]byte // commands that recreate the database contents
+}
+
+// Replication link states.
+const (
+	ReplConnect   = "connect"   // connecting to the primary
+	ReplSync      = "sync"      // loading the snapshot
+	ReplConnected = "connected" // applying the changes
+)
+
+// ReplicationRole describes the replication role of the server.
+type ReplicationRole struct {
+	Replica bool   // whether the server is a replica
+	Host    string // primary host (replica only)
+	Port    int    // primary port (replica only)
+	State   string // replication link state (replica only)
+	Seq     int64  // last change log sequence number (applied, for replicas)
+}
+
+// Saver writes database snapshots to disk.
+type Saver interface {
+	// Save writes a snapshot and waits for it to complete.
+	Save() error
+	// BgSave starts writing a snapshot in the background.
+	BgSave() error
+	// LastSave returns the time of the last successful snapshot.
+	LastSave() time.Time
+}
@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Command flags.
+const (
+	FlagAdmin       = "admin"
+	FlagBlocking    = "blocking"
+	FlagDenyOOM     = "denyoom"
+	FlagFast        = "fast"
+	FlagLoading     = "loading"
+	FlagMovableKeys = "movablekeys"
+	FlagNoScript    = "noscript"
+	FlagPubSub      = "pubsub"
+	FlagReadonly    = "readonly"
+	FlagStale       = "stale"
+	FlagWrite       = "write"
+)
+
+// CommandInfo describes a command: its arity, flags,
+// key positions and ACL categories.
+type CommandInfo struct {
+	Name     string   // lowercase command name
+	Arity    int      // number of arguments including the name, negative = at least
+	Flags    []string // command flags (write, readonly, fast, ...)
+	FirstKey int      // position of the first key (0 = no keys)
+	LastKey  int      // position of the last key (negative = counting from the end)
+	Step     int      // step between the first and the last key
+	NumKeys  int      // position of the numkeys argument (0 = none)
+	ACL      []string // ACL categories (@read, @string, ...)
+	Group    string   // command group (string, list, ...)
+	Since    string   // Redis version the command was introduced in
+	Summary  string   // short command description
+}
+
+// HasFlag reports whether the command has the given flag.
+func (c CommandInfo) HasFlag(flag string) bool {
+	return slices.Contains(c.Flags, flag)
+}
+
+// CheckArity reports whether the number of arguments
+// (including the command name) matches the command arity.
+func (c CommandInfo) CheckArity(nargs int) bool {
+	if c.Arity >= 0 {
+		return nargs == c.Arity
+	}
+	return nargs >= -c.Arity
+}
+
+// Keys returns the key arguments of the command.
+// The args include the command name.
+func (c CommandInfo) Keys(args [][]byte) ([]string, error) {
+	if !c.CheckArity(len(args)) {
+		return nil, ErrInvalidArgNum
+	}
+
+	var keys []string
+
+	// Keys at fixed positions.
+	if c.FirstKey > 0 {
+		last := c.LastKey
+		if last < 0 {
+			last = len(args) + last
+		}
+		if c.NumKeys > 0 {
+			last = min(last, c.NumKeys-1)
+		}
+		for i := c.FirstKey; i <= last && i < len(args); i += c.Step {
+			keys = append(keys, string(args[i]))
+		}
+	}
+
+	// Keys following the numkeys argument.
+	if c.NumKeys > 0 {
+		if c.NumKeys >= len(args) {
+			return nil, ErrInvalidArgNum
+		}
+		n, err := strconv.Atoi(string(args[c.NumKeys]))
+		if err != nil {
+			return nil, ErrInvalidInt
+		}
+		if n < 0 || c.NumKeys+n >= len(args) {
+			return nil, ErrInvalidArgNum
+		}
+		for _, arg := range args[c.NumKeys+1 : c.NumKeys+1+n] {
+			keys = append(keys, string(arg))
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	return keys, nil
+}
+
+// CommandTable is a set of command descriptions ordered by name.
+type CommandTable struct {
+	cmds  []CommandInfo
+	index map[string]int
+}
+
+// NewCommandTable creates a new command table.
+func NewCommandTable(cmds []CommandInfo) *CommandTable {
+	cmds = slices.Clone(cmds)
+	slices.SortFunc(cmds, func(a, b CommandInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	index := make(map[string]int, len(cmds))
+	for i, cmd := range cmds {
+		index[cmd.Name] = i
+	}
+	return &CommandTable{cmds: cmds, index: index}
+}
+
+// Get returns the description of a command by name.
+// The name is case-insensitive.
+func (t *CommandTable) Get(name string) (CommandInfo, bool) {
+	idx, ok := t.index[strings.ToLower(name)]
+	if !ok {
+		return CommandInfo{}, false
+	}
+	return t.cmds[idx], true
+}
+
+// All returns all commands ordered by name.
+func (t *CommandTable) All() []CommandInfo {
+	return t.cmds
+}
+
+// Len returns the number of commands in the table.
+func (t *CommandTable) Len() int {
+	return len(t.cmds)
+}
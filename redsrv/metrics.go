@@ -0,0 +1,243 @@
+package redsrv
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/redsrv/internal/command"
+	"github.com/tidwall/redcon"
+)
+
+// Upper bounds of the command duration histogram buckets, in seconds.
+var metricsBuckets = []float64{
+	0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005,
+	0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5,
+}
+
+// Key types reported in the keyspace metrics.
+var metricsKeyTypes = []redka.TypeID{
+	redka.TypeString, redka.TypeList, redka.TypeSet, redka.TypeHash, redka.TypeZSet,
+}
+
+// Metrics collects the server metrics and exposes them
+// in the Prometheus text format. The command metrics are
+// updated as the commands are processed, while the client,
+// keyspace and database metrics are collected on each scrape.
+//
+// Metrics is safe for concurrent use by multiple goroutines.
+type Metrics struct {
+	mu      sync.Mutex
+	cmds    map[string]*cmdMetrics
+	db      *redka.DB
+	clients *Clients
+}
+
+// cmdMetrics contains the metrics of a single command.
+type cmdMetrics struct {
+	calls   uint64
+	errors  uint64
+	buckets []uint64 // non-cumulative counts per bucket
+	sum     float64  // total duration in seconds
+}
+
+// newMetrics creates an empty metrics registry.
+func newMetrics(db *redka.DB, clients *Clients) *Metrics {
+	return &Metrics{cmds: map[string]*cmdMetrics{}, db: db, clients: clients}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	m.writeCommands(bw)
+	m.writeClients(bw)
+	m.writeKeys(bw)
+	m.writeDB(bw)
+	_ = bw.Flush()
+}
+
+// observe records a processed command.
+func (m *Metrics) observe(name string, dur time.Duration, failed bool) {
+	if _, ok := command.Table.Get(name); !ok {
+		// Limit the number of label values.
+		name = "unknown"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cm, ok := m.cmds[name]
+	if !ok {
+		cm = &cmdMetrics{buckets: make([]uint64, len(metricsBuckets)+1)}
+		m.cmds[name] = cm
+	}
+	cm.calls++
+	if failed {
+		cm.errors++
+	}
+	sec := dur.Seconds()
+	cm.sum += sec
+	idx, _ := slices.BinarySearch(metricsBuckets, sec)
+	cm.buckets[idx]++
+}
+
+// reset clears the command metrics.
+func (m *Metrics) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cmds = map[string]*cmdMetrics{}
+}
+
+// writeCommands writes the per-command metrics.
+func (m *Metrics) writeCommands(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.cmds))
+	for name := range m.cmds {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	writeHeader(w, "redka_commands_total", "counter", "Number of processed commands.")
+	for _, name := range names {
+		fmt.Fprintf(w, "redka_commands_total{cmd=%q} %d\n", name, m.cmds[name].calls)
+	}
+
+	writeHeader(w, "redka_command_errors_total", "counter", "Number of commands that returned an error.")
+	for _, name := range names {
+		fmt.Fprintf(w, "redka_command_errors_total{cmd=%q} %d\n", name, m.cmds[name].errors)
+	}
+
+	writeHeader(w, "redka_command_duration_seconds", "histogram", "Command processing time.")
+	for _, name := range names {
+		cm := m.cmds[name]
+		var count uint64
+		for i, le := range metricsBuckets {
+			count += cm.buckets[i]
+			fmt.Fprintf(w, "redka_command_duration_seconds_bucket{cmd=%q,le=%q} %d\n",
+				name, formatFloat(le), count)
+		}
+		fmt.Fprintf(w, "redka_command_duration_seconds_bucket{cmd=%q,le=\"+Inf\"} %d\n", name, cm.calls)
+		fmt.Fprintf(w, "redka_command_duration_seconds_sum{cmd=%q} %s\n", name, formatFloat(cm.sum))
+		fmt.Fprintf(w, "redka_command_duration_seconds_count{cmd=%q} %d\n", name, cm.calls)
+	}
+}
+
+// writeClients writes the client metrics.
+func (m *Metrics) writeClients(w *bufio.Writer) {
+	writeHeader(w, "redka_connected_clients", "gauge", "Number of connected clients.")
+	fmt.Fprintf(w, "redka_connected_clients %d\n", m.clients.Len())
+}
+
+// writeKeys writes the keyspace metrics.
+func (m *Metrics) writeKeys(w *bufio.Writer) {
+	counts, err := m.db.Key().LenByType()
+	if err != nil {
+		m.db.Log().Warn("metrics: count keys", "error", err)
+	} else {
+		writeHeader(w, "redka_keys", "gauge", "Number of keys by type.")
+		for _, typ := range metricsKeyTypes {
+			name := redka.Key{Type: typ}.TypeName()
+			fmt.Fprintf(w, "redka_keys{type=%q} %d\n", name, counts[typ])
+		}
+	}
+
+	stats := m.db.Stats()
+	writeHeader(w, "redka_expired_keys_total", "counter",
+		"Number of expired keys deleted in the background.")
+	fmt.Fprintf(w, "redka_expired_keys_total %d\n", stats.ExpiredKeys)
+	writeHeader(w, "redka_expire_cycles_total", "counter",
+		"Number of background expiration cycles.")
+	fmt.Fprintf(w, "redka_expire_cycles_total %d\n", stats.ExpireCycles)
+	writeHeader(w, "redka_expire_capped_cycles_total", "counter",
+		"Number of expiration cycles stopped by the time budget.")
+	fmt.Fprintf(w, "redka_expire_capped_cycles_total %d\n", stats.ExpireCappedCycles)
+	writeHeader(w, "redka_expire_seconds_total", "counter",
+		"Time spent in the expiration cycles.")
+	fmt.Fprintf(w, "redka_expire_seconds_total %s\n", formatFloat(stats.ExpireTime.Seconds()))
+	writeHeader(w, "redka_evicted_keys_total", "counter",
+		"Number of keys deleted by the eviction policy.")
+	fmt.Fprintf(w, "redka_evicted_keys_total %d\n", stats.EvictedKeys)
+}
+
+// writeDB writes the database connection pool metrics.
+func (m *Metrics) writeDB(w *bufio.Writer) {
+	stats := m.db.Stats()
+	pools := []struct {
+		name  string
+		stats sql.DBStats
+	}{
+		{"rw", stats.RW},
+		{"ro", stats.RO},
+	}
+	metrics := []struct {
+		name, typ, help string
+		value           func(s sql.DBStats) string
+	}{
+		{
+			"redka_db_max_open_connections", "gauge",
+			"Maximum number of open database connections.",
+			func(s sql.DBStats) string { return strconv.Itoa(s.MaxOpenConnections) },
+		},
+		{
+			"redka_db_open_connections", "gauge",
+			"Number of open database connections.",
+			func(s sql.DBStats) string { return strconv.Itoa(s.OpenConnections) },
+		},
+		{
+			"redka_db_in_use_connections", "gauge",
+			"Number of database connections in use.",
+			func(s sql.DBStats) string { return strconv.Itoa(s.InUse) },
+		},
+		{
+			"redka_db_idle_connections", "gauge",
+			"Number of idle database connections.",
+			func(s sql.DBStats) string { return strconv.Itoa(s.Idle) },
+		},
+		{
+			"redka_db_wait_count_total", "counter",
+			"Number of waits for a database connection.",
+			func(s sql.DBStats) string { return strconv.FormatInt(s.WaitCount, 10) },
+		},
+		{
+			"redka_db_wait_duration_seconds_total", "counter",
+			"Time spent waiting for a database connection.",
+			func(s sql.DBStats) string { return formatFloat(s.WaitDuration.Seconds()) },
+		},
+	}
+	for _, metric := range metrics {
+		writeHeader(w, metric.name, metric.typ, metric.help)
+		for _, pool := range pools {
+			fmt.Fprintf(w, "%s{pool=%q} %s\n", metric.name, pool.name, metric.value(pool.stats))
+		}
+	}
+}
+
+// writeHeader writes the metric description and type.
+func writeHeader(w *bufio.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+// formatFloat formats the value the way Prometheus expects.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// errConn is a connection that remembers
+// whether an error reply has been written.
+type errConn struct {
+	redcon.Conn
+	failed bool
+}
+
+// WriteError writes an error reply and remembers it.
+func (c *errConn) WriteError(msg string) {
+	c.failed = true
+	c.Conn.WriteError(msg)
+}
@@ -0,0 +1,98 @@
+package redsrv
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestMetricsObserve(t *testing.T) {
+	db := testx.OpenDB(t)
+	metrics := newMetrics(db, newClients())
+
+	metrics.observe("get", 300*time.Microsecond, false)
+	metrics.observe("get", 2*time.Millisecond, true)
+	metrics.observe("nosuchcmd", time.Millisecond, false)
+
+	out := scrape(metrics)
+	be.True(t, strings.Contains(out, `redka_commands_total{cmd="get"} 2`))
+	be.True(t, strings.Contains(out, `redka_command_errors_total{cmd="get"} 1`))
+	be.True(t, strings.Contains(out, `redka_commands_total{cmd="unknown"} 1`))
+	be.True(t, strings.Contains(out, `redka_command_duration_seconds_bucket{cmd="get",le="0.00025"} 0`))
+	be.True(t, strings.Contains(out, `redka_command_duration_seconds_bucket{cmd="get",le="0.0005"} 1`))
+	be.True(t, strings.Contains(out, `redka_command_duration_seconds_bucket{cmd="get",le="0.0025"} 2`))
+	be.True(t, strings.Contains(out, `redka_command_duration_seconds_bucket{cmd="get",le="+Inf"} 2`))
+	be.True(t, strings.Contains(out, `redka_command_duration_seconds_sum{cmd="get"} 0.0023`))
+	be.True(t, strings.Contains(out, `redka_command_duration_seconds_count{cmd="get"} 2`))
+
+	metrics.reset()
+	out = scrape(metrics)
+	be.Equal(t, strings.Contains(out, `redka_commands_total{cmd="get"}`), false)
+}
+
+func TestMetricsScrape(t *testing.T) {
+	db := testx.OpenDB(t)
+	_ = db.Str().Set("name", "alice")
+	_ = db.Str().Set("age", 25)
+	_, _ = db.Hash().Set("person", "name", "alice")
+
+	clients := newClients()
+	clients.add(new(fakeConn))
+	metrics := newMetrics(db, clients)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	be.Equal(t, rec.Code, 200)
+	be.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+
+	out := rec.Body.String()
+	be.True(t, strings.Contains(out, "# TYPE redka_connected_clients gauge\n"))
+	be.True(t, strings.Contains(out, "redka_connected_clients 1\n"))
+	be.True(t, strings.Contains(out, `redka_keys{type="string"} 2`))
+	be.True(t, strings.Contains(out, `redka_keys{type="hash"} 1`))
+	be.True(t, strings.Contains(out, `redka_keys{type="zset"} 0`))
+	be.True(t, strings.Contains(out, "redka_expired_keys_total 0\n"))
+	be.True(t, strings.Contains(out, "# TYPE redka_expire_cycles_total counter\n"))
+	be.True(t, strings.Contains(out, "# TYPE redka_expire_capped_cycles_total counter\n"))
+	be.True(t, strings.Contains(out, "# TYPE redka_expire_seconds_total counter\n"))
+	be.True(t, strings.Contains(out, "redka_evicted_keys_total 0\n"))
+	be.True(t, strings.Contains(out, `redka_db_open_connections{pool="rw"}`))
+	be.True(t, strings.Contains(out, `redka_db_open_connections{pool="ro"}`))
+}
+
+func TestMetricsTracking(t *testing.T) {
+	db := testx.OpenDB(t)
+	srv := newTestState(db)
+	mux := createHandlers(db, srv)
+	conn := new(fakeConn)
+	serve := func(args ...string) {
+		conn.parts = nil
+		mux.ServeRESP(conn, buildCommand(args...))
+	}
+
+	serve("set", "name", "alice")
+	serve("get", "name")
+	serve("get")
+	serve("incr", "name")
+	serve("multi")
+	serve("incr", "name")
+	serve("exec")
+
+	out := scrape(srv.metrics)
+	be.True(t, strings.Contains(out, `redka_commands_total{cmd="get"} 2`))
+	be.True(t, strings.Contains(out, `redka_command_errors_total{cmd="get"} 1`))
+	be.True(t, strings.Contains(out, `redka_command_errors_total{cmd="incr"} 1`))
+	be.True(t, strings.Contains(out, `redka_command_errors_total{cmd="exec"} 1`))
+	be.True(t, strings.Contains(out, `redka_command_errors_total{cmd="set"} 0`))
+}
+
+// scrape returns the metrics in the Prometheus text format.
+func scrape(metrics *Metrics) string {
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	return rec.Body.String()
+}
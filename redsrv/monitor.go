@@ -0,0 +1,191 @@
+package redsrv
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tidwall/redcon"
+)
+
+// Max number of pending lines per monitor.
+// Monitors that fall behind are disconnected.
+const monitorBufferSize = 1024
+
+// monitors is a registry of clients that
+// called MONITOR and receive the processed commands.
+type monitors struct {
+	mu   sync.Mutex
+	subs map[*monitorConn]struct{}
+	n    atomic.Int32 // number of subscribers
+	log  *slog.Logger
+}
+
+// monitorConn is a detached client connection
+// that receives the processed commands.
+//
+// Only the writer goroutine (see [monitors.write]) writes to the
+// connection and closes it. Others ask it to stop (see [monitorConn.stop]).
+type monitorConn struct {
+	conn  redcon.DetachedConn
+	lines chan string
+	done  chan struct{}
+	once  sync.Once
+}
+
+// stop asks the writer goroutine to close the connection.
+// Safe to call multiple times and from any goroutine.
+func (mon *monitorConn) stop() {
+	mon.once.Do(func() { close(mon.done) })
+}
+
+// newMonitors creates an empty monitor registry.
+func newMonitors(log *slog.Logger) *monitors {
+	return &monitors{subs: map[*monitorConn]struct{}{}, log: log}
+}
+
+// add starts streaming the processed commands to the connection
+// until the client disconnects or sends QUIT.
+func (m *monitors) add(conn redcon.DetachedConn) {
+	mon := &monitorConn{
+		conn:  conn,
+		lines: make(chan string, monitorBufferSize),
+		done:  make(chan struct{}),
+	}
+	m.mu.Lock()
+	m.subs[mon] = struct{}{}
+	m.n.Store(int32(len(m.subs)))
+	m.mu.Unlock()
+
+	go m.write(mon)
+	go m.read(mon)
+}
+
+// active reports whether there are any monitors.
+func (m *monitors) active() bool {
+	return m.n.Load() > 0
+}
+
+// feed sends the command line to all monitors.
+func (m *monitors) feed(line string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for mon := range m.subs {
+		select {
+		case mon.lines <- line:
+		default:
+			// The monitor can't keep up, so disconnect it
+			// instead of blocking the command processing.
+			m.log.Warn("monitor buffer overflow", "client", mon.conn.RemoteAddr())
+			delete(m.subs, mon)
+			m.n.Store(int32(len(m.subs)))
+			mon.stop()
+		}
+	}
+}
+
+// close stops all monitors.
+func (m *monitors) close() {
+	m.mu.Lock()
+	subs := make([]*monitorConn, 0, len(m.subs))
+	for mon := range m.subs {
+		subs = append(subs, mon)
+	}
+	m.mu.Unlock()
+	for _, mon := range subs {
+		mon.stop()
+	}
+}
+
+// remove unregisters the monitor.
+func (m *monitors) remove(mon *monitorConn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.subs, mon)
+	m.n.Store(int32(len(m.subs)))
+}
+
+// write sends the command lines to the monitor connection.
+// Closes the connection when the monitor stops.
+func (m *monitors) write(mon *monitorConn) {
+	defer func() {
+		m.remove(mon)
+		mon.stop()
+		_ = mon.conn.Close()
+	}()
+	for {
+		select {
+		case line := <-mon.lines:
+			mon.conn.WriteString(line)
+			if len(mon.lines) > 0 {
+				// More lines are pending, flush later.
+				continue
+			}
+			if err := mon.conn.Flush(); err != nil {
+				return
+			}
+		case <-mon.done:
+			return
+		}
+	}
+}
+
+// read waits for the monitor connection to close.
+// Stops the monitor on QUIT, ignores other commands.
+func (m *monitors) read(mon *monitorConn) {
+	defer mon.stop()
+	for {
+		cmd, err := mon.conn.ReadCommand()
+		if err != nil {
+			return
+		}
+		if strings.EqualFold(string(cmd.Args[0]), "quit") {
+			return
+		}
+	}
+}
+
+// formatMonitorLine formats the command the same way Redis does:
+// 1339518083.107412 [0 127.0.0.1:60866] "keys" "*"
+func formatMonitorLine(now time.Time, addr string, args [][]byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d.%06d [0 %s]", now.Unix(), now.Nanosecond()/1000, addr)
+	for _, arg := range args {
+		b.WriteByte(' ')
+		writeQuoted(&b, arg)
+	}
+	return b.String()
+}
+
+// writeQuoted writes the argument as a quoted string,
+// escaping the special and non-printable characters.
+func writeQuoted(b *strings.Builder, arg []byte) {
+	b.WriteByte('"')
+	for _, c := range arg {
+		switch c {
+		case '\\', '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		default:
+			if c < ' ' || c > '~' {
+				fmt.Fprintf(b, `\x%02x`, c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
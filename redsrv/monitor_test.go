@@ -0,0 +1,152 @@
+package redsrv
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/testx"
+	"github.com/tidwall/redcon"
+)
+
+func TestFormatMonitorLine(t *testing.T) {
+	now := time.Unix(1339518083, 107412000)
+	t.Run("simple", func(t *testing.T) {
+		args := [][]byte{[]byte("keys"), []byte("*")}
+		line := formatMonitorLine(now, "127.0.0.1:60866", args)
+		be.Equal(t, line, `1339518083.107412 [0 127.0.0.1:60866] "keys" "*"`)
+	})
+	t.Run("escape", func(t *testing.T) {
+		args := [][]byte{[]byte("set"), []byte(`say "hi"`), []byte("a\r\n\\\x00\xff")}
+		line := formatMonitorLine(now, "127.0.0.1:60866", args)
+		be.Equal(t, line, `1339518083.107412 [0 127.0.0.1:60866] `+
+			`"set" "say \"hi\"" "a\r\n\\\x00\xff"`)
+	})
+}
+
+func TestMonitor(t *testing.T) {
+	db := testx.OpenDB(t)
+	srv := newTestState(db)
+	mux := createHandlers(db, srv)
+	serve := func(conn redcon.Conn, args ...string) {
+		mux.ServeRESP(conn, buildCommand(args...))
+	}
+
+	// Start monitoring.
+	mconn := new(fakeConn)
+	serve(mconn, "monitor")
+	be.Equal(t, mconn.out(), "OK")
+	be.True(t, srv.monitors.active())
+
+	// Run some commands from another client.
+	conn := new(fakeConn)
+	serve(conn, "set", "name", "alice")
+	serve(conn, "multi")
+	serve(conn, "incr", "age")
+	serve(conn, "exec")
+	serve(conn, "get", "name", "age")
+
+	// Check the monitored commands. The command
+	// with the wrong number of arguments is skipped.
+	want := []string{
+		`"set" "name" "alice"`, `"multi"`, `"incr" "age"`, `"exec"`,
+	}
+	lines := waitMonitorLines(t, mconn, len(want)+1)
+	be.Equal(t, len(lines), len(want)+1)
+	for i, suffix := range want {
+		be.True(t, strings.HasSuffix(lines[i+1], suffix))
+	}
+
+	// Stop monitoring.
+	mconn.dconn.in <- buildCommand("quit")
+	waitFor(t, func() bool { return !srv.monitors.active() })
+}
+
+func TestMonitorClose(t *testing.T) {
+	db := testx.OpenDB(t)
+	srv := newTestState(db)
+	mux := createHandlers(db, srv)
+	mconn := new(fakeConn)
+	mux.ServeRESP(mconn, buildCommand("monitor"))
+	be.True(t, srv.monitors.active())
+
+	srv.monitors.close()
+	waitFor(t, func() bool { return !srv.monitors.active() })
+}
+
+func TestMonitorOverflow(t *testing.T) {
+	mons := newMonitors(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	conn := &stalledConn{
+		fakeDetachedConn: new(fakeConn).Detach().(*fakeDetachedConn),
+		flush:            make(chan struct{}),
+	}
+	mons.add(conn)
+
+	// The writer is stuck flushing the first line,
+	// so the buffer overflows and the monitor is removed.
+	for range monitorBufferSize + 2 {
+		mons.feed("line")
+	}
+	be.Equal(t, mons.active(), false)
+	select {
+	case <-conn.done:
+		t.Fatal("closed while the writer is flushing")
+	default:
+	}
+
+	// The writer closes the connection once it's done.
+	close(conn.flush)
+	<-conn.done
+}
+
+// stalledConn is a monitor connection
+// that blocks on flush until released.
+type stalledConn struct {
+	*fakeDetachedConn
+	flush chan struct{}
+}
+
+func (c *stalledConn) Flush() error {
+	<-c.flush
+	return nil
+}
+
+func TestMonitorInMulti(t *testing.T) {
+	db := testx.OpenDB(t)
+	srv := newTestState(db)
+	mux := createHandlers(db, srv)
+	conn := new(fakeConn)
+	mux.ServeRESP(conn, buildCommand("multi"))
+	mux.ServeRESP(conn, buildCommand("monitor"))
+	be.Equal(t, conn.out(), "OK,ERR MONITOR is not allowed in MULTI")
+	be.Equal(t, srv.monitors.active(), false)
+}
+
+// waitMonitorLines waits until the monitor
+// connection receives n lines and returns them.
+func waitMonitorLines(t *testing.T, conn *fakeConn, n int) []string {
+	t.Helper()
+	var lines []string
+	waitFor(t, func() bool {
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		lines = append([]string(nil), conn.parts...)
+		return len(lines) >= n
+	})
+	return lines
+}
+
+// waitFor waits until the condition is true or fails the test.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
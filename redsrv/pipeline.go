@@ -0,0 +1,183 @@
+package redsrv
+
+import (
+	"sync/atomic"
+
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/redsrv/internal/command"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+	"github.com/tidwall/redcon"
+)
+
+// Default maximum number of pipelined write
+// commands executed in a single transaction.
+const defaultPipelineBatchSize = 1000
+
+// batcher holds the pipeline batching settings.
+//
+// When a client pipelines commands (e.g. with redis-cli --pipe),
+// the server executes consecutive write commands in a single
+// transaction instead of one transaction per command, which is
+// much faster for bulk loads. Each command still gets its own
+// reply, and a failed command does not affect the others.
+//
+// batcher is safe for concurrent use by multiple goroutines.
+type batcher struct {
+	size atomic.Int64
+}
+
+// newBatcher creates a batcher with the default settings.
+func newBatcher() *batcher {
+	b := new(batcher)
+	b.size.Store(defaultPipelineBatchSize)
+	return b
+}
+
+// Size returns the maximum number of commands in a batch.
+// Zero or one means the batching is disabled.
+func (b *batcher) Size() int {
+	return int(b.size.Load())
+}
+
+// SetSize sets the maximum number of commands in a batch.
+func (b *batcher) SetSize(n int) {
+	b.size.Store(int64(n))
+}
+
+// pipeline executes consecutive write commands from
+// the client pipeline in a single transaction.
+// Other commands are executed one by one as usual.
+//
+// The batch only includes the commands up to the first
+// non-write command. The rest of the pipeline is left to the
+// server, so that the commands that detach the connection
+// (SUBSCRIBE, PSUBSCRIBE and MONITOR) get the commands
+// that follow them.
+func pipeline(next redcon.HandlerFunc, db *redka.DB, b *batcher) redcon.HandlerFunc {
+	return func(conn redcon.Conn, cmd redcon.Command) {
+		state := getState(conn)
+		if state.skip > 0 {
+			// The command has already been executed
+			// as part of the previous batch.
+			state.skip--
+			return
+		}
+		size := b.Size()
+		if size < 2 || state.inMulti || !batchable(cmd) {
+			next(conn, cmd)
+			return
+		}
+		peeked := conn.PeekPipeline()
+		n := 0
+		for n < len(peeked) && n+1 < size && batchable(peeked[n]) {
+			n++
+		}
+		if n == 0 {
+			next(conn, cmd)
+			return
+		}
+
+		cmds := append([]redcon.Command{cmd}, peeked[:n]...)
+		if !runBatch(conn, state.traced(db), cmds, next) {
+			next(conn, cmd)
+			return
+		}
+		// The server still has the batched commands
+		// in the pipeline, so skip them.
+		state.skip = n
+	}
+}
+
+// runBatch executes the commands in a single transaction.
+// Buffers the replies and the side effects of the commands
+// (see [connState.afterCommit]) until the transaction commits.
+// If the commit fails, replies to every command in the batch
+// with the commit error and drops the side effects. If the database
+// is over the limits, does nothing and returns false,
+// so that the commands are executed one by one.
+func runBatch(conn redcon.Conn, db *redka.DB, cmds []redcon.Command, next redcon.HandlerFunc) bool {
+	state := getState(conn)
+	if err := db.Evict(); err != nil {
+		// The database is over the limits, so the commands
+		// that may increase the database size should be
+		// rejected one by one.
+		return false
+	}
+	bconn := newBufferedConn(conn)
+	err := db.Update(func(tx *redka.Tx) error {
+		state.tx = tx
+		defer func() { state.tx = nil }()
+		for _, cmd := range cmds {
+			next(bconn, cmd)
+		}
+		return nil
+	})
+	effects := state.effects
+	state.effects = nil
+	if err != nil {
+		db.Log().Warn("run pipeline batch", "client", conn.RemoteAddr(),
+			"count", len(cmds), "err", err)
+		for range cmds {
+			conn.WriteError("ERR " + err.Error())
+		}
+		return true
+	}
+	for _, f := range effects {
+		f()
+	}
+	conn.WriteRaw(bconn.wr.Buffer())
+	return true
+}
+
+// batchable reports whether the command
+// can be executed as part of a batch.
+func batchable(cmd redcon.Command) bool {
+	info, ok := command.Table.Get(normName(cmd))
+	return ok && info.HasFlag(redis.FlagWrite) && !info.HasFlag(redis.FlagBlocking)
+}
+
+// bufferedConn is a connection that buffers the replies
+// instead of sending them to the client.
+type bufferedConn struct {
+	redcon.Conn
+	wr *redcon.Writer // never flushed
+}
+
+// newBufferedConn creates a buffered connection on top of conn.
+func newBufferedConn(conn redcon.Conn) *bufferedConn {
+	return &bufferedConn{Conn: conn, wr: redcon.NewWriter(nil)}
+}
+
+func (c *bufferedConn) WriteError(msg string) {
+	c.wr.WriteError(msg)
+}
+func (c *bufferedConn) WriteString(str string) {
+	c.wr.WriteString(str)
+}
+func (c *bufferedConn) WriteBulk(bulk []byte) {
+	c.wr.WriteBulk(bulk)
+}
+func (c *bufferedConn) WriteBulkString(bulk string) {
+	c.wr.WriteBulkString(bulk)
+}
+func (c *bufferedConn) WriteInt(num int) {
+	c.wr.WriteInt(num)
+}
+func (c *bufferedConn) WriteInt64(num int64) {
+	c.wr.WriteInt64(num)
+}
+func (c *bufferedConn) WriteUint64(num uint64) {
+	c.wr.WriteUint64(num)
+}
+func (c *bufferedConn) WriteArray(count int) {
+	c.wr.WriteArray(count)
+}
+func (c *bufferedConn) WriteNull() {
+	c.wr.WriteNull()
+}
+func (c *bufferedConn) WriteRaw(data []byte) {
+	c.wr.WriteRaw(data)
+}
+func (c *bufferedConn) WriteAny(v any) {
+	c.wr.WriteAny(v)
+}
@@ -0,0 +1,209 @@
+package redsrv
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+	"github.com/tidwall/redcon"
+)
+
+func TestPipeline(t *testing.T) {
+	// The fake connection records a batch reply as a single
+	// RESP-encoded part, and the other replies part by part.
+	t.Run("batch", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		srv := newTestState(db)
+		mux := createHandlers(db, srv)
+
+		conn := new(fakeConn)
+		servePipeline(mux, conn,
+			buildCommand("set", "name", "alice"),
+			buildCommand("incr", "age"),
+			buildCommand("sadd", "tags", "go", "sql"),
+		)
+		be.Equal(t, conn.out(), "+OK\r\n:1\r\n:2\r\n")
+	})
+	t.Run("failed command", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		srv := newTestState(db)
+		mux := createHandlers(db, srv)
+
+		conn := new(fakeConn)
+		servePipeline(mux, conn,
+			buildCommand("set", "name", "alice"),
+			buildCommand("incr", "name"),
+			buildCommand("set", "age", "25"),
+		)
+		be.Equal(t, conn.out(), "+OK\r\n-invalid value type (incr)\r\n+OK\r\n")
+		name, _ := db.Str().Get("name")
+		be.Equal(t, name.String(), "alice")
+		age, _ := db.Str().Get("age")
+		be.Equal(t, age.String(), "25")
+	})
+	t.Run("failed commit", func(t *testing.T) {
+		if testx.Driver() != "sqlite3" {
+			t.Skip("requires sqlite3")
+		}
+		db := testx.OpenDBWith(t, &redka.Options{ChangeLog: true})
+		srv := newTestState(db)
+		srv.slowlog.SetThreshold(0)
+		mux := createHandlers(db, srv)
+
+		// Without the change log table, the batch fails
+		// after every command in it has already run.
+		sdb, err := sql.Open("sqlite3", "file:/redka.db?vfs=memdb")
+		be.Err(t, err, nil)
+		defer sdb.Close()
+		_, err = sdb.Exec("drop table rchange")
+		be.Err(t, err, nil)
+
+		conn := new(fakeConn)
+		servePipeline(mux, conn,
+			buildCommand("set", "name", "alice"),
+			buildCommand("set", "age", "25"),
+		)
+		be.Equal(t, conn.out(), "ERR no such table: rchange,ERR no such table: rchange")
+		be.Equal(t, srv.slowlog.Len(), 0)
+		_, ok := srv.metrics.cmds["set"]
+		be.Equal(t, ok, false)
+	})
+	t.Run("committed effects", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		srv := newTestState(db)
+		srv.slowlog.SetThreshold(0)
+		mux := createHandlers(db, srv)
+
+		conn := new(fakeConn)
+		servePipeline(mux, conn,
+			buildCommand("set", "name", "alice"),
+			buildCommand("set", "age", "25"),
+		)
+		be.Equal(t, conn.out(), "+OK\r\n+OK\r\n")
+		be.Equal(t, srv.slowlog.Len(), 2)
+		be.Equal(t, srv.metrics.cmds["set"].calls, uint64(2))
+	})
+	t.Run("mixed commands", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		srv := newTestState(db)
+		mux := createHandlers(db, srv)
+
+		conn := new(fakeConn)
+		servePipeline(mux, conn,
+			buildCommand("set", "name", "alice"),
+			buildCommand("set", "age", "25"),
+			buildCommand("get", "name"),
+			buildCommand("set", "city", "paris"),
+			buildCommand("multi"),
+			buildCommand("set", "name", "bob"),
+			buildCommand("set", "age", "50"),
+			buildCommand("exec"),
+		)
+		be.Equal(t, conn.out(), "+OK\r\n+OK\r\n,alice,OK,OK,QUEUED,QUEUED,2,OK,OK")
+	})
+	t.Run("batch size", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		srv := newTestState(db)
+		srv.batcher.SetSize(2)
+		mux := createHandlers(db, srv)
+
+		conn := new(fakeConn)
+		servePipeline(mux, conn,
+			buildCommand("set", "k1", "1"),
+			buildCommand("set", "k2", "2"),
+			buildCommand("set", "k3", "3"),
+			buildCommand("set", "k4", "4"),
+			buildCommand("set", "k5", "5"),
+		)
+		be.Equal(t, conn.out(), "+OK\r\n+OK\r\n,+OK\r\n+OK\r\n,OK")
+	})
+	t.Run("over the limits", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{
+			ExpireInterval: 5 * time.Millisecond,
+			MaxKeys:        1,
+		})
+		_ = db.Str().Set("name", "alice")
+		_ = db.Str().Set("age", 25)
+		time.Sleep(20 * time.Millisecond)
+		srv := newTestState(db)
+		mux := createHandlers(db, srv)
+
+		// The commands are executed one by one,
+		// so that the deletes still work.
+		conn := new(fakeConn)
+		servePipeline(mux, conn,
+			buildCommand("set", "city", "paris"),
+			buildCommand("del", "name"),
+		)
+		be.Equal(t, conn.out(), redis.ErrOOM.Error()+",1")
+	})
+	t.Run("subscribe", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		srv := startTestServer(t, db)
+		client := dialTestServer(t, srv)
+
+		// The commands after SUBSCRIBE are executed
+		// by the subscribed connection.
+		var buf []byte
+		for _, args := range [][]string{
+			{"set", "k1", "1"},
+			{"set", "k2", "2"},
+			{"subscribe", "ch"},
+			{"set", "k3", "3"},
+			{"ping"},
+		} {
+			buf = redcon.AppendArray(buf, len(args))
+			for _, arg := range args {
+				buf = redcon.AppendBulkString(buf, arg)
+			}
+		}
+		_ = client.conn.SetDeadline(time.Now().Add(5 * time.Second))
+		_, err := client.conn.Write(buf)
+		be.Err(t, err, nil)
+
+		var replies []string
+		for range 5 {
+			reply, err := client.read()
+			if err != nil {
+				replies = append(replies, err.Error())
+				continue
+			}
+			replies = append(replies, formatReply(reply))
+		}
+		be.Equal(t, replies[:3], []string{"OK", "OK", "[subscribe ch 1]"})
+		be.True(t, strings.HasPrefix(replies[3], "ERR"))
+		be.Equal(t, replies[4], "[pong ]")
+
+		_, err = db.Str().Get("k3")
+		be.Err(t, err, redka.ErrNotFound)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		srv := newTestState(db)
+		srv.batcher.SetSize(0)
+		mux := createHandlers(db, srv)
+
+		conn := new(fakeConn)
+		servePipeline(mux, conn,
+			buildCommand("set", "name", "alice"),
+			buildCommand("set", "age", "25"),
+		)
+		be.Equal(t, conn.out(), "OK,OK")
+	})
+}
+
+// servePipeline serves the commands the way the server
+// serves a pipeline received from the client.
+func servePipeline(mux redcon.Handler, conn *fakeConn, cmds ...redcon.Command) {
+	conn.pipeline = cmds
+	for len(conn.pipeline) > 0 {
+		cmd := conn.pipeline[0]
+		conn.pipeline = conn.pipeline[1:]
+		mux.ServeRESP(conn, cmd)
+	}
+}
@@ -0,0 +1,92 @@
+package redsrv
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/nalgeon/redka"
+	"github.com/tidwall/redcon"
+)
+
+// Max number of pending keyspace notifications.
+// Notifications that don't fit are dropped.
+const notifyBufferSize = 4096
+
+// broker is a publish/subscribe message broker.
+// Delivers the messages published by the clients
+// and the database keyspace notifications.
+type broker struct {
+	ps     redcon.PubSub
+	notifs chan redka.Notification
+	done   chan struct{}
+	once   sync.Once
+	cancel func() // unregisters the notification hook
+	log    *slog.Logger
+}
+
+// newBroker creates a message broker.
+func newBroker(log *slog.Logger) *broker {
+	return &broker{
+		notifs: make(chan redka.Notification, notifyBufferSize),
+		done:   make(chan struct{}),
+		log:    log,
+	}
+}
+
+// Publish sends the message to the channel subscribers.
+// Returns the number of clients that received the message.
+func (b *broker) Publish(channel, message string) int {
+	return b.ps.Publish(channel, message)
+}
+
+// subscribe subscribes the connection to the channel
+// (or the channel pattern if pattern is true).
+// Detaches the connection on the first call.
+func (b *broker) subscribe(conn redcon.Conn, channel string, pattern bool) {
+	if pattern {
+		b.ps.Psubscribe(conn, channel)
+	} else {
+		b.ps.Subscribe(conn, channel)
+	}
+}
+
+// listen starts publishing the database keyspace
+// notifications until the broker is closed.
+func (b *broker) listen(db *redka.DB) {
+	b.cancel = db.OnNotify(b.notify)
+	go b.run()
+}
+
+// notify queues the keyspace notification for publishing.
+// Does not block the database writer: drops the notification
+// if the subscribers can't keep up.
+func (b *broker) notify(n redka.Notification) {
+	select {
+	case b.notifs <- n:
+	case <-b.done:
+	default:
+		b.log.Warn("keyspace notification dropped", "channel", n.Channel)
+	}
+}
+
+// run publishes the queued keyspace notifications.
+func (b *broker) run() {
+	for {
+		select {
+		case n := <-b.notifs:
+			b.ps.Publish(n.Channel, n.Message)
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// close stops publishing the keyspace notifications.
+func (b *broker) close() {
+	b.once.Do(func() {
+		if b.cancel != nil {
+			b.cancel()
+		}
+		close(b.done)
+	})
+}
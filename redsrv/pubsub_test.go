@@ -0,0 +1,97 @@
+package redsrv
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/testx"
+	"github.com/tidwall/redcon"
+)
+
+func TestPublish(t *testing.T) {
+	db := testx.OpenDB(t)
+	srv := newTestState(db)
+	mux := createHandlers(db, srv)
+	serve := func(conn redcon.Conn, args ...string) {
+		mux.ServeRESP(conn, buildCommand(args...))
+	}
+
+	// Subscribe to a channel and a pattern.
+	sconn := new(fakeConn)
+	serve(sconn, "subscribe", "news", "chat")
+	be.Equal(t, sconn.out(), "3,subscribe,news,1,3,subscribe,chat,2")
+	sconn.dconn.in <- buildCommand("psubscribe", "n*")
+
+	// Publish from another client.
+	conn := new(fakeConn)
+	waitFor(t, func() bool {
+		conn.parts = nil
+		serve(conn, "publish", "news", "hello")
+		return conn.out() == "2"
+	})
+
+	// Quit the subscriber mode.
+	sconn.dconn.in <- buildCommand("quit")
+	waitFor(t, func() bool {
+		conn.parts = nil
+		serve(conn, "publish", "news", "hello")
+		return conn.out() == "0"
+	})
+}
+
+func TestSubscribeNotify(t *testing.T) {
+	db := testx.OpenDB(t)
+	srv := newTestState(db)
+	registerConfig(srv.config, db, srv.slowlog, srv.saver, srv.batcher)
+	mux := createHandlers(db, srv)
+	serve := func(conn redcon.Conn, args ...string) {
+		mux.ServeRESP(conn, buildCommand(args...))
+	}
+
+	conn := new(fakeConn)
+	serve(conn, "config", "set", "notify-keyspace-events", "KEA")
+	serve(conn, "config", "get", "notify-keyspace-events")
+	be.Equal(t, conn.out(), "OK,2,notify-keyspace-events,AKE")
+
+	sconn := new(fakeConn)
+	serve(sconn, "psubscribe", "__key*__:*")
+	serve(conn, "set", "name", "alice")
+	serve(conn, "multi")
+	serve(conn, "del", "name")
+	serve(conn, "discard")
+
+	want := "3,psubscribe,__key*__:*,1," +
+		"4,pmessage,__key*__:*,__keyspace@0__:name,set," +
+		"4,pmessage,__key*__:*,__keyevent@0__:set,name"
+	waitFor(t, func() bool { return sconn.out() == want })
+	sconn.dconn.in <- buildCommand("quit")
+}
+
+func TestSubscribeNotifyInvalid(t *testing.T) {
+	db := testx.OpenDB(t)
+	srv := newTestState(db)
+	registerConfig(srv.config, db, srv.slowlog, srv.saver, srv.batcher)
+	mux := createHandlers(db, srv)
+	conn := new(fakeConn)
+	mux.ServeRESP(conn, buildCommand("config", "set", "notify-keyspace-events", "KEQ"))
+	be.Equal(t, conn.out(), "ERR invalid config value 'KEQ' for 'notify-keyspace-events' "+
+		"(invalid notify-keyspace-events flags) (config)")
+}
+
+func TestUnsubscribe(t *testing.T) {
+	db := testx.OpenDB(t)
+	mux := createHandlers(db, newTestState(db))
+	conn := new(fakeConn)
+	mux.ServeRESP(conn, buildCommand("unsubscribe"))
+	mux.ServeRESP(conn, buildCommand("punsubscribe", "n*"))
+	be.Equal(t, conn.out(), "3,unsubscribe,(nil),0,3,punsubscribe,n*,0")
+}
+
+func TestSubscribeInMulti(t *testing.T) {
+	db := testx.OpenDB(t)
+	mux := createHandlers(db, newTestState(db))
+	conn := new(fakeConn)
+	mux.ServeRESP(conn, buildCommand("multi"))
+	mux.ServeRESP(conn, buildCommand("subscribe", "news"))
+	be.Equal(t, conn.out(), "OK,ERR SUBSCRIBE is not allowed in MULTI")
+}
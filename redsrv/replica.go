@@ -0,0 +1,549 @@
+package redsrv
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/redsrv/internal/command"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+	"github.com/tidwall/redcon"
+)
+
+// Replication settings.
+const (
+	// How often the replica checks for new changes
+	// when it has caught up with the primary.
+	replPollInterval = 100 * time.Millisecond
+	// How long the replica waits before reconnecting
+	// to the primary after an error.
+	replRetryInterval = time.Second
+	// Max time to wait for the primary to reply.
+	replTimeout = time.Minute
+	// Max number of changes the replica requests at once.
+	replBatchSize = 1000
+)
+
+// errResync means the replica has missed some changes
+// (the primary has trimmed its change log) and has to
+// load a new snapshot.
+var errResync = errors.New("missed changes, full resync required")
+
+// replication is the primary-replica replication state.
+// Every server is a primary unless it is turned into
+// a replica with [Server.ReplicaOf].
+type replication struct {
+	db    *redka.DB
+	srv   redis.Server
+	snaps *snapshots
+	log   *slog.Logger
+
+	mu      sync.Mutex
+	replica *replica
+}
+
+// newReplication creates the replication state of a primary.
+func newReplication(db *redka.DB) *replication {
+	return &replication{db: db, snaps: newSnapshots(db), log: db.Log()}
+}
+
+// isReplica reports whether the server is a replica.
+func (r *replication) isReplica() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.replica != nil
+}
+
+// Role returns the replication role of the server.
+func (r *replication) Role() redis.ReplicationRole {
+	r.mu.Lock()
+	rep := r.replica
+	r.mu.Unlock()
+	if rep == nil {
+		seq, _ := r.db.Changes().Last()
+		return redis.ReplicationRole{Seq: seq}
+	}
+	return rep.role()
+}
+
+// ChangeLog reports whether the database change log is enabled.
+func (r *replication) ChangeLog() bool {
+	return r.db.ChangeLog()
+}
+
+// Snapshot returns the next page of the database snapshot.
+func (r *replication) Snapshot(cursor int64, count int) (redis.SnapshotPage, error) {
+	return r.snaps.page(cursor, count)
+}
+
+// replicaOf starts replicating the primary at the given address.
+// Stops replicating the previous primary, if any.
+func (r *replication) replicaOf(host string, port int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.replica != nil {
+		r.replica.stop()
+	}
+	r.replica = newReplica(host, port, r.db, r.srv, r.log)
+	r.replica.start()
+}
+
+// stop stops replicating the primary, if any, and cancels
+// the snapshots being read by the replicas.
+// The server remains a replica.
+func (r *replication) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.replica != nil {
+		r.replica.stop()
+	}
+	r.snaps.close()
+}
+
+// replica replicates the primary database. It loads
+// the primary snapshot (REDKA.SNAPSHOT), then polls
+// the primary change log (REDKA.CHANGES) and applies
+// the changes. Reconnects and loads a new snapshot
+// if the connection breaks.
+type replica struct {
+	host string
+	port int
+	db   *redka.DB
+	srv  redis.Server
+	log  *slog.Logger
+
+	mu    sync.Mutex
+	state string
+	seq   int64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newReplica creates a new replica of the primary
+// at the given address. Does not start replicating.
+func newReplica(host string, port int, db *redka.DB, srv redis.Server, log *slog.Logger) *replica {
+	return &replica{
+		host:  host,
+		port:  port,
+		db:    db,
+		srv:   srv,
+		log:   log,
+		state: redis.ReplConnect,
+		done:  make(chan struct{}),
+	}
+}
+
+// start starts replicating in the background.
+func (r *replica) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	go r.run(ctx)
+}
+
+// stop stops replicating and waits
+// for the background goroutine to exit.
+func (r *replica) stop() {
+	r.cancel()
+	<-r.done
+}
+
+// role returns the replica role description.
+func (r *replica) role() redis.ReplicationRole {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return redis.ReplicationRole{
+		Replica: true,
+		Host:    r.host,
+		Port:    r.port,
+		State:   r.state,
+		Seq:     r.seq,
+	}
+}
+
+// setState sets the replication link state
+// and the last applied sequence number.
+func (r *replica) setState(state string, seq int64) {
+	r.mu.Lock()
+	r.state = state
+	r.seq = seq
+	r.mu.Unlock()
+}
+
+// run replicates the primary until the context is canceled.
+func (r *replica) run(ctx context.Context) {
+	defer close(r.done)
+	addr := net.JoinHostPort(r.host, strconv.Itoa(r.port))
+	for {
+		r.log.Info("connect to primary", "addr", addr)
+		err := r.sync(ctx, addr)
+		if ctx.Err() != nil {
+			r.log.Debug("replication stopped", "addr", addr)
+			return
+		}
+		r.log.Warn("replication failed", "addr", addr, "err", err)
+		r.mu.Lock()
+		r.state = redis.ReplConnect
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(replRetryInterval):
+		}
+	}
+}
+
+// sync connects to the primary, loads the snapshot
+// and applies the changes until an error occurs
+// or the context is canceled.
+func (r *replica) sync(ctx context.Context, addr string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// Unblock the pending reads when the replication stops.
+	stopClose := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stopClose()
+	client := newRespClient(conn)
+
+	// Load the snapshot.
+	r.setState(redis.ReplSync, 0)
+	seq, count, err := r.load(ctx, client)
+	if err != nil {
+		return fmt.Errorf("load snapshot: %w", err)
+	}
+	r.setState(redis.ReplConnected, seq)
+	r.log.Info("loaded snapshot", "addr", addr, "commands", count, "seq", seq)
+
+	// Apply the changes.
+	for {
+		reply, err := client.do("redka.changes",
+			strconv.FormatInt(seq, 10), strconv.Itoa(replBatchSize))
+		if err != nil {
+			return fmt.Errorf("changes: %w", err)
+		}
+		lastSeq, cmds, err := parseChanges(reply, seq)
+		if err != nil {
+			return fmt.Errorf("changes: %w", err)
+		}
+		if len(cmds) == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(replPollInterval):
+			}
+			continue
+		}
+		err = r.apply(cmds)
+		if err != nil {
+			return fmt.Errorf("apply changes: %w", err)
+		}
+		seq = lastSeq
+		r.setState(redis.ReplConnected, seq)
+	}
+}
+
+// load replaces the database contents with the primary snapshot.
+// First downloads the snapshot page by page into a temporary file,
+// so that the network transfer does not hold up the writers.
+// Then deletes the keys and loads the snapshot from the file in
+// a single transaction, so the readers never see a partially
+// loaded database. Returns the sequence number of the snapshot
+// and the number of loaded commands.
+func (r *replica) load(ctx context.Context, client *respClient) (seq int64, count int, err error) {
+	f, err := os.CreateTemp("", "redka-snapshot-*")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	seq, count, err = download(client, f)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+
+	rd := respReader{r: bufio.NewReader(f)}
+	err = r.db.UpdateContext(ctx, func(tx *redka.Tx) error {
+		err := tx.Key().DeleteAll()
+		if err != nil {
+			return fmt.Errorf("flush: %w", err)
+		}
+		for range count {
+			reply, err := rd.read()
+			if err != nil {
+				return err
+			}
+			args, err := parseArgs(reply)
+			if err != nil {
+				return err
+			}
+			r.exec(tx, args)
+		}
+		return nil
+	})
+	return seq, count, err
+}
+
+// download reads the snapshot from the primary page by page
+// and writes the commands to w in the RESP format. Returns
+// the sequence number of the snapshot and the number of commands.
+func download(client *respClient, w io.Writer) (seq int64, count int, err error) {
+	bw := bufio.NewWriter(w)
+	var buf []byte
+	var cursor int64
+	for {
+		reply, err := client.do("redka.snapshot",
+			strconv.FormatInt(cursor, 10), "count", strconv.Itoa(replBatchSize))
+		if err != nil {
+			return 0, 0, err
+		}
+		page, err := parseSnapshot(reply)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, args := range page.Cmds {
+			buf = redcon.AppendArray(buf[:0], len(args))
+			for _, arg := range args {
+				buf = redcon.AppendBulk(buf, arg)
+			}
+			if _, err := bw.Write(buf); err != nil {
+				return 0, 0, err
+			}
+		}
+		seq, count = page.Seq, count+len(page.Cmds)
+		if page.Cursor == 0 {
+			return seq, count, bw.Flush()
+		}
+		cursor = page.Cursor
+	}
+}
+
+// apply executes the commands against the database
+// in a single transaction.
+func (r *replica) apply(cmds [][][]byte) error {
+	return r.db.Update(func(tx *redka.Tx) error {
+		for _, args := range cmds {
+			r.exec(tx, args)
+		}
+		return nil
+	})
+}
+
+// exec executes a single write command in a savepoint of the
+// transaction, so that if it fails, the other commands are not
+// affected. Skips the commands that fail (like Redis replicas do).
+// Ignores the non-write commands, since the primary never sends them.
+func (r *replica) exec(tx *redka.Tx, args [][]byte) {
+	name := strings.ToLower(string(args[0]))
+	if info, ok := command.Table.Get(name); !ok || !info.HasFlag(redis.FlagWrite) {
+		r.log.Warn("skip replicated command", "name", name)
+		return
+	}
+	pcmd, err := command.Parse(args, r.srv, 0)
+	if err != nil {
+		r.log.Warn("parse replicated command", "name", name, "err", err)
+		return
+	}
+	err = tx.Savepoint(func(tx *redka.Tx) error {
+		_, err := pcmd.Run(discardWriter{}, redis.RedkaTx(tx))
+		return err
+	})
+	if err != nil {
+		r.log.Warn("run replicated command", "name", name, "err", err)
+	}
+}
+
+// parseSnapshot parses the REDKA.SNAPSHOT reply.
+func parseSnapshot(reply any) (redis.SnapshotPage, error) {
+	items, ok := reply.([]any)
+	if !ok || len(items) != 3 {
+		return redis.SnapshotPage{}, errors.New("invalid reply")
+	}
+	cursor, ok := items[0].(int64)
+	if !ok {
+		return redis.SnapshotPage{}, errors.New("invalid cursor")
+	}
+	seq, ok := items[1].(int64)
+	if !ok {
+		return redis.SnapshotPage{}, errors.New("invalid sequence number")
+	}
+	cmdItems, ok := items[2].([]any)
+	if !ok {
+		return redis.SnapshotPage{}, errors.New("invalid commands")
+	}
+	cmds := make([][][]byte, 0, len(cmdItems))
+	for _, item := range cmdItems {
+		args, err := parseArgs(item)
+		if err != nil {
+			return redis.SnapshotPage{}, err
+		}
+		cmds = append(cmds, args)
+	}
+	return redis.SnapshotPage{Cursor: cursor, Seq: seq, Cmds: cmds}, nil
+}
+
+// parseChanges parses the REDKA.CHANGES reply. Returns the
+// sequence number of the last change and the commands to apply.
+// Returns errResync if the first change does not follow
+// the since sequence number.
+func parseChanges(reply any, since int64) (int64, [][][]byte, error) {
+	items, ok := reply.([]any)
+	if !ok {
+		return 0, nil, errors.New("invalid reply")
+	}
+	seq := since
+	cmds := make([][][]byte, 0, len(items))
+	for i, item := range items {
+		entry, ok := item.([]any)
+		if !ok || len(entry) != 5 {
+			return 0, nil, errors.New("invalid change")
+		}
+		s, ok := entry[0].(int64)
+		if !ok {
+			return 0, nil, errors.New("invalid sequence number")
+		}
+		if i == 0 && s != since+1 {
+			return 0, nil, errResync
+		}
+		args, err := parseArgs(entry[4])
+		if err != nil {
+			return 0, nil, err
+		}
+		seq = s
+		cmds = append(cmds, args)
+	}
+	return seq, cmds, nil
+}
+
+// parseArgs parses the command name and arguments.
+func parseArgs(reply any) ([][]byte, error) {
+	items, ok := reply.([]any)
+	if !ok || len(items) == 0 {
+		return nil, errors.New("invalid command")
+	}
+	args := make([][]byte, len(items))
+	for i, item := range items {
+		arg, ok := item.([]byte)
+		if !ok {
+			return nil, errors.New("invalid command argument")
+		}
+		args[i] = arg
+	}
+	return args, nil
+}
+
+// respClient is a minimal RESP client
+// the replica uses to talk to the primary.
+type respClient struct {
+	conn net.Conn
+	respReader
+	buf []byte
+}
+
+// newRespClient creates a client over the connection.
+func newRespClient(conn net.Conn) *respClient {
+	return &respClient{conn: conn, respReader: respReader{r: bufio.NewReader(conn)}}
+}
+
+// do sends the command and reads the reply.
+func (c *respClient) do(args ...string) (any, error) {
+	c.buf = redcon.AppendArray(c.buf[:0], len(args))
+	for _, arg := range args {
+		c.buf = redcon.AppendBulkString(c.buf, arg)
+	}
+	err := c.conn.SetDeadline(time.Now().Add(replTimeout))
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.conn.Write(c.buf)
+	if err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// respReader reads the RESP replies.
+type respReader struct {
+	r *bufio.Reader
+}
+
+// read reads a single reply. Returns simple strings as strings,
+// integers as int64, bulk strings as byte slices, arrays as
+// slices of replies, nulls as nil and errors as errors.
+func (c *respReader) read() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("invalid reply: %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, errors.New(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			items[i], err = c.read()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("invalid reply: %q", line)
+	}
+}
+
+// discardWriter is a writer that discards the replies.
+type discardWriter struct{}
+
+func (discardWriter) WriteAny(v any)              {}
+func (discardWriter) WriteArray(count int)        {}
+func (discardWriter) WriteBulk(bulk []byte)       {}
+func (discardWriter) WriteBulkString(bulk string) {}
+func (discardWriter) WriteError(msg string)       {}
+func (discardWriter) WriteInt(num int)            {}
+func (discardWriter) WriteInt64(num int64)        {}
+func (discardWriter) WriteNull()                  {}
+func (discardWriter) WriteRaw(data []byte)        {}
+func (discardWriter) WriteString(str string)      {}
+func (discardWriter) WriteUint64(num uint64)      {}
@@ -0,0 +1,153 @@
+package redsrv
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestReplica(t *testing.T) {
+	pdb := testx.OpenDBWith(t, &redka.Options{ChangeLog: true})
+	rdb := testx.OpenReplicaDB(t)
+
+	// The snapshot contains the keys written before
+	// the replica connects and replaces the replica keys.
+	_ = pdb.Str().Set("name", "alice")
+	_, _ = pdb.List().PushBack("list", "a")
+	_, _ = pdb.List().PushBack("list", "b")
+	_ = rdb.Str().Set("stale", "value")
+
+	primary := startTestServer(t, pdb)
+	replica := startTestServer(t, rdb)
+	addr := primary.srv.Addr().(*net.TCPAddr)
+	replica.ReplicaOf(addr.IP.String(), addr.Port)
+
+	pconn := dialTestServer(t, primary)
+	rconn := dialTestServer(t, replica)
+
+	waitForReply(t, rconn, "alice", "get", "name")
+	be.Equal(t, doString(t, rconn, "get", "stale"), "(nil)")
+	be.Equal(t, doString(t, rconn, "lrange", "list", "0", "-1"), "[a b]")
+
+	t.Run("changes", func(t *testing.T) {
+		doString(t, pconn, "rpush", "list", "c")
+		doString(t, pconn, "hset", "person", "name", "bob")
+		doString(t, pconn, "del", "name")
+		waitForReply(t, rconn, "(nil)", "get", "name")
+		be.Equal(t, doString(t, rconn, "lrange", "list", "0", "-1"), "[a b c]")
+		be.Equal(t, doString(t, rconn, "hget", "person", "name"), "bob")
+	})
+	t.Run("flushdb", func(t *testing.T) {
+		doString(t, pconn, "flushdb")
+		doString(t, pconn, "set", "city", "paris")
+		waitForReply(t, rconn, "paris", "get", "city")
+		be.Equal(t, doString(t, rconn, "dbsize"), "1")
+	})
+	t.Run("readonly", func(t *testing.T) {
+		_, err := rconn.do("set", "name", "bob")
+		be.Equal(t, err.Error(), redis.ErrReadOnly.Error())
+
+		be.Equal(t, doString(t, rconn, "multi"), "OK")
+		_, err = rconn.do("del", "city")
+		be.Equal(t, err.Error(), redis.ErrReadOnly.Error())
+		be.Equal(t, doString(t, rconn, "get", "city"), "QUEUED")
+		be.Equal(t, doString(t, rconn, "exec"), "[paris]")
+	})
+	t.Run("role", func(t *testing.T) {
+		seq, _ := pdb.Changes().Last()
+		want := "[master " + strconv.FormatInt(seq, 10) + " []]"
+		be.Equal(t, doString(t, pconn, "role"), want)
+
+		want = fmt.Sprintf("[slave %s %d connected %d]", addr.IP, addr.Port, seq)
+		be.Equal(t, doString(t, rconn, "role"), want)
+	})
+}
+
+func TestReplicaNoPrimary(t *testing.T) {
+	rdb := testx.OpenReplicaDB(t)
+	replica := startTestServer(t, rdb)
+	replica.ReplicaOf("127.0.0.1", 1)
+
+	rconn := dialTestServer(t, replica)
+	be.Equal(t, doString(t, rconn, "role"), "[slave 127.0.0.1 1 connect 0]")
+	_, err := rconn.do("set", "name", "alice")
+	be.Equal(t, err.Error(), redis.ErrReadOnly.Error())
+}
+
+// startTestServer starts a server on a random loopback port
+// and stops it when the test ends.
+func startTestServer(t *testing.T, db *redka.DB) *Server {
+	t.Helper()
+	srv := New("tcp", "127.0.0.1:0", db)
+	ready := make(chan error, 1)
+	go func() { _ = srv.Start(ready) }()
+	if err := <-ready; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = srv.Stop() })
+	return srv
+}
+
+// dialTestServer connects to the server.
+func dialTestServer(t *testing.T, srv *Server) *respClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.srv.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return newRespClient(conn)
+}
+
+// doString sends the command and returns
+// the reply in a human-readable form.
+func doString(t *testing.T, c *respClient, args ...string) string {
+	t.Helper()
+	reply, err := c.do(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return formatReply(reply)
+}
+
+// formatReply formats the reply read by respClient.
+func formatReply(reply any) string {
+	switch v := reply.(type) {
+	case nil:
+		return "(nil)"
+	case []byte:
+		return string(v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatReply(item)
+		}
+		return "[" + strings.Join(items, " ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// waitForReply waits until the command returns the wanted reply.
+func waitForReply(t *testing.T, c *respClient, want string, args ...string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := doString(t, c, args...)
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("want %q, got %q", want, got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
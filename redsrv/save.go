@@ -0,0 +1,330 @@
+package redsrv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Default snapshot file name.
+const defaultDBFilename = "dump.db"
+
+// Snapshot scheduling settings.
+const (
+	// How often the saver checks the save rules.
+	saveCheckInterval = time.Second
+	// How long the saver waits before retrying
+	// a failed scheduled snapshot.
+	saveRetryDelay = 5 * time.Second
+)
+
+// saveRule is a snapshot rule: save the database
+// if at least changes keys changed in the last
+// seconds seconds (like Redis' save config).
+type saveRule struct {
+	seconds int
+	changes int
+}
+
+// saver writes database snapshots to disk
+// (the SAVE and BGSAVE commands). The snapshot
+// is written to the dbfilename file in the dir
+// directory (both are config parameters).
+//
+// If the save rules are set, the saver also writes
+// the snapshots in the background according to the
+// rules, and on shutdown.
+type saver struct {
+	db  *redka.DB
+	log *slog.Logger
+	wg  sync.WaitGroup
+
+	mu       sync.Mutex
+	dir      string
+	filename string
+	saving   bool
+	lastSave time.Time
+	lastTry  time.Time // time of the last snapshot attempt
+	lastErr  error     // error of the last snapshot attempt
+	rules    []saveRule
+	dirty    int    // number of changes since the last snapshot
+	cancel   func() // unregisters the change listener
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// newSaver creates a new saver. Considers the server
+// start time as the time of the last snapshot (like Redis).
+func newSaver(db *redka.DB) *saver {
+	return &saver{
+		db:       db,
+		log:      db.Log(),
+		dir:      ".",
+		filename: defaultDBFilename,
+		lastSave: time.Now(),
+	}
+}
+
+// Save writes a snapshot and waits for it to complete.
+// Fails if a background snapshot is in progress.
+func (s *saver) Save() error {
+	path, dirty, ok := s.begin()
+	if !ok {
+		return redis.ErrBgSaveInProgress
+	}
+	return s.save(path, dirty)
+}
+
+// BgSave starts writing a snapshot in the background.
+// Fails if a background snapshot is in progress.
+func (s *saver) BgSave() error {
+	path, dirty, ok := s.begin()
+	if !ok {
+		return redis.ErrBgSaveInProgress
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		_ = s.save(path, dirty)
+	}()
+	return nil
+}
+
+// LastSave returns the time of the last successful snapshot.
+func (s *saver) LastSave() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSave
+}
+
+// start starts writing the snapshots
+// according to the save rules.
+func (s *saver) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run(s.stop, s.done)
+}
+
+// shutdown stops writing the scheduled snapshots,
+// waits for the background snapshot to complete,
+// and writes the final snapshot if the save rules
+// are set.
+func (s *saver) shutdown() error {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	hasRules := len(s.rules) > 0
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	s.wait()
+	if !hasRules {
+		return nil
+	}
+	return s.Save()
+}
+
+// wait waits for the background snapshot to complete.
+func (s *saver) wait() {
+	s.wg.Wait()
+}
+
+// run writes the snapshots according to the save
+// rules until the stop channel is closed.
+func (s *saver) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(saveCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if s.due(now) {
+				_ = s.BgSave()
+			}
+		}
+	}
+}
+
+// due reports whether any of the save rules
+// requires a snapshot at the given time.
+func (s *saver) due(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saving || s.dirty == 0 {
+		return false
+	}
+	if s.lastErr != nil && now.Sub(s.lastTry) < saveRetryDelay {
+		// Don't retry the failed snapshot right away.
+		return false
+	}
+	elapsed := now.Sub(s.lastSave)
+	for _, rule := range s.rules {
+		if s.dirty >= rule.changes && elapsed >= time.Duration(rule.seconds)*time.Second {
+			return true
+		}
+	}
+	return false
+}
+
+// begin marks the start of a snapshot and returns the file path
+// and the number of changes since the last snapshot.
+// Returns false if another snapshot is in progress.
+func (s *saver) begin() (string, int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saving {
+		return "", 0, false
+	}
+	s.saving = true
+	s.lastTry = time.Now()
+	return filepath.Join(s.dir, s.filename), s.dirty, true
+}
+
+// save writes the snapshot to the file
+// and marks the end of the snapshot.
+func (s *saver) save(path string, dirty int) error {
+	start := time.Now()
+	err := s.db.Backup(context.Background(), path)
+
+	s.mu.Lock()
+	s.saving = false
+	s.lastErr = err
+	if err == nil {
+		s.lastSave = time.Now()
+		// The changes made during the snapshot
+		// may not be included, so keep them.
+		s.dirty -= dirty
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		s.log.Warn("save snapshot", "path", path, "err", err)
+		return err
+	}
+	s.log.Info("save snapshot", "path", path, "time", time.Since(start))
+	return nil
+}
+
+// getDir returns the snapshot directory.
+func (s *saver) getDir() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dir
+}
+
+// setDir sets the snapshot directory.
+// The directory must exist.
+func (s *saver) setDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", dir)
+	}
+	s.mu.Lock()
+	s.dir = dir
+	s.mu.Unlock()
+	return nil
+}
+
+// getFilename returns the snapshot file name.
+func (s *saver) getFilename() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.filename
+}
+
+// setFilename sets the snapshot file name.
+// The name can't be a path.
+func (s *saver) setFilename(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return errors.New("dbfilename can't be a path, just a filename")
+	}
+	s.mu.Lock()
+	s.filename = name
+	s.mu.Unlock()
+	return nil
+}
+
+// getRules returns the save rules in the
+// "seconds changes [seconds changes ...]" format.
+func (s *saver) getRules() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parts := make([]string, 0, len(s.rules)*2)
+	for _, rule := range s.rules {
+		parts = append(parts, strconv.Itoa(rule.seconds), strconv.Itoa(rule.changes))
+	}
+	return strings.Join(parts, " ")
+}
+
+// setRules sets the save rules in the
+// "seconds changes [seconds changes ...]" format.
+// An empty string disables the scheduled snapshots.
+// Counts the changes only while the rules are set.
+func (s *saver) setRules(value string) error {
+	rules, err := parseSaveRules(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = rules
+	if len(rules) > 0 && s.cancel == nil {
+		s.cancel = s.db.OnCommit(func(changes int) {
+			s.mu.Lock()
+			s.dirty += changes
+			s.mu.Unlock()
+		})
+	}
+	if len(rules) == 0 && s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+		s.dirty = 0
+	}
+	return nil
+}
+
+// parseSaveRules parses the save rules in the
+// "seconds changes [seconds changes ...]" format.
+func parseSaveRules(value string) ([]saveRule, error) {
+	fields := strings.Fields(value)
+	if len(fields)%2 != 0 {
+		return nil, errors.New("invalid save rules")
+	}
+	rules := make([]saveRule, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		seconds, err := strconv.Atoi(fields[i])
+		if err != nil || seconds < 1 {
+			return nil, errors.New("invalid save rules")
+		}
+		changes, err := strconv.Atoi(fields[i+1])
+		if err != nil || changes < 1 {
+			return nil, errors.New("invalid save rules")
+		}
+		rules = append(rules, saveRule{seconds: seconds, changes: changes})
+	}
+	return rules, nil
+}
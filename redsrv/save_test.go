@@ -0,0 +1,180 @@
+package redsrv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestSaver(t *testing.T) {
+	t.Run("save", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		_ = db.Str().Set("name", "alice")
+		saver := newSaver(db)
+		dir := t.TempDir()
+		be.Err(t, saver.setDir(dir), nil)
+		be.Err(t, saver.setFilename("snapshot.db"), nil)
+
+		start := saver.LastSave()
+		err := saver.Save()
+		if errors.Is(err, redka.ErrNotSupported) {
+			t.Skip("backup is not supported")
+		}
+		be.Err(t, err, nil)
+		be.Equal(t, saver.LastSave().After(start), true)
+
+		path := filepath.Join(dir, "snapshot.db")
+		sdb, err := redka.Open(path, &redka.Options{DriverName: "sqlite3"})
+		be.Err(t, err, nil)
+		defer sdb.Close()
+		name, _ := sdb.Str().Get("name")
+		be.Equal(t, name.String(), "alice")
+	})
+	t.Run("bgsave", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		saver := newSaver(db)
+		dir := t.TempDir()
+		be.Err(t, saver.setDir(dir), nil)
+
+		err := saver.BgSave()
+		be.Err(t, err, nil)
+		saver.wait()
+
+		_, err = os.Stat(filepath.Join(dir, defaultDBFilename))
+		if err != nil {
+			t.Skip("backup is not supported")
+		}
+	})
+	t.Run("in progress", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		saver := newSaver(db)
+		_, _, ok := saver.begin()
+		be.True(t, ok)
+		be.Err(t, saver.Save(), redis.ErrBgSaveInProgress)
+		be.Err(t, saver.BgSave(), redis.ErrBgSaveInProgress)
+	})
+	t.Run("config", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		saver := newSaver(db)
+		be.Err(t, saver.setDir(filepath.Join(t.TempDir(), "missing")))
+		be.Err(t, saver.setFilename("dir/snapshot.db"))
+		be.Err(t, saver.setFilename(""))
+		be.Equal(t, saver.getDir(), ".")
+		be.Equal(t, saver.getFilename(), defaultDBFilename)
+		be.Equal(t, time.Since(saver.LastSave()) < time.Second, true)
+	})
+}
+
+func TestSaverRules(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		saver := newSaver(db)
+		defer func() { _ = saver.setRules("") }()
+
+		be.Err(t, saver.setRules("3600 1 300 100"), nil)
+		be.Equal(t, saver.getRules(), "3600 1 300 100")
+		be.Err(t, saver.setRules(""), nil)
+		be.Equal(t, saver.getRules(), "")
+
+		be.Err(t, saver.setRules("3600"))
+		be.Err(t, saver.setRules("3600 one"))
+		be.Err(t, saver.setRules("0 1"))
+		be.Err(t, saver.setRules("60 0"))
+	})
+	t.Run("due", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		saver := newSaver(db)
+		be.Err(t, saver.setRules("60 1 10 2"), nil)
+		defer func() { _ = saver.setRules("") }()
+		now := saver.LastSave()
+
+		// No changes, nothing to save.
+		be.Equal(t, saver.due(now.Add(time.Hour)), false)
+
+		// One change, wait for the first rule.
+		_ = db.Str().Set("name", "alice")
+		be.Equal(t, saver.due(now.Add(30*time.Second)), false)
+		be.Equal(t, saver.due(now.Add(60*time.Second)), true)
+
+		// Two changes, the second rule applies.
+		_ = db.Str().Set("age", 25)
+		be.Equal(t, saver.due(now.Add(5*time.Second)), false)
+		be.Equal(t, saver.due(now.Add(10*time.Second)), true)
+
+		// Disabling the rules stops counting the changes.
+		be.Err(t, saver.setRules(""), nil)
+		be.Equal(t, saver.due(now.Add(time.Hour)), false)
+	})
+	t.Run("save resets changes", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		saver := newSaver(db)
+		be.Err(t, saver.setDir(t.TempDir()), nil)
+		be.Err(t, saver.setRules("1 1"), nil)
+		defer func() { _ = saver.setRules("") }()
+
+		_ = db.Str().Set("name", "alice")
+		err := saver.Save()
+		if errors.Is(err, redka.ErrNotSupported) {
+			t.Skip("backup is not supported")
+		}
+		be.Err(t, err, nil)
+		be.Equal(t, saver.due(time.Now().Add(time.Hour)), false)
+	})
+	t.Run("scheduled", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		saver := newSaver(db)
+		dir := t.TempDir()
+		be.Err(t, saver.setDir(dir), nil)
+		be.Err(t, saver.setRules("1 1"), nil)
+		saver.start()
+
+		_ = db.Str().Set("name", "alice")
+		path := filepath.Join(dir, defaultDBFilename)
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, err := os.Stat(path); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Skip("backup is not supported")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		be.Err(t, saver.shutdown(), nil)
+	})
+	t.Run("shutdown", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		_ = db.Str().Set("name", "alice")
+		saver := newSaver(db)
+		dir := t.TempDir()
+		be.Err(t, saver.setDir(dir), nil)
+		be.Err(t, saver.setRules("3600 1"), nil)
+		saver.start()
+
+		err := saver.shutdown()
+		if errors.Is(err, redka.ErrNotSupported) {
+			t.Skip("backup is not supported")
+		}
+		be.Err(t, err, nil)
+		_, err = os.Stat(filepath.Join(dir, defaultDBFilename))
+		be.Err(t, err, nil)
+	})
+	t.Run("shutdown without rules", func(t *testing.T) {
+		db := testx.OpenDB(t)
+		saver := newSaver(db)
+		dir := t.TempDir()
+		be.Err(t, saver.setDir(dir), nil)
+		saver.start()
+
+		be.Err(t, saver.shutdown(), nil)
+		_, err := os.Stat(filepath.Join(dir, defaultDBFilename))
+		be.Err(t, err, os.ErrNotExist)
+	})
+}
@@ -4,8 +4,11 @@ package redsrv
 import (
 	"fmt"
 	"log/slog"
+	"math"
+	"time"
 
 	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
 	"github.com/tidwall/redcon"
 )
 
@@ -17,23 +20,58 @@ import (
 //
 // To stop the server, call [Server.Stop] method.
 type Server struct {
-	net  string
-	addr string
-	srv  *redcon.Server
-	db   *redka.DB
-	log  *slog.Logger
+	net      string
+	addr     string
+	srv      *redcon.Server
+	db       *redka.DB
+	config   *Config
+	clients  *Clients
+	slowlog  *SlowLog
+	monitors *monitors
+	metrics  *Metrics
+	broker   *broker
+	repl     *replication
+	saver    *saver
+	batcher  *batcher
+	log      *slog.Logger
 }
 
 // New creates a new Redka server with the given
 // network, address and database. Does not start the server.
 func New(net string, addr string, db *redka.DB) *Server {
 	log := db.Log()
-	handler := createHandlers(db)
+	config := newConfig()
+	clients := newClients()
+	slowlog := newSlowLog()
+	monitors := newMonitors(log)
+	metrics := newMetrics(db, clients)
+	broker := newBroker(log)
+	broker.listen(db)
+	repl := newReplication(db)
+	saver := newSaver(db)
+	batcher := newBatcher()
+	registerConfig(config, db, slowlog, saver, batcher)
+	config.OnResetStat(metrics.reset)
+	state := srvState{
+		config:   config,
+		clients:  clients,
+		slowlog:  slowlog,
+		monitors: monitors,
+		metrics:  metrics,
+		broker:   broker,
+		repl:     repl,
+		saver:    saver,
+		batcher:  batcher,
+	}
+	repl.srv = state
+	handler := createHandlers(db, state)
 	accept := func(conn redcon.Conn) bool {
 		log.Info("accept connection", "client", conn.RemoteAddr())
+		getState(conn).client = clients.add(conn)
 		return true
 	}
 	closed := func(conn redcon.Conn, err error) {
+		clients.remove(conn)
 		if err != nil {
 			log.Debug("close connection", "client", conn.RemoteAddr(), "error", err)
 		} else {
@@ -41,19 +79,70 @@ func New(net string, addr string, db *redka.DB) *Server {
 		}
 	}
 	return &Server{
-		net:  net,
-		addr: addr,
-		srv:  redcon.NewServerNetwork(net, addr, handler, accept, closed),
-		db:   db,
-		log:  log,
+		net:      net,
+		addr:     addr,
+		srv:      redcon.NewServerNetwork(net, addr, handler, accept, closed),
+		db:       db,
+		config:   config,
+		clients:  clients,
+		slowlog:  slowlog,
+		monitors: monitors,
+		metrics:  metrics,
+		broker:   broker,
+		repl:     repl,
+		saver:    saver,
+		batcher:  batcher,
+		log:      log,
 	}
 }
 
+// Config returns the runtime configuration registry.
+// Use it to load the configuration file, register
+// application-specific parameters or change the values.
+func (s *Server) Config() *Config {
+	return s.config
+}
+
+// Clients returns the registry of connected clients.
+// Use it to list the clients or close their connections.
+func (s *Server) Clients() *Clients {
+	return s.clients
+}
+
+// SlowLog returns the log of the slow commands.
+func (s *Server) SlowLog() *SlowLog {
+	return s.slowlog
+}
+
+// Metrics returns the server metrics.
+// Serve them over HTTP to expose them to Prometheus.
+func (s *Server) Metrics() *Metrics {
+	return s.metrics
+}
+
+// ReplicaOf turns the server into a read-only replica
+// of the primary Redka server at the given host and port.
+// The primary must have the change log enabled
+// (see [redka.Options.ChangeLog]).
+//
+// The replica deletes all its keys, loads the primary
+// snapshot and then keeps applying the primary changes
+// until the server stops. It reconnects and loads a new
+// snapshot if the connection breaks or if it misses some
+// changes because the primary has trimmed its change log.
+//
+// The replica serves the read commands and rejects
+// the write commands with a READONLY error.
+func (s *Server) ReplicaOf(host string, port int) {
+	s.repl.replicaOf(host, port)
+}
+
 // Start starts the server.
 // If ready chan is not nil, sends a nil value when the server
 // is ready to accept connections, or an error if it fails to start.
 func (s *Server) Start(ready chan error) error {
 	s.log.Info("starting redcon server", "addr", s.addr)
+	s.saver.start()
 	err := s.srv.ListenServeAndSignal(ready)
 	if err != nil {
 		return fmt.Errorf("serve: %w", err)
@@ -67,6 +156,13 @@ func (s *Server) Stop() error {
 	if err != nil {
 		return fmt.Errorf("server close: %w", err)
 	}
+	s.repl.stop()
+	err = s.saver.shutdown()
+	if err != nil {
+		s.log.Error("save snapshot on shutdown", "err", err)
+	}
+	s.monitors.close()
+	s.broker.close()
 	s.log.Debug("redcon server stopped", "addr", s.addr)
 
 	err = s.db.Close()
@@ -77,3 +173,98 @@ func (s *Server) Stop() error {
 
 	return nil
 }
+
+// evictionPolicies are the values of the maxmemory-policy parameter.
+var evictionPolicies = []string{
+	string(redka.NoEviction), string(redka.AllKeysLRU), string(redka.VolatileLRU),
+	string(redka.AllKeysRandom), string(redka.VolatileRandom), string(redka.VolatileTTL),
+}
+
+// registerConfig registers the server configuration parameters.
+func registerConfig(config *Config, db *redka.DB, slowlog *SlowLog, saver *saver, batcher *batcher) {
+	config.Register(
+		ReadOnlyParam("databases", func() string {
+			return "1"
+		}),
+		IntParam("db-timeout", 1, 3_600_000,
+			func() int { return int(db.Timeout().Milliseconds()) },
+			func(ms int) { db.SetTimeout(time.Duration(ms) * time.Millisecond) },
+		),
+		StringParam("dbfilename", saver.getFilename, saver.setFilename),
+		StringParam("dir", saver.getDir, saver.setDir),
+		IntParam("expire-interval", 1, 86_400,
+			func() int { return int(db.ExpireInterval().Seconds()) },
+			func(sec int) { db.SetExpireInterval(time.Duration(sec) * time.Second) },
+		),
+		IntParam("maxbytes", 0, math.MaxInt,
+			func() int { return int(db.MaxBytes()) },
+			func(n int) { db.SetMaxBytes(int64(n)) },
+		),
+		IntParam("maxkeys", 0, math.MaxInt,
+			db.MaxKeys, db.SetMaxKeys,
+		),
+		EnumParam("maxmemory-policy", evictionPolicies,
+			func() string { return string(db.EvictionPolicy()) },
+			func(policy string) { _ = db.SetEvictionPolicy(redka.EvictionPolicy(policy)) },
+		),
+		StringParam("notify-keyspace-events",
+			db.NotifyEvents, db.SetNotifyEvents,
+		),
+		IntParam("pipeline-batch-size", 0, 1_000_000,
+			batcher.Size, batcher.SetSize,
+		),
+		StringParam("save", saver.getRules, saver.setRules),
+		IntParam("slowlog-log-slower-than", -1, math.MaxInt32,
+			func() int { return int(slowlog.Threshold().Microseconds()) },
+			func(us int) { slowlog.SetThreshold(time.Duration(us) * time.Microsecond) },
+		),
+		IntParam("slowlog-max-len", 0, math.MaxInt32,
+			slowlog.MaxLen, slowlog.SetMaxLen,
+		),
+		BoolParam("track-access", db.TrackAccess, db.SetTrackAccess),
+	)
+}
+
+// srvState provides access to the server state
+// for the server management commands.
+type srvState struct {
+	config   *Config
+	clients  *Clients
+	slowlog  *SlowLog
+	monitors *monitors
+	metrics  *Metrics
+	broker   *broker
+	repl     *replication
+	saver    *saver
+	batcher  *batcher
+}
+
+// Config returns the runtime configuration.
+func (s srvState) Config() redis.Config {
+	return s.config
+}
+
+// Clients returns the client registry.
+func (s srvState) Clients() redis.Clients {
+	return s.clients
+}
+
+// SlowLog returns the slow log.
+func (s srvState) SlowLog() redis.SlowLog {
+	return s.slowlog
+}
+
+// PubSub returns the message broker.
+func (s srvState) PubSub() redis.PubSub {
+	return s.broker
+}
+
+// Replication returns the replication state.
+func (s srvState) Replication() redis.Replication {
+	return s.repl
+}
+
+// Saver returns the snapshot writer.
+func (s srvState) Saver() redis.Saver {
+	return s.saver
+}
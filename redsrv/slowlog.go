@@ -0,0 +1,153 @@
+package redsrv
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Slow log defaults (same as in Redis).
+const (
+	defaultSlowLogThreshold = 10 * time.Millisecond
+	defaultSlowLogMaxLen    = 128
+)
+
+// Slow log entry limits (same as in Redis).
+const (
+	slowLogMaxArgs   = 32  // max number of arguments in an entry
+	slowLogMaxArgLen = 128 // max length of an argument in an entry
+)
+
+// SlowLogEntry describes a slow command.
+type SlowLogEntry = redis.SlowLogEntry
+
+// SlowLog is a bounded in-memory log of the commands
+// that took longer than the configured threshold.
+// When the log is full, the oldest entries are removed.
+//
+// SlowLog is safe for concurrent use by multiple goroutines.
+type SlowLog struct {
+	mu        sync.Mutex
+	entries   []SlowLogEntry // oldest first
+	lastID    int64
+	threshold time.Duration
+	maxLen    int
+}
+
+// newSlowLog creates an empty slow log with the default settings.
+func newSlowLog() *SlowLog {
+	return &SlowLog{
+		threshold: defaultSlowLogThreshold,
+		maxLen:    defaultSlowLogMaxLen,
+	}
+}
+
+// Entries returns up to count latest entries, newest first.
+// Returns all entries if count is negative.
+func (l *SlowLog) Entries(count int) []SlowLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if count < 0 || count > len(l.entries) {
+		count = len(l.entries)
+	}
+	entries := make([]SlowLogEntry, count)
+	for i := range count {
+		entries[i] = l.entries[len(l.entries)-1-i]
+	}
+	return entries
+}
+
+// Len returns the number of entries in the log.
+func (l *SlowLog) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.entries)
+}
+
+// Reset removes all entries from the log.
+func (l *SlowLog) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = nil
+}
+
+// Threshold returns the execution time that
+// makes a command slow. Negative means the log
+// is disabled, zero means every command is logged.
+func (l *SlowLog) Threshold() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.threshold
+}
+
+// SetThreshold changes the execution time that makes a command slow.
+// Negative value disables the log, zero logs every command.
+func (l *SlowLog) SetThreshold(threshold time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.threshold = threshold
+}
+
+// MaxLen returns the maximum number of entries in the log.
+func (l *SlowLog) MaxLen() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.maxLen
+}
+
+// SetMaxLen changes the maximum number of entries in the log.
+// Removes the oldest entries if the log is longer than maxLen.
+func (l *SlowLog) SetMaxLen(maxLen int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxLen = maxLen
+	l.trim()
+}
+
+// isSlow reports whether the command with
+// the given execution time should be logged.
+func (l *SlowLog) isSlow(dur time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.threshold >= 0 && dur >= l.threshold
+}
+
+// add adds an entry to the log and assigns it an ID.
+func (l *SlowLog) add(entry SlowLogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.ID = l.lastID
+	l.lastID++
+	l.entries = append(l.entries, entry)
+	l.trim()
+}
+
+// trim removes the oldest entries that exceed the max length.
+func (l *SlowLog) trim() {
+	if n := len(l.entries) - l.maxLen; n > 0 {
+		l.entries = append(l.entries[:0:0], l.entries[n:]...)
+	}
+}
+
+// slowLogArgs converts the command arguments for a slow log entry.
+// Truncates the long arguments and the long argument lists.
+func slowLogArgs(args [][]byte) []string {
+	n := min(len(args), slowLogMaxArgs)
+	strs := make([]string, n)
+	for i := range n {
+		if i == slowLogMaxArgs-1 && len(args) > slowLogMaxArgs {
+			strs[i] = fmt.Sprintf("... (%d more arguments)", len(args)-i)
+			break
+		}
+		arg := args[i]
+		if len(arg) > slowLogMaxArgLen {
+			strs[i] = fmt.Sprintf("%s... (%d more bytes)",
+				arg[:slowLogMaxArgLen], len(arg)-slowLogMaxArgLen)
+		} else {
+			strs[i] = string(arg)
+		}
+	}
+	return strs
+}
@@ -0,0 +1,115 @@
+package redsrv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestSlowLog(t *testing.T) {
+	t.Run("add", func(t *testing.T) {
+		slowlog := newSlowLog()
+		slowlog.add(SlowLogEntry{Args: []string{"get", "name"}})
+		slowlog.add(SlowLogEntry{Args: []string{"set", "name", "alice"}})
+		be.Equal(t, slowlog.Len(), 2)
+
+		entries := slowlog.Entries(-1)
+		be.Equal(t, len(entries), 2)
+		be.Equal(t, entries[0].ID, int64(1))
+		be.Equal(t, entries[0].Args, []string{"set", "name", "alice"})
+		be.Equal(t, entries[1].ID, int64(0))
+		be.Equal(t, entries[1].Args, []string{"get", "name"})
+
+		entries = slowlog.Entries(1)
+		be.Equal(t, len(entries), 1)
+		be.Equal(t, entries[0].ID, int64(1))
+	})
+	t.Run("max len", func(t *testing.T) {
+		slowlog := newSlowLog()
+		slowlog.SetMaxLen(3)
+		for range 5 {
+			slowlog.add(SlowLogEntry{})
+		}
+		entries := slowlog.Entries(-1)
+		be.Equal(t, len(entries), 3)
+		be.Equal(t, entries[0].ID, int64(4))
+		be.Equal(t, entries[2].ID, int64(2))
+
+		slowlog.SetMaxLen(1)
+		be.Equal(t, slowlog.Len(), 1)
+	})
+	t.Run("reset", func(t *testing.T) {
+		slowlog := newSlowLog()
+		slowlog.add(SlowLogEntry{})
+		slowlog.Reset()
+		be.Equal(t, slowlog.Len(), 0)
+		slowlog.add(SlowLogEntry{})
+		be.Equal(t, slowlog.Entries(-1)[0].ID, int64(1))
+	})
+	t.Run("threshold", func(t *testing.T) {
+		slowlog := newSlowLog()
+		be.Equal(t, slowlog.isSlow(time.Millisecond), false)
+		be.Equal(t, slowlog.isSlow(10*time.Millisecond), true)
+		slowlog.SetThreshold(0)
+		be.Equal(t, slowlog.isSlow(0), true)
+		slowlog.SetThreshold(-1)
+		be.Equal(t, slowlog.isSlow(time.Hour), false)
+	})
+}
+
+func TestSlowLogArgs(t *testing.T) {
+	t.Run("short", func(t *testing.T) {
+		args := slowLogArgs([][]byte{[]byte("get"), []byte("name")})
+		be.Equal(t, args, []string{"get", "name"})
+	})
+	t.Run("long argument", func(t *testing.T) {
+		value := bytes.Repeat([]byte("x"), slowLogMaxArgLen+10)
+		args := slowLogArgs([][]byte{[]byte("set"), []byte("name"), value})
+		be.Equal(t, args[2], strings.Repeat("x", slowLogMaxArgLen)+"... (10 more bytes)")
+	})
+	t.Run("many arguments", func(t *testing.T) {
+		cmd := [][]byte{[]byte("del")}
+		for range 40 {
+			cmd = append(cmd, []byte("key"))
+		}
+		args := slowLogArgs(cmd)
+		be.Equal(t, len(args), slowLogMaxArgs)
+		be.Equal(t, args[slowLogMaxArgs-1], "... (10 more arguments)")
+	})
+}
+
+func TestSlowLogTracking(t *testing.T) {
+	db := testx.OpenDB(t)
+	srv := newTestState(db)
+	mux := createHandlers(db, srv)
+	conn := new(fakeConn)
+	serve := func(args ...string) string {
+		conn.parts = nil
+		mux.ServeRESP(conn, buildCommand(args...))
+		return conn.out()
+	}
+
+	t.Run("fast", func(t *testing.T) {
+		serve("set", "name", "alice")
+		be.Equal(t, srv.slowlog.Len(), 0)
+	})
+	t.Run("slow", func(t *testing.T) {
+		srv.slowlog.SetThreshold(0)
+		serve("client", "setname", "api")
+		serve("get", "name")
+		entries := srv.slowlog.Entries(1)
+		be.Equal(t, len(entries), 1)
+		be.Equal(t, entries[0].Args, []string{"get", "name"})
+		be.Equal(t, entries[0].ClientName, "api")
+		be.True(t, entries[0].Query != "")
+	})
+	t.Run("command", func(t *testing.T) {
+		srv.slowlog.SetThreshold(-1)
+		srv.slowlog.Reset()
+		be.Equal(t, serve("slowlog", "len"), "0")
+	})
+}
@@ -0,0 +1,160 @@
+package redsrv
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+// Snapshot settings.
+const (
+	// Default number of commands in a snapshot page.
+	snapshotPageSize = 1000
+	// How long the primary keeps an unfinished snapshot
+	// after the last page request. The snapshot transaction
+	// blocks the writers of the in-memory SQLite databases,
+	// so the timeout is short.
+	snapshotTimeout = 10 * time.Second
+)
+
+// snapshots are the database snapshots being read in pages
+// (see [redis.Replication]). Each snapshot keeps its read
+// transaction open until the last page is read, so all
+// pages come from the same view of the database.
+type snapshots struct {
+	db     *redka.DB
+	mu     sync.Mutex
+	byID   map[int64]*snapshot
+	lastID int64
+}
+
+// newSnapshots creates an empty set of snapshots.
+func newSnapshots(db *redka.DB) *snapshots {
+	return &snapshots{db: db, byID: map[int64]*snapshot{}}
+}
+
+// page returns the next page of the snapshot, no more than
+// count commands. Cursor 0 starts a new snapshot.
+func (ss *snapshots) page(cursor int64, count int) (redis.SnapshotPage, error) {
+	if count == 0 {
+		count = snapshotPageSize
+	}
+	var snap *snapshot
+	if cursor == 0 {
+		cursor, snap = ss.start()
+	} else {
+		ss.mu.Lock()
+		snap = ss.byID[cursor]
+		ss.mu.Unlock()
+		if snap == nil {
+			return redis.SnapshotPage{}, redis.ErrInvalidCursor
+		}
+	}
+
+	cmds, done := snap.next(count)
+	if !done {
+		snap.timer.Reset(snapshotTimeout)
+		return redis.SnapshotPage{Cursor: cursor, Seq: snap.seq, Cmds: cmds}, nil
+	}
+	ss.remove(cursor)
+	if snap.err != nil {
+		return redis.SnapshotPage{}, snap.err
+	}
+	return redis.SnapshotPage{Cursor: 0, Seq: snap.seq, Cmds: cmds}, nil
+}
+
+// start starts a new snapshot and returns its cursor.
+func (ss *snapshots) start() (int64, *snapshot) {
+	ctx, cancel := context.WithCancel(context.Background())
+	snap := &snapshot{cmds: make(chan [][]byte), cancel: cancel}
+
+	ss.mu.Lock()
+	ss.lastID++
+	id := ss.lastID
+	// Cancel the snapshot if the reader goes away.
+	snap.timer = time.AfterFunc(snapshotTimeout, func() { ss.remove(id) })
+	ss.byID[id] = snap
+	ss.mu.Unlock()
+
+	go snap.run(ctx, ss.db)
+	return id, snap
+}
+
+// remove cancels the snapshot and forgets it.
+func (ss *snapshots) remove(id int64) {
+	ss.mu.Lock()
+	snap := ss.byID[id]
+	delete(ss.byID, id)
+	ss.mu.Unlock()
+	if snap != nil {
+		snap.timer.Stop()
+		snap.cancel()
+	}
+}
+
+// close cancels all the snapshots.
+func (ss *snapshots) close() {
+	ss.mu.Lock()
+	ids := make([]int64, 0, len(ss.byID))
+	for id := range ss.byID {
+		ids = append(ids, id)
+	}
+	ss.mu.Unlock()
+	for _, id := range ids {
+		ss.remove(id)
+	}
+}
+
+// snapshot is a database snapshot being read in pages.
+// The background goroutine reads the database and sends
+// the commands one by one, blocking until the reader
+// asks for the next page.
+type snapshot struct {
+	mu     sync.Mutex    // allows one page at a time
+	cmds   chan [][]byte // closed when the snapshot is complete
+	seq    int64         // set before the first command is sent
+	err    error         // set before cmds is closed
+	cancel context.CancelFunc
+	timer  *time.Timer
+}
+
+// run reads the snapshot in a single read transaction
+// and sends the commands until the context is canceled.
+func (s *snapshot) run(ctx context.Context, db *redka.DB) {
+	defer close(s.cmds)
+	s.err = db.ViewContext(ctx, func(tx *redka.Tx) error {
+		var err error
+		s.seq, err = tx.Changes().Last()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Snapshot(func(cmd [][]byte) error {
+			select {
+			case s.cmds <- cmd:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		return err
+	})
+}
+
+// next returns up to count commands.
+// Reports whether the snapshot is complete.
+func (s *snapshot) next(count int) ([][][]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var cmds [][][]byte
+	for len(cmds) < count {
+		cmd, ok := <-s.cmds
+		if !ok {
+			return cmds, true
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, false
+}
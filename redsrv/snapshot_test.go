@@ -0,0 +1,78 @@
+package redsrv
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+	"github.com/nalgeon/redka/redsrv/internal/redis"
+)
+
+func TestSnapshots(t *testing.T) {
+	t.Run("pages", func(t *testing.T) {
+		// The file database allows writing while
+		// the snapshot transaction is open.
+		db := testx.OpenFileDBWith(t, &redka.Options{ChangeLog: true})
+		_ = db.Str().Set("k1", "v1")
+		_ = db.Str().Set("k2", "v2")
+		_ = db.Str().Set("k3", "v3")
+		seq, _ := db.Changes().Last()
+
+		snaps := newSnapshots(db)
+		page, err := snaps.page(0, 2)
+		be.Err(t, err, nil)
+		be.True(t, page.Cursor != 0)
+		be.Equal(t, page.Seq, seq)
+		be.Equal(t, formatCmds(page.Cmds), []string{"set k1 v1", "set k2 v2"})
+
+		// The changes made after the snapshot
+		// has started are not included.
+		err = db.Str().Set("k4", "v4")
+		be.Err(t, err, nil)
+
+		page, err = snaps.page(page.Cursor, 2)
+		be.Err(t, err, nil)
+		be.Equal(t, page.Cursor, int64(0))
+		be.Equal(t, page.Seq, seq)
+		be.Equal(t, formatCmds(page.Cmds), []string{"set k3 v3"})
+	})
+	t.Run("single page", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{ChangeLog: true})
+		_ = db.Str().Set("name", "alice")
+
+		snaps := newSnapshots(db)
+		page, err := snaps.page(0, 0)
+		be.Err(t, err, nil)
+		be.Equal(t, page.Cursor, int64(0))
+		be.Equal(t, formatCmds(page.Cmds), []string{"set name alice"})
+	})
+	t.Run("invalid cursor", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{ChangeLog: true})
+		snaps := newSnapshots(db)
+		_, err := snaps.page(42, 10)
+		be.Err(t, err, redis.ErrInvalidCursor)
+	})
+	t.Run("close", func(t *testing.T) {
+		db := testx.OpenDBWith(t, &redka.Options{ChangeLog: true})
+		_ = db.Str().Set("k1", "v1")
+		_ = db.Str().Set("k2", "v2")
+
+		snaps := newSnapshots(db)
+		page, err := snaps.page(0, 1)
+		be.Err(t, err, nil)
+		snaps.close()
+		_, err = snaps.page(page.Cursor, 1)
+		be.Err(t, err, redis.ErrInvalidCursor)
+	})
+}
+
+// formatCmds formats the snapshot commands.
+func formatCmds(cmds [][][]byte) []string {
+	strs := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		strs[i] = string(bytes.Join(cmd, []byte(" ")))
+	}
+	return strs
+}
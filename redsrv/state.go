@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/nalgeon/redka"
 	"github.com/nalgeon/redka/redsrv/internal/redis"
 	"github.com/tidwall/redcon"
 )
@@ -25,8 +26,46 @@ func getState(conn redcon.Conn) *connState {
 
 // connState represents the connection state.
 type connState struct {
-	inMulti bool
-	cmds    []redis.Cmd
+	client    *client
+	inMulti   bool
+	cmds      []redis.Cmd
+	monitored []string     // MONITOR lines for the commands queued in MULTI
+	db        *redka.DB    // database that records statements in the trace
+	trace     *redka.Trace // SQL statements of the current command
+	tx        *redka.Tx    // transaction of the current pipeline batch
+	effects   []func()     // side effects of the batch commands (see [connState.afterCommit])
+	skip      int          // number of the next commands already executed in a batch
+}
+
+// clientID returns the ID of the connected client.
+func (s *connState) clientID() int64 {
+	if s.client == nil {
+		return 0
+	}
+	return s.client.id
+}
+
+// traced returns the database that records the executed
+// SQL statements in the connection trace.
+func (s *connState) traced(db *redka.DB) *redka.DB {
+	if s.db == nil {
+		s.trace = new(redka.Trace)
+		s.db = db.WithTrace(s.trace)
+	}
+	return s.db
+}
+
+// afterCommit runs f right away, or after the pipeline batch
+// commits if the command is executed as part of a batch
+// (see [runBatch]). Use it for the side effects (like metrics
+// or the slow log) that should not report the commands
+// of a failed batch.
+func (s *connState) afterCommit(f func()) {
+	if s.tx == nil {
+		f()
+		return
+	}
+	s.effects = append(s.effects, f)
 }
 
 // push adds a command to the state.
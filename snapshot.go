@@ -0,0 +1,160 @@
+package redka
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/nalgeon/redka/internal/core"
+)
+
+// Snapshot calls f with the commands that recreate the
+// current database contents, one or more commands per key
+// (e.g. SET name alice, or SADD key a b c followed
+// by PEXPIREAT key 1700000000000).
+//
+// The snapshot is taken in a single read transaction,
+// so it is consistent with the change log. Returns the
+// sequence number of the last change included in the
+// snapshot (see [DB.Changes]). Applying the snapshot to an
+// empty database and then replaying the changes after the
+// returned sequence number reproduces the database.
+//
+// Stops and returns the error if f returns an error.
+func (db *DB) Snapshot(f func(cmd [][]byte) error) (seq int64, err error) {
+	err = db.View(func(tx *Tx) error {
+		var err error
+		seq, err = tx.Snapshot(f)
+		return err
+	})
+	return seq, err
+}
+
+// Snapshot calls f with the commands that recreate the
+// database contents as seen by the transaction.
+// Returns the sequence number of the last change
+// included in the snapshot. See [DB.Snapshot] for details.
+func (tx *Tx) Snapshot(f func(cmd [][]byte) error) (seq int64, err error) {
+	seq, err = tx.Changes().Last()
+	if err != nil {
+		return 0, err
+	}
+	scanner := tx.Key().Scanner("*", core.TypeAny, 0)
+	for scanner.Scan() {
+		cmds, err := snapshotKey(tx, scanner.Key())
+		if err != nil {
+			return 0, err
+		}
+		for _, cmd := range cmds {
+			if err := f(cmd); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+// snapshotKey returns the commands that recreate the key.
+// Returns no commands if the key has no value (e.g. it has
+// expired since the scan).
+func snapshotKey(tx *Tx, key core.Key) ([][][]byte, error) {
+	var cmd [][]byte
+	switch key.Type {
+	case core.TypeString:
+		val, err := tx.Str().Get(key.Key)
+		if err == core.ErrNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		cmd = snapshotCmd("set", key.Key, val.Bytes())
+		if key.ETime != nil {
+			// Strings set the expiration time along with the value.
+			cmd = append(cmd, []byte("pxat"), strconv.AppendInt(nil, *key.ETime, 10))
+			return [][][]byte{cmd}, nil
+		}
+
+	case core.TypeList:
+		vals, err := tx.List().Range(key.Key, 0, -1)
+		if err != nil {
+			return nil, err
+		}
+		// RPUSH only accepts a single element.
+		cmds := make([][][]byte, 0, len(vals)+1)
+		for _, val := range vals {
+			cmds = append(cmds, snapshotCmd("rpush", key.Key, val.Bytes()))
+		}
+		if len(cmds) > 0 && key.ETime != nil {
+			etime := strconv.AppendInt(nil, *key.ETime, 10)
+			cmds = append(cmds, snapshotCmd("pexpireat", key.Key, etime))
+		}
+		return cmds, nil
+
+	case core.TypeSet:
+		vals, err := tx.Set().Items(key.Key)
+		if err != nil {
+			return nil, err
+		}
+		cmd = snapshotCmd("sadd", key.Key)
+		for _, val := range vals {
+			cmd = append(cmd, val.Bytes())
+		}
+
+	case core.TypeHash:
+		items, err := tx.Hash().Items(key.Key)
+		if err != nil {
+			return nil, err
+		}
+		fields := make([]string, 0, len(items))
+		for field := range items {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+		cmd = snapshotCmd("hset", key.Key)
+		for _, field := range fields {
+			cmd = append(cmd, []byte(field), items[field].Bytes())
+		}
+
+	case core.TypeZSet:
+		n, err := tx.ZSet().Len(key.Key)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		items, err := tx.ZSet().Range(key.Key, 0, n-1)
+		if err != nil {
+			return nil, err
+		}
+		cmd = snapshotCmd("zadd", key.Key)
+		for _, item := range items {
+			score := strconv.FormatFloat(item.Score, 'f', -1, 64)
+			cmd = append(cmd, []byte(score), item.Elem.Bytes())
+		}
+
+	default:
+		return nil, nil
+	}
+
+	if len(cmd) == 2 {
+		// The key has no elements.
+		return nil, nil
+	}
+	cmds := [][][]byte{cmd}
+	if key.ETime != nil {
+		etime := strconv.AppendInt(nil, *key.ETime, 10)
+		cmds = append(cmds, snapshotCmd("pexpireat", key.Key, etime))
+	}
+	return cmds, nil
+}
+
+// snapshotCmd creates a command from the name and arguments.
+func snapshotCmd(name string, key string, args ...[]byte) [][]byte {
+	cmd := make([][]byte, 0, 2+len(args))
+	cmd = append(cmd, []byte(name), []byte(key))
+	return append(cmd, args...)
+}
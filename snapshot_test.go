@@ -0,0 +1,59 @@
+package redka_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/nalgeon/redka"
+	"github.com/nalgeon/redka/internal/testx"
+)
+
+func TestSnapshot(t *testing.T) {
+	db := testx.OpenDBWith(t, &redka.Options{ChangeLog: true})
+	at := time.UnixMilli(4102444800000)
+	_, _ = db.Str().SetWith("name", "alice").At(at).Run()
+	_ = db.Str().Set("age", 25)
+	_, _ = db.List().PushBack("list", "a")
+	_, _ = db.List().PushBack("list", "b")
+	_ = db.Key().ExpireAt("list", at)
+	_, _ = db.Set().Add("set", "a")
+	_, _ = db.Hash().Set("hash", "f2", "v2")
+	_, _ = db.Hash().Set("hash", "f1", "v1")
+	_, _ = db.ZSet().Add("zset", "b", 2.5)
+	_, _ = db.ZSet().Add("zset", "a", 1)
+
+	var got []string
+	seq, err := db.Snapshot(func(cmd [][]byte) error {
+		args := make([]string, len(cmd))
+		for i, arg := range cmd {
+			args[i] = string(arg)
+		}
+		got = append(got, strings.Join(args, " "))
+		return nil
+	})
+	be.Err(t, err, nil)
+
+	last, _ := db.Changes().Last()
+	be.Equal(t, seq, last)
+	slices.Sort(got)
+	be.Equal(t, got, []string{
+		"hset hash f1 v1 f2 v2",
+		"pexpireat list 4102444800000",
+		"rpush list a",
+		"rpush list b",
+		"sadd set a",
+		"set age 25",
+		"set name alice pxat 4102444800000",
+		"zadd zset 1 a 2.5 b",
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errStop := errors.New("stop")
+		_, err := db.Snapshot(func(cmd [][]byte) error { return errStop })
+		be.Equal(t, err, errStop)
+	})
+}